@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// benchResult é a medição de uma etapa do comando bench.
+type benchResult struct {
+	stage   string
+	records int
+	elapsed time.Duration
+	mallocs uint64
+	bytes   uint64
+}
+
+func (r benchResult) String() string {
+	perRecord := func(v uint64) float64 { return float64(v) / float64(max(r.records, 1)) }
+	return fmt.Sprintf("%-10s %10d %12v %14.0f %14.1f %12.0f", r.stage, r.records, r.elapsed.Round(time.Millisecond),
+		float64(r.records)/r.elapsed.Seconds(), perRecord(r.mallocs), perRecord(r.bytes))
+}
+
+// measureStage executa a etapa e mede o tempo e as alocações do heap. run retorna o número de
+// registros processados.
+func measureStage(stage string, run func() (int, error)) (benchResult, error) {
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	records, err := run()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		return benchResult{}, fmt.Errorf("erro na etapa %s: %w", stage, err)
+	}
+	return benchResult{stage, records, elapsed, after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc}, nil
+}
+
+// runBench implementa o comando bench: gera uma carga sintética reproduzível e reporta a vazão
+// (registros/s) e as alocações por registro do parser, do gerador e dos codificadores. Retorna o
+// código de saída do processo: 0 com a geração acima de -min-rate, 1 abaixo e 2 em erro.
+func runBench(args []string) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	devices := flags.Int("devices", 10, "dispositivos da frota sintética")
+	days := flags.Int("days", 365, "dias de clima sintético horário")
+	seed := flags.Int64("seed", 42, "semente do simulador")
+	formats := flags.String("formats", "json,arrow,orc,sqlite", "formatos de saída medidos, separados por vírgula (vazio: nenhum)")
+	climateFile := flags.String("climate", "", "arquivo do INMET (.csv ou .zip) para medir o parser (vazio: pula)")
+	var profile profiling
+	flags.StringVar(&profile.cpuProfile, "cpuprofile", "", "grava o perfil de CPU das medições neste arquivo")
+	flags.StringVar(&profile.memProfile, "memprofile", "", "grava o perfil de heap do fim das medições neste arquivo")
+	minRate := flags.Float64("min-rate", 0, "vazão mínima da geração (registros/s); abaixo dela o comando sai com código 1")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Uso: mock-generator bench [opções]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 || *devices <= 0 || *days <= 0 {
+		flags.Usage()
+		return 2
+	}
+
+	stopProfiling, err := profile.start()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return 2
+	}
+	defer stopProfiling()
+
+	fleet := make([]hvac.Device, *devices)
+	for i := range fleet {
+		fleet[i] = hvac.Device{ID: fmt.Sprintf("SALA-%d", i+1)}
+	}
+	climateData := climate.Synthetic(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), *days)
+	var results []benchResult
+
+	if *climateFile != "" {
+		log.SetOutput(io.Discard) // Os avisos das linhas sem medição do INMET poluiriam a tabela
+		result, err := measureStage("parser", func() (int, error) {
+			data, err := climate.ReadInmetCSV(*climateFile)
+			return len(data), err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+			return 2
+		}
+		results = append(results, result)
+	}
+
+	var data []hvac.HvacSensorData
+	generation, err := measureStage("geração", func() (int, error) {
+		simulator, err := hvac.NewSimulator(hvac.SimulatorConfig{Devices: fleet, Seed: *seed})
+		if err != nil {
+			return 0, err
+		}
+		for _, record := range climateData {
+			data = append(data, simulator.Step(record)...)
+		}
+		return len(data), nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return 2
+	}
+	results = append(results, generation)
+
+	renderer, err := hvac.NewPayloadRenderer(hvac.PayloadConfig{}, fleet)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao criar o renderizador de payloads: %v\n", err)
+		return 2
+	}
+	for _, format := range strings.Split(*formats, ",") {
+		if format = strings.TrimSpace(format); format == "" {
+			continue
+		}
+		result, err := measureStage(format, func() (int, error) {
+			_, err := hvac.WriteOutput(hvac.OutputConfig{Format: format}, renderer, data)
+			return len(data), err
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+			return 2
+		}
+		results = append(results, result)
+	}
+
+	fmt.Printf("%-10s %10s %12s %14s %14s %12s\n", "etapa", "registros", "tempo", "registros/s", "alocações/reg", "bytes/reg")
+	for _, result := range results {
+		fmt.Println(result)
+	}
+	if rate := float64(generation.records) / generation.elapsed.Seconds(); rate < *minRate {
+		fmt.Printf("Geração abaixo da vazão mínima: %.0f registros/s, mínimo %.0f.\n", rate, *minRate)
+		return 1
+	}
+	return 0
+}
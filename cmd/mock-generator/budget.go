@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+	"github.com/patrik-rangel/mock-data-hvac/internal/config"
+	"github.com/patrik-rangel/mock-data-hvac/internal/encryption"
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// parseByteSize interpreta tamanhos como 512MiB, 400MB, 1G ou 1073741824 (bytes). Os sufixos
+// decimais (KB, MB, GB) usam potências de 1000 e os binários (KiB, MiB, GiB, ou só K, M, G),
+// potências de 1024.
+func parseByteSize(raw string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+		{"KB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1},
+	}
+	multiplier := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("tamanho '%s' inválido (ex: 512MiB, 400MB, 1G)", raw)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// recordMemoryBytes é a memória estimada de um registro em trânsito entre a geração e a codificação.
+const recordMemoryBytes = 2048
+
+// spillCapacity dimensiona o canal entre a geração e a codificação para que os passos em trânsito
+// ocupem no máximo 1/16 do orçamento de memória.
+func spillCapacity(budget int64, recordsPerStep int) int {
+	perStep := int64(max(recordsPerStep, 1)) * recordMemoryBytes
+	return int(min(max(budget/16/perStep, 1), 1024))
+}
+
+// dataSpill grava o arquivo principal de dados em JSON durante a geração, no modo com orçamento
+// de memória. O laço de geração envia os registros de cada passo por um canal limitado, e a
+// contrapressão segura o gerador quando a codificação atrasa; a codificação grava em disco, em vez
+// de montar o arquivo inteiro em memória.
+type dataSpill struct {
+	buffers *hvac.RecordPool // Os buffers dos passos voltam ao pool depois de codificados
+	steps   chan []hvac.HvacSensorData
+	done    chan error
+	file    *os.File
+	local   bool // O arquivo já é a saída final em Output.LocalDir
+}
+
+// startDataSpill abre o arquivo (o final em Output.LocalDir ou um temporário, enviado ao bucket ao
+// fim) e inicia a etapa de codificação. Com encryptionKey, o arquivo final em Output.LocalDir é
+// gravado cifrado; o temporário é cifrado pelo Uploader no envio.
+func startDataSpill(output hvac.OutputConfig, renderer *hvac.PayloadRenderer, buffers *hvac.RecordPool, fileName string, capacity int, encryptionKey []byte) (*dataSpill, error) {
+	var file *os.File
+	var err error
+	if output.LocalDir != "" {
+		if err := os.MkdirAll(output.LocalDir, 0o755); err != nil {
+			return nil, fmt.Errorf("erro ao criar o diretório de saída '%s': %w", output.LocalDir, err)
+		}
+		file, err = os.Create(filepath.Join(output.LocalDir, fileName))
+	} else {
+		file, err = os.CreateTemp("", "hvac_mock_data_*.json")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o arquivo de dados em disco: %w", err)
+	}
+	var out io.Writer = file
+	var sealer *encryption.Writer
+	if output.LocalDir != "" && encryptionKey != nil {
+		sealer, err = encryption.NewWriter(file, encryptionKey)
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return nil, err
+		}
+		out = sealer
+	}
+	stream, err := renderer.NewJSONStream(out)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	spill := &dataSpill{
+		buffers: buffers,
+		steps:   make(chan []hvac.HvacSensorData, capacity),
+		done:    make(chan error, 1),
+		file:    file,
+		local:   output.LocalDir != "",
+	}
+	go func() {
+		var err error
+		for records := range spill.steps {
+			if err == nil {
+				err = stream.Write(records)
+			}
+			spill.buffers.Put(records)
+		}
+		if err == nil {
+			err = stream.Close()
+		}
+		if err == nil && sealer != nil {
+			err = sealer.Close()
+		}
+		spill.done <- err
+	}()
+	return spill, nil
+}
+
+// send entrega os registros de um passo à codificação, bloqueando com o canal cheio. O buffer passa
+// a ser da codificação, que o devolve ao pool: os registros não podem ser lidos depois do envio.
+func (s *dataSpill) send(records []hvac.HvacSensorData) {
+	s.steps <- records
+}
+
+// finish espera a codificação dos registros enviados e volta o arquivo ao início, para o upload.
+func (s *dataSpill) finish() error {
+	close(s.steps)
+	if err := <-s.done; err != nil {
+		return err
+	}
+	if _, err := s.file.Seek(0, 0); err != nil {
+		return fmt.Errorf("erro ao reler o arquivo de dados em disco: %w", err)
+	}
+	return nil
+}
+
+// close fecha o arquivo e remove o temporário.
+func (s *dataSpill) close() {
+	s.file.Close()
+	if !s.local {
+		os.Remove(s.file.Name())
+	}
+}
+
+// fullDataConsumers lista as saídas habilitadas que precisam de todos os registros em memória. No
+// modo com orçamento de memória, os registros vão para o disco durante a geração e não são
+// acumulados, e essas saídas não têm de onde lê-los. sinks indica os destinos configurados por
+// variável de ambiente, pelo nome da variável.
+func fullDataConsumers(scenario config.Scenario, sinks map[string]bool) []string {
+	var consumers []string
+	for _, name := range slices.Sorted(maps.Keys(sinks)) {
+		if sinks[name] {
+			consumers = append(consumers, name)
+		}
+	}
+	for _, export := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"edge", scenario.Edge != nil},
+		{"rollups", scenario.Rollups != nil},
+		{"trendLogs", scenario.TrendLogs != nil},
+		{"mlDataset", scenario.MLDataset != nil},
+		{"features", scenario.Features != nil},
+		{"greenButton", scenario.GreenButton != nil},
+		{"shadows", scenario.Shadows != nil},
+		{"badges", scenario.Badges != nil},
+	} {
+		if export.enabled {
+			consumers = append(consumers, export.name)
+		}
+	}
+	return consumers
+}
+
+// generatedData é o resultado do laço de geração.
+type generatedData struct {
+	records []hvac.HvacSensorData        // Todos os registros; nil no modo com orçamento de memória
+	latest  []hvac.HvacSensorData        // Modo com orçamento de memória: última leitura de cada dispositivo
+	sensors []hvac.WirelessSensorReading // Leituras dos sensores sem fio
+	count   int                          // Registros gerados
+}
+
+// generateData roda a simulação sobre a série climática: a frota inteira a cada passo ou, com
+// sampled, uma leitura isolada de uma sala sorteada (a saída original do gerador). Sem spill, os
+// registros são acumulados em records, com capacity reservada; com spill, cada passo vai para o
+// arquivo em disco sem ser acumulado, e só as últimas leituras de cada dispositivo ficam em
+// memória. runID, quando não vazio, é gravado em cada registro.
+func generateData(simulator *hvac.Simulator, sampled bool, climateRecords []climate.InmetClimateData, buffers *hvac.RecordPool, spill *dataSpill, precision *hvac.Precision, runID string, capacity int) generatedData {
+	var data generatedData
+	if spill == nil {
+		data.records = make([]hvac.HvacSensorData, 0, capacity)
+	}
+	generator := &hvac.Generator{Simulator: simulator}
+	for _, record := range climateRecords {
+		var records []hvac.HvacSensorData
+		if sampled {
+			records = append(buffers.Get(), generator.Generate(record))
+		} else {
+			records = simulator.StepInto(buffers.Get(), record)
+		}
+		readings := simulator.SensorReadings()
+		if precision != nil {
+			precision.Apply(records)
+			precision.ApplySensors(readings)
+		}
+		if runID != "" {
+			for i := range records {
+				records[i].RunId = runID
+			}
+		}
+		data.count += len(records)
+		data.sensors = append(data.sensors, readings...)
+		if spill != nil {
+			data.latest = hvac.LatestReadings(append(data.latest, records...))
+			spill.send(records)
+		} else {
+			data.records = append(data.records, records...)
+			buffers.Put(records) // Já copiados para records
+		}
+	}
+	return data
+}
+
+// latestReadings retorna a última leitura de cada dispositivo (ver hvac.LatestReadings).
+func (d generatedData) latestReadings() []hvac.HvacSensorData {
+	if d.records == nil {
+		return d.latest
+	}
+	return hvac.LatestReadings(d.records)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+	"github.com/patrik-rangel/mock-data-hvac/internal/config"
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+func TestGenerateDataSpillDoesNotAccumulate(t *testing.T) {
+	climateRecords := climate.Synthetic(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), 2)
+	devices := hvac.DefaultDevices()
+	newSimulator := func() *hvac.Simulator {
+		simulator, err := hvac.NewSimulator(hvac.SimulatorConfig{Seed: 7})
+		if err != nil {
+			t.Fatalf("NewSimulator: %v", err)
+		}
+		return simulator
+	}
+	renderer, err := hvac.NewPayloadRenderer(hvac.PayloadConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffers hvac.RecordPool
+	spill, err := startDataSpill(hvac.OutputConfig{LocalDir: t.TempDir()}, renderer, &buffers, "dados.json", 4, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spill.close()
+	spilled := generateData(newSimulator(), false, climateRecords, &buffers, spill, nil, "run-1", len(climateRecords)*len(devices))
+	if spilled.records != nil {
+		t.Fatalf("o modo com orçamento de memória acumulou %d registros", len(spilled.records))
+	}
+	if err := spill.finish(); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(spill.file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written []map[string]any
+	if err := json.Unmarshal(raw, &written); err != nil {
+		t.Fatalf("arquivo em disco não é um array JSON: %v", err)
+	}
+	if want := len(climateRecords) * len(devices); spilled.count != want || len(written) != want {
+		t.Fatalf("gerados %d e gravados %d registros, esperado %d", spilled.count, len(written), want)
+	}
+
+	// As últimas leituras acompanhadas durante a geração são as mesmas calculadas sobre tudo
+	inMemory := generateData(newSimulator(), false, climateRecords, &buffers, nil, nil, "run-1", 0)
+	if len(inMemory.records) != spilled.count {
+		t.Fatalf("sem orçamento, acumulados %d registros, esperado %d", len(inMemory.records), spilled.count)
+	}
+	latest, want := spilled.latestReadings(), inMemory.latestReadings()
+	if len(latest) != len(devices) {
+		t.Fatalf("%d últimas leituras, esperado uma por dispositivo (%d)", len(latest), len(devices))
+	}
+	for i := range latest {
+		if latest[i].DeviceId != want[i].DeviceId || !latest[i].Timestamp.Equal(want[i].Timestamp) || latest[i].RunId != "run-1" {
+			t.Errorf("última leitura %d: %s em %v, esperado %s em %v", i, latest[i].DeviceId, latest[i].Timestamp, want[i].DeviceId, want[i].Timestamp)
+		}
+	}
+}
+
+func TestFullDataConsumers(t *testing.T) {
+	if consumers := fullDataConsumers(config.Scenario{PointCatalog: true}, map[string]bool{"REDIS_URL": false}); len(consumers) != 0 {
+		t.Errorf("sem saídas que leem todos os registros, recebido %v", consumers)
+	}
+	scenario := config.Scenario{Rollups: &hvac.RollupConfig{}, Badges: &hvac.BadgeConfig{}}
+	got := fullDataConsumers(scenario, map[string]bool{"REDIS_URL": true, "MONGODB_URI": true, "NATS_URL": false})
+	if want := []string{"MONGODB_URI", "REDIS_URL", "rollups", "badges"}; !slices.Equal(got, want) {
+		t.Errorf("fullDataConsumers = %v, esperado %v", got, want)
+	}
+}
+
+func TestGenerateDataDefaultScenarioKeepsOneReadingPerHour(t *testing.T) {
+	if (config.Scenario{Seed: 7}).SimulatesFleet() {
+		t.Fatal("cenário só com a semente simula a frota, esperado uma leitura por hora")
+	}
+	if !(config.Scenario{Precooling: &hvac.PrecoolingConfig{}}).SimulatesFleet() {
+		t.Fatal("cenário com precooling não simula a frota")
+	}
+
+	climateRecords := climate.Synthetic(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), 2)
+	simulator, err := hvac.NewSimulator(hvac.SimulatorConfig{Seed: 7})
+	if err != nil {
+		t.Fatalf("NewSimulator: %v", err)
+	}
+	var buffers hvac.RecordPool
+	generated := generateData(simulator, true, climateRecords, &buffers, nil, nil, "", len(climateRecords))
+	if len(generated.records) != len(climateRecords) {
+		t.Fatalf("gerados %d registros, esperado um por hora (%d)", len(generated.records), len(climateRecords))
+	}
+	for i, record := range generated.records {
+		if !record.Timestamp.Equal(climateRecords[i].Timestamp) {
+			t.Errorf("registro %d em %v, esperado %v", i, record.Timestamp, climateRecords[i].Timestamp)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/patrik-rangel/mock-data-hvac/internal/config"
+	"github.com/patrik-rangel/mock-data-hvac/internal/secrets"
+)
+
+// stringList acumula os valores de uma flag repetível, como --set.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// loadDotEnv carrega o arquivo .env do diretório atual, se houver. As variáveis já definidas no
+// ambiente têm precedência sobre as do arquivo.
+func loadDotEnv() {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Aviso: não foi possível carregar o arquivo .env: %v", err)
+	}
+}
+
+// runConfig implementa o comando config. O subcomando print mostra o valor efetivo de cada opção
+// do cenário e das variáveis de execução, e a camada de onde ele veio, com as mesmas --scenario e
+// --set da geração. Retorna o código de saída do processo: 0 com sucesso e 2 em erro.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "Uso: mock-generator config print [--scenario arquivo] [--set caminho=valor]...")
+		return 2
+	}
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	scenarioFile := flags.String("scenario", "", "arquivo JSON de cenário (padrão: SCENARIO_FILE)")
+	var sets stringList
+	flags.Var(&sets, "set", "ajusta uma opção do cenário (ex: --set output.format=parquet); repetível")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	loadDotEnv()
+	path := cmp.Or(*scenarioFile, config.Getenv("SCENARIO_FILE"))
+	_, settings, err := config.Resolve(path, sets)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return 2
+	}
+
+	fmt.Println("Cenário (padrão < arquivo < ambiente HVACMOCK_ < --set):")
+	for _, setting := range settings {
+		value := setting.Value
+		switch {
+		case setting.Disabled:
+			value = "(desativado)"
+		case setting.Enabled:
+			value = "(ativado)"
+		case value == "":
+			value = "(padrão)"
+		}
+		fmt.Printf("  %-42s %-40s %s\n", setting.Key, truncateExcerpt(value, 40), setting.Source)
+	}
+
+	fmt.Println("Execução (padrão < ambiente sem prefixo < ambiente HVACMOCK_):")
+	for _, name := range config.RuntimeEnv {
+		value, variable := config.LookupEnv(name)
+		source := config.SourceDefault
+		if variable != "" {
+			source = config.SourceEnv + " " + variable
+		}
+		if name == "SCENARIO_FILE" && *scenarioFile != "" {
+			value, source = *scenarioFile, "flag --scenario"
+		}
+		if value == "" {
+			value = "(vazio)"
+		}
+		fmt.Printf("  %-42s %-40s %s\n", name, truncateExcerpt(maskSecret(name, value), 40), source)
+	}
+	return 0
+}
+
+// maskSecret esconde as senhas e chaves e a senha embutida nas URLs de conexão. As referências a
+// segredos (ver o pacote secrets) aparecem como estão, já que não trazem a credencial.
+func maskSecret(name, value string) string {
+	if value == "(vazio)" || secrets.IsReference(value) {
+		return value
+	}
+	if strings.Contains(name, "PASSWORD") || strings.Contains(name, "KEY") || strings.Contains(name, "SECRET") {
+		return "****"
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return value
+}
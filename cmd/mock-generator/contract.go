@@ -0,0 +1,93 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/config"
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// contractIndexEntry descreve uma fixture no índice gravado junto com os payloads.
+type contractIndexEntry struct {
+	Name        string `json:"name"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	File        string `json:"file"`
+}
+
+// runContractFixtures implementa o comando contract-fixtures: grava as fixtures de contrato do
+// esquema atual (ver hvac.ContractFixtures) como um payload JSON por arquivo, em
+// <saída>/<categoria>/<nome>.json, e o índice em <saída>/index.json. Os códigos de
+// faultModel.codes e a representação dos campos ausentes vêm do cenário. Retorna o código de saída
+// do processo: 0 com sucesso e 2 em erro.
+func runContractFixtures(args []string) int {
+	flags := flag.NewFlagSet("contract-fixtures", flag.ContinueOnError)
+	outDir := flags.String("out", "contract-fixtures", "diretório onde as fixtures são gravadas")
+	scenarioFile := flags.String("scenario", "", "arquivo JSON de cenário (padrão: SCENARIO_FILE)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Uso: mock-generator contract-fixtures [opções]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	loadDotEnv()
+	scenario, _, err := config.Resolve(cmp.Or(*scenarioFile, config.Getenv("SCENARIO_FILE")), nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return 2
+	}
+	var opts hvac.ContractOptions
+	if scenario.FaultModel != nil {
+		opts.FaultCodes = scenario.FaultModel.Codes
+	}
+	renderer, err := hvac.NewPayloadRenderer(hvac.PayloadConfig{OmitMissing: scenario.Missingness != nil && scenario.Missingness.Representation == "omit"}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao criar o formato dos payloads: %v\n", err)
+		return 2
+	}
+
+	fixtures := hvac.ContractFixtures(opts)
+	index := make([]contractIndexEntry, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		payload, err := renderer.Render(fixture.Record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro ao gerar a fixture '%s': %v\n", fixture.Name, err)
+			return 2
+		}
+		file := filepath.Join(fixture.Category, fixture.Name+".json")
+		if err := writeJSONFile(filepath.Join(*outDir, file), payload); err != nil {
+			fmt.Fprintf(os.Stderr, "Erro ao gravar a fixture '%s': %v\n", fixture.Name, err)
+			return 2
+		}
+		index = append(index, contractIndexEntry{Name: fixture.Name, Category: fixture.Category, Description: fixture.Description, File: filepath.ToSlash(file)})
+	}
+	if err := writeJSONFile(filepath.Join(*outDir, "index.json"), index); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao gravar o índice das fixtures: %v\n", err)
+		return 2
+	}
+	fmt.Printf("%d fixtures de contrato gravadas em %s.\n", len(fixtures), *outDir)
+	return 0
+}
+
+// writeJSONFile grava o valor como JSON indentado, criando o diretório do arquivo.
+func writeJSONFile(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar '%s': %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("erro ao criar o diretório de '%s': %w", path, err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
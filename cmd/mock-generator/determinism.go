@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/config"
+	"github.com/patrik-rangel/mock-data-hvac/internal/s3/s3fake"
+)
+
+// auditRunID e auditBucket são o ID da execução e o bucket, fixos em todas as execuções da
+// auditoria para que não diferenciem as saídas (o bucket consta do manifesto).
+const (
+	auditRunID  = "determinism-audit"
+	auditBucket = "hvac-determinism"
+)
+
+// auditedEnv são as variáveis de ambiente esvaziadas nas execuções da auditoria: os sinks
+// externos receberiam os dados duas vezes, o estado no DynamoDB faria a segunda execução retomar a
+// primeira, a cifragem sorteia um nonce por arquivo e as opções do S3 não se aplicam ao S3 local.
+var auditedEnv = []string{
+	"DYNAMODB_STATE_TABLE", "IOT_DATA_ENDPOINT", "OPENSEARCH_URL", "REDIS_URL", "NATS_URL", "PULSAR_URL",
+	"AMQP_URL", "MONGODB_URI", "OUTPUT_ENCRYPTION_KEY", "S3_ADDRESSING_STYLE", "S3_INSECURE_SKIP_VERIFY",
+	"S3_CA_BUNDLE", "S3_ANONYMOUS", "S3_EXPIRATION_DAYS",
+}
+
+var (
+	// runTimestampPattern reconhece o carimbo da execução nos nomes dos objetos.
+	runTimestampPattern = regexp.MustCompile(`\d{8}_\d{6}`)
+	// wallClockPattern reconhece instantes RFC 3339 e épocas Unix em milissegundos no conteúdo.
+	wallClockPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?|\b1\d{12}\b`)
+)
+
+// auditRun são as saídas de uma execução do gerador, pelo nome normalizado (o carimbo da execução
+// trocado por <data>).
+type auditRun map[string][]byte
+
+// runDeterminismAudit implementa o comando audit-determinism: executa o gerador várias vezes com o
+// mesmo cenário e a mesma semente, cada vez num S3 local novo, e compara byte a byte todos
+// os objetos e arquivos locais gerados. Retorna o código de saída do processo: 0 com as saídas
+// idênticas, 1 com diferenças e 2 em erro.
+func runDeterminismAudit(args []string) int {
+	flags := flag.NewFlagSet("audit-determinism", flag.ContinueOnError)
+	runs := flags.Int("runs", 2, "número de execuções comparadas")
+	seed := flags.Int64("seed", 0, "semente das execuções (padrão: a do cenário, ou 42 se o cenário não tiver)")
+	serialCheck := flags.Bool("serial-check", true, "com diferenças, repete as execuções com GOMAXPROCS=1 para isolar o paralelismo")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Uso: mock-generator audit-determinism [opções] [-- opções do gerador]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *runs < 2 {
+		flags.Usage()
+		return 2
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao localizar o executável do gerador: %v\n", err)
+		return 2
+	}
+	workDir, err := os.MkdirTemp("", "hvac-determinism-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao criar o diretório da auditoria: %v\n", err)
+		return 2
+	}
+	defer os.RemoveAll(workDir)
+
+	audit := determinismAudit{
+		executable: executable,
+		args:       flags.Args(),
+		workDir:    workDir,
+		seed:       *seed,
+	}
+	fmt.Printf("Executando o gerador %d vezes com o mesmo cenário...\n", *runs)
+	results, err := audit.runAll(*runs, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return 2
+	}
+	findings := compareRuns(results, audit.started, time.Now())
+	if len(findings) == 0 {
+		fmt.Printf("Saídas idênticas nas %d execuções: %d objetos comparados byte a byte.\n", *runs, len(results[0]))
+		return 0
+	}
+
+	fmt.Printf("Saídas diferentes entre as execuções (%d objetos):\n", len(findings))
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+	if *serialCheck {
+		fmt.Println("Repetindo as execuções com GOMAXPROCS=1...")
+		serial, err := audit.runAll(*runs, []string{"GOMAXPROCS=1"})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+			return 2
+		}
+		if len(compareRuns(serial, audit.started, time.Now())) == 0 {
+			fmt.Println("Com GOMAXPROCS=1 as saídas são idênticas: a fonte das diferenças é o paralelismo (goroutines que gravam sem ordem definida).")
+		} else {
+			fmt.Println("As diferenças continuam com GOMAXPROCS=1: a fonte não depende do paralelismo.")
+		}
+	}
+	return 1
+}
+
+// determinismAudit prepara e executa as execuções do gerador.
+type determinismAudit struct {
+	executable string
+	args       []string
+	workDir    string
+	seed       int64
+	started    time.Time
+	count      int
+}
+
+// runAll executa o gerador n vezes, com as variáveis extras em extraEnv.
+func (a *determinismAudit) runAll(n int, extraEnv []string) ([]auditRun, error) {
+	if a.started.IsZero() {
+		a.started = time.Now()
+	}
+	results := make([]auditRun, 0, n)
+	for range n {
+		a.count++
+		result, err := a.run(a.count, extraEnv)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// run executa o gerador uma vez, com um S3 local e um diretório local próprios, e coleta as saídas.
+func (a *determinismAudit) run(n int, extraEnv []string) (auditRun, error) {
+	server := s3fake.NewServer()
+	defer server.Close()
+	localDir := filepath.Join(a.workDir, fmt.Sprintf("run-%d", n))
+	scenarioFile, err := a.writeScenario(n, localDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(a.executable, append([]string{"--run-id", auditRunID}, a.args...)...)
+	// Com o prefixo HVACMOCK_, as variáveis da auditoria têm precedência sobre as do ambiente
+	cmd.Env = append(os.Environ(),
+		config.EnvPrefix+"SCENARIO_FILE="+scenarioFile,
+		config.EnvPrefix+"ENDPOINT_URL="+server.URL,
+		config.EnvPrefix+"S3_BUCKET_NAME="+auditBucket,
+		config.EnvPrefix+"S3_BOOTSTRAP=true",
+	)
+	for _, name := range auditedEnv {
+		cmd.Env = append(cmd.Env, config.EnvPrefix+name+"=")
+	}
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"} {
+		if os.Getenv(name) == "" {
+			cmd.Env = append(cmd.Env, name+"=determinism")
+		}
+	}
+	if config.Getenv("AWS_REGION") == "" {
+		cmd.Env = append(cmd.Env, config.EnvPrefix+"AWS_REGION=us-east-1")
+	}
+	cmd.Env = append(cmd.Env, extraEnv...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("erro na execução %d do gerador: %w\n%s", n, err, tail(output, 20))
+	}
+
+	result := make(auditRun)
+	for key, object := range server.Bucket(auditBucket).Objects {
+		result[runTimestampPattern.ReplaceAllString(key, "<data>")] = normalizeRunTimestamp(object.Data)
+	}
+	entries, err := os.ReadDir(localDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("erro ao ler as saídas locais da execução %d: %w", n, err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(localDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler as saídas locais da execução %d: %w", n, err)
+		}
+		result["local/"+runTimestampPattern.ReplaceAllString(entry.Name(), "<data>")] = normalizeRunTimestamp(data)
+	}
+	return result, nil
+}
+
+// writeScenario grava a cópia do cenário da execução: a mesma semente em todas as execuções e, se
+// o cenário grava o arquivo principal localmente, um diretório próprio da execução.
+func (a *determinismAudit) writeScenario(n int, localDir string) (string, error) {
+	scenario := make(map[string]any)
+	if path := config.Getenv("SCENARIO_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("erro ao ler o arquivo de cenário '%s': %w", path, err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&scenario); err != nil {
+			return "", fmt.Errorf("erro ao interpretar o arquivo de cenário '%s': %w", path, err)
+		}
+	}
+	switch current, _ := scenario["seed"].(json.Number); {
+	case a.seed != 0:
+		scenario["seed"] = a.seed
+	case current == "" || current == "0":
+		scenario["seed"] = 42
+	}
+	if output, ok := scenario["output"].(map[string]any); ok && output["localDir"] != nil && output["localDir"] != "" {
+		output["localDir"] = localDir
+	}
+
+	data, err := json.Marshal(scenario)
+	if err != nil {
+		return "", fmt.Errorf("erro ao serializar o cenário da auditoria: %w", err)
+	}
+	path := filepath.Join(a.workDir, fmt.Sprintf("scenario-%d.json", n))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("erro ao gravar o cenário da auditoria: %w", err)
+	}
+	return path, nil
+}
+
+// normalizeRunTimestamp troca o carimbo da execução (ex: nas chaves listadas no manifesto) por
+// <data>, já que duas execuções em segundos diferentes têm carimbos diferentes por construção.
+func normalizeRunTimestamp(data []byte) []byte {
+	return runTimestampPattern.ReplaceAll(data, []byte("<data>"))
+}
+
+// compareRuns compara as execuções com a primeira e descreve cada objeto diferente, com a provável
+// fonte da diferença. started e finished delimitam as execuções, para reconhecer o relógio do sistema.
+func compareRuns(results []auditRun, started, finished time.Time) []string {
+	var findings []string
+	keys := make(map[string]bool)
+	for _, result := range results {
+		for key := range result {
+			keys[key] = true
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		reference, ok := results[0][key]
+		for i, result := range results[1:] {
+			data, found := result[key]
+			if !ok || !found {
+				findings = append(findings, fmt.Sprintf("  %s: gerado só em parte das execuções (provável fonte: nome sorteado fora da semente, como um UUID, ou decisão que depende do relógio)", key))
+				break
+			}
+			if !bytes.Equal(reference, data) {
+				findings = append(findings, describeDifference(key, i+2, reference, data, started, finished))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// describeDifference localiza a primeira linha diferente do objeto e classifica a fonte: o mesmo
+// conteúdo em outra ordem aponta para iteração de map ou paralelismo; instantes entre o início e o
+// fim da auditoria apontam para o relógio do sistema (time.Now).
+func describeDifference(key string, run int, reference, data []byte, started, finished time.Time) string {
+	referenceLines, lines := bytes.Split(reference, []byte("\n")), bytes.Split(data, []byte("\n"))
+	line := 0
+	for line < min(len(referenceLines), len(lines)) && bytes.Equal(referenceLines[line], lines[line]) {
+		line++
+	}
+	excerpt := func(lines [][]byte) string {
+		if line >= len(lines) {
+			return "(fim do arquivo)"
+		}
+		return truncateExcerpt(strings.TrimSpace(string(lines[line])), 160)
+	}
+	first, other := excerpt(referenceLines), excerpt(lines)
+
+	source := "valores diferentes (sorteio fora da semente do cenário ou estado externo)"
+	switch {
+	case sameLines(referenceLines, lines):
+		source = "mesmo conteúdo em outra ordem (iteração de map ou paralelismo sem ordenação)"
+	case mentionsWallClock(first, started, finished) || mentionsWallClock(other, started, finished):
+		source = "instante da própria execução (time.Now)"
+	}
+	return fmt.Sprintf("  %s: difere na linha %d entre as execuções 1 e %d\n    execução 1: %s\n    execução %d: %s\n    provável fonte: %s",
+		key, line+1, run, first, run, other, source)
+}
+
+// sameLines indica se os dois conteúdos têm as mesmas linhas, em qualquer ordem.
+func sameLines(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA, sortedB := slices.Clone(a), slices.Clone(b)
+	slices.SortFunc(sortedA, bytes.Compare)
+	slices.SortFunc(sortedB, bytes.Compare)
+	return slices.EqualFunc(sortedA, sortedB, bytes.Equal)
+}
+
+// mentionsWallClock indica se o trecho tem um instante entre o início e o fim da auditoria, com um
+// minuto de folga.
+func mentionsWallClock(excerpt string, started, finished time.Time) bool {
+	from, to := started.Add(-time.Minute), finished.Add(time.Minute)
+	for _, match := range wallClockPattern.FindAllString(excerpt, -1) {
+		var instant time.Time
+		if millis, err := strconv.ParseInt(match, 10, 64); err == nil {
+			instant = time.UnixMilli(millis)
+		} else if parsed, err := time.Parse(time.RFC3339Nano, match); err == nil {
+			instant = parsed
+		} else if parsed, err := time.ParseInLocation("2006-01-02T15:04:05", match, time.Local); err == nil {
+			instant = parsed
+		} else {
+			continue
+		}
+		if !instant.Before(from) && !instant.After(to) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateExcerpt limita o trecho exibido a n caracteres.
+func truncateExcerpt(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n]) + "..."
+	}
+	return s
+}
+
+// tail retorna as últimas n linhas da saída do gerador, para o relatório de uma execução com erro.
+func tail(output []byte, n int) string {
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	return strings.Join(lines[max(0, len(lines)-n):], "\n")
+}
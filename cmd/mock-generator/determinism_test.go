@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/config"
+)
+
+// TestDeterminismAuditAllExports executa a auditoria de determinismo com o cenário que liga todas
+// as exportações, inclusive as de vários arquivos (trend logs e conjunto de ML), cuja ordem de
+// gravação muda o manifesto.
+func TestDeterminismAuditAllExports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compila o gerador e o executa várias vezes")
+	}
+	binary := filepath.Join(t.TempDir(), "mock-generator")
+	if output, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		t.Fatalf("erro ao compilar o gerador: %v\n%s", err, output)
+	}
+	scenario, err := filepath.Abs(filepath.Join("testdata", "audit-exports.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(binary, "audit-determinism", "-runs", "3", "-serial-check=false", "--", "--from", "2024-03-01", "--to", "2024-03-10")
+	cmd.Dir = filepath.Join("..", "..") // O arquivo climático padrão é relativo à raiz do repositório
+	cmd.Env = append(os.Environ(), config.EnvPrefix+"SCENARIO_FILE="+scenario)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("saídas diferentes entre as execuções: %v\n%s", err, output)
+	}
+}
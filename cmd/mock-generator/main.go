@@ -1,66 +1,907 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata" // Fusos horários embutidos para ambientes sem zoneinfo (ex: Lambda, containers mínimos)
 
-	"github.com/joho/godotenv"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 
 	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+	"github.com/patrik-rangel/mock-data-hvac/internal/config"
+	"github.com/patrik-rangel/mock-data-hvac/internal/dynamodb"
+	"github.com/patrik-rangel/mock-data-hvac/internal/encryption"
 	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+	"github.com/patrik-rangel/mock-data-hvac/internal/iot"
+	"github.com/patrik-rangel/mock-data-hvac/internal/mongodb"
+	"github.com/patrik-rangel/mock-data-hvac/internal/opensearch"
 	"github.com/patrik-rangel/mock-data-hvac/internal/s3"
+	"github.com/patrik-rangel/mock-data-hvac/internal/secrets"
+	"github.com/patrik-rangel/mock-data-hvac/internal/stream"
 )
 
 func main() {
-	err := godotenv.Load()
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		switch os.Args[1] {
+		case "validate-output":
+			os.Exit(runValidateOutput(os.Args[2:]))
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "audit-determinism":
+			os.Exit(runDeterminismAudit(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "contract-fixtures":
+			os.Exit(runContractFixtures(os.Args[2:]))
+		default:
+			log.Fatalf("Erro fatal: comando '%s' desconhecido (disponíveis: validate-output, bench, audit-determinism, config, contract-fixtures)", os.Args[1])
+		}
+	}
+
+	runIDFlag := flag.String("run-id", "", "ID da execução gravado nos logs, no manifesto, nos metadados dos objetos e nas mensagens (padrão: RUN_ID, ou um UUID novo)")
+	fromFlag := flag.String("from", "", "início da janela simulada (ex: 2024-03-15T06:00), no fuso da série climática")
+	toFlag := flag.String("to", "", "fim da janela simulada, inclusive (ex: 2024-03-18T22:00; só a data vai até o fim do dia)")
+	scenarioFlag := flag.String("scenario", "", "arquivo JSON de cenário (padrão: SCENARIO_FILE)")
+	var sets stringList
+	flag.Var(&sets, "set", "ajusta uma opção do cenário, acima do arquivo e das variáveis HVACMOCK_ (ex: --set output.format=parquet); repetível")
+	maxMemory := flag.String("max-memory", "", "orçamento de memória do processo (ex: 400MiB); grava o arquivo JSON principal em disco durante a geração")
+	var profile profiling
+	flag.StringVar(&profile.cpuProfile, "cpuprofile", "", "grava o perfil de CPU da execução neste arquivo")
+	flag.StringVar(&profile.memProfile, "memprofile", "", "grava o perfil de heap do fim da execução neste arquivo")
+	flag.StringVar(&profile.pprofAddr, "pprof", "", "expõe os endpoints net/http/pprof neste endereço durante a execução (ex: :6060)")
+	flag.Parse()
+	stopProfiling, err := profile.start()
+	if err != nil {
+		log.Fatalf("Erro fatal ao configurar o profiling: %v", err)
+	}
+	defer stopProfiling()
+	var memoryBudget int64
+	if *maxMemory != "" {
+		budget, err := parseByteSize(*maxMemory)
+		if err != nil {
+			log.Fatalf("Erro fatal: valor inválido para --max-memory: %v", err)
+		}
+		memoryBudget = budget
+		debug.SetMemoryLimit(memoryBudget)
+	}
+
+	loadDotEnv()
+	resolved, err := config.ResolveSecrets(context.TODO(), secrets.NewResolver(config.Getenv("AWS_REGION"), config.Getenv("ENDPOINT_URL")))
+	if err != nil {
+		log.Fatalf("Erro fatal ao ler os segredos das variáveis de execução: %v", err)
+	}
+	if resolved > 0 {
+		fmt.Printf("Lidas %d credenciais do Secrets Manager/SSM.\n", resolved)
+	}
+
+	runID, err := resolveRunID(*runIDFlag, config.Getenv("RUN_ID"))
+	if err != nil {
+		log.Fatalf("Erro fatal: %v", err)
+	}
+	log.SetPrefix("[run " + runID + "] ")
+	log.SetFlags(log.Flags() | log.Lmsgprefix)
+	fmt.Printf("ID da execução: %s\n", runID)
+
+	bucketName := config.Getenv("S3_BUCKET_NAME")
+	awsRegion := config.Getenv("AWS_REGION")
+	endpointUrl := config.Getenv("ENDPOINT_URL")
+	stateTableName := config.Getenv("DYNAMODB_STATE_TABLE")
+	iotDataEndpoint := config.Getenv("IOT_DATA_ENDPOINT")
+	openSearchURL := config.Getenv("OPENSEARCH_URL")
+	redisURL := config.Getenv("REDIS_URL")
+	natsURL := config.Getenv("NATS_URL")
+	pulsarURL := config.Getenv("PULSAR_URL")
+	amqpURL := config.Getenv("AMQP_URL")
+	mongoURI := config.Getenv("MONGODB_URI")
+	s3Options := s3.ClientOptions{
+		VirtualHostStyle:   config.Getenv("S3_ADDRESSING_STYLE") == "virtual",
+		InsecureSkipVerify: envBool("S3_INSECURE_SKIP_VERIFY"),
+		CABundle:           config.Getenv("S3_CA_BUNDLE"),
+		Anonymous:          envBool("S3_ANONYMOUS"),
+	}
+
+	scenario, _, err := config.Resolve(cmp.Or(*scenarioFlag, config.Getenv("SCENARIO_FILE")), sets)
 	if err != nil {
-		log.Println("Aviso: Não foi possível carregar o arquivo .env. Erro:", err)
+		log.Fatalf("Erro fatal ao carregar o cenário de simulação: %v", err)
+	}
+	// Decidido antes do tenant, que copia a frota padrão para o cenário
+	simulatesFleet := scenario.SimulatesFleet()
+	tenant := scenario.Tenant
+	if tenant != "" {
+		if err := scenario.ApplyTenant(tenant); err != nil {
+			log.Fatalf("Erro fatal ao configurar o tenant: %v", err)
+		}
+		fmt.Printf("Gerando os dados do tenant '%s'.\n", tenant)
 	}
 
-	bucketName := os.Getenv("S3_BUCKET_NAME")
-	awsRegion := os.Getenv("AWS_REGION")
-	endpointUrl := os.Getenv("ENDPOINT_URL")
+	uploader, err := s3.NewUploader(bucketName, awsRegion, endpointUrl, s3Options)
+	if err != nil {
+		log.Fatalf("Erro fatal ao configurar o cliente S3: %v", err)
+	}
+	uploader.SetMetadata(map[string]string{"run-id": runID})
+	// Configuração AWS compartilhada pelos demais clientes (DynamoDB, IoT)
+	awsOptions := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(awsRegion)}
+	if endpointUrl != "" {
+		awsOptions = append(awsOptions, awsconfig.WithBaseEndpoint(endpointUrl))
+	}
+	awsConfig, err := awsconfig.LoadDefaultConfig(context.TODO(), awsOptions...)
+	if err != nil {
+		log.Fatalf("Erro fatal ao carregar a configuração AWS: %v", err)
+	}
+	if tenant != "" {
+		uploader.SetKeyPrefix(tenant + "/")
+	}
+	var encryptionKey []byte
+	if raw := config.Getenv("OUTPUT_ENCRYPTION_KEY"); raw != "" {
+		encryptionKey, err = encryption.ParseKey(raw)
+		if err != nil {
+			log.Fatalf("Erro fatal em OUTPUT_ENCRYPTION_KEY: %v", err)
+		}
+		// Os leitores Delta (Spark, Athena, delta-rs) não decifram o log nem os Parquet da tabela
+		if scenario.Output.IsTable() {
+			log.Fatalf("Erro fatal em OUTPUT_ENCRYPTION_KEY: a tabela Delta (output.format=delta) não pode ser cifrada no cliente; use a criptografia do bucket (SSE-S3 ou SSE-KMS)")
+		}
+		uploader.SetEncryptionKey(encryptionKey)
+		fmt.Printf("Arquivos de saída cifrados com %s.\n", encryption.Algorithm)
+	}
 
+	if envBool("S3_BOOTSTRAP") {
+		var expirationDays int64
+		if raw := config.Getenv("S3_EXPIRATION_DAYS"); raw != "" {
+			expirationDays, err = strconv.ParseInt(raw, 10, 32)
+			if err != nil || expirationDays < 0 {
+				log.Fatalf("Erro fatal: valor inválido '%s' para S3_EXPIRATION_DAYS (use um número de dias)", raw)
+			}
+		}
+		fmt.Printf("Preparando o bucket S3 '%s'...\n", bucketName)
+		if err := uploader.Bootstrap(int32(expirationDays)); err != nil {
+			log.Fatalf("Erro fatal ao preparar o bucket S3: %v", err)
+		}
+		fmt.Println("Bucket S3 pronto para gravação.")
+	}
+
+	var climateSource climate.SourceConfig
+	if scenario.Climate != nil {
+		climateSource = *scenario.Climate
+	}
 	inmetCSVPath := "data/inmet/dados-202401-202501.zip"
-	fmt.Printf("Lendo dados climáticos do CSV: %s\n", inmetCSVPath)
+	if climateSource.File != "" {
+		inmetCSVPath = climateSource.File
+	}
+	fmt.Printf("Lendo dados climáticos do arquivo: %s\n", inmetCSVPath)
 
-	climateRecords, err := climate.ReadInmetCSV(inmetCSVPath)
+	climateRecords, parseReport, err := climate.ReadClimate(inmetCSVPath, climateSource)
 	if err != nil {
-		log.Fatalf("Erro fatal ao ler dados do INMET: %v", err)
+		log.Fatalf("Erro fatal ao ler o arquivo climático: %v", err)
+	}
+	fmt.Printf("Lidos %d registros climáticos.\n", len(climateRecords))
+	if len(parseReport.Skipped) > 0 {
+		log.Printf("Aviso: %s", parseReport.Summary())
+	}
+	var stationCode string
+	var altitude float64
+	if station := parseReport.Station; station != nil {
+		stationCode = station.Code
+		if station.Altitude != nil {
+			altitude = *station.Altitude
+		}
+		fmt.Printf("Estação meteorológica: %s\n", station)
+	}
+	if scenario.Altitude != nil {
+		altitude = *scenario.Altitude
 	}
-	fmt.Printf("Lidos %d registros climáticos do INMET.\n", len(climateRecords))
 
 	if len(climateRecords) == 0 {
 		log.Println("Nenhum registro climático encontrado no CSV. Saindo.")
 		return
 	}
 
+	seed := scenario.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	if len(scenario.ExtremeEvents) > 0 {
+		climateRecords, err = climate.ApplyExtremeEvents(climateRecords, scenario.ExtremeEvents)
+		if err != nil {
+			log.Fatalf("Erro fatal ao injetar eventos climáticos extremos: %v", err)
+		}
+		fmt.Printf("Injetados %d eventos climáticos extremos.\n", len(scenario.ExtremeEvents))
+	}
+
+	if scenario.Forecast != nil {
+		rng := rand.New(rand.NewSource(seed))
+		climateRecords = climate.AttachForecasts(climateRecords, *scenario.Forecast, rng)
+		fmt.Println("Previsões de temperatura externa anexadas aos registros climáticos.")
+	}
+
+	var warmUp []climate.InmetClimateData
+	warmUpDuration := time.Duration(scenario.WarmUpHours * float64(time.Hour))
+	if *fromFlag != "" || *toFlag != "" || warmUpDuration > 0 {
+		span, err := parseSpan(*fromFlag, *toFlag, climateRecords[0].Timestamp.Location())
+		if err != nil {
+			log.Fatalf("Erro fatal: %v", err)
+		}
+		warmUp, climateRecords = span.split(climateRecords, warmUpDuration)
+		if len(climateRecords) == 0 {
+			log.Fatalf("Erro fatal: nenhum registro climático na janela de --from '%s' a --to '%s' depois de %.2f horas de aquecimento", *fromFlag, *toFlag, scenario.WarmUpHours)
+		}
+		fmt.Printf("Janela da simulação: %d registros climáticos, de %s a %s (%d de aquecimento, sem emitir registros).\n",
+			len(climateRecords), climateRecords[0].Timestamp.Format(time.RFC3339), climateRecords[len(climateRecords)-1].Timestamp.Format(time.RFC3339), len(warmUp))
+	}
+
 	fmt.Println("Iniciando a geração de dados de sensores HVAC mocados...")
 
-	var allHvacData []hvac.HvacSensorData
-	for _, record := range climateRecords {
-		hvacData := hvac.GenerateHvacData(record)
-		allHvacData = append(allHvacData, hvacData)
+	var control hvac.ControlStrategy
+	if scenario.ControlStrategy != "" {
+		control, err = hvac.LookupControlStrategy(scenario.ControlStrategy)
+		if err != nil {
+			log.Fatalf("Erro fatal ao configurar a estratégia de controle: %v", err)
+		}
+	}
+	if scenario.BASSchedule != nil {
+		if scenario.ControlStrategy != "" {
+			log.Printf("Aviso: basSchedule definido; controlStrategy '%s' ignorada", scenario.ControlStrategy)
+		}
+		schedules, err := readBASSchedules(*scenario.BASSchedule)
+		if err != nil {
+			log.Fatalf("Erro fatal ao ler a programação da automação: %v", err)
+		}
+		control, err = hvac.NewBASScheduleStrategy(schedules, scenario.BASSchedule.SetbackOffset)
+		if err != nil {
+			log.Fatalf("Erro fatal ao configurar a programação da automação: %v", err)
+		}
+		fmt.Printf("Lidas %d programações da automação de %s.\n", len(schedules), scenario.BASSchedule.File)
+	}
+
+	var faultModel hvac.FaultModel
+	if scenario.FaultModel != nil {
+		var faultLog []hvac.FaultLogEntry
+		if scenario.FaultModel.Type == "replay" {
+			faultLog, err = readFaultLog(scenario.FaultModel.LogFile)
+			if err != nil {
+				log.Fatalf("Erro fatal ao ler o log de falhas: %v", err)
+			}
+			if tenant != "" { // O log traz os IDs sem o tenant, como o cenário
+				for i := range faultLog {
+					faultLog[i].DeviceID = tenant + "-" + faultLog[i].DeviceID
+				}
+			}
+			fmt.Printf("Lidos %d alarmes do log de falhas %s.\n", len(faultLog), scenario.FaultModel.LogFile)
+		}
+		faultModel, err = hvac.NewFaultModel(*scenario.FaultModel, faultLog)
+		if err != nil {
+			log.Fatalf("Erro fatal ao configurar o modelo de falhas: %v", err)
+		}
+	}
+
+	var precision *hvac.Precision
+	if scenario.Precision != nil {
+		precision, err = hvac.NewPrecision(*scenario.Precision)
+		if err != nil {
+			log.Fatalf("Erro fatal ao configurar a precisão dos valores: %v", err)
+		}
+	}
+
+	simulatorConfig := scenario.SimulatorConfig()
+	simulatorConfig.Seed = seed
+	simulatorConfig.Control = control
+	simulatorConfig.Faults = faultModel
+	simulatorConfig.Station = stationCode
+	simulatorConfig.Altitude = altitude
+	simulator, err := hvac.NewSimulator(simulatorConfig)
+	if err != nil {
+		log.Fatalf("Erro fatal ao configurar o simulador: %v", err)
+	}
+	if len(warmUp) > 0 {
+		simulator.WarmUp(warmUp)
+	}
+
+	renderer, err := hvac.NewPayloadRenderer(hvac.PayloadConfig{
+		Dialects:    scenario.Dialects,
+		Templates:   scenario.Templates,
+		Envelope:    scenario.Envelope,
+		Sensors:     scenario.Sensors,
+		Batching:    scenario.Batching,
+		Seed:        seed,
+		Transforms:  scenario.Transforms,
+		OmitMissing: scenario.Missingness != nil && scenario.Missingness.Representation == "omit",
+	}, scenario.Devices)
+	if err != nil {
+		log.Fatalf("Erro fatal ao configurar os dialetos de payload: %v", err)
+	}
+
+	runTimestamp := time.Now().Format("20060102_150405")
+
+	recordsPerStep := 1 // Sem opções de simulação, uma leitura isolada por hora
+	if simulatesFleet {
+		recordsPerStep = len(scenario.Devices)
+		if recordsPerStep == 0 {
+			recordsPerStep = len(hvac.DefaultDevices())
+		}
+	}
+	var recordBuffers hvac.RecordPool
+	var spill *dataSpill
+	if memoryBudget > 0 {
+		switch {
+		case scenario.Output.Format != "" && scenario.Output.Format != "json":
+			log.Printf("Aviso: o formato '%s' é montado em memória; --max-memory só limita o heap", scenario.Output.Format)
+		case scenario.Batching != nil:
+			log.Println("Aviso: os lotes por gateway são montados em memória; --max-memory só limita o heap")
+		default:
+			sinks := map[string]bool{"OPENSEARCH_URL": openSearchURL != "", "MONGODB_URI": mongoURI != "", "REDIS_URL": redisURL != "", "NATS_URL": natsURL != "", "AMQP_URL": amqpURL != "", "PULSAR_URL": pulsarURL != ""}
+			if consumers := fullDataConsumers(scenario, sinks); len(consumers) > 0 {
+				log.Fatalf("Erro fatal: com --max-memory os registros são gravados em disco durante a geração, sem ficar em memória, mas %s precisam de todos eles; desative essas saídas ou rode sem --max-memory", strings.Join(consumers, ", "))
+			}
+			fileName := fmt.Sprintf("hvac_mock_data_A701_%s%s", runTimestamp, scenario.Output.Extension())
+			spill, err = startDataSpill(scenario.Output, renderer, &recordBuffers, fileName, spillCapacity(memoryBudget, recordsPerStep), encryptionKey)
+			if err != nil {
+				log.Fatalf("Erro fatal ao preparar a gravação em disco dos dados: %v", err)
+			}
+			defer spill.close()
+			fmt.Printf("Orçamento de memória de %d MiB: dados JSON gravados em disco durante a geração.\n", memoryBudget>>20)
+		}
+	}
+
+	var recordRunID string
+	if scenario.RecordRunID {
+		recordRunID = runID
+	}
+	generated := generateData(simulator, !simulatesFleet, climateRecords, &recordBuffers, spill, precision, recordRunID, len(climateRecords)*recordsPerStep)
+	allHvacData, sensorReadings := generated.records, generated.sensors
+	fmt.Printf("Gerados %d registros de dados HVAC mocados.\n", generated.count)
+	if inconsistencies := simulator.Inconsistencies(); len(inconsistencies) > 0 {
+		action := "registrados"
+		if scenario.Consistency.Mode == "fix" {
+			action = "corrigidos"
+		}
+		log.Printf("Aviso: %d registros incoerentes entre modo e grandezas %s; o primeiro: %s", len(inconsistencies), action, inconsistencies[0])
+	}
+
+	manifest := s3.Manifest{RunTimestamp: runTimestamp, RunID: runID, Bucket: bucketName, Site: parseReport.Station}
+	uploadObject := func(data []byte, key string) error {
+		checksum, err := uploader.Put(key, data)
+		if err != nil {
+			return err
+		}
+		manifest.Objects = append(manifest.Objects, checksum)
+		return nil
 	}
-	fmt.Printf("Gerados %d registros de dados HVAC mocados.\n", len(allHvacData))
 
-	fmt.Println("Convertendo dados HVAC para formato JSON...")
-	jsonData, err := hvac.WriteJSON(allHvacData)
+	if scenario.DeviceRegistry != "" {
+		registry := simulator.DeviceRegistry()
+		writeRegistry := hvac.WriteDeviceRegistryJSON
+		if scenario.DeviceRegistry == "csv" {
+			writeRegistry = hvac.WriteDeviceRegistryCSV
+		}
+		registryData, err := writeRegistry(registry)
+		if err != nil {
+			log.Fatalf("Erro fatal ao gerar o registro de dispositivos: %v", err)
+		}
+		registryFileName := fmt.Sprintf("hvac_devices_A701_%s.%s", runTimestamp, scenario.DeviceRegistry)
+		fmt.Printf("Salvando registro de %d dispositivos no bucket como: %s\n", len(registry), registryFileName)
+		if err := uploadObject(registryData, registryFileName); err != nil {
+			log.Fatalf("Erro fatal ao salvar o registro de dispositivos no bucket: %v", err)
+		}
+	}
+
+	var iotProvisioning *iot.Provisioning
+	cleanupIoT := func() {
+		if iotProvisioning == nil || !scenario.IoTProvisioning.Cleanup {
+			return
+		}
+		if err := iotProvisioning.Cleanup(); err != nil {
+			log.Printf("Aviso: a limpeza do IoT Core não removeu tudo o que a execução criou: %v", err)
+		}
+		iotProvisioning = nil
+	}
+	if scenario.IoTProvisioning != nil {
+		provisionConfig := *scenario.IoTProvisioning
+		if provisionConfig.ThingPrefix == "" && scenario.Shadows != nil {
+			provisionConfig.ThingPrefix = scenario.Shadows.ThingPrefix
+		}
+		iotProvisioning, err = iot.Provision(awsConfig, provisionConfig, simulator.DeviceRegistry())
+		if err != nil {
+			cleanupIoT()
+			log.Fatalf("Erro fatal ao cadastrar os dispositivos no IoT Core: %v", err)
+		}
+	}
+
+	// Depois do cadastro no IoT Core, qualquer falha passa pela limpeza do que a execução criou
+	writeOutputs := func() error {
+		if spill != nil {
+			if err := writeSpilledDataFile(uploader, &manifest, spill, runTimestamp); err != nil {
+				return fmt.Errorf("falha ao gravar o arquivo JSON de dados: %w", err)
+			}
+		} else if scenario.Output.IsTable() {
+			if err := appendDeltaTable(uploader, bucketName, &manifest, scenario.Output, allHvacData); err != nil {
+				return fmt.Errorf("falha ao gravar a tabela Delta: %w", err)
+			}
+		} else if err := writeDataFile(uploadObject, scenario.Output, renderer, allHvacData, runTimestamp, encryptionKey); err != nil {
+			return err
+		}
+
+		if stateTableName != "" {
+			if err := dynamodb.UpsertLatestState(awsConfig, stateTableName, renderer, generated.latestReadings()); err != nil {
+				return fmt.Errorf("falha ao atualizar a tabela de estado atual: %w", err)
+			}
+		}
+
+		if openSearchURL != "" {
+			openSearchConfig := opensearch.Config{}
+			if scenario.OpenSearch != nil {
+				openSearchConfig = *scenario.OpenSearch
+			}
+			if err := opensearch.IndexRecords(openSearchURL, config.Getenv("OPENSEARCH_USERNAME"), config.Getenv("OPENSEARCH_PASSWORD"), openSearchConfig, allHvacData); err != nil {
+				return fmt.Errorf("falha ao indexar os registros no OpenSearch: %w", err)
+			}
+		}
+
+		if mongoURI != "" {
+			mongoConfig := mongodb.Config{}
+			if scenario.MongoDB != nil {
+				mongoConfig = *scenario.MongoDB
+			}
+			if err := mongodb.InsertTimeSeries(mongoURI, mongoConfig, allHvacData); err != nil {
+				return fmt.Errorf("falha ao gravar os registros no MongoDB: %w", err)
+			}
+		}
+
+		streamConfig := stream.Config{}
+		if scenario.Stream != nil {
+			streamConfig = *scenario.Stream
+		}
+		streamConfig.RunID = runID
+		streamConfig.Tenant = tenant
+		if redisURL != "" || natsURL != "" || amqpURL != "" {
+			messages, err := renderer.StreamMessages(allHvacData)
+			if err != nil {
+				return fmt.Errorf("falha ao renderizar as mensagens de streaming: %w", err)
+			}
+			if redisURL != "" {
+				if err := stream.PublishRedis(redisURL, streamConfig, messages); err != nil {
+					return fmt.Errorf("falha ao publicar no Redis Stream: %w", err)
+				}
+			}
+			if natsURL != "" {
+				if err := stream.PublishNATS(natsURL, streamConfig, messages); err != nil {
+					return fmt.Errorf("falha ao publicar no NATS JetStream: %w", err)
+				}
+			}
+			if amqpURL != "" {
+				if err := stream.PublishAMQP(amqpURL, streamConfig, messages); err != nil {
+					return fmt.Errorf("falha ao publicar no AMQP: %w", err)
+				}
+			}
+		}
+		if pulsarURL != "" {
+			if err := stream.PublishPulsar(pulsarURL, streamConfig, renderer, allHvacData); err != nil {
+				return fmt.Errorf("falha ao publicar no Pulsar: %w", err)
+			}
+		}
+
+		if scenario.Edge != nil {
+			edgeStreams, err := renderer.EdgeStreams(*scenario.Edge, allHvacData)
+			if err != nil {
+				return fmt.Errorf("falha ao simular o gateway de borda: %w", err)
+			}
+			fmt.Printf("Gateway de borda: %d leituras recebidas, %d mensagens encaminhadas para a nuvem.\n", len(edgeStreams.Local), len(edgeStreams.Cloud))
+			for _, side := range []struct {
+				name     string
+				messages []hvac.EdgeMessage
+			}{{"local", edgeStreams.Local}, {"cloud", edgeStreams.Cloud}} {
+				edgeJSON, err := hvac.WriteEdgeStreamJSON(side.messages)
+				if err != nil {
+					return fmt.Errorf("falha ao converter o fluxo %s do gateway de borda para JSON: %w", side.name, err)
+				}
+				edgeFileName := fmt.Sprintf("hvac_edge_%s_A701_%s.json", side.name, runTimestamp)
+				fmt.Printf("Salvando fluxo %s do gateway de borda no bucket como: %s\n", side.name, edgeFileName)
+				if err := uploadObject(edgeJSON, edgeFileName); err != nil {
+					return fmt.Errorf("falha ao salvar o fluxo %s do gateway de borda no bucket: %w", side.name, err)
+				}
+			}
+		}
+
+		if scenario.Rollups != nil {
+			for _, interval := range scenario.Rollups.Intervals() {
+				rollupJSON, err := hvac.WriteRollupsJSON(hvac.BuildRollups(allHvacData, interval))
+				if err != nil {
+					return fmt.Errorf("falha ao converter agregados de %v para JSON: %w", interval, err)
+				}
+				rollupFileName := fmt.Sprintf("hvac_rollup_%dmin_A701_%s.json", int(interval.Minutes()), runTimestamp)
+				fmt.Printf("Salvando agregados de %v no bucket como: %s\n", interval, rollupFileName)
+				if err := uploadObject(rollupJSON, rollupFileName); err != nil {
+					return fmt.Errorf("falha ao salvar os agregados no bucket: %w", err)
+				}
+			}
+		}
+
+		if scenario.TrendLogs != nil {
+			trendLogs, err := hvac.BuildTrendLogs(*scenario.TrendLogs, allHvacData)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar os trend logs: %w", err)
+			}
+			trendPrefix := fmt.Sprintf("trends_A701_%s/", runTimestamp)
+			fmt.Printf("Salvando %d trend logs no bucket em: %s\n", len(trendLogs), trendPrefix)
+			for _, file := range trendLogs {
+				if err := uploadObject(file.Data, trendPrefix+file.Name); err != nil {
+					return fmt.Errorf("falha ao salvar o trend log '%s' no bucket: %w", file.Name, err)
+				}
+			}
+		}
+
+		if scenario.MLDataset != nil {
+			mlFiles, err := hvac.BuildMLDataset(*scenario.MLDataset, allHvacData)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar o conjunto de dados para ML: %w", err)
+			}
+			mlPrefix := fmt.Sprintf("ml_A701_%s/", runTimestamp)
+			fmt.Printf("Salvando %d arquivos do conjunto de dados para ML no bucket em: %s\n", len(mlFiles), mlPrefix)
+			for _, file := range mlFiles {
+				if err := uploadObject(file.Data, mlPrefix+file.Name); err != nil {
+					return fmt.Errorf("falha ao salvar o arquivo de ML '%s' no bucket: %w", file.Name, err)
+				}
+			}
+		}
+
+		if scenario.Features != nil {
+			features, err := hvac.BuildFeatures(*scenario.Features, allHvacData)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar os atributos derivados: %w", err)
+			}
+			featuresJSON, err := hvac.WriteFeaturesJSON(features)
+			if err != nil {
+				return fmt.Errorf("falha ao converter os atributos derivados para JSON: %w", err)
+			}
+			featuresFileName := fmt.Sprintf("hvac_features_A701_%s.json", runTimestamp)
+			fmt.Printf("Salvando atributos derivados no bucket como: %s\n", featuresFileName)
+			if err := uploadObject(featuresJSON, featuresFileName); err != nil {
+				return fmt.Errorf("falha ao salvar os atributos derivados no bucket: %w", err)
+			}
+		}
+
+		if scenario.GreenButton != nil {
+			greenButtonXML, err := hvac.WriteGreenButtonXML(*scenario.GreenButton, allHvacData)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar o Green Button: %w", err)
+			}
+			greenButtonFileName := fmt.Sprintf("hvac_greenbutton_A701_%s.xml", runTimestamp)
+			fmt.Printf("Salvando consumo em Green Button no bucket como: %s\n", greenButtonFileName)
+			if err := uploadObject(greenButtonXML, greenButtonFileName); err != nil {
+				return fmt.Errorf("falha ao salvar o Green Button no bucket: %w", err)
+			}
+		}
+
+		if scenario.Shadows != nil {
+			shadowUpdates := hvac.BuildShadowUpdates(*scenario.Shadows, scenario.Devices, allHvacData)
+			shadowJSON, err := hvac.WriteShadowUpdatesJSON(shadowUpdates)
+			if err != nil {
+				return fmt.Errorf("falha ao converter as atualizações de shadow para JSON: %w", err)
+			}
+			shadowFileName := fmt.Sprintf("hvac_shadow_A701_%s.json", runTimestamp)
+			fmt.Printf("Salvando %d atualizações de shadow no bucket como: %s\n", len(shadowUpdates), shadowFileName)
+			if err := uploadObject(shadowJSON, shadowFileName); err != nil {
+				return fmt.Errorf("falha ao salvar as atualizações de shadow no bucket: %w", err)
+			}
+			if iotDataEndpoint != "" {
+				if err := iot.UpdateThingShadows(awsConfig, iotDataEndpoint, shadowUpdates); err != nil {
+					return fmt.Errorf("falha ao publicar as atualizações de shadow no IoT Core: %w", err)
+				}
+			}
+		}
+
+		if scenario.Badges != nil {
+			badgeEvents, err := hvac.BuildBadgeEvents(*scenario.Badges, allHvacData, seed)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar as passagens de crachá: %w", err)
+			}
+			badgesJSON, err := hvac.WriteBadgeEventsJSON(badgeEvents)
+			if err != nil {
+				return fmt.Errorf("falha ao converter as passagens de crachá para JSON: %w", err)
+			}
+			badgesFileName := fmt.Sprintf("hvac_badges_A701_%s.json", runTimestamp)
+			fmt.Printf("Salvando %d passagens de crachá no bucket como: %s\n", len(badgeEvents), badgesFileName)
+			if err := uploadObject(badgesJSON, badgesFileName); err != nil {
+				return fmt.Errorf("falha ao salvar as passagens de crachá no bucket: %w", err)
+			}
+		}
+
+		if len(sensorReadings) > 0 {
+			sensorsJSON, err := renderer.WriteSensorsJSON(sensorReadings)
+			if err != nil {
+				return fmt.Errorf("falha ao converter leituras dos sensores sem fio para JSON: %w", err)
+			}
+			sensorsFileName := fmt.Sprintf("hvac_wireless_A701_%s.json", runTimestamp)
+			fmt.Printf("Salvando %d leituras de sensores sem fio no bucket como: %s\n", len(sensorReadings), sensorsFileName)
+			if err := uploadObject(sensorsJSON, sensorsFileName); err != nil {
+				return fmt.Errorf("falha ao salvar as leituras dos sensores sem fio no bucket: %w", err)
+			}
+		}
+
+		if scenario.EnergyBalance != nil {
+			balances := simulator.EnergyBalance()
+			violated := 0
+			for _, balance := range balances {
+				if balance.Violated {
+					violated++
+				}
+			}
+			if violated > 0 {
+				log.Printf("Aviso: balanço de energia violado em %d de %d dias de zona (resíduo acima da tolerância)", violated, len(balances))
+			}
+			balanceJSON, err := hvac.WriteEnergyBalanceJSON(balances)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar o balanço de energia: %w", err)
+			}
+			balanceFileName := fmt.Sprintf("hvac_energy_balance_A701_%s.json", runTimestamp)
+			fmt.Printf("Salvando balanço de energia de %d dias de zona no bucket como: %s\n", len(balances), balanceFileName)
+			if err := uploadObject(balanceJSON, balanceFileName); err != nil {
+				return fmt.Errorf("falha ao salvar o balanço de energia no bucket: %w", err)
+			}
+		}
+
+		if scenario.Maintenance != nil {
+			workOrders := simulator.WorkOrders()
+			workOrdersJSON, err := hvac.WriteWorkOrdersJSON(workOrders)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar as ordens de serviço: %w", err)
+			}
+			workOrdersFileName := fmt.Sprintf("hvac_work_orders_A701_%s.json", runTimestamp)
+			fmt.Printf("Salvando %d ordens de serviço no bucket como: %s\n", len(workOrders), workOrdersFileName)
+			if err := uploadObject(workOrdersJSON, workOrdersFileName); err != nil {
+				return fmt.Errorf("falha ao salvar as ordens de serviço no bucket: %w", err)
+			}
+		}
+
+		if scenario.Lifecycle != nil {
+			lifecycleEvents := simulator.LifecycleEvents()
+			lifecycleJSON, err := hvac.WriteLifecycleJSON(lifecycleEvents)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar os eventos de ciclo de vida: %w", err)
+			}
+			lifecycleFileName := fmt.Sprintf("hvac_lifecycle_A701_%s.json", runTimestamp)
+			fmt.Printf("Salvando %d eventos de ciclo de vida no bucket como: %s\n", len(lifecycleEvents), lifecycleFileName)
+			if err := uploadObject(lifecycleJSON, lifecycleFileName); err != nil {
+				return fmt.Errorf("falha ao salvar os eventos de ciclo de vida no bucket: %w", err)
+			}
+		}
+
+		if scenario.PointCatalog {
+			catalogOpts := hvac.CatalogOptions{}
+			if scenario.Forecast != nil {
+				catalogOpts.ForecastHorizons = scenario.Forecast.Horizons()
+			}
+			catalogCSV, err := hvac.WritePointCatalogCSV(simulator.PointCatalog(catalogOpts))
+			if err != nil {
+				return fmt.Errorf("falha ao gerar o catálogo de pontos: %w", err)
+			}
+			catalogFileName := fmt.Sprintf("hvac_points_A701_%s.csv", runTimestamp)
+			fmt.Printf("Salvando catálogo de pontos no bucket como: %s\n", catalogFileName)
+			if err := uploadObject(catalogCSV, catalogFileName); err != nil {
+				return fmt.Errorf("falha ao salvar o catálogo de pontos no bucket: %w", err)
+			}
+		}
+
+		if len(manifest.Objects) > 0 {
+			manifestJSON, err := s3.WriteManifestJSON(manifest)
+			if err != nil {
+				return fmt.Errorf("falha ao gerar o manifesto da execução: %w", err)
+			}
+			manifestFileName := fmt.Sprintf("hvac_manifest_A701_%s.json", runTimestamp)
+			fmt.Printf("Salvando manifesto com os checksums de %d objetos no bucket como: %s\n", len(manifest.Objects), manifestFileName)
+			if _, err := uploader.Put(manifestFileName, manifestJSON); err != nil {
+				return fmt.Errorf("falha ao salvar o manifesto da execução no bucket: %w", err)
+			}
+		}
+		return nil
+	}
+	err = writeOutputs()
+	cleanupIoT()
 	if err != nil {
-		log.Fatalf("Erro fatal ao converter dados HVAC para JSON: %v", err)
+		log.Fatalf("Erro fatal: %v", err)
 	}
-	fmt.Println("Dados HVAC convertidos para JSON com sucesso.")
 
-	localFileName := fmt.Sprintf("hvac_mock_data_A701_%s.json", time.Now().Format("20060102_150405"))
+	var destinations []string
+	if scenario.Output.LocalDir != "" && !scenario.Output.IsTable() {
+		destinations = append(destinations, fmt.Sprintf("arquivo de dados em %s", scenario.Output.LocalDir))
+	}
+	if len(manifest.Objects) > 0 {
+		location := "s3://" + bucketName + "/"
+		if tenant != "" {
+			location += tenant + "/"
+		}
+		destinations = append(destinations, fmt.Sprintf("%d objetos em %s", len(manifest.Objects)+1, location)) // Mais o manifesto
+	}
+	fmt.Printf("Processo concluído com sucesso! Dados mocados salvos: %s.\n", strings.Join(destinations, " e "))
+}
+
+// writeDataFile converte os registros para o formato configurado e salva o arquivo principal de
+// dados (um por partição, com Output.Partition) no bucket, ou em Output.LocalDir, cifrado com
+// encryptionKey quando informada.
+func writeDataFile(uploadObject func([]byte, string) error, output hvac.OutputConfig, renderer *hvac.PayloadRenderer, allHvacData []hvac.HvacSensorData, runTimestamp string, encryptionKey []byte) error {
+	outputFormat := strings.ToUpper(strings.TrimPrefix(output.Extension(), "."))
+	localFileName := fmt.Sprintf("hvac_mock_data_A701_%s%s", runTimestamp, output.Extension())
+
+	fmt.Printf("Convertendo dados HVAC para formato %s...\n", outputFormat)
+	var files []hvac.OutputFile
+	if output.Partition != "" {
+		partitions, err := hvac.WritePartitionedOutput(output, allHvacData, localFileName)
+		if err != nil {
+			return fmt.Errorf("falha ao converter dados HVAC para %s: %w", outputFormat, err)
+		}
+		files = partitions
+	} else {
+		outputData, err := hvac.WriteOutput(output, renderer, allHvacData)
+		if err != nil {
+			return fmt.Errorf("falha ao converter dados HVAC para %s: %w", outputFormat, err)
+		}
+		files = []hvac.OutputFile{{Name: localFileName, Data: outputData}}
+	}
+	fmt.Printf("Dados HVAC convertidos para %s com sucesso.\n", outputFormat)
 
-	fmt.Printf("Salvando dados JSON no bucket como: %s\n", localFileName)
+	for _, file := range files {
+		if output.LocalDir != "" {
+			localPath := filepath.Join(output.LocalDir, filepath.FromSlash(file.Name))
+			fmt.Printf("Salvando dados %s localmente em: %s\n", outputFormat, localPath)
+			if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+				return fmt.Errorf("falha ao criar o diretório de saída '%s': %w", filepath.Dir(localPath), err)
+			}
+			outputData := file.Data
+			if encryptionKey != nil {
+				var err error
+				if outputData, err = encryption.Seal(encryptionKey, outputData); err != nil {
+					return fmt.Errorf("falha ao cifrar o %s: %w", outputFormat, err)
+				}
+			}
+			if err := os.WriteFile(localPath, outputData, 0o644); err != nil {
+				return fmt.Errorf("falha ao salvar o %s em '%s': %w", outputFormat, localPath, err)
+			}
+			continue
+		}
 
-	err = s3.UploadDataToS3(bucketName, awsRegion, endpointUrl, jsonData, localFileName)
+		fmt.Printf("Salvando dados %s no bucket como: %s\n", outputFormat, file.Name)
+		if err := uploadObject(file.Data, file.Name); err != nil {
+			return fmt.Errorf("falha ao salvar o %s no bucket: %w", outputFormat, err)
+		}
+	}
+	return nil
+}
+
+// writeSpilledDataFile conclui o arquivo de dados gravado em disco durante a geração e o envia ao
+// bucket em partes, sem carregá-lo em memória. Em Output.LocalDir, o arquivo já é a saída final.
+func writeSpilledDataFile(uploader *s3.Uploader, manifest *s3.Manifest, spill *dataSpill, runTimestamp string) error {
+	if err := spill.finish(); err != nil {
+		return err
+	}
+	if spill.local {
+		fmt.Printf("Dados JSON salvos localmente em: %s\n", spill.file.Name())
+		return nil
+	}
+	fileName := fmt.Sprintf("hvac_mock_data_A701_%s.json", runTimestamp)
+	fmt.Printf("Salvando dados JSON no bucket como: %s\n", fileName)
+	checksum, err := uploader.PutStream(fileName, spill.file)
 	if err != nil {
-		log.Fatalf("Erro fatal ao salvar o JSON no bucket: %v", err)
+		return err
 	}
+	manifest.Objects = append(manifest.Objects, checksum)
+	return nil
+}
 
-	fmt.Println("Processo concluído com sucesso! Dados mocados salvos no s3.")
+// appendDeltaTable lê o log de transações da tabela Delta no bucket e acrescenta os registros como
+// um novo commit. Os dados são gravados antes do commit, para que leitores nunca vejam um commit
+// apontando para um arquivo inexistente, e o commit é gravado só se a versão ainda não existir
+// (If-None-Match). Se outra execução gravou a versão antes, o arquivo de dados é apagado e o log é
+// relido para tentar a versão seguinte, até maxDeltaCommitAttempts vezes.
+func appendDeltaTable(uploader *s3.Uploader, bucketName string, manifest *s3.Manifest, output hvac.OutputConfig, allHvacData []hvac.HvacSensorData) error {
+	tablePath := output.TablePath()
+	for attempt := 1; ; attempt++ {
+		table, err := readDeltaTable(uploader, tablePath)
+		if err != nil {
+			return err
+		}
+		write, err := hvac.WriteDeltaCommit(table, allHvacData, output.Compression, time.Now())
+		if err != nil {
+			return err
+		}
+
+		version := table.Version + 1
+		fmt.Printf("Salvando %d registros na tabela Delta s3://%s/%s (versão %d)...\n", len(allHvacData), bucketName, tablePath, version)
+		dataChecksum, err := uploader.Put(tablePath+write.DataKey, write.Data)
+		if err != nil {
+			return err
+		}
+		commitChecksum, err := uploader.PutIfAbsent(tablePath+write.CommitKey, write.Commit)
+		if err == nil {
+			manifest.Objects = append(manifest.Objects, dataChecksum, commitChecksum)
+			return nil
+		}
+		// Sem o commit, o Parquet não faz parte da tabela: apagado para não deixar um arquivo órfão
+		if deleteErr := uploader.Delete(tablePath + write.DataKey); deleteErr != nil {
+			log.Printf("Aviso: arquivo '%s' fora da tabela Delta não foi apagado: %v", tablePath+write.DataKey, deleteErr)
+		}
+		if !errors.Is(err, s3.ErrPreconditionFailed) {
+			return err
+		}
+		if attempt == maxDeltaCommitAttempts {
+			return fmt.Errorf("conflito na tabela Delta '%s': a versão %d já foi gravada por outra execução, e as %d tentativas com a versão seguinte também conflitaram", tablePath, version, maxDeltaCommitAttempts)
+		}
+		log.Printf("Aviso: a versão %d da tabela Delta '%s' foi gravada por outra execução; relendo o log para tentar a versão seguinte.", version, tablePath)
+	}
+}
+
+// maxDeltaCommitAttempts limita as releituras do log quando outras execuções gravam na mesma
+// tabela Delta ao mesmo tempo.
+const maxDeltaCommitAttempts = 5
+
+// readDeltaTable lê os commits do log da tabela Delta, que devem formar uma sequência sem lacunas
+// desde a versão 0.
+func readDeltaTable(uploader *s3.Uploader, tablePath string) (hvac.DeltaTable, error) {
+	keys, err := uploader.List(tablePath + hvac.DeltaLogDir)
+	if err != nil {
+		return hvac.DeltaTable{}, err
+	}
+	var commits [][]byte
+	for _, key := range keys {
+		version, ok := hvac.DeltaCommitVersion(key)
+		if !ok {
+			continue
+		}
+		if version != int64(len(commits)) {
+			return hvac.DeltaTable{}, fmt.Errorf("log Delta incompleto em '%s': esperada a versão %d, encontrada %d", tablePath, len(commits), version)
+		}
+		commit, err := uploader.Get(key)
+		if err != nil {
+			return hvac.DeltaTable{}, err
+		}
+		commits = append(commits, commit)
+	}
+	return hvac.ReadDeltaLog(commits)
+}
+
+// readFaultLog lê o log real de falhas reproduzido pelo modelo de falhas replay.
+func readFaultLog(path string) ([]hvac.FaultLogEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o log de falhas '%s': %w", path, err)
+	}
+	defer file.Close()
+	return hvac.ReadFaultLogCSV(file)
+}
+
+// readBASSchedules lê o arquivo de programações exportado da automação predial.
+func readBASSchedules(cfg hvac.ScheduleImportConfig) ([]hvac.BASSchedule, error) {
+	format, err := cfg.ScheduleFormat()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(cfg.File)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir a programação '%s': %w", cfg.File, err)
+	}
+	defer file.Close()
+	return hvac.ReadBASSchedules(file, format)
+}
+
+// envBool lê uma variável de ambiente booleana (true/false, 1/0); ausente vale false.
+func envBool(name string) bool {
+	raw := config.Getenv(name)
+	if raw == "" {
+		return false
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Fatalf("Erro fatal: valor inválido '%s' para %s (use true ou false)", raw, name)
+	}
+	return value
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+	"github.com/patrik-rangel/mock-data-hvac/internal/s3"
+	"github.com/patrik-rangel/mock-data-hvac/internal/s3/s3fake"
+)
+
+// competingWriter simula outra execução que grava na mesma tabela Delta: antes de cada commit do
+// gerador, até conflicts vezes, commita a mesma versão com seu próprio arquivo de dados.
+type competingWriter struct {
+	t         *testing.T
+	tablePath string
+	conflicts int
+	commits   [][]byte
+}
+
+func (c *competingWriter) beforePut(key string) map[string][]byte {
+	version, ok := hvac.DeltaCommitVersion(strings.TrimPrefix(key, c.tablePath))
+	if !ok || len(c.commits) == c.conflicts || version != int64(len(c.commits)) {
+		return nil
+	}
+	table, err := hvac.ReadDeltaLog(c.commits)
+	if err != nil {
+		c.t.Errorf("ReadDeltaLog da execução concorrente: %v", err)
+		return nil
+	}
+	data := []hvac.HvacSensorData{{DeviceId: "CONCORRENTE", Timestamp: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}}
+	write, err := hvac.WriteDeltaCommit(table, data, "", time.Now())
+	if err != nil {
+		c.t.Errorf("WriteDeltaCommit da execução concorrente: %v", err)
+		return nil
+	}
+	c.commits = append(c.commits, write.Commit)
+	return map[string][]byte{c.tablePath + write.DataKey: write.Data, c.tablePath + write.CommitKey: write.Commit}
+}
+
+func TestAppendDeltaTableConflicts(t *testing.T) {
+	log.SetOutput(io.Discard) // Os conflitos geram avisos
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", os.DevNull)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", os.DevNull)
+
+	output := hvac.OutputConfig{Format: "delta", Table: "delta/hvac"}
+	tablePath := output.TablePath()
+	data := []hvac.HvacSensorData{{DeviceId: "SALA-1", Timestamp: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), InternalTemperature: 22.5}}
+
+	tests := []struct {
+		name      string
+		conflicts int
+		committed bool  // O gerador commita; sem isso, desiste com o erro de conflito
+		version   int64 // Última versão da tabela ao final
+	}{
+		{name: "sem conflito", committed: true, version: 0},
+		{name: "versão tomada por outra execução", conflicts: 2, committed: true, version: 2},
+		{name: "tentativas esgotadas", conflicts: maxDeltaCommitAttempts, version: maxDeltaCommitAttempts - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := s3fake.NewServer()
+			defer server.Close()
+			competitor := &competingWriter{t: t, tablePath: tablePath, conflicts: tt.conflicts}
+			server.BeforePut = competitor.beforePut
+
+			uploader, err := s3.NewUploader("hvac-delta", "us-east-1", server.URL, s3.ClientOptions{})
+			if err != nil {
+				t.Fatalf("NewUploader: %v", err)
+			}
+			if err := uploader.Bootstrap(0); err != nil {
+				t.Fatalf("Bootstrap: %v", err)
+			}
+
+			var manifest s3.Manifest
+			err = appendDeltaTable(uploader, "hvac-delta", &manifest, output, data)
+			if !tt.committed {
+				if err == nil || !strings.Contains(err.Error(), "conflito na tabela Delta") {
+					t.Fatalf("appendDeltaTable retornou %v, esperado erro de conflito", err)
+				}
+			} else if err != nil {
+				t.Fatalf("appendDeltaTable: %v", err)
+			}
+
+			table, err := readDeltaTable(uploader, tablePath)
+			if err != nil {
+				t.Fatalf("readDeltaTable: %v", err)
+			}
+			if table.Version != tt.version {
+				t.Errorf("tabela na versão %d, esperado %d", table.Version, tt.version)
+			}
+
+			// Só os arquivos de dados commitados ficam no bucket: os das tentativas perdidas são apagados
+			var dataFiles int
+			for key := range server.Bucket("hvac-delta").Objects {
+				if strings.HasPrefix(key, tablePath) && !strings.Contains(key, hvac.DeltaLogDir) {
+					dataFiles++
+				}
+			}
+			if want := int(table.Version) + 1; dataFiles != want {
+				t.Errorf("%d arquivos de dados na tabela, esperado %d", dataFiles, want)
+			}
+			want := 0
+			if tt.committed {
+				want = 2 // O arquivo de dados e o commit
+			}
+			if len(manifest.Objects) != want {
+				t.Errorf("%d objetos no manifesto, esperado %d", len(manifest.Objects), want)
+			}
+		})
+	}
+}
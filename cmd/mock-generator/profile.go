@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+)
+
+// profiling reúne as opções de profiling de uma execução.
+type profiling struct {
+	cpuProfile string // Arquivo do perfil de CPU da execução inteira
+	memProfile string // Arquivo do perfil de heap gravado ao fim
+	pprofAddr  string // Endereço dos endpoints net/http/pprof durante a execução (ex: :6060)
+}
+
+// start liga o profiling configurado e retorna a função que grava os perfis ao fim da execução.
+// Os endpoints de pprof ficam em um servidor próprio, fora do http.DefaultServeMux.
+func (p profiling) start() (func(), error) {
+	var cpuFile *os.File
+	if p.cpuProfile != "" {
+		file, err := os.Create(p.cpuProfile)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao criar o perfil de CPU '%s': %w", p.cpuProfile, err)
+		}
+		if err := runtimepprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("erro ao iniciar o perfil de CPU: %w", err)
+		}
+		cpuFile = file
+	}
+
+	if p.pprofAddr != "" {
+		listener, err := net.Listen("tcp", p.pprofAddr)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao abrir os endpoints de pprof em '%s': %w", p.pprofAddr, err)
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		fmt.Printf("Endpoints de pprof em http://%s/debug/pprof/\n", listener.Addr())
+		go func() {
+			if err := http.Serve(listener, mux); err != nil {
+				log.Printf("Aviso: servidor de pprof encerrado: %v", err)
+			}
+		}()
+	}
+
+	return func() {
+		if cpuFile != nil {
+			runtimepprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				log.Printf("Aviso: erro ao fechar o perfil de CPU: %v", err)
+			}
+		}
+		if p.memProfile != "" {
+			if err := writeHeapProfile(p.memProfile); err != nil {
+				log.Printf("Aviso: %v", err)
+			}
+		}
+	}, nil
+}
+
+// writeHeapProfile grava o perfil de heap depois de uma coleta, com as alocações atualizadas.
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("erro ao criar o perfil de memória '%s': %w", path, err)
+	}
+	defer file.Close()
+	runtime.GC()
+	if err := runtimepprof.WriteHeapProfile(file); err != nil {
+		return fmt.Errorf("erro ao gravar o perfil de memória: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// runIDPattern restringe o ID da execução a caracteres aceitos sem escape nos metadados do S3, nos
+// cabeçalhos das mensagens e nos logs.
+var runIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// resolveRunID escolhe o ID da execução: o informado em --run-id, senão o de RUN_ID, senão um UUID
+// novo. Um ID informado permite ao orquestrador do pipeline (ex: o ID do job do Airflow) ligar os
+// dados gerados à execução que os pediu.
+func resolveRunID(flagValue, envValue string) (string, error) {
+	runID := flagValue
+	if runID == "" {
+		runID = envValue
+	}
+	if runID == "" {
+		return uuid.NewString(), nil
+	}
+	if !runIDPattern.MatchString(runID) {
+		return "", fmt.Errorf("ID da execução '%s' inválido (use até 128 letras, dígitos, '.', '_', ':' ou '-')", runID)
+	}
+	return runID, nil
+}
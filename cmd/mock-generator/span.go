@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// spanLayouts são os formatos aceitos em --from e --to; os sem fuso usam o fuso da série climática.
+var spanLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+// simulationSpan é a janela da simulação definida por --from e --to. Os limites zero deixam a
+// série aberta naquele lado.
+type simulationSpan struct {
+	from, to time.Time
+}
+
+// parseSpan interpreta --from e --to no fuso loc. Um --to só com a data vai até o fim do dia.
+func parseSpan(rawFrom, rawTo string, loc *time.Location) (simulationSpan, error) {
+	var span simulationSpan
+	var err error
+	if rawFrom != "" {
+		if span.from, _, err = parseSpanTime(rawFrom, loc); err != nil {
+			return span, fmt.Errorf("valor inválido para --from: %w", err)
+		}
+	}
+	if rawTo != "" {
+		var dateOnly bool
+		if span.to, dateOnly, err = parseSpanTime(rawTo, loc); err != nil {
+			return span, fmt.Errorf("valor inválido para --to: %w", err)
+		}
+		if dateOnly {
+			span.to = span.to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+	if !span.from.IsZero() && !span.to.IsZero() && !span.to.After(span.from) {
+		return span, fmt.Errorf("--to (%s) deve ser posterior a --from (%s)", rawTo, rawFrom)
+	}
+	return span, nil
+}
+
+func parseSpanTime(raw string, loc *time.Location) (time.Time, bool, error) {
+	for _, layout := range spanLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, layout == "2006-01-02", nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("'%s' não é uma data (use AAAA-MM-DD, AAAA-MM-DDTHH:MM ou RFC 3339)", raw)
+}
+
+// split separa a série climática em aquecimento e janela. A janela vai de from a to, inclusive; o
+// aquecimento são os registros simulados sem emitir registros antes de from, para que a janela
+// comece com o estado de uma série contínua: os de warmUpDuration antes de from ou, sem duração,
+// os do mesmo dia de from. Sem from, a janela começa warmUpDuration depois do início da série.
+func (s simulationSpan) split(records []climate.InmetClimateData, warmUpDuration time.Duration) (warmUp, window []climate.InmetClimateData) {
+	if s.from.IsZero() && warmUpDuration > 0 && len(records) > 0 {
+		s.from = records[0].Timestamp.Add(warmUpDuration)
+	}
+	var warmUpStart time.Time
+	switch {
+	case s.from.IsZero():
+	case warmUpDuration > 0:
+		warmUpStart = s.from.Add(-warmUpDuration)
+	default:
+		warmUpStart = time.Date(s.from.Year(), s.from.Month(), s.from.Day(), 0, 0, 0, 0, s.from.Location())
+	}
+	for _, record := range records {
+		switch {
+		case !s.to.IsZero() && record.Timestamp.After(s.to):
+		case !s.from.IsZero() && record.Timestamp.Before(s.from):
+			if !record.Timestamp.Before(warmUpStart) {
+				warmUp = append(warmUp, record)
+			}
+		default:
+			window = append(window, record)
+		}
+	}
+	return warmUp, window
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/config"
+	"github.com/patrik-rangel/mock-data-hvac/internal/encryption"
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+	"github.com/patrik-rangel/mock-data-hvac/internal/secrets"
+)
+
+// runValidateOutput implementa o comando validate-output: verifica os invariantes físicos de um
+// arquivo de saída no formato canônico (array JSON ou JSON Lines; "-" lê da entrada padrão).
+// Arquivos cifrados pelo gerador são decifrados com a chave de OUTPUT_ENCRYPTION_KEY. Retorna o código de saída do processo: 0 sem violações, 1 com violações e 2 em erro de uso.
+func runValidateOutput(args []string) int {
+	limits := hvac.DefaultValidationLimits()
+	flags := flag.NewFlagSet("validate-output", flag.ContinueOnError)
+	flags.Float64Var(&limits.MinCO2Ppm, "min-co2", limits.MinCO2Ppm, "CO2 mínimo plausível (ppm)")
+	flags.Float64Var(&limits.MaxCO2Ppm, "max-co2", limits.MaxCO2Ppm, "CO2 máximo plausível (ppm)")
+	flags.Float64Var(&limits.MaxTempJumpC, "max-jump", limits.MaxTempJumpC, "maior variação da temperatura interna entre leituras seguidas (°C)")
+	flags.Float64Var(&limits.SupplyReturnTolC, "supply-return-tolerance", limits.SupplyReturnTolC, "folga entre insuflamento e retorno (°C)")
+	maxShown := flags.Int("max-violations", 50, "violações listadas na saída (0 lista todas)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Uso: mock-generator validate-output [opções] <arquivo.json|->")
+		fmt.Fprintln(flags.Output(), "Arquivos cifrados são decifrados com a chave de OUTPUT_ENCRYPTION_KEY.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	var input io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Erro ao abrir o arquivo de saída '%s': %v\n", path, err)
+			return 2
+		}
+		defer file.Close()
+		input = file
+	}
+	input, err := decryptedInput(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao decifrar o arquivo de saída: %v\n", err)
+		return 2
+	}
+	data, err := hvac.ReadRecordsJSON(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao ler os registros: %v\n", err)
+		return 2
+	}
+
+	violations := hvac.Validate(data, limits)
+	for i, violation := range violations {
+		if *maxShown > 0 && i == *maxShown {
+			fmt.Printf("... e mais %d violações\n", len(violations)-i)
+			break
+		}
+		fmt.Println(violation)
+	}
+	if len(violations) > 0 {
+		fmt.Printf("%d registros verificados: %d violações dos invariantes físicos.\n", len(data), len(violations))
+		return 1
+	}
+	fmt.Printf("%d registros verificados: nenhuma violação dos invariantes físicos.\n", len(data))
+	return 0
+}
+
+// decryptedInput decifra a entrada quando ela começa com o cabeçalho dos arquivos cifrados, com a
+// chave de OUTPUT_ENCRYPTION_KEY; as demais entradas são lidas como estão.
+func decryptedInput(input io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(input)
+	header, _ := buffered.Peek(encryption.PrefixSize)
+	if !encryption.IsEncrypted(header) {
+		return buffered, nil
+	}
+	if _, err := config.ResolveSecrets(context.TODO(), secrets.NewResolver(config.Getenv("AWS_REGION"), config.Getenv("ENDPOINT_URL")), "OUTPUT_ENCRYPTION_KEY"); err != nil {
+		return nil, err
+	}
+	raw := config.Getenv("OUTPUT_ENCRYPTION_KEY")
+	if raw == "" {
+		return nil, fmt.Errorf("o arquivo está cifrado: defina a chave em OUTPUT_ENCRYPTION_KEY")
+	}
+	key, err := encryption.ParseKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	return encryption.NewReader(buffered, key)
+}
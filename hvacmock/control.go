@@ -0,0 +1,40 @@
+package hvacmock
+
+import "github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+
+// ZoneState é o que a estratégia de controle enxerga de uma sala a cada passo.
+type ZoneState = hvac.ZoneState
+
+// ControlDecision é o modo de operação escolhido para o passo e o setpoint efetivo.
+type ControlDecision = hvac.ControlDecision
+
+// ControlStrategy decide o modo de operação e o setpoint de uma sala. Implemente-a para levar a
+// lógica de controle do seu prédio ao Generator, com WithControlStrategy ou
+// RegisterControlStrategy.
+type ControlStrategy = hvac.ControlStrategy
+
+// Estratégias embutidas: ThermostatStrategy é o padrão do gerador, ScheduledStrategy segue a
+// programação da automação e G36Strategy, os modos de zona do ASHRAE Guideline 36.
+type (
+	ThermostatStrategy = hvac.ThermostatStrategy
+	ScheduledStrategy  = hvac.ScheduledStrategy
+	G36Strategy        = hvac.G36Strategy
+)
+
+// WithControlStrategy substitui o termostato simples por outra estratégia de controle, embutida
+// ou própria.
+func WithControlStrategy(strategy ControlStrategy) GeneratorOption {
+	return hvac.WithControlStrategy(strategy)
+}
+
+// RegisterControlStrategy registra uma estratégia de controle pelo nome, ao lado das embutidas
+// (thermostat, scheduled e g36), para que o cenário a escolha em controlStrategy. Um nome já
+// registrado é substituído.
+func RegisterControlStrategy(name string, factory func() ControlStrategy) {
+	hvac.RegisterControlStrategy(name, factory)
+}
+
+// LookupControlStrategy cria a estratégia de controle registrada com o nome informado.
+func LookupControlStrategy(name string) (ControlStrategy, error) {
+	return hvac.LookupControlStrategy(name)
+}
@@ -0,0 +1,135 @@
+// Package hvacmock expõe uma unidade HVAC simulada para ser usada como dispositivo falso nos testes
+// de outros serviços. Cada chamada a Next avança a simulação até o instante informado, com a mesma
+// física, inércia térmica, ocupação e falhas do gerador, e retorna a leitura do dispositivo:
+//
+//	device, err := hvacmock.New(hvacmock.WithID("SALA-7"), hvacmock.WithSeed(42))
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	start := time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)
+//	for i := range 24 {
+//		reading, err := device.Next(start.Add(time.Duration(i) * time.Hour))
+//		...
+//	}
+//
+// Com a mesma semente e os mesmos instantes, as leituras são sempre as mesmas.
+package hvacmock
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// Reading é a leitura do dispositivo, no mesmo formato dos registros do gerador.
+type Reading = hvac.HvacSensorData
+
+// OutdoorFunc informa as condições externas no instante: temperatura (°C) e umidade relativa (%).
+type OutdoorFunc func(t time.Time) (temperature, humidity float64)
+
+// Option ajusta o dispositivo criado por New.
+type Option func(*config)
+
+type config struct {
+	device  hvac.Device
+	seed    int64
+	outdoor OutdoorFunc
+}
+
+// WithID define o identificador do dispositivo (padrão: SALA-1).
+func WithID(id string) Option {
+	return func(c *config) { c.device.ID = id }
+}
+
+// WithZone define a zona atendida pelo dispositivo (padrão: Zona-A).
+func WithZone(zone string) Option {
+	return func(c *config) { c.device.Zone = zone }
+}
+
+// WithAssetModel define o modelo do equipamento (padrão: HVAC-Model-B).
+func WithAssetModel(model string) Option {
+	return func(c *config) { c.device.AssetModel = model }
+}
+
+// WithSetpoint define o setpoint da sala (°C, padrão: o do modelo).
+func WithSetpoint(setpoint float64) Option {
+	return func(c *config) { c.device.Setpoint = setpoint }
+}
+
+// WithSeed define a semente dos sorteios da simulação (padrão: 1). Ao contrário do gerador, a
+// semente nunca vem do relógio, para que os testes sejam reprodutíveis.
+func WithSeed(seed int64) Option {
+	return func(c *config) { c.seed = seed }
+}
+
+// WithOutdoor substitui o clima externo padrão, um dia típico de São Paulo (ver DefaultOutdoor),
+// por outra fonte, como uma série lida de arquivo ou condições fixas para o caso de teste.
+func WithOutdoor(outdoor OutdoorFunc) Option {
+	return func(c *config) { c.outdoor = outdoor }
+}
+
+// DefaultOutdoor é o clima externo padrão: a temperatura oscila entre 16 e 28 °C ao longo do dia,
+// com o pico às 15h e a mínima às 3h na hora local de t, e a umidade relativa faz o inverso,
+// entre 50 e 90%.
+func DefaultOutdoor(t time.Time) (temperature, humidity float64) {
+	hour := float64(t.Hour()) + float64(t.Minute())/60.0
+	cycle := math.Sin(2 * math.Pi * (hour - 9.0) / 24.0)
+	return 22.0 + 6.0*cycle, 70.0 - 20.0*cycle
+}
+
+// Device é uma unidade HVAC simulada que produz uma leitura por chamada a Next. Pode ser usado por
+// várias goroutines.
+type Device struct {
+	mu        sync.Mutex
+	id        string
+	simulator *hvac.Simulator
+	outdoor   OutdoorFunc
+	last      time.Time
+}
+
+// New cria o dispositivo com os parâmetros padrão ajustados pelas opções, na ordem informada.
+func New(opts ...Option) (*Device, error) {
+	cfg := config{device: hvac.Device{ID: "SALA-1"}, seed: 1, outdoor: DefaultOutdoor}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.device.ID == "" {
+		return nil, fmt.Errorf("o dispositivo precisa de um identificador")
+	}
+	if cfg.outdoor == nil {
+		return nil, fmt.Errorf("a fonte do clima externo do dispositivo '%s' não pode ser nula", cfg.device.ID)
+	}
+	simulator, err := hvac.NewSimulator(hvac.SimulatorConfig{Devices: []hvac.Device{cfg.device}, Seed: cfg.seed})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o simulador do dispositivo '%s': %w", cfg.device.ID, err)
+	}
+	return &Device{id: cfg.device.ID, simulator: simulator, outdoor: cfg.outdoor}, nil
+}
+
+// ID retorna o identificador do dispositivo.
+func (d *Device) ID() string {
+	return d.id
+}
+
+// Next avança a simulação até t e retorna a leitura do dispositivo nesse instante. O estado
+// térmico evolui pelo tempo desde a leitura anterior, então os instantes precisam ser crescentes;
+// o intervalo entre eles é livre (ex: uma leitura por minuto ou por hora).
+func (d *Device) Next(t time.Time) (Reading, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.last.IsZero() && !t.After(d.last) {
+		return Reading{}, fmt.Errorf("instante %s do dispositivo '%s' não é posterior ao da leitura anterior (%s)", t.Format(time.RFC3339), d.id, d.last.Format(time.RFC3339))
+	}
+	temperature, humidity := d.outdoor(t)
+	records := d.simulator.Step(climate.InmetClimateData{Timestamp: t, TemperatureAir: temperature, RelativeHumidity: humidity})
+	d.last = t
+	if len(records) == 0 {
+		return Reading{}, fmt.Errorf("o dispositivo '%s' não reportou no instante %s", d.id, t.Format(time.RFC3339))
+	}
+	return records[0], nil
+}
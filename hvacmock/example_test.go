@@ -0,0 +1,135 @@
+package hvacmock_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/hvacmock"
+)
+
+func ExampleNewGenerator() {
+	generator, err := hvacmock.NewGenerator(
+		hvacmock.WithGeneratorSeed(42),
+		hvacmock.WithUnits(hvacmock.Unit{ID: "SALA-1", Zone: "Zona-A"}, hvacmock.Unit{ID: "SALA-2", Zone: "Zona-A"}),
+		hvacmock.WithBaseSetpoint(23),
+		hvacmock.WithDeadband(1.5),
+		hvacmock.WithNoise(0),
+	)
+	if err != nil {
+		panic(err)
+	}
+	start := time.Date(2024, time.January, 15, 8, 0, 0, 0, time.UTC)
+	for hour := range 3 {
+		records := generator.Step(hvacmock.ClimateRecord{Timestamp: start.Add(time.Duration(hour) * time.Hour), TemperatureAir: 30, RelativeHumidity: 60})
+		for _, record := range records {
+			fmt.Printf("%s %s %s\n", record.Timestamp.Format("15h"), record.DeviceId, record.SystemStatus)
+		}
+	}
+	// Output:
+	// 08h SALA-1 COOLING
+	// 08h SALA-2 COOLING
+	// 09h SALA-1 FAN_ONLY
+	// 09h SALA-2 IDLE
+	// 10h SALA-1 IDLE
+	// 10h SALA-2 IDLE
+}
+
+// alwaysCool resfria sempre que a sala passa do setpoint, ocupada ou não.
+type alwaysCool struct{}
+
+func (alwaysCool) Decide(state hvacmock.ZoneState) hvacmock.ControlDecision {
+	if state.Temperature > state.Setpoint {
+		return hvacmock.ControlDecision{Mode: "COOLING", Setpoint: state.Setpoint}
+	}
+	return hvacmock.ControlDecision{Mode: "IDLE", Setpoint: state.Setpoint}
+}
+
+func ExampleRegisterControlStrategy() {
+	hvacmock.RegisterControlStrategy("always-cool", func() hvacmock.ControlStrategy { return alwaysCool{} })
+
+	strategy, err := hvacmock.LookupControlStrategy("always-cool")
+	if err != nil {
+		panic(err)
+	}
+	generator, err := hvacmock.NewGenerator(
+		hvacmock.WithGeneratorSeed(42),
+		hvacmock.WithUnits(hvacmock.Unit{ID: "SALA-1", Zone: "Zona-A"}),
+		hvacmock.WithControlStrategy(strategy),
+	)
+	if err != nil {
+		panic(err)
+	}
+	// Domingo à noite, com a sala vazia: o termostato padrão desligaria
+	records := generator.Step(hvacmock.ClimateRecord{Timestamp: time.Date(2024, time.January, 14, 23, 0, 0, 0, time.UTC), TemperatureAir: 34, RelativeHumidity: 60})
+	fmt.Println(records[0].DeviceId, records[0].SystemStatus)
+	// Output:
+	// SALA-1 COOLING
+}
+
+func ExampleNewRenderer() {
+	device, err := hvacmock.New(hvacmock.WithID("SALA-7"))
+	if err != nil {
+		panic(err)
+	}
+	reading, err := device.Next(time.Date(2024, time.January, 15, 14, 0, 0, 0, time.UTC))
+	if err != nil {
+		panic(err)
+	}
+
+	site := hvacmock.TransformerFunc(func(payload map[string]any) error {
+		payload["site"] = "PREDIO-SP-01"
+		return nil
+	})
+	renderer, err := hvacmock.NewRenderer([]hvacmock.TransformConfig{
+		{Type: "rename", Fields: map[string]string{"deviceId": "equipmentId"}},
+	}, site)
+	if err != nil {
+		panic(err)
+	}
+	payload, err := renderer.Render(reading)
+	if err != nil {
+		panic(err)
+	}
+	fields := payload.(map[string]any)
+	fmt.Println(fields["equipmentId"], fields["site"])
+	// Output:
+	// SALA-7 PREDIO-SP-01
+}
+
+func ExampleGenerator_Records() {
+	generator, err := hvacmock.NewGenerator(hvacmock.WithGeneratorSeed(42))
+	if err != nil {
+		panic(err)
+	}
+	series := hvacmock.SyntheticClimate(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), 30)
+	cooling := 0
+	for record := range generator.Records(series) {
+		if record.Timestamp.Day() > 7 {
+			break // Só a primeira semana: os passos seguintes não são simulados
+		}
+		if record.SystemStatus == "COOLING" {
+			cooling++
+		}
+	}
+	fmt.Println(cooling > 0)
+	// Output:
+	// true
+}
+
+func ExampleGenerator_Stream() {
+	generator, err := hvacmock.NewGenerator(hvacmock.WithGeneratorSeed(42), hvacmock.WithUnits(hvacmock.Unit{ID: "SALA-1", Zone: "Zona-A"}))
+	if err != nil {
+		panic(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	series := hvacmock.SyntheticClimate(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), 1)
+	received := 0
+	for range generator.Stream(ctx, series) {
+		received++
+	}
+	fmt.Println(received)
+	// Output:
+	// 24
+}
@@ -0,0 +1,106 @@
+package hvacmock
+
+import (
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// Generator gera séries completas da frota, com os parâmetros do modelo ajustados por opções:
+// Step simula um passo de todas as unidades e Generate, uma leitura isolada. Para processar uma
+// série climática sem montá-la em memória, Records retorna um iter.Seq que simula cada passo só
+// quando o consumidor chega a ele, e Stream entrega os mesmos registros por um canal, até o fim da
+// série ou o cancelamento do contexto. Ao contrário de Device, que simula uma unidade para os
+// testes, é o gerador usado pelo comando mock-generator, para quem usa o módulo como biblioteca.
+type Generator = hvac.Generator
+
+// GeneratorOption ajusta a configuração do Generator criado por NewGenerator.
+type GeneratorOption = hvac.Option
+
+// SimulatorConfig é a configuração completa do simulador, usada por WithConfig.
+type SimulatorConfig = hvac.SimulatorConfig
+
+// Unit é uma unidade do catálogo simulado pelo Generator.
+type Unit = hvac.Device
+
+// ClimateRecord é o registro climático horário que alimenta cada passo do Generator.
+type ClimateRecord = climate.InmetClimateData
+
+// SyntheticClimate gera dias de registros climáticos horários a partir de start, com ciclo diário
+// de temperatura e umidade, para alimentar o Generator sem um arquivo do INMET.
+func SyntheticClimate(start time.Time, days int) []ClimateRecord {
+	return climate.Synthetic(start, days)
+}
+
+// OccupancyModel decide se a sala está ocupada no instante informado (ver WithOccupancyModel).
+type OccupancyModel = hvac.OccupancyModel
+
+// ScheduleOccupancy é o modelo de ocupação por horário, o padrão do gerador.
+type ScheduleOccupancy = hvac.ScheduleOccupancy
+
+// FaultModel substitui o desgaste sazonal e os alarmes sorteados (ver WithFaultModel).
+type FaultModel = hvac.FaultModel
+
+// NewGenerator cria o gerador com os parâmetros padrão ajustados pelas opções, na ordem informada.
+func NewGenerator(opts ...GeneratorOption) (*Generator, error) {
+	return hvac.NewGenerator(opts...)
+}
+
+// DefaultUnits retorna o catálogo de unidades padrão do gerador.
+func DefaultUnits() []Unit {
+	return hvac.DefaultDevices()
+}
+
+// DefaultOccupancy retorna o modelo de ocupação padrão do gerador.
+func DefaultOccupancy() ScheduleOccupancy {
+	return hvac.DefaultOccupancy()
+}
+
+// WithConfig parte de uma configuração completa do simulador; as demais opções são aplicadas por
+// cima.
+func WithConfig(cfg SimulatorConfig) GeneratorOption {
+	return hvac.WithConfig(cfg)
+}
+
+// WithGeneratorSeed fixa a semente dos geradores aleatórios (padrão: o relógio). É o WithSeed do
+// Generator; o de Device ajusta só o dispositivo.
+func WithGeneratorSeed(seed int64) GeneratorOption {
+	return hvac.WithSeed(seed)
+}
+
+// WithUnits define o catálogo de unidades simuladas (padrão: DefaultUnits).
+func WithUnits(units ...Unit) GeneratorOption {
+	return hvac.WithUnits(units...)
+}
+
+// WithBaseSetpoint define o setpoint programado das salas (°C).
+func WithBaseSetpoint(celsius float64) GeneratorOption {
+	return hvac.WithBaseSetpoint(celsius)
+}
+
+// WithDeadband define o desvio do setpoint que liga o resfriamento ou o aquecimento (°C).
+func WithDeadband(celsius float64) GeneratorOption {
+	return hvac.WithDeadband(celsius)
+}
+
+// WithOccupancyModel substitui o modelo de ocupação por horário.
+func WithOccupancyModel(model OccupancyModel) GeneratorOption {
+	return hvac.WithOccupancyModel(model)
+}
+
+// WithFaultRate multiplica a taxa de alarmes de compressor e filtro (1 mantém, 0 desliga).
+func WithFaultRate(scale float64) GeneratorOption {
+	return hvac.WithFaultRate(scale)
+}
+
+// WithNoise multiplica o ruído de processo e de medição (1 mantém, 0 gera séries sem ruído).
+func WithNoise(scale float64) GeneratorOption {
+	return hvac.WithNoise(scale)
+}
+
+// WithFaultModel substitui o desgaste sazonal e os alarmes sorteados por outro modelo de falhas.
+// WithFaultRate deixa de valer.
+func WithFaultModel(model FaultModel) GeneratorOption {
+	return hvac.WithFaultModel(model)
+}
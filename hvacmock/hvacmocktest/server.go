@@ -0,0 +1,281 @@
+// Package hvacmocktest sobe um servidor HTTP local, no estilo do httptest, que serve telemetria
+// gerada por dispositivos hvacmock para os testes de integração de outros repositórios, no lugar
+// de fixtures JSON copiadas à mão:
+//
+//	server := hvacmocktest.NewServer(t, hvacmocktest.WithDevices("SALA-1", "SALA-2"))
+//	resp, err := http.Get(server.URL + "/readings?steps=24")
+//
+// O servidor tem um relógio simulado: cada passo gera uma leitura de cada dispositivo no instante
+// seguinte da série (padrão: de hora em hora a partir de 15/01/2024 00:00 UTC). As rotas são:
+//
+//	GET /devices                   Identificadores dos dispositivos
+//	GET /readings?steps=N          Avança N passos (padrão: 1) e retorna as leituras geradas
+//	GET /devices/{id}/latest       Última leitura gerada do dispositivo (404 antes do primeiro passo)
+//	GET /ws?steps=N                WebSocket: avança um passo a cada PushInterval e envia cada
+//	                               leitura como uma mensagem JSON, até N passos ou o cliente fechar
+//
+// Os passos são compartilhados entre as rotas e os clientes, como num dispositivo real: duas
+// conexões simultâneas recebem passos diferentes.
+package hvacmocktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/patrik-rangel/mock-data-hvac/hvacmock"
+)
+
+// Option ajusta o servidor criado por NewServer.
+type Option func(*config)
+
+type config struct {
+	ids          []string
+	seed         int64
+	start        time.Time
+	step         time.Duration
+	pushInterval time.Duration
+	deviceOpts   []hvacmock.Option
+}
+
+// WithDevices define os identificadores dos dispositivos servidos (padrão: SALA-1).
+func WithDevices(ids ...string) Option {
+	return func(c *config) { c.ids = ids }
+}
+
+// WithSeed define a semente do primeiro dispositivo (padrão: 1); os seguintes usam as sementes
+// consecutivas, para não gerarem leituras idênticas.
+func WithSeed(seed int64) Option {
+	return func(c *config) { c.seed = seed }
+}
+
+// WithStart define o instante simulado do primeiro passo (padrão: 15/01/2024 00:00 UTC).
+func WithStart(start time.Time) Option {
+	return func(c *config) { c.start = start }
+}
+
+// WithStep define o intervalo simulado entre os passos (padrão: 1 h).
+func WithStep(step time.Duration) Option {
+	return func(c *config) { c.step = step }
+}
+
+// WithPushInterval define o intervalo real entre os passos enviados pelo WebSocket (padrão: 10 ms).
+func WithPushInterval(interval time.Duration) Option {
+	return func(c *config) { c.pushInterval = interval }
+}
+
+// WithDeviceOptions repassa opções a todos os dispositivos (ex: hvacmock.WithOutdoor).
+func WithDeviceOptions(opts ...hvacmock.Option) Option {
+	return func(c *config) { c.deviceOpts = append(c.deviceOpts, opts...) }
+}
+
+// Server é o servidor de telemetria. URL, Client e Close vêm do httptest.Server embutido.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	devices      []*hvacmock.Device
+	next         time.Time
+	step         time.Duration
+	pushInterval time.Duration
+	served       []hvacmock.Reading
+	latest       map[string]hvacmock.Reading
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewServer cria os dispositivos e inicia o servidor, encerrado automaticamente ao fim do teste.
+// Configurações inválidas falham o teste.
+func NewServer(tb testing.TB, opts ...Option) *Server {
+	tb.Helper()
+	cfg := config{
+		ids:          []string{"SALA-1"},
+		seed:         1,
+		start:        time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		step:         time.Hour,
+		pushInterval: 10 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if len(cfg.ids) == 0 {
+		tb.Fatalf("hvacmocktest: o servidor precisa de ao menos um dispositivo")
+	}
+	if cfg.step <= 0 {
+		tb.Fatalf("hvacmocktest: o intervalo entre os passos deve ser positivo, recebido %s", cfg.step)
+	}
+
+	s := &Server{
+		next:         cfg.start,
+		step:         cfg.step,
+		pushInterval: cfg.pushInterval,
+		latest:       make(map[string]hvacmock.Reading),
+		done:         make(chan struct{}),
+	}
+	for i, id := range cfg.ids {
+		deviceOpts := append([]hvacmock.Option{hvacmock.WithID(id), hvacmock.WithSeed(cfg.seed + int64(i))}, cfg.deviceOpts...)
+		device, err := hvacmock.New(deviceOpts...)
+		if err != nil {
+			tb.Fatalf("hvacmocktest: %v", err)
+		}
+		s.devices = append(s.devices, device)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /devices", s.handleDevices)
+	mux.HandleFunc("GET /readings", s.handleReadings)
+	mux.HandleFunc("GET /devices/{id}/latest", s.handleLatest)
+	mux.HandleFunc("GET /ws", s.handleWebSocket)
+	s.Server = httptest.NewServer(mux)
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Close encerra as conexões WebSocket abertas e o servidor.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.Server.Close()
+	})
+}
+
+// WebSocketURL é o endereço ws:// da rota /ws.
+func (s *Server) WebSocketURL() string {
+	return "ws" + s.URL[len("http"):] + "/ws"
+}
+
+// Advance avança n passos e retorna as leituras geradas, como GET /readings.
+func (s *Server) Advance(n int) ([]hvacmock.Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var readings []hvacmock.Reading
+	for range n {
+		for _, device := range s.devices {
+			reading, err := device.Next(s.next)
+			if err != nil {
+				return readings, err
+			}
+			s.latest[device.ID()] = reading
+			readings = append(readings, reading)
+		}
+		s.next = s.next.Add(s.step)
+	}
+	s.served = append(s.served, readings...)
+	return readings, nil
+}
+
+// Served retorna todas as leituras geradas até agora, em ordem, para as asserções do teste.
+func (s *Server) Served() []hvacmock.Reading {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]hvacmock.Reading(nil), s.served...)
+}
+
+func (s *Server) handleDevices(w http.ResponseWriter, _ *http.Request) {
+	ids := make([]string, len(s.devices))
+	for i, device := range s.devices {
+		ids[i] = device.ID()
+	}
+	writeJSON(w, http.StatusOK, ids)
+}
+
+func (s *Server) handleReadings(w http.ResponseWriter, r *http.Request) {
+	steps, err := stepsParam(r, 1)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	readings, err := s.Advance(steps)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, readings)
+}
+
+func (s *Server) handleLatest(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	reading, ok := s.latest[id]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("nenhuma leitura do dispositivo '%s'", id)})
+		return
+	}
+	writeJSON(w, http.StatusOK, reading)
+}
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	steps, err := stepsParam(r, 0)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // O Upgrader já respondeu ao cliente
+	}
+	defer conn.Close()
+
+	// Lê as mensagens do cliente só para detectar o fechamento da conexão
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(max(s.pushInterval, time.Millisecond))
+	defer ticker.Stop()
+	for sent := 0; steps == 0 || sent < steps; sent++ {
+		select {
+		case <-ticker.C:
+		case <-closed:
+			return
+		case <-s.done:
+			return
+		}
+		readings, err := s.Advance(1)
+		if err != nil {
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+			return
+		}
+		for _, reading := range readings {
+			if err := conn.WriteJSON(reading); err != nil {
+				return
+			}
+		}
+	}
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
+
+// stepsParam lê o parâmetro steps da consulta, com o valor padrão quando ausente.
+func stepsParam(r *http.Request, fallback int) (int, error) {
+	raw := r.URL.Query().Get("steps")
+	if raw == "" {
+		return fallback, nil
+	}
+	steps, err := strconv.Atoi(raw)
+	if err != nil || steps < 0 {
+		return 0, fmt.Errorf("valor inválido '%s' para steps (use um inteiro não negativo)", raw)
+	}
+	return steps, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
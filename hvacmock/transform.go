@@ -0,0 +1,24 @@
+package hvacmock
+
+import "github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+
+// Transformer ajusta o payload JSON de um registro antes da serialização.
+type Transformer = hvac.Transformer
+
+// TransformerFunc adapta uma função comum a Transformer, para ganchos próprios.
+type TransformerFunc = hvac.TransformerFunc
+
+// TransformConfig é um passo do pipeline de pós-processamento do cenário: units, rename, round ou
+// anonymize.
+type TransformConfig = hvac.TransformConfig
+
+// Renderer converte os registros do Generator nos payloads JSON do formato canônico, com o
+// pipeline de pós-processamento aplicado: Render retorna o payload de um registro e WriteJSON, o
+// arquivo de dados.
+type Renderer = hvac.PayloadRenderer
+
+// NewRenderer cria o Renderer com os passos do pipeline do cenário seguidos dos ganchos próprios,
+// aplicados a cada payload na ordem informada.
+func NewRenderer(transforms []TransformConfig, hooks ...Transformer) (*Renderer, error) {
+	return hvac.NewPayloadRenderer(hvac.PayloadConfig{Transforms: transforms, Hooks: hooks}, nil)
+}
@@ -0,0 +1,250 @@
+package climate
+
+import (
+	"archive/zip"
+	"fmt"
+	"log"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// StationSeries é a série de uma estação lida de um .zip com os CSVs de várias (ex: o arquivo anual
+// do INMET com todas as estações do país).
+type StationSeries struct {
+	Station string             // Código da estação (ex: A701), ou o nome do CSV fora do padrão do INMET
+	Files   []string           // CSVs da estação no .zip, em ordem de nome
+	Records []InmetClimateData // Medições de todos os CSVs, em ordem cronológica
+	Report  *ParseReport       // Relatório conjunto da leitura dos CSVs
+}
+
+// ReadClimateArchive lê todas as estações do .zip em paralelo, com a estrutura de CSV da
+// configuração. O resultado vem em ordem de código da estação, independente da ordem em que as
+// leituras terminam. No modo estrito, qualquer linha inválida em qualquer estação resulta em
+// *ParseError com o relatório conjunto.
+func ReadClimateArchive(filepath string, cfg SourceConfig) ([]StationSeries, error) {
+	opts, err := cfg.ParseOptions()
+	if err != nil {
+		return nil, err
+	}
+	layout, err := cfg.csvLayout()
+	if err != nil {
+		return nil, err
+	}
+	zipReader, err := zip.OpenReader(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo ZIP '%s': %w", filepath, err)
+	}
+	defer zipReader.Close()
+
+	stations := groupStations(zipReader.File)
+	if len(stations) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo .csv encontrado dentro do ZIP '%s'", filepath)
+	}
+	var entries []*zip.File
+	for _, station := range stations {
+		entries = append(entries, station.entries...)
+	}
+	results := parseEntries(entries, layout, cfg.Workers)
+
+	series := make([]StationSeries, len(stations))
+	total := &ParseReport{}
+	next := 0
+	for i, station := range stations {
+		series[i], err = mergeStation(station, results[next:next+len(station.entries)], len(entries) > 1)
+		if err != nil {
+			return nil, err
+		}
+		next += len(station.entries)
+		total.Rows += series[i].Report.Rows
+		total.Skipped = append(total.Skipped, series[i].Report.Skipped...)
+	}
+	if opts.Strict && len(total.Skipped) > 0 {
+		return nil, &ParseError{Report: total}
+	}
+	return series, nil
+}
+
+// readArchiveStation lê a estação escolhida do .zip, ou a primeira se nenhuma foi escolhida e há
+// várias. Os CSVs da estação (ex: um por ano) são lidos em paralelo e unidos em uma série.
+func readArchiveStation(filepath string, layout csvLayout, opts ParseOptions, code string, workers int) ([]InmetClimateData, *ParseReport, error) {
+	zipReader, err := zip.OpenReader(filepath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao abrir arquivo ZIP '%s': %w", filepath, err)
+	}
+	defer func() {
+		if cerr := zipReader.Close(); cerr != nil {
+			log.Printf("Aviso: Erro ao fechar o leitor: %v", cerr)
+		}
+	}()
+
+	stations := groupStations(zipReader.File)
+	if len(stations) == 0 {
+		return nil, nil, fmt.Errorf("nenhum arquivo .csv encontrado dentro do ZIP '%s'", filepath)
+	}
+	station := stations[0]
+	if code != "" {
+		found := false
+		for _, candidate := range stations {
+			if strings.EqualFold(candidate.code, code) {
+				station, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("estação '%s' não encontrada no ZIP '%s' (%d estações, a primeira é %s)", code, filepath, len(stations), stations[0].code)
+		}
+	} else if len(stations) > 1 {
+		log.Printf("Aviso: O ZIP '%s' tem %d estações e nenhuma foi escolhida em climate.station; usando %s.", filepath, len(stations), station.code)
+	}
+
+	var results []entryResult
+	if len(station.entries) == 1 {
+		// Um só CSV: leitura direta, com os avisos de cada linha como nos arquivos avulsos.
+		results = []entryResult{parseEntry(station.entries[0], layout, opts)}
+	} else {
+		results = parseEntries(station.entries, layout, workers)
+	}
+	series, err := mergeStation(station, results, len(station.entries) > 1)
+	if err != nil {
+		return nil, series.Report, err
+	}
+	if opts.Strict && len(series.Report.Skipped) > 0 {
+		return nil, series.Report, &ParseError{Report: series.Report}
+	}
+	return series.Records, series.Report, nil
+}
+
+// archiveStation são os CSVs de uma estação no .zip.
+type archiveStation struct {
+	code    string
+	entries []*zip.File
+}
+
+// groupStations agrupa os CSVs do .zip por estação, em ordem de código e, dentro dela, de nome.
+func groupStations(files []*zip.File) []archiveStation {
+	var entries []*zip.File
+	for _, f := range files {
+		if !f.FileInfo().IsDir() && strings.EqualFold(path.Ext(f.Name), ".csv") {
+			entries = append(entries, f)
+		}
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Name < entries[b].Name })
+
+	index := make(map[string]int)
+	var stations []archiveStation
+	for _, entry := range entries {
+		code := stationCode(entry.Name)
+		i, ok := index[code]
+		if !ok {
+			i = len(stations)
+			index[code] = i
+			stations = append(stations, archiveStation{code: code})
+		}
+		stations[i].entries = append(stations[i].entries, entry)
+	}
+	sort.SliceStable(stations, func(a, b int) bool { return stations[a].code < stations[b].code })
+	return stations
+}
+
+// stationCode extrai o código da estação do nome do CSV do INMET, nos arquivos anuais
+// (INMET_SE_SP_A701_SAO PAULO - MIRANTE_01-01-2024_A_31-12-2024.CSV) e nas exportações do BDMEP
+// (dados_A701_H_2024-01-01_2025-01-01.csv); nos demais, usa o nome sem diretório nem extensão.
+func stationCode(name string) string {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	parts := strings.Split(base, "_")
+	switch {
+	case len(parts) >= 4 && strings.EqualFold(parts[0], "INMET"):
+		return strings.ToUpper(parts[3])
+	case len(parts) >= 3 && strings.EqualFold(parts[0], "dados"):
+		return strings.ToUpper(parts[1])
+	}
+	return base
+}
+
+// entryResult é a leitura de um CSV do .zip.
+type entryResult struct {
+	records []InmetClimateData
+	report  *ParseReport
+	err     error
+}
+
+func parseEntry(entry *zip.File, layout csvLayout, opts ParseOptions) entryResult {
+	rc, err := entry.Open()
+	if err != nil {
+		return entryResult{err: fmt.Errorf("erro ao abrir arquivo '%s' dentro do ZIP: %w", entry.Name, err)}
+	}
+	defer rc.Close()
+	records, report, err := parseCSV(rc, layout, opts)
+	if err != nil {
+		err = fmt.Errorf("erro ao ler '%s': %w", entry.Name, err)
+	}
+	return entryResult{records: records, report: report, err: err}
+}
+
+// parseEntries lê os CSVs com um pool de workers (padrão: número de CPUs), no modo tolerante e sem
+// os avisos de cada linha, que se misturariam entre as leituras; o modo estrito é aplicado depois,
+// ao relatório conjunto. Os resultados seguem a ordem das entradas.
+func parseEntries(entries []*zip.File, layout csvLayout, workers int) []entryResult {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	results := make([]entryResult, len(entries))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(entries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = parseEntry(entries[i], layout, ParseOptions{quiet: true})
+			}
+		}()
+	}
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// mergeStation une as leituras dos CSVs da estação em uma série cronológica. Instantes repetidos
+// entre os CSVs (ex: arquivos anuais sobrepostos) ficam com a medição do primeiro, em ordem de nome.
+func mergeStation(station archiveStation, results []entryResult, tagFiles bool) (StationSeries, error) {
+	series := StationSeries{Station: station.code, Report: &ParseReport{}}
+	for i, result := range results {
+		name := station.entries[i].Name
+		series.Files = append(series.Files, name)
+		if result.report != nil {
+			if series.Report.Station == nil {
+				series.Report.Station = result.report.Station
+			}
+			series.Report.Rows += result.report.Rows
+			for _, issue := range result.report.Skipped {
+				if tagFiles {
+					issue.File = name
+				}
+				series.Report.Skipped = append(series.Report.Skipped, issue)
+			}
+		}
+		if result.err != nil {
+			return series, result.err
+		}
+		series.Records = append(series.Records, result.records...)
+	}
+	if len(results) > 1 {
+		sort.SliceStable(series.Records, func(a, b int) bool { return series.Records[a].Timestamp.Before(series.Records[b].Timestamp) })
+		unique := series.Records[:0]
+		for _, record := range series.Records {
+			if len(unique) > 0 && unique[len(unique)-1].Timestamp.Equal(record.Timestamp) {
+				continue
+			}
+			unique = append(unique, record)
+		}
+		series.Records = unique
+	}
+	return series, nil
+}
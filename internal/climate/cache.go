@@ -0,0 +1,148 @@
+package climate
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// cacheVersion muda quando o formato do cache ou a interpretação dos arquivos mudam, invalidando as
+// séries gravadas antes.
+const cacheVersion = 2
+
+// cachedSeries é a série gravada no cache: as medições em colunas, com os instantes em nanossegundos
+// Unix, e o relatório da leitura.
+type cachedSeries struct {
+	Version     int
+	Timestamps  []int64
+	Temperature []float64
+	Humidity    []float64
+	Report      ParseReport
+}
+
+// readClimateCached lê a série do cache, se houver uma para o conteúdo do arquivo e a configuração de
+// leitura, ou interpreta o arquivo e grava a série. O modo estrito é aplicado ao relatório guardado,
+// então uma série gravada no modo tolerante também falha no estrito se teve linhas descartadas.
+func readClimateCached(filename string, cfg SourceConfig) ([]InmetClimateData, *ParseReport, error) {
+	opts, err := cfg.ParseOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := cacheKey(filename, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	cachePath := filepath.Join(cfg.CacheDir, fmt.Sprintf("%s-%s.gob", strings.TrimSuffix(path.Base(filename), path.Ext(filename)), key))
+
+	if series, err := loadCachedSeries(cachePath); err == nil {
+		location := time.UTC
+		if cfg.Format != "isd" && cfg.Format != "era5" {
+			if location, err = cfg.location(); err != nil {
+				return nil, nil, err
+			}
+		}
+		records := make([]InmetClimateData, len(series.Timestamps))
+		for i, ns := range series.Timestamps {
+			records[i] = InmetClimateData{
+				Timestamp:        time.Unix(0, ns).In(location),
+				TemperatureAir:   series.Temperature[i],
+				RelativeHumidity: series.Humidity[i],
+			}
+		}
+		log.Printf("Série climática lida do cache '%s'.", cachePath)
+		report := &series.Report
+		if opts.Strict && len(report.Skipped) > 0 {
+			return nil, report, &ParseError{Report: report}
+		}
+		return records, report, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Aviso: Cache climático '%s' ilegível, o arquivo será lido de novo: %v", cachePath, err)
+	}
+
+	records, report, err := readClimate(filename, cfg)
+	if err != nil {
+		return nil, report, err
+	}
+	series := cachedSeries{Version: cacheVersion, Report: *report}
+	for _, record := range records {
+		series.Timestamps = append(series.Timestamps, record.Timestamp.UnixNano())
+		series.Temperature = append(series.Temperature, record.TemperatureAir)
+		series.Humidity = append(series.Humidity, record.RelativeHumidity)
+	}
+	if err := storeCachedSeries(cachePath, series); err != nil {
+		log.Printf("Aviso: Não foi possível gravar o cache climático '%s': %v", cachePath, err)
+	}
+	return records, report, nil
+}
+
+// cacheKey identifica a série pelo conteúdo do arquivo e pela configuração que muda a interpretação
+// dele (formato, colunas, ponto da grade...). O modo de leitura e o próprio cache ficam de fora.
+func cacheKey(filename string, cfg SourceConfig) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("erro ao abrir o arquivo climático '%s': %w", filename, err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("erro ao calcular o hash do arquivo climático '%s': %w", filename, err)
+	}
+
+	cfg.ParseMode, cfg.CacheDir, cfg.File, cfg.Workers = "", "", "", 0
+	settings, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("erro ao serializar a configuração climática para o cache: %w", err)
+	}
+	fmt.Fprintf(hash, "\x00%d\x00%s", cacheVersion, settings)
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+func loadCachedSeries(cachePath string) (*cachedSeries, error) {
+	file, err := os.Open(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var series cachedSeries
+	if err := gob.NewDecoder(file).Decode(&series); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar: %w", err)
+	}
+	if series.Version != cacheVersion {
+		return nil, fmt.Errorf("versão %d do cache, esperada %d", series.Version, cacheVersion)
+	}
+	if len(series.Temperature) != len(series.Timestamps) || len(series.Humidity) != len(series.Timestamps) {
+		return nil, fmt.Errorf("colunas de tamanhos diferentes")
+	}
+	return &series, nil
+}
+
+// storeCachedSeries grava a série em um arquivo temporário e o renomeia, para que uma execução
+// concorrente nunca leia um cache pela metade.
+func storeCachedSeries(cachePath string, series cachedSeries) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), ".climate-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(series); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cachePath)
+}
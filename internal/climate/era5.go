@@ -0,0 +1,114 @@
+package climate
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// gridSample são os valores de um instante na célula da grade (nil se ausentes).
+type gridSample struct {
+	temperature *float64 // Temperatura a 2 m (K)
+	dewPoint    *float64 // Ponto de orvalho a 2 m (K)
+}
+
+// gridPoint é a série extraída da célula da grade mais próxima do ponto pedido.
+type gridPoint struct {
+	latitude, longitude float64 // Centro da célula
+	samples             map[time.Time]*gridSample
+}
+
+// sample retorna os valores do instante, criando-os se preciso.
+func (p *gridPoint) sample(timestamp time.Time) *gridSample {
+	s, ok := p.samples[timestamp]
+	if !ok {
+		s = &gridSample{}
+		p.samples[timestamp] = s
+	}
+	return s
+}
+
+func (p *gridPoint) set(timestamp time.Time, temperature bool, value float64) {
+	s := p.sample(timestamp)
+	if temperature {
+		s.temperature = &value
+	} else {
+		s.dewPoint = &value
+	}
+}
+
+// readERA5 extrai a série horária da célula mais próxima do ponto de um arquivo de reanálise ERA5,
+// em NetCDF clássico ou GRIB, com a temperatura (t2m/2t) e o ponto de orvalho (d2m/2d) a 2 m. O
+// formato é reconhecido pelo conteúdo. Cada instante da grade conta como uma linha de medição do
+// relatório, numerada a partir de 1 na ordem cronológica.
+func readERA5(filepath string, latitude, longitude float64, opts ParseOptions) ([]InmetClimateData, *ParseReport, error) {
+	reader, closeFile, err := openClimateFile(filepath, "nc", "grib", "grb", "grib1", "grb1", "grib2", "grb2")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeFile()
+
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(4)
+	var point *gridPoint
+	switch {
+	case bytes.HasPrefix(magic, []byte("CDF")):
+		// O NetCDF é lido por offset: direto do arquivo, ou da memória se veio compactado.
+		if file, ok := reader.(*os.File); ok {
+			info, statErr := file.Stat()
+			if statErr != nil {
+				return nil, nil, fmt.Errorf("erro ao ler o arquivo NetCDF '%s': %w", filepath, statErr)
+			}
+			point, err = readNetCDFPoint(file, info.Size(), latitude, longitude)
+		} else {
+			data, readErr := io.ReadAll(buffered)
+			if readErr != nil {
+				return nil, nil, fmt.Errorf("erro ao ler o arquivo NetCDF '%s': %w", filepath, readErr)
+			}
+			point, err = readNetCDFPoint(bytes.NewReader(data), int64(len(data)), latitude, longitude)
+		}
+	case bytes.HasPrefix(magic, []byte("\x89HDF")):
+		return nil, nil, fmt.Errorf("arquivo '%s' é NetCDF-4 (HDF5), não suportado: baixe o ERA5 em GRIB ou converta para o NetCDF clássico (ex: nccopy -k classic)", filepath)
+	default:
+		point, err = readGRIBPoint(buffered, latitude, longitude)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler a reanálise '%s': %w", filepath, err)
+	}
+	log.Printf("Série climática extraída da célula da grade em %.3f, %.3f (ponto pedido: %.3f, %.3f).", point.latitude, point.longitude, latitude, longitude)
+	if math.Abs(point.latitude-latitude) > 1 || math.Abs(math.Remainder(point.longitude-longitude, 360)) > 1 {
+		log.Printf("Aviso: A célula mais próxima fica a mais de 1° do ponto pedido: o ponto provavelmente está fora da área do arquivo.")
+	}
+
+	times := make([]time.Time, 0, len(point.samples))
+	for timestamp := range point.samples {
+		times = append(times, timestamp)
+	}
+	sort.Slice(times, func(a, b int) bool { return times[a].Before(times[b]) })
+
+	obs := &noaaObservations{report: &ParseReport{Rows: len(times)}, opts: opts}
+	for i, timestamp := range times {
+		s := point.samples[timestamp]
+		instant := timestamp.Format(time.RFC3339)
+		switch {
+		case s.temperature == nil:
+			obs.skip(i+1, "temperatura do ar ausente", fmt.Sprintf("Temperatura a 2 m ausente em %s", instant))
+		case s.dewPoint == nil:
+			obs.skip(i+1, "umidade relativa ausente", fmt.Sprintf("Ponto de orvalho a 2 m ausente em %s", instant))
+		default:
+			temperature := *s.temperature - 273.15
+			obs.records = append(obs.records, InmetClimateData{
+				Timestamp:        timestamp,
+				TemperatureAir:   temperature,
+				RelativeHumidity: relativeHumidity(temperature, *s.dewPoint-273.15),
+			})
+		}
+	}
+	return obs.result()
+}
@@ -0,0 +1,200 @@
+package climate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"log"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// As fixtures em testdata/ são uma grade de 3 longitudes (47 W a 45 W) por 2 latitudes (-23 e
+// -24), com valores diferentes em cada célula. O ponto pedido (-23.6, -46.2) cai na célula de
+// -24, -46.
+//
+//   - era5.grib2: 00h com 2t e 2d na mesma mensagem; 01h com 2t em previsão de 1 h (com bitmap,
+//     primeira célula ausente) e 2d em outra referência; 02h com 2t em previsão de 120 min e 2d
+//     com a célula do ponto fora do bitmap. Uma mensagem de pressão à superfície é ignorada.
+//   - era5.grib1: varredura para o norte, 00h e 01h (previsão de 1 h), com o 2d das 01h fora do
+//     bitmap no ponto, e uma mensagem de pressão à superfície ignorada.
+//   - era5.nc: NetCDF clássico no layout do ERA5 (t2m e d2m em short com scale_factor e
+//     add_offset, tempo em horas desde 1900), 00h a 02h, com o d2m das 02h em _FillValue.
+const (
+	era5Latitude  = -23.6
+	era5Longitude = -46.2
+)
+
+// era5Sample é uma medição esperada, em kelvin.
+type era5Sample struct {
+	hour                  int
+	temperature, dewPoint float64
+}
+
+func quietLog(t *testing.T) {
+	log.SetOutput(io.Discard) // A célula usada e as linhas descartadas geram avisos
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+func TestReadERA5Fixtures(t *testing.T) {
+	quietLog(t)
+	tests := []struct {
+		file    string
+		samples []era5Sample
+		skipped int // Instantes sem o ponto de orvalho no ponto
+	}{
+		{"testdata/era5.grib2", []era5Sample{{0, 296.15, 288.15}, {1, 297.15, 289.15}}, 1},
+		{"testdata/era5.grib1", []era5Sample{{0, 296.1, 288.1}}, 1},
+		{"testdata/era5.nc", []era5Sample{{0, 296.15, 288.15}, {1, 297.15, 289.15}}, 1},
+	}
+	latitude, longitude := era5Latitude, era5Longitude
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			records, report, err := ReadClimate(tt.file, SourceConfig{Format: "era5", Latitude: &latitude, Longitude: &longitude})
+			if err != nil {
+				t.Fatalf("ReadClimate: %v", err)
+			}
+			if len(records) != len(tt.samples) {
+				t.Fatalf("%d registros, esperado %d: %+v", len(records), len(tt.samples), records)
+			}
+			for i, want := range tt.samples {
+				record := records[i]
+				timestamp := time.Date(2024, time.January, 15, want.hour, 0, 0, 0, time.UTC)
+				temperature := want.temperature - 273.15
+				humidity := relativeHumidity(temperature, want.dewPoint-273.15)
+				if !record.Timestamp.Equal(timestamp) || math.Abs(record.TemperatureAir-temperature) > 1e-3 || math.Abs(record.RelativeHumidity-humidity) > 1e-3 {
+					t.Errorf("registro %d = %s %.3f °C %.3f%%, esperado %s %.3f °C %.3f%%", i, record.Timestamp.Format(time.RFC3339), record.TemperatureAir, record.RelativeHumidity, timestamp.Format(time.RFC3339), temperature, humidity)
+				}
+			}
+			if report.Rows != len(tt.samples)+tt.skipped || len(report.Skipped) != tt.skipped {
+				t.Errorf("relatório com %d linhas e %d descartadas, esperado %d e %d", report.Rows, len(report.Skipped), len(tt.samples)+tt.skipped, tt.skipped)
+			}
+			for _, issue := range report.Skipped {
+				if issue.Reason != "umidade relativa ausente" {
+					t.Errorf("linha %d descartada por '%s', esperado ponto de orvalho ausente", issue.Line, issue.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestERA5NearestCell(t *testing.T) {
+	for _, file := range []string{"testdata/era5.grib2", "testdata/era5.grib1", "testdata/era5.nc"} {
+		data := readFixture(t, file)
+		var point *gridPoint
+		var err error
+		if bytes.HasPrefix(data, []byte("CDF")) {
+			point, err = readNetCDFPoint(bytes.NewReader(data), int64(len(data)), era5Latitude, era5Longitude)
+		} else {
+			point, err = readGRIBPoint(bytes.NewReader(data), era5Latitude, era5Longitude)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if math.Abs(point.latitude+24) > 1e-6 || math.Abs(point.longitude+46) > 1e-6 {
+			t.Errorf("%s: célula %.3f, %.3f, esperado -24, -46", file, point.latitude, point.longitude)
+		}
+	}
+}
+
+func TestReadGRIBCorrupt(t *testing.T) {
+	for _, file := range []string{"testdata/era5.grib2", "testdata/era5.grib1"} {
+		data := readFixture(t, file)
+		// Cortado em qualquer ponto, o arquivo só é aceito se terminar no fim de uma mensagem
+		for n := range len(data) {
+			if _, err := readGRIBPoint(bytes.NewReader(data[:n]), era5Latitude, era5Longitude); err == nil && !bytes.HasSuffix(data[:n], []byte("7777")) {
+				t.Errorf("%s cortado em %d bytes aceito sem erro", file, n)
+			}
+		}
+	}
+
+	grib2 := readFixture(t, "testdata/era5.grib2")
+	grib1 := readFixture(t, "testdata/era5.grib1")
+	// As mutações valem para a segunda mensagem, a primeira com 2t; o restante do arquivo segue
+	// intacto
+	tests := []struct {
+		name   string
+		data   []byte
+		mutate func(message []byte)
+	}{
+		{"GRIB2 com tamanho da mensagem inválido", grib2, func(m []byte) { binary.BigEndian.PutUint64(m[8:16], 1<<40) }},
+		{"GRIB2 com seção maior que a mensagem", grib2, func(m []byte) { binary.BigEndian.PutUint32(m[16:20], 0xFFFF) }},
+		{"GRIB2 com edição desconhecida", grib2, func(m []byte) { m[7] = 3 }},
+		{"GRIB2 sem o indicador de fim", grib2, func(m []byte) { copy(m[len(m)-4:], "0000") }},
+		{"GRIB2 com dados curtos", grib2, func(m []byte) {
+			// 64 bits por valor: a seção 7 não tem o valor da célula do ponto
+			m[bytes.Index(m, []byte{0, 0, 0, 21, 5})+19] = 64
+		}},
+		{"GRIB1 com grade não suportada", grib1, func(m []byte) { m[8+28+5] = 1 }},
+		{"GRIB1 com tamanho da mensagem inválido", grib1, func(m []byte) { copy(m[4:7], []byte{0, 0, 1}) }},
+		{"GRIB1 com dados curtos", grib1, func(m []byte) { m[8+28+32+10] = 64 }}, // Bits por valor da BDS, depois da PDS e da GDS
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Clone(tt.data)
+			first := gribLength(data)
+			tt.mutate(data[first : first+gribLength(data[first:])])
+			if _, err := readGRIBPoint(bytes.NewReader(data), era5Latitude, era5Longitude); err == nil {
+				t.Error("arquivo corrompido aceito sem erro")
+			}
+		})
+	}
+}
+
+func TestReadNetCDFCorrupt(t *testing.T) {
+	data := readFixture(t, "testdata/era5.nc")
+	// Os dois últimos bytes são uma célula fora do ponto; qualquer corte antes deles falha
+	for n := range len(data) - 2 {
+		if _, err := readNetCDFPoint(bytes.NewReader(data[:n]), int64(n), era5Latitude, era5Longitude); err == nil {
+			t.Errorf("NetCDF cortado em %d bytes aceito sem erro", n)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(data []byte)
+	}{
+		{"versão desconhecida", func(d []byte) { d[3] = 3 }},
+		{"número de registros além do arquivo", func(d []byte) { binary.BigEndian.PutUint32(d[4:8], 1000) }},
+		{"registros em streaming", func(d []byte) { binary.BigEndian.PutUint32(d[4:8], math.MaxUint32) }},
+		{"marcador da lista de dimensões", func(d []byte) { binary.BigEndian.PutUint32(d[8:12], ncVariableTag) }},
+		{"contagem de dimensões enorme", func(d []byte) { binary.BigEndian.PutUint32(d[12:16], 1<<30) }},
+		{"dimensão inexistente na variável", func(d []byte) {
+			// Primeira variável: longitude, com a dimensão 0 logo depois da contagem de dimensões
+			at := bytes.Index(d, []byte("longitude\x00\x00\x00\x00\x00\x00\x01"))
+			binary.BigEndian.PutUint32(d[at+16:at+20], 9)
+		}},
+		{"tipo desconhecido na variável", func(d []byte) {
+			at := bytes.Index(d, []byte("degrees_north"))
+			binary.BigEndian.PutUint32(d[at+16:at+20], 42)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrupt := bytes.Clone(data)
+			tt.mutate(corrupt)
+			if _, err := readNetCDFPoint(bytes.NewReader(corrupt), int64(len(corrupt)), era5Latitude, era5Longitude); err == nil {
+				t.Error("arquivo corrompido aceito sem erro")
+			}
+		})
+	}
+}
+
+func readFixture(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// gribLength retorna o tamanho declarado da mensagem GRIB no início dos dados.
+func gribLength(data []byte) int {
+	if data[7] == 1 {
+		return int(unsigned(data[4:7]))
+	}
+	return int(binary.BigEndian.Uint64(data[8:16]))
+}
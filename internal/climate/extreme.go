@@ -0,0 +1,82 @@
+package climate
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const extremeEventDateLayout = "2006-01-02"
+
+// ExtremeEvent descreve um evento climático extremo (onda de calor, frente fria)
+// injetado sobre a série histórica.
+type ExtremeEvent struct {
+	Name             string  `json:"name"`             // Identificador do evento (ex: onda-de-calor-fev)
+	Start            string  `json:"start"`            // Data de início no formato AAAA-MM-DD
+	DurationDays     float64 `json:"durationDays"`     // Duração do evento em dias
+	TemperatureDelta float64 `json:"temperatureDelta"` // Variação somada à temperatura do ar (°C)
+	HumidityDelta    float64 `json:"humidityDelta"`    // Variação somada à umidade relativa (pontos percentuais)
+	Stress           float64 `json:"stress"`           // Intensidade do estresse nos equipamentos (0 a 1)
+}
+
+// extremeEventRampHours é o tempo de subida/descida do evento, evitando degraus na série.
+const extremeEventRampHours = 6.0
+
+func (e ExtremeEvent) window() (time.Time, time.Time, error) {
+	start, err := time.Parse(extremeEventDateLayout, e.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("data de início inválida no evento extremo '%s': %w", e.Name, err)
+	}
+	if e.DurationDays <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("duração do evento extremo '%s' deve ser maior que zero", e.Name)
+	}
+	end := start.Add(time.Duration(e.DurationDays * 24 * float64(time.Hour)))
+	return start, end, nil
+}
+
+// intensity retorna o fator (0 a 1) do evento no instante t, com rampas no início e no fim.
+func intensity(t, start, end time.Time) float64 {
+	if t.Before(start) || !t.Before(end) {
+		return 0
+	}
+	fromStart := t.Sub(start).Hours()
+	toEnd := end.Sub(t).Hours()
+	return math.Min(1.0, math.Min(fromStart, toEnd)/extremeEventRampHours)
+}
+
+// ApplyExtremeEvents injeta os eventos extremos configurados nos registros climáticos,
+// ajustando temperatura e umidade e marcando o estresse correspondente em cada registro.
+func ApplyExtremeEvents(records []InmetClimateData, events []ExtremeEvent) ([]InmetClimateData, error) {
+	if len(events) == 0 {
+		return records, nil
+	}
+
+	type window struct {
+		event      ExtremeEvent
+		start, end time.Time
+	}
+	windows := make([]window, 0, len(events))
+	for _, e := range events {
+		start, end, err := e.window()
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window{event: e, start: start, end: end})
+	}
+
+	result := make([]InmetClimateData, len(records))
+	for i, record := range records {
+		for _, w := range windows {
+			factor := intensity(record.Timestamp, w.start, w.end)
+			if factor == 0 {
+				continue
+			}
+			record.TemperatureAir += w.event.TemperatureDelta * factor
+			record.RelativeHumidity = math.Max(0.0, math.Min(100.0, record.RelativeHumidity+w.event.HumidityDelta*factor))
+			record.ExtremeEvent = w.event.Name
+			record.Stress = math.Max(record.Stress, math.Max(0.0, math.Min(1.0, w.event.Stress*factor)))
+		}
+		result[i] = record
+	}
+	return result, nil
+}
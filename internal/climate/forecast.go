@@ -0,0 +1,61 @@
+package climate
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ForecastConfig controla a geração de previsões imperfeitas de temperatura externa.
+type ForecastConfig struct {
+	HorizonsHours []int   `json:"horizonsHours"` // Horizontes de previsão em horas (padrão: 1, 6 e 24)
+	ErrorStdDev   float64 `json:"errorStdDev"`   // Desvio padrão do erro no horizonte de 24 h (°C)
+	Bias          float64 `json:"bias"`          // Viés sistemático somado a todas as previsões (°C)
+}
+
+// Forecast é a temperatura externa prevista para um horizonte a partir do registro.
+type Forecast struct {
+	HorizonHours int     `json:"horizonHours"` // Horizonte da previsão (h)
+	Temperature  float64 `json:"temperature"`  // Temperatura externa prevista (°C)
+}
+
+var defaultForecastHorizons = []int{1, 6, 24}
+
+// Horizons retorna os horizontes configurados ou os horizontes padrão.
+func (c ForecastConfig) Horizons() []int {
+	if len(c.HorizonsHours) == 0 {
+		return defaultForecastHorizons
+	}
+	return c.HorizonsHours
+}
+
+// AttachForecasts anexa a cada registro as previsões de temperatura para os horizontes
+// configurados, derivadas dos valores reais futuros somados a um erro que cresce com o horizonte.
+// Horizontes sem dado real correspondente (fim da série ou lacunas) são omitidos.
+func AttachForecasts(records []InmetClimateData, cfg ForecastConfig, r *rand.Rand) []InmetClimateData {
+	horizons := cfg.Horizons()
+
+	actuals := make(map[time.Time]float64, len(records))
+	for _, record := range records {
+		actuals[record.Timestamp] = record.TemperatureAir
+	}
+
+	result := make([]InmetClimateData, len(records))
+	for i, record := range records {
+		forecasts := make([]Forecast, 0, len(horizons))
+		for _, h := range horizons {
+			actual, ok := actuals[record.Timestamp.Add(time.Duration(h)*time.Hour)]
+			if !ok {
+				continue
+			}
+			stdDev := cfg.ErrorStdDev * math.Sqrt(float64(h)/24.0)
+			forecasts = append(forecasts, Forecast{
+				HorizonHours: h,
+				Temperature:  actual + cfg.Bias + r.NormFloat64()*stdDev,
+			})
+		}
+		record.Forecasts = forecasts
+		result[i] = record
+	}
+	return result
+}
@@ -0,0 +1,541 @@
+package climate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Decodificador mínimo de GRIB (edições 1 e 2) para extrair a série de um ponto dos campos de
+// temperatura e ponto de orvalho a 2 m: grades regulares de latitude e longitude com empacotamento
+// simples, o padrão do ERA5 nos parâmetros de superfície. Os demais campos da mensagem são ignorados.
+
+// gribMaxMessage limita o tamanho de uma mensagem, para que um arquivo corrompido não reserve
+// memória arbitrária.
+const gribMaxMessage = 1 << 30
+
+// gribGrid é uma grade regular de latitude e longitude.
+type gribGrid struct {
+	ni, nj   int
+	la1, lo1 float64 // Primeiro ponto (graus)
+	la2, lo2 float64 // Último ponto (graus)
+	di, dj   float64 // Incrementos (graus)
+	scan     byte    // Modo de varredura
+}
+
+// nearest retorna o índice do ponto da grade mais próximo e as coordenadas dele.
+func (g gribGrid) nearest(latitude, longitude float64) (int, float64, float64, error) {
+	if g.ni <= 0 || g.nj <= 0 {
+		return 0, 0, 0, fmt.Errorf("grade GRIB vazia")
+	}
+	if g.scan&0x20 != 0 {
+		return 0, 0, 0, fmt.Errorf("modo de varredura GRIB 0x%02X (colunas consecutivas) não suportado", g.scan)
+	}
+	di, dj := g.di, g.dj
+	if di <= 0 && g.ni > 1 {
+		di = math.Abs(math.Remainder(g.lo2-g.lo1, 360)) / float64(g.ni-1)
+	}
+	if dj <= 0 && g.nj > 1 {
+		dj = math.Abs(g.la2-g.la1) / float64(g.nj-1)
+	}
+	eastward := g.scan&0x80 == 0
+	northward := g.scan&0x40 != 0
+
+	lonOf := func(i int) float64 {
+		if eastward {
+			return g.lo1 + float64(i)*di
+		}
+		return g.lo1 - float64(i)*di
+	}
+	latOf := func(j int) float64 {
+		if northward {
+			return g.la1 + float64(j)*dj
+		}
+		return g.la1 - float64(j)*dj
+	}
+	i, j := 0, 0
+	if di > 0 {
+		delta := longitude - g.lo1
+		if !eastward {
+			delta = -delta
+		}
+		delta = math.Mod(math.Mod(delta, 360)+360, 360)
+		i = int(math.Round(delta / di))
+		if i > g.ni-1 {
+			// Depois do último ponto: o mais próximo é ele ou, dando a volta no globo, o primeiro.
+			i = g.ni - 1
+			if math.Abs(math.Remainder(lonOf(0)-longitude, 360)) < math.Abs(math.Remainder(lonOf(i)-longitude, 360)) {
+				i = 0
+			}
+		}
+	}
+	if dj > 0 {
+		delta := g.la1 - latitude
+		if northward {
+			delta = -delta
+		}
+		j = min(max(int(math.Round(delta/dj)), 0), g.nj-1)
+	}
+	return j*g.ni + i, latOf(j), math.Remainder(lonOf(i), 360), nil
+}
+
+// gribField é o valor de um campo no ponto, com a identificação do parâmetro e o instante.
+type gribField struct {
+	parameter string // 2t, 2d ou vazio (outro parâmetro)
+	valid     time.Time
+	latitude  float64 // Célula da grade
+	longitude float64
+	value     float64
+	present   bool // Falso se o ponto está fora do bitmap
+}
+
+// gribUnit converte a unidade de tempo das edições 1 (tabela 4) e 2 (tabela 4.4).
+func gribUnit(edition, unit byte) (time.Duration, error) {
+	switch unit {
+	case 0:
+		return time.Minute, nil
+	case 1:
+		return time.Hour, nil
+	case 2:
+		return 24 * time.Hour, nil
+	case 10:
+		return 3 * time.Hour, nil
+	case 11:
+		return 6 * time.Hour, nil
+	case 12:
+		return 12 * time.Hour, nil
+	case 13:
+		if edition == 2 {
+			return time.Second, nil
+		}
+		return 15 * time.Minute, nil
+	case 14:
+		if edition == 1 {
+			return 30 * time.Minute, nil
+		}
+	case 254:
+		if edition == 1 {
+			return time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("unidade de tempo %d do GRIB%d desconhecida", unit, edition)
+}
+
+// signMagnitude interpreta um inteiro com o bit mais alto como sinal, como no GRIB.
+func signMagnitude(b []byte) int64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	sign := uint64(1) << (8*len(b) - 1)
+	if v&sign != 0 {
+		return -int64(v &^ sign)
+	}
+	return int64(v)
+}
+
+func unsigned(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// ibmFloat converte o ponto flutuante IBM de 32 bits do GRIB1.
+func ibmFloat(b []byte) float64 {
+	v := binary.BigEndian.Uint32(b)
+	mantissa := float64(v & 0x00FFFFFF)
+	value := mantissa / (1 << 24) * math.Pow(16, float64(int(v>>24&0x7F)-64))
+	if v&0x80000000 != 0 {
+		return -value
+	}
+	return value
+}
+
+// simplePacking decodifica o valor empacotado no índice: (R + X·2^E) / 10^D.
+type simplePacking struct {
+	reference float64
+	binary    int
+	decimal   int
+	bits      int
+	data      []byte
+	bitmap    []byte // nil sem bitmap
+}
+
+func (p simplePacking) value(index int) (float64, bool, error) {
+	if p.bitmap != nil {
+		if index/8 >= len(p.bitmap) {
+			return 0, false, fmt.Errorf("bitmap GRIB truncado")
+		}
+		if p.bitmap[index/8]&(0x80>>(index%8)) == 0 {
+			return 0, false, nil
+		}
+		// O valor empacotado é o dos pontos presentes antes deste.
+		set := 0
+		for _, b := range p.bitmap[:index/8] {
+			set += bits.OnesCount8(b)
+		}
+		set += bits.OnesCount8(p.bitmap[index/8] >> (8 - index%8))
+		index = set
+	}
+	if p.bits > 64 {
+		return 0, false, fmt.Errorf("%d bits por valor no GRIB não suportados", p.bits)
+	}
+	var x uint64
+	if p.bits > 0 {
+		start := index * p.bits
+		if (start+p.bits+7)/8 > len(p.data) {
+			return 0, false, fmt.Errorf("dados GRIB truncados")
+		}
+		for k := 0; k < p.bits; k++ {
+			bit := start + k
+			x = x<<1 | uint64(p.data[bit/8]>>(7-bit%8)&1)
+		}
+	}
+	return (p.reference + float64(x)*math.Pow(2, float64(p.binary))) / math.Pow(10, float64(p.decimal)), true, nil
+}
+
+// readGRIBPoint extrai a série de temperatura e ponto de orvalho a 2 m da célula mais próxima do
+// ponto, em todas as mensagens do arquivo.
+func readGRIBPoint(reader io.Reader, latitude, longitude float64) (*gridPoint, error) {
+	buffered := bufio.NewReader(reader)
+	var point *gridPoint
+	for n := 1; ; n++ {
+		message, err := nextGRIBMessage(buffered)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var fields []gribField
+		switch message[7] {
+		case 1:
+			fields, err = decodeGRIB1(message, latitude, longitude)
+		case 2:
+			fields, err = decodeGRIB2(message, latitude, longitude)
+		default:
+			err = fmt.Errorf("edição %d não suportada", message[7])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao decodificar a mensagem GRIB %d: %w", n, err)
+		}
+		for _, field := range fields {
+			if field.parameter == "" {
+				continue
+			}
+			if point == nil {
+				point = &gridPoint{latitude: field.latitude, longitude: field.longitude, samples: make(map[time.Time]*gridSample)}
+			}
+			if field.present {
+				point.set(field.valid, field.parameter == "2t", field.value)
+			} else {
+				point.sample(field.valid)
+			}
+		}
+	}
+	if point == nil {
+		return nil, fmt.Errorf("nenhum campo de temperatura (2t) ou ponto de orvalho (2d) a 2 m no arquivo GRIB")
+	}
+	return point, nil
+}
+
+// nextGRIBMessage lê a próxima mensagem inteira, a partir do indicador "GRIB".
+func nextGRIBMessage(r *bufio.Reader) ([]byte, error) {
+	// Pula o que houver entre as mensagens até o indicador.
+	matched := 0
+	for matched < 4 {
+		c, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && matched == 0 {
+				return nil, io.EOF
+			}
+			if err == io.EOF {
+				return nil, fmt.Errorf("arquivo GRIB truncado")
+			}
+			return nil, fmt.Errorf("erro ao ler o arquivo GRIB: %w", err)
+		}
+		switch {
+		case c == "GRIB"[matched]:
+			matched++
+		case c == 'G':
+			matched = 1
+		default:
+			matched = 0
+		}
+	}
+	head := make([]byte, 16)
+	copy(head, "GRIB")
+	if _, err := io.ReadFull(r, head[4:8]); err != nil {
+		return nil, fmt.Errorf("arquivo GRIB truncado: %w", err)
+	}
+	var length uint64
+	switch head[7] {
+	case 1:
+		length = unsigned(head[4:7])
+		head = head[:8]
+	case 2:
+		if _, err := io.ReadFull(r, head[8:16]); err != nil {
+			return nil, fmt.Errorf("arquivo GRIB truncado: %w", err)
+		}
+		length = unsigned(head[8:16])
+	default:
+		return nil, fmt.Errorf("edição GRIB %d não suportada", head[7])
+	}
+	if length < uint64(len(head))+4 || length > gribMaxMessage {
+		return nil, fmt.Errorf("tamanho de mensagem GRIB %d inválido", length)
+	}
+	// Lê pelo tamanho declarado sem reservá-lo antes, para não alocar o de uma mensagem corrompida.
+	var buffer bytes.Buffer
+	buffer.Write(head)
+	if n, err := io.Copy(&buffer, io.LimitReader(r, int64(length)-int64(len(head)))); err != nil || uint64(n)+uint64(len(head)) != length {
+		return nil, fmt.Errorf("mensagem GRIB truncada")
+	}
+	message := buffer.Bytes()
+	if !bytes.HasSuffix(message, []byte("7777")) {
+		return nil, fmt.Errorf("mensagem GRIB sem o indicador de fim 7777")
+	}
+	return message, nil
+}
+
+// section recorta a seção de tamanho declarado nos primeiros bytes (3 no GRIB1, 4 no GRIB2).
+func section(message []byte, offset, sizeBytes, minimum int) ([]byte, error) {
+	if offset+sizeBytes > len(message) {
+		return nil, fmt.Errorf("seção fora da mensagem")
+	}
+	length := int(unsigned(message[offset : offset+sizeBytes]))
+	if length < minimum || offset+length > len(message) {
+		return nil, fmt.Errorf("seção de tamanho %d inválido", length)
+	}
+	return message[offset : offset+length], nil
+}
+
+// decodeGRIB1 decodifica a mensagem GRIB1 no ponto.
+func decodeGRIB1(message []byte, latitude, longitude float64) ([]gribField, error) {
+	pds, err := section(message, 8, 3, 28)
+	if err != nil {
+		return nil, fmt.Errorf("PDS: %w", err)
+	}
+	field := gribField{}
+	table, parameter, levelType, level := pds[3], pds[8], pds[9], unsigned(pds[10:12])
+	switch {
+	case table == 128 && parameter == 167, table <= 3 && parameter == 11 && levelType == 105 && level == 2:
+		field.parameter = "2t"
+	case table == 128 && parameter == 168, table <= 3 && parameter == 17 && levelType == 105 && level == 2:
+		field.parameter = "2d"
+	default:
+		return nil, nil
+	}
+
+	year := (int(pds[24])-1)*100 + int(pds[12])
+	reference := time.Date(year, time.Month(pds[13]), int(pds[14]), int(pds[15]), int(pds[16]), 0, 0, time.UTC)
+	unit, err := gribUnit(1, pds[17])
+	if err != nil {
+		return nil, err
+	}
+	p1 := int64(pds[18])
+	if pds[20] == 10 {
+		p1 = int64(unsigned(pds[18:20]))
+	}
+	field.valid = reference.Add(time.Duration(p1) * unit)
+
+	flags := pds[7]
+	if flags&0x80 == 0 {
+		return nil, fmt.Errorf("campo sem GDS (grade pré-definida) não suportado")
+	}
+	offset := 8 + len(pds)
+	gds, err := section(message, offset, 3, 28)
+	if err != nil {
+		return nil, fmt.Errorf("GDS: %w", err)
+	}
+	if gds[5] != 0 {
+		return nil, fmt.Errorf("grade GRIB1 do tipo %d não suportada: use grade regular de latitude e longitude", gds[5])
+	}
+	grid := gribGrid{
+		ni:   int(unsigned(gds[6:8])),
+		nj:   int(unsigned(gds[8:10])),
+		la1:  float64(signMagnitude(gds[10:13])) / 1000,
+		lo1:  float64(signMagnitude(gds[13:16])) / 1000,
+		la2:  float64(signMagnitude(gds[17:20])) / 1000,
+		lo2:  float64(signMagnitude(gds[20:23])) / 1000,
+		scan: gds[27],
+	}
+	if gds[16]&0x80 != 0 {
+		grid.di = float64(unsigned(gds[23:25])) / 1000
+		grid.dj = float64(unsigned(gds[25:27])) / 1000
+	}
+	if grid.ni == 0xFFFF {
+		return nil, fmt.Errorf("grade GRIB1 quase regular não suportada: use grade regular de latitude e longitude")
+	}
+	offset += len(gds)
+
+	packing := simplePacking{decimal: int(signMagnitude(pds[26:28]))}
+	if flags&0x40 != 0 {
+		bms, err := section(message, offset, 3, 6)
+		if err != nil {
+			return nil, fmt.Errorf("BMS: %w", err)
+		}
+		if unsigned(bms[4:6]) != 0 {
+			return nil, fmt.Errorf("bitmap GRIB1 pré-definido não suportado")
+		}
+		packing.bitmap = bms[6:]
+		offset += len(bms)
+	}
+	bds, err := section(message, offset, 3, 11)
+	if err != nil {
+		return nil, fmt.Errorf("BDS: %w", err)
+	}
+	if bds[3]&0xD0 != 0 {
+		return nil, fmt.Errorf("empacotamento GRIB1 0x%X não suportado: use empacotamento simples em pontos de grade", bds[3]>>4)
+	}
+	packing.binary = int(signMagnitude(bds[4:6]))
+	packing.reference = ibmFloat(bds[6:10])
+	packing.bits = int(bds[10])
+	packing.data = bds[11:]
+
+	index, cellLat, cellLon, err := grid.nearest(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+	field.latitude, field.longitude = cellLat, cellLon
+	if field.value, field.present, err = packing.value(index); err != nil {
+		return nil, err
+	}
+	return []gribField{field}, nil
+}
+
+// decodeGRIB2 decodifica os campos da mensagem GRIB2 no ponto. Uma mensagem pode repetir as
+// seções 4 a 7 com vários campos sobre a mesma grade.
+func decodeGRIB2(message []byte, latitude, longitude float64) ([]gribField, error) {
+	discipline := message[6]
+	var (
+		fields    []gribField
+		reference time.Time
+		grid      *gribGrid
+		field     gribField
+		packing   simplePacking
+		bitmap    []byte
+	)
+	for offset := 16; offset < len(message)-4; {
+		sec, err := section(message, offset, 4, 5)
+		if err != nil {
+			return nil, err
+		}
+		offset += len(sec)
+		switch sec[4] {
+		case 1:
+			if len(sec) < 19 {
+				return nil, fmt.Errorf("seção 1 curta")
+			}
+			reference = time.Date(int(unsigned(sec[12:14])), time.Month(sec[14]), int(sec[15]), int(sec[16]), int(sec[17]), int(sec[18]), 0, time.UTC)
+		case 3:
+			if len(sec) < 14 || unsigned(sec[12:14]) != 0 {
+				return nil, fmt.Errorf("grade GRIB2 não suportada: use grade regular de latitude e longitude (modelo 3.0)")
+			}
+			if len(sec) < 72 {
+				return nil, fmt.Errorf("seção 3 curta")
+			}
+			scale := 1e-6
+			if basic, subdivisions := unsigned(sec[38:42]), unsigned(sec[42:46]); basic != 0 && basic != 0xFFFFFFFF && subdivisions != 0 && subdivisions != 0xFFFFFFFF {
+				scale = float64(basic) / float64(subdivisions)
+			}
+			grid = &gribGrid{
+				ni:   int(unsigned(sec[30:34])),
+				nj:   int(unsigned(sec[34:38])),
+				la1:  float64(signMagnitude(sec[46:50])) * scale,
+				lo1:  float64(signMagnitude(sec[50:54])) * scale,
+				la2:  float64(signMagnitude(sec[55:59])) * scale,
+				lo2:  float64(signMagnitude(sec[59:63])) * scale,
+				scan: sec[71],
+			}
+			if sec[54]&0x20 != 0 {
+				grid.di = float64(unsigned(sec[63:67])) * scale
+			}
+			if sec[54]&0x10 != 0 {
+				grid.dj = float64(unsigned(sec[67:71])) * scale
+			}
+		case 4:
+			field = gribField{}
+			if len(sec) < 9 {
+				return nil, fmt.Errorf("seção 4 curta")
+			}
+			template := unsigned(sec[7:9])
+			if template != 0 && template != 8 {
+				continue // Outros modelos de produto não trazem os campos de superfície do ERA5
+			}
+			if len(sec) < 34 {
+				return nil, fmt.Errorf("seção 4 curta")
+			}
+			category, number, surface := sec[9], sec[10], sec[22]
+			height := float64(unsigned(sec[24:28])) / math.Pow(10, float64(signMagnitude(sec[23:24])))
+			if discipline == 0 && category == 0 && surface == 103 && height == 2 {
+				switch number {
+				case 0:
+					field.parameter = "2t"
+				case 6:
+					field.parameter = "2d"
+				}
+			}
+			unit, err := gribUnit(2, sec[17])
+			if err != nil {
+				return nil, err
+			}
+			field.valid = reference.Add(time.Duration(int32(unsigned(sec[18:22]))) * unit)
+		case 5:
+			if len(sec) < 11 {
+				return nil, fmt.Errorf("seção 5 curta")
+			}
+			packing = simplePacking{}
+			if template := unsigned(sec[9:11]); template != 0 {
+				if field.parameter != "" {
+					return nil, fmt.Errorf("empacotamento GRIB2 do modelo 5.%d não suportado: use empacotamento simples (5.0)", template)
+				}
+				continue
+			}
+			if len(sec) < 20 {
+				return nil, fmt.Errorf("seção 5 curta")
+			}
+			packing.reference = float64(math.Float32frombits(binary.BigEndian.Uint32(sec[11:15])))
+			packing.binary = int(signMagnitude(sec[15:17]))
+			packing.decimal = int(signMagnitude(sec[17:19]))
+			packing.bits = int(sec[19])
+		case 6:
+			if len(sec) < 6 {
+				return nil, fmt.Errorf("seção 6 curta")
+			}
+			switch sec[5] {
+			case 0:
+				bitmap = sec[6:]
+			case 255:
+				bitmap = nil
+			case 254: // Reaproveita o bitmap anterior
+			default:
+				return nil, fmt.Errorf("bitmap GRIB2 pré-definido não suportado")
+			}
+		case 7:
+			if field.parameter == "" {
+				continue
+			}
+			if grid == nil {
+				return nil, fmt.Errorf("campo sem a seção de grade")
+			}
+			index, cellLat, cellLon, err := grid.nearest(latitude, longitude)
+			if err != nil {
+				return nil, err
+			}
+			packing.data, packing.bitmap = sec[5:], bitmap
+			field.latitude, field.longitude = cellLat, cellLon
+			if field.value, field.present, err = packing.value(index); err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,521 @@
+package climate
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Leitor mínimo do NetCDF clássico (CDF-1, CDF-2 de offsets de 64 bits e CDF-5), o suficiente para
+// extrair a série de um ponto das variáveis de uma grade: o cabeçalho é lido inteiro e os valores,
+// um a um, por offset no arquivo.
+
+// Tipos de dado do NetCDF clássico.
+const (
+	ncByte   = 1
+	ncChar   = 2
+	ncShort  = 3
+	ncInt    = 4
+	ncFloat  = 5
+	ncDouble = 6
+	ncUbyte  = 7
+	ncUshort = 8
+	ncUint   = 9
+	ncInt64  = 10
+	ncUint64 = 11
+)
+
+// Marcadores das listas do cabeçalho.
+const (
+	ncDimensionTag = 0x0A
+	ncVariableTag  = 0x0B
+	ncAttributeTag = 0x0C
+)
+
+// ncMaxElements limita as contagens lidas do cabeçalho, para que um arquivo corrompido não reserve
+// memória arbitrária.
+const ncMaxElements = 1 << 24
+
+type ncDim struct {
+	name   string
+	length int64 // 0 na dimensão de registros (ilimitada)
+}
+
+type ncAttr struct {
+	text   string    // Atributos de texto
+	values []float64 // Atributos numéricos
+}
+
+type ncVar struct {
+	name  string
+	dims  []int // Índices das dimensões, da mais lenta à mais rápida
+	attrs map[string]ncAttr
+	typ   int32
+	vsize int64 // Bytes por registro (variáveis de registro) ou no total, com preenchimento
+	begin int64 // Offset dos dados no arquivo
+}
+
+type ncFile struct {
+	r       io.ReaderAt
+	size    int64 // Tamanho do arquivo
+	numRecs int64
+	dims    []ncDim
+	vars    map[string]*ncVar
+	recSize int64 // Bytes de um registro com todas as variáveis de registro
+}
+
+// ncTypeSize retorna o tamanho em bytes de um valor do tipo, ou 0 se o tipo é desconhecido.
+func ncTypeSize(typ int32) int64 {
+	switch typ {
+	case ncByte, ncChar, ncUbyte:
+		return 1
+	case ncShort, ncUshort:
+		return 2
+	case ncInt, ncFloat, ncUint:
+		return 4
+	case ncDouble, ncInt64, ncUint64:
+		return 8
+	}
+	return 0
+}
+
+// ncDecode converte um valor big-endian do tipo para float64.
+func ncDecode(typ int32, b []byte) float64 {
+	switch typ {
+	case ncByte:
+		return float64(int8(b[0]))
+	case ncChar, ncUbyte:
+		return float64(b[0])
+	case ncShort:
+		return float64(int16(binary.BigEndian.Uint16(b)))
+	case ncUshort:
+		return float64(binary.BigEndian.Uint16(b))
+	case ncInt:
+		return float64(int32(binary.BigEndian.Uint32(b)))
+	case ncUint:
+		return float64(binary.BigEndian.Uint32(b))
+	case ncFloat:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case ncDouble:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	case ncInt64:
+		return float64(int64(binary.BigEndian.Uint64(b)))
+	case ncUint64:
+		return float64(binary.BigEndian.Uint64(b))
+	}
+	return math.NaN()
+}
+
+// ncHeader lê os campos do cabeçalho em sequência, guardando o primeiro erro.
+type ncHeader struct {
+	r       *bufio.Reader
+	version byte
+	err     error
+}
+
+func (h *ncHeader) read(n int64) []byte {
+	if h.err != nil {
+		return nil
+	}
+	if n < 0 || n > ncMaxElements {
+		h.err = fmt.Errorf("tamanho %d inválido no cabeçalho", n)
+		return nil
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(h.r, b); err != nil {
+		h.err = fmt.Errorf("cabeçalho truncado: %w", err)
+		return nil
+	}
+	return b
+}
+
+func (h *ncHeader) u32() uint32 {
+	if b := h.read(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (h *ncHeader) u64() uint64 {
+	if b := h.read(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+// count lê uma contagem: 4 bytes, ou 8 no CDF-5.
+func (h *ncHeader) count() int64 {
+	var n int64
+	if h.version == 5 {
+		n = int64(h.u64())
+	} else {
+		n = int64(h.u32())
+	}
+	if h.err == nil && (n < 0 || n > ncMaxElements) {
+		h.err = fmt.Errorf("contagem %d inválida no cabeçalho", n)
+		return 0
+	}
+	return n
+}
+
+// offset lê um offset de dados: 4 bytes no CDF-1, 8 nos demais.
+func (h *ncHeader) offset() int64 {
+	if h.version == 1 {
+		return int64(h.u32())
+	}
+	return int64(h.u64())
+}
+
+// padded lê n bytes e descarta o preenchimento até o múltiplo de 4.
+func (h *ncHeader) padded(n int64) []byte {
+	b := h.read(n)
+	h.read((4 - n%4) % 4)
+	return b
+}
+
+func (h *ncHeader) name() string {
+	return string(h.padded(h.count()))
+}
+
+// list lê o marcador de uma lista e a quantidade de elementos; listas ausentes têm zero.
+func (h *ncHeader) list(tag uint32) int64 {
+	got := h.u32()
+	n := h.count()
+	if h.err == nil && got != tag && (got != 0 || n != 0) {
+		h.err = fmt.Errorf("marcador de lista 0x%X inesperado no cabeçalho, esperado 0x%X", got, tag)
+	}
+	return n
+}
+
+func (h *ncHeader) attrs() map[string]ncAttr {
+	attrs := make(map[string]ncAttr)
+	for n := h.list(ncAttributeTag); n > 0 && h.err == nil; n-- {
+		name := h.name()
+		typ := int32(h.u32())
+		count := h.count()
+		size := ncTypeSize(typ)
+		if size == 0 {
+			if h.err == nil {
+				h.err = fmt.Errorf("tipo %d desconhecido no atributo '%s'", typ, name)
+			}
+			return attrs
+		}
+		b := h.padded(count * size)
+		if h.err != nil {
+			return attrs
+		}
+		if typ == ncChar {
+			attrs[name] = ncAttr{text: strings.TrimRight(string(b), "\x00")}
+			continue
+		}
+		values := make([]float64, count)
+		for i := range values {
+			values[i] = ncDecode(typ, b[int64(i)*size:])
+		}
+		attrs[name] = ncAttr{values: values}
+	}
+	return attrs
+}
+
+// openNetCDF lê o cabeçalho do arquivo NetCDF clássico.
+func openNetCDF(r io.ReaderAt, size int64) (*ncFile, error) {
+	h := &ncHeader{r: bufio.NewReader(io.NewSectionReader(r, 0, size))}
+	magic := h.read(4)
+	if h.err != nil {
+		return nil, fmt.Errorf("erro ao ler o cabeçalho do NetCDF: %w", h.err)
+	}
+	if string(magic[:3]) != "CDF" || (magic[3] != 1 && magic[3] != 2 && magic[3] != 5) {
+		return nil, fmt.Errorf("arquivo não é NetCDF clássico (CDF-1, CDF-2 ou CDF-5)")
+	}
+	h.version = magic[3]
+
+	f := &ncFile{r: r, size: size, vars: make(map[string]*ncVar)}
+	if h.version == 5 {
+		f.numRecs = int64(h.u64())
+	} else {
+		f.numRecs = int64(h.u32())
+	}
+	if h.err == nil && (f.numRecs < 0 || f.numRecs == math.MaxUint32) {
+		return nil, fmt.Errorf("NetCDF em streaming (número de registros indeterminado) não é suportado")
+	}
+
+	for n := h.list(ncDimensionTag); n > 0 && h.err == nil; n-- {
+		f.dims = append(f.dims, ncDim{name: h.name(), length: h.count()})
+	}
+	h.attrs() // Atributos globais
+
+	var recordVars []*ncVar
+	for n := h.list(ncVariableTag); n > 0 && h.err == nil; n-- {
+		v := &ncVar{name: h.name()}
+		for d := h.count(); d > 0 && h.err == nil; d-- {
+			dim := h.count()
+			if dim >= int64(len(f.dims)) {
+				h.err = fmt.Errorf("dimensão %d inexistente na variável '%s'", dim, v.name)
+				break
+			}
+			v.dims = append(v.dims, int(dim))
+		}
+		v.attrs = h.attrs()
+		v.typ = int32(h.u32())
+		if h.version == 5 {
+			v.vsize = int64(h.u64())
+		} else {
+			v.vsize = int64(h.u32())
+		}
+		v.begin = h.offset()
+		if h.err == nil && ncTypeSize(v.typ) == 0 {
+			h.err = fmt.Errorf("tipo %d desconhecido na variável '%s'", v.typ, v.name)
+		}
+		f.vars[v.name] = v
+		if f.isRecord(v) {
+			recordVars = append(recordVars, v)
+		}
+	}
+	if h.err != nil {
+		return nil, fmt.Errorf("erro ao ler o cabeçalho do NetCDF: %w", h.err)
+	}
+
+	// Com uma só variável de registro, os registros não têm preenchimento entre si.
+	for _, v := range recordVars {
+		f.recSize += v.vsize
+	}
+	if len(recordVars) == 1 {
+		v := recordVars[0]
+		f.recSize = ncTypeSize(v.typ)
+		for _, dim := range v.dims[1:] {
+			f.recSize *= f.dims[dim].length
+		}
+	}
+	return f, nil
+}
+
+// isRecord indica se a variável cresce na dimensão de registros.
+func (f *ncFile) isRecord(v *ncVar) bool {
+	return len(v.dims) > 0 && f.dims[v.dims[0]].length == 0
+}
+
+// dimLength retorna o tamanho da dimensão, com a de registros no número de registros do arquivo.
+func (f *ncFile) dimLength(dim int) int64 {
+	if f.dims[dim].length == 0 {
+		return f.numRecs
+	}
+	return f.dims[dim].length
+}
+
+// value lê o valor bruto da variável no índice (um por dimensão), sem escala nem preenchimento.
+func (f *ncFile) value(v *ncVar, index []int64) (float64, error) {
+	size := ncTypeSize(v.typ)
+	offset := v.begin
+	dims, rest := v.dims, index
+	if f.isRecord(v) {
+		offset += index[0] * f.recSize
+		dims, rest = v.dims[1:], index[1:]
+	}
+	linear := int64(0)
+	for i, dim := range dims {
+		linear = linear*f.dimLength(dim) + rest[i]
+	}
+	b := make([]byte, size)
+	if _, err := f.r.ReadAt(b, offset+linear*size); err != nil {
+		return 0, fmt.Errorf("erro ao ler a variável '%s' do NetCDF: %w", v.name, err)
+	}
+	return ncDecode(v.typ, b), nil
+}
+
+// values lê a variável unidimensional inteira.
+func (f *ncFile) values(v *ncVar) ([]float64, error) {
+	if len(v.dims) != 1 {
+		return nil, fmt.Errorf("variável '%s' do NetCDF deveria ter uma dimensão, tem %d", v.name, len(v.dims))
+	}
+	n := f.dimLength(v.dims[0])
+	if n*ncTypeSize(v.typ) > f.size {
+		return nil, fmt.Errorf("variável '%s' do NetCDF maior que o arquivo", v.name)
+	}
+	values := make([]float64, n)
+	for i := range values {
+		value, err := f.value(v, []int64{int64(i)})
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// unpack aplica scale_factor e add_offset ao valor bruto, ou indica que é o valor de preenchimento.
+func (v *ncVar) unpack(raw float64) (float64, bool) {
+	for _, name := range []string{"_FillValue", "missing_value"} {
+		if fill, ok := v.attrs[name]; ok && len(fill.values) > 0 && raw == fill.values[0] {
+			return 0, false
+		}
+	}
+	if math.IsNaN(raw) {
+		return 0, false
+	}
+	if scale, ok := v.attrs["scale_factor"]; ok && len(scale.values) > 0 {
+		raw *= scale.values[0]
+	}
+	if offset, ok := v.attrs["add_offset"]; ok && len(offset.values) > 0 {
+		raw += offset.values[0]
+	}
+	return raw, true
+}
+
+// firstVar retorna a primeira variável existente entre os nomes.
+func (f *ncFile) firstVar(names ...string) *ncVar {
+	for _, name := range names {
+		if v, ok := f.vars[name]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// netcdfTimes converte a variável de tempo do padrão CF ("hours since 1900-01-01 00:00:00").
+func (f *ncFile) netcdfTimes(v *ncVar) ([]time.Time, error) {
+	units := v.attrs["units"].text
+	unit, since, found := strings.Cut(units, " since ")
+	if !found {
+		return nil, fmt.Errorf("unidade de tempo '%s' do NetCDF sem referência (esperado '<unidade> since <data>')", units)
+	}
+	var step time.Duration
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "seconds", "second", "s":
+		step = time.Second
+	case "minutes", "minute", "min":
+		step = time.Minute
+	case "hours", "hour", "h":
+		step = time.Hour
+	case "days", "day", "d":
+		step = 24 * time.Hour
+	default:
+		return nil, fmt.Errorf("unidade de tempo '%s' do NetCDF desconhecida", unit)
+	}
+	since = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(since), " UTC"), "Z")
+	var origin time.Time
+	var err error
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if origin, err = time.ParseInLocation(layout, since, time.UTC); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("referência de tempo '%s' do NetCDF inválida: %w", since, err)
+	}
+
+	raw, err := f.values(v)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Time, len(raw))
+	for i, value := range raw {
+		times[i] = origin.Add(time.Duration(math.Round(value * float64(step))))
+	}
+	return times, nil
+}
+
+// readNetCDFPoint extrai a série de temperatura e ponto de orvalho da célula mais próxima do ponto.
+// Dimensões além de tempo, latitude e longitude (ex: expver, que separa o ERA5 do ERA5T preliminar)
+// são percorridas até o primeiro valor presente.
+func readNetCDFPoint(r io.ReaderAt, size int64, latitude, longitude float64) (*gridPoint, error) {
+	f, err := openNetCDF(r, size)
+	if err != nil {
+		return nil, err
+	}
+	latVar, lonVar := f.firstVar("latitude", "lat"), f.firstVar("longitude", "lon")
+	timeVar := f.firstVar("valid_time", "time")
+	temperature, dewPoint := f.firstVar("t2m", "2t"), f.firstVar("d2m", "2d")
+	switch {
+	case latVar == nil || lonVar == nil:
+		return nil, fmt.Errorf("NetCDF sem as coordenadas latitude e longitude")
+	case timeVar == nil:
+		return nil, fmt.Errorf("NetCDF sem a coordenada de tempo (time ou valid_time)")
+	case temperature == nil || dewPoint == nil:
+		return nil, fmt.Errorf("NetCDF sem as variáveis t2m (temperatura a 2 m) e d2m (ponto de orvalho a 2 m)")
+	}
+
+	lats, err := f.values(latVar)
+	if err != nil {
+		return nil, err
+	}
+	lons, err := f.values(lonVar)
+	if err != nil {
+		return nil, err
+	}
+	times, err := f.netcdfTimes(timeVar)
+	if err != nil {
+		return nil, err
+	}
+	if len(lats) == 0 || len(lons) == 0 {
+		return nil, fmt.Errorf("grade do NetCDF vazia")
+	}
+	j := nearestIndex(len(lats), func(i int) float64 { return math.Abs(lats[i] - latitude) })
+	i := nearestIndex(len(lons), func(i int) float64 { return math.Abs(math.Remainder(lons[i]-longitude, 360)) })
+
+	point := &gridPoint{latitude: lats[j], longitude: math.Remainder(lons[i], 360), samples: make(map[time.Time]*gridSample)}
+	coordinates := map[int]int64{latVar.dims[0]: int64(j), lonVar.dims[0]: int64(i)}
+	for _, variable := range []*ncVar{temperature, dewPoint} {
+		for t, timestamp := range times {
+			coordinates[timeVar.dims[0]] = int64(t)
+			value, ok, err := f.pointValue(variable, coordinates)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				point.set(timestamp, variable == temperature, value)
+			} else {
+				point.sample(timestamp)
+			}
+		}
+	}
+	return point, nil
+}
+
+// pointValue lê o valor da variável nas coordenadas fixadas, percorrendo as demais dimensões até o
+// primeiro valor presente.
+func (f *ncFile) pointValue(v *ncVar, coordinates map[int]int64) (float64, bool, error) {
+	index := make([]int64, len(v.dims))
+	var free []int // Posições das dimensões sem coordenada fixada
+	for k, dim := range v.dims {
+		if c, ok := coordinates[dim]; ok {
+			index[k] = c
+		} else {
+			free = append(free, k)
+		}
+	}
+	for {
+		raw, err := f.value(v, index)
+		if err != nil {
+			return 0, false, err
+		}
+		if value, ok := v.unpack(raw); ok {
+			return value, true, nil
+		}
+		k := len(free) - 1
+		for ; k >= 0; k-- {
+			index[free[k]]++
+			if index[free[k]] < f.dimLength(v.dims[free[k]]) {
+				break
+			}
+			index[free[k]] = 0
+		}
+		if k < 0 {
+			return 0, false, nil
+		}
+	}
+}
+
+// nearestIndex retorna o índice de menor distância.
+func nearestIndex(n int, distance func(int) float64) int {
+	best := 0
+	for i := 1; i < n; i++ {
+		if distance(i) < distance(best) {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,333 @@
+package climate
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Leitura dos arquivos horários da NOAA, para simular locais fora do Brasil com dados oficiais dos
+// EUA e das estações da rede global:
+//
+//   - ISD (Integrated Surface Database): o formato bruto de largura fixa, com um relatório por
+//     linha, ou o CSV do serviço global-hourly do NCEI. A temperatura e o ponto de orvalho vêm em
+//     décimos de °C com código de qualidade, e os instantes em UTC.
+//   - LCD (Local Climatological Data): o CSV com as colunas Hourly*, em °F e na hora padrão local
+//     da estação, sem horário de verão.
+//
+// Sem a umidade relativa, ela é calculada da temperatura e do ponto de orvalho (Magnus). Relatórios
+// repetidos no mesmo instante (ex: SYNOP e METAR da mesma hora) ficam só no primeiro.
+
+// isdMissing é o valor de temperatura ausente do ISD.
+const isdMissing = "+9999"
+
+// readNOAA lê o arquivo ISD ou LCD no formato configurado.
+func readNOAA(filepath, format string, location *time.Location, opts ParseOptions) ([]InmetClimateData, *ParseReport, error) {
+	reader, closeFile, err := openClimateFile(filepath, "", "csv", "txt")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeFile()
+	// O BOM do UTF-8 antes da aspa do cabeçalho é recusado pelo leitor de CSV
+	buffered := bufio.NewReader(reader)
+	if bom, _ := buffered.Peek(3); string(bom) == "\ufeff" {
+		buffered.Discard(3)
+	}
+	if format == "lcd" {
+		return parseLCD(buffered, location, opts)
+	}
+	return parseISD(buffered, opts)
+}
+
+// noaaObservations acumula as observações lidas, com o relatório das linhas descartadas.
+type noaaObservations struct {
+	records []InmetClimateData
+	report  *ParseReport
+	opts    ParseOptions
+}
+
+func (o *noaaObservations) skip(line int, reason, message string) {
+	o.report.skip(line, reason, message)
+	if !o.opts.Strict {
+		log.Printf("Aviso: %s na linha %d. Pulando linha.", message, line)
+	}
+}
+
+// repeated indica se o instante repete o da última observação aceita.
+func (o *noaaObservations) repeated(timestamp time.Time) bool {
+	return len(o.records) > 0 && o.records[len(o.records)-1].Timestamp.Equal(timestamp)
+}
+
+func (o *noaaObservations) result() ([]InmetClimateData, *ParseReport, error) {
+	if o.opts.Strict && len(o.report.Skipped) > 0 {
+		return nil, o.report, &ParseError{Report: o.report}
+	}
+	return o.records, o.report, nil
+}
+
+// parseISD lê o ISD, no CSV global-hourly (cabeçalho com STATION) ou no formato bruto.
+func parseISD(reader io.Reader, opts ParseOptions) ([]InmetClimateData, *ParseReport, error) {
+	buffered := bufio.NewReader(reader)
+	head, _ := buffered.Peek(8)
+	if strings.HasPrefix(strings.TrimPrefix(string(head), `"`), "STATION") {
+		return parseISDCSV(buffered, opts)
+	}
+
+	obs := &noaaObservations{report: &ParseReport{}, opts: opts}
+	scanner := bufio.NewScanner(buffered)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if len(text) < 99 {
+			obs.report.Rows++
+			obs.skip(line, "colunas faltando", fmt.Sprintf("%d caracteres, esperados ao menos 99", len(text)))
+			continue
+		}
+		timestamp, err := time.ParseInLocation("200601021504", text[15:27], time.UTC)
+		if err != nil {
+			obs.report.Rows++
+			obs.skip(line, "data inválida", fmt.Sprintf("Erro ao fazer parse do timestamp '%s': %v", text[15:27], err))
+			continue
+		}
+		if obs.repeated(timestamp) {
+			continue
+		}
+		obs.report.Rows++
+		obs.observe(line, timestamp, text[87:93], text[93:99])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, obs.report, fmt.Errorf("erro ao ler o arquivo ISD: %w", err)
+	}
+	return obs.result()
+}
+
+// parseISDCSV lê o CSV global-hourly do NCEI, com as colunas DATE, TMP e DEW.
+func parseISDCSV(reader io.Reader, opts ParseOptions) ([]InmetClimateData, *ParseReport, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler o cabeçalho do CSV do ISD: %w", err)
+	}
+	index, columns, err := noaaColumns(header, "DATE", "TMP", "DEW")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obs := &noaaObservations{report: &ParseReport{}, opts: opts}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, obs.report, fmt.Errorf("erro ao ler o CSV do ISD: %w", err)
+		}
+		line, _ := csvReader.FieldPos(0)
+		if len(record) < columns {
+			obs.report.Rows++
+			obs.skip(line, "colunas faltando", fmt.Sprintf("%d colunas, esperadas ao menos %d", len(record), columns))
+			continue
+		}
+		timestamp, err := time.ParseInLocation("2006-01-02T15:04:05", record[index["DATE"]], time.UTC)
+		if err != nil {
+			obs.report.Rows++
+			obs.skip(line, "data inválida", fmt.Sprintf("Erro ao fazer parse do timestamp '%s': %v", record[index["DATE"]], err))
+			continue
+		}
+		if obs.repeated(timestamp) {
+			continue
+		}
+		obs.report.Rows++
+		// O CSV separa o valor do código de qualidade por vírgula: "+0056,1".
+		obs.observe(line, timestamp, strings.Replace(record[index["TMP"]], ",", "", 1), strings.Replace(record[index["DEW"]], ",", "", 1))
+	}
+	return obs.result()
+}
+
+// observe interpreta a temperatura e o ponto de orvalho do ISD (sinal, quatro dígitos em décimos de
+// °C e o código de qualidade) e registra a observação.
+func (o *noaaObservations) observe(line int, timestamp time.Time, tmp, dew string) {
+	temperature, err := isdValue(tmp)
+	if err != nil {
+		o.skip(line, "temperatura do ar "+reasonOf(err), fmt.Sprintf("Erro ao fazer parse da temperatura do ar '%s': %v", tmp, err))
+		return
+	}
+	dewPoint, err := isdValue(dew)
+	if err != nil {
+		o.skip(line, "umidade relativa "+reasonOf(err), fmt.Sprintf("Erro ao fazer parse do ponto de orvalho '%s': %v", dew, err))
+		return
+	}
+	o.records = append(o.records, InmetClimateData{
+		Timestamp:        timestamp,
+		TemperatureAir:   temperature,
+		RelativeHumidity: relativeHumidity(temperature, dewPoint),
+	})
+}
+
+// errMissing marca a medição ausente, separada dos valores ilegíveis ou reprovados no controle de
+// qualidade.
+var errMissing = errors.New("medição ausente")
+
+func reasonOf(err error) string {
+	if errors.Is(err, errMissing) {
+		return "ausente"
+	}
+	return "inválida"
+}
+
+// isdValue interpreta um valor do ISD com o código de qualidade. Os códigos 2, 3, 6 e 7 (suspeito ou
+// errado) reprovam a medição.
+func isdValue(raw string) (float64, error) {
+	if len(raw) != 6 {
+		return 0, fmt.Errorf("esperados 6 caracteres (sinal, 4 dígitos e qualidade)")
+	}
+	if raw[:5] == isdMissing {
+		return 0, errMissing
+	}
+	if strings.ContainsRune("2367", rune(raw[5])) {
+		return 0, fmt.Errorf("reprovado no controle de qualidade (código %c)", raw[5])
+	}
+	tenths, err := strconv.Atoi(raw[:5])
+	if err != nil || (raw[0] != '+' && raw[0] != '-') {
+		return 0, fmt.Errorf("valor ilegível")
+	}
+	return float64(tenths) / 10, nil
+}
+
+// parseLCD lê o CSV do LCD, com as colunas DATE, HourlyDryBulbTemperature e HourlyRelativeHumidity
+// (ou HourlyDewPointTemperature). Os resumos diários e mensais (REPORT_TYPE SOD e SOM) não são
+// medições horárias e ficam de fora.
+func parseLCD(reader io.Reader, location *time.Location, opts ParseOptions) ([]InmetClimateData, *ParseReport, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler o cabeçalho do CSV do LCD: %w", err)
+	}
+	index, columns, err := noaaColumns(header, "DATE", "HourlyDryBulbTemperature")
+	if err != nil {
+		return nil, nil, err
+	}
+	reportType, hasReportType := index["REPORT_TYPE"]
+	humidityColumn, hasHumidity := index["HourlyRelativeHumidity"]
+	dewPointColumn, hasDewPoint := index["HourlyDewPointTemperature"]
+	if !hasHumidity && !hasDewPoint {
+		return nil, nil, fmt.Errorf("cabeçalho do CSV do LCD sem HourlyRelativeHumidity nem HourlyDewPointTemperature")
+	}
+
+	obs := &noaaObservations{report: &ParseReport{}, opts: opts}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, obs.report, fmt.Errorf("erro ao ler o CSV do LCD: %w", err)
+		}
+		if hasReportType && reportType < len(record) {
+			if kind := strings.TrimSpace(record[reportType]); kind == "SOD" || kind == "SOM" {
+				continue
+			}
+		}
+		line, _ := csvReader.FieldPos(0)
+		if len(record) < columns || (hasHumidity && len(record) <= humidityColumn) || (hasDewPoint && len(record) <= dewPointColumn) {
+			obs.report.Rows++
+			obs.skip(line, "colunas faltando", fmt.Sprintf("%d colunas, esperadas ao menos %d", len(record), columns))
+			continue
+		}
+		timestamp, err := time.ParseInLocation("2006-01-02T15:04:05", record[index["DATE"]], location)
+		if err != nil {
+			obs.report.Rows++
+			obs.skip(line, "data inválida", fmt.Sprintf("Erro ao fazer parse do timestamp '%s': %v", record[index["DATE"]], err))
+			continue
+		}
+		if obs.repeated(timestamp) {
+			continue
+		}
+		obs.report.Rows++
+
+		tempStr := record[index["HourlyDryBulbTemperature"]]
+		temperature, err := lcdValue(tempStr)
+		if err != nil {
+			obs.skip(line, "temperatura do ar "+reasonOf(err), fmt.Sprintf("Erro ao fazer parse da temperatura do ar '%s': %v", tempStr, err))
+			continue
+		}
+		temperature = (temperature - 32) * 5 / 9
+
+		humidity, humidityErr, humidityStr := 0.0, errMissing, ""
+		if hasHumidity {
+			humidityStr = record[humidityColumn]
+			humidity, humidityErr = lcdValue(humidityStr)
+		}
+		if errors.Is(humidityErr, errMissing) && hasDewPoint {
+			humidityStr = record[dewPointColumn]
+			var dewPoint float64
+			if dewPoint, humidityErr = lcdValue(humidityStr); humidityErr == nil {
+				humidity = relativeHumidity(temperature, (dewPoint-32)*5/9)
+			}
+		}
+		if humidityErr != nil {
+			obs.skip(line, "umidade relativa "+reasonOf(humidityErr), fmt.Sprintf("Erro ao fazer parse da umidade relativa '%s': %v", humidityStr, humidityErr))
+			continue
+		}
+
+		obs.records = append(obs.records, InmetClimateData{
+			Timestamp:        timestamp,
+			TemperatureAir:   temperature,
+			RelativeHumidity: humidity,
+		})
+	}
+	return obs.result()
+}
+
+// lcdValue interpreta um valor horário do LCD. Vazio, "M" e "*" são ausentes; o sufixo "s" marca o
+// valor suspeito, reprovado como no ISD.
+func lcdValue(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "" || raw == "M" || raw == "*":
+		return 0, errMissing
+	case strings.HasSuffix(raw, "s"):
+		return 0, fmt.Errorf("valor suspeito")
+	}
+	return parseMeasurement(raw)
+}
+
+// noaaColumns localiza as colunas do cabeçalho dos CSVs da NOAA e retorna quantas colunas as linhas
+// precisam ter para conter as obrigatórias.
+func noaaColumns(header []string, required ...string) (map[string]int, int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	columns := 0
+	var missing []string
+	for _, name := range required {
+		i, ok := index[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		columns = max(columns, i+1)
+	}
+	if len(missing) > 0 {
+		return nil, 0, fmt.Errorf("cabeçalho do CSV da NOAA não contém todas as colunas esperadas (faltando: %s)", strings.Join(missing, ", "))
+	}
+	return index, columns, nil
+}
+
+// relativeHumidity calcula a umidade relativa (%) da temperatura e do ponto de orvalho (°C) pela
+// razão das pressões de vapor de Magnus.
+func relativeHumidity(temperature, dewPoint float64) float64 {
+	rh := 100 * SaturationPressure(dewPoint) / SaturationPressure(temperature)
+	return math.Min(100, rh)
+}
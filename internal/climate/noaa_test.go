@@ -0,0 +1,147 @@
+package climate
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// noaaSample é uma observação esperada: instante, temperatura e ponto de orvalho (°C), ou a
+// umidade relativa informada pelo arquivo quando dewPoint é NaN.
+type noaaSample struct {
+	timestamp             time.Time
+	temperature, dewPoint float64
+	humidity              float64
+}
+
+func TestReadNOAAFixtures(t *testing.T) {
+	quietLog(t)
+	utc := func(hour int) time.Time { return time.Date(2024, time.January, 15, hour, 0, 0, 0, time.UTC) }
+	cst := time.FixedZone("", -6*3600)
+	lst := func(hour int) time.Time { return time.Date(2024, time.January, 15, hour, 51, 0, 0, cst) }
+	fahrenheit := func(f float64) float64 { return (f - 32) * 5 / 9 }
+
+	tests := []struct {
+		name    string
+		file    string
+		cfg     SourceConfig
+		samples []noaaSample
+		skipped []string // Motivos das linhas descartadas, em ordem
+		rows    int
+	}{
+		{
+			// Largura fixa: data e hora nas colunas 16 a 27, temperatura e ponto de orvalho nas
+			// colunas 88 a 99, com o código de qualidade no último caractere. O relatório SYNOP das
+			// 12h repete o instante do METAR e fica de fora; a última linha é curta.
+			name: "ISD bruto", file: "testdata/isd.txt", cfg: SourceConfig{Format: "isd"},
+			samples: []noaaSample{
+				{utc(12), 23.5, 15.0, 0},
+				{utc(16), -1.5, -5.0, 0},
+				{utc(17), 24.0, 13.0, 0}, // Código A: suspeito, mas aceito
+			},
+			skipped: []string{"temperatura do ar ausente", "umidade relativa ausente", "temperatura do ar inválida", "colunas faltando"},
+			rows:    7,
+		},
+		{
+			// CSV global-hourly com BOM antes do cabeçalho entre aspas
+			name: "ISD em CSV", file: "testdata/isd.csv", cfg: SourceConfig{Format: "isd"},
+			samples: []noaaSample{
+				{utc(12), 23.5, 15.0, 0},
+				{utc(15), -1.5, -5.0, 0},
+			},
+			skipped: []string{"temperatura do ar ausente", "umidade relativa inválida"},
+			rows:    4,
+		},
+		{
+			// Hora padrão local (UTC-6, sem horário de verão); o resumo diário (SOD) não é medição
+			name: "LCD", file: "testdata/lcd.csv", cfg: SourceConfig{Format: "lcd", Location: "Etc/GMT+6"},
+			samples: []noaaSample{
+				{lst(6), fahrenheit(41), math.NaN(), 75},
+				{lst(7), fahrenheit(43), fahrenheit(34), 0}, // Sem a umidade, calculada do ponto de orvalho
+			},
+			skipped: []string{"temperatura do ar inválida", "temperatura do ar ausente", "umidade relativa ausente", "umidade relativa inválida"},
+			rows:    6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, report, err := ReadClimate(tt.file, tt.cfg)
+			if err != nil {
+				t.Fatalf("ReadClimate: %v", err)
+			}
+			if len(records) != len(tt.samples) {
+				t.Fatalf("%d registros, esperado %d: %+v", len(records), len(tt.samples), records)
+			}
+			for i, want := range tt.samples {
+				record := records[i]
+				humidity := want.humidity
+				if !math.IsNaN(want.dewPoint) {
+					humidity = relativeHumidity(want.temperature, want.dewPoint)
+				}
+				if !record.Timestamp.Equal(want.timestamp) || math.Abs(record.TemperatureAir-want.temperature) > 1e-9 || math.Abs(record.RelativeHumidity-humidity) > 1e-9 {
+					t.Errorf("registro %d = %s %.3f °C %.3f%%, esperado %s %.3f °C %.3f%%", i, record.Timestamp.Format(time.RFC3339), record.TemperatureAir, record.RelativeHumidity, want.timestamp.Format(time.RFC3339), want.temperature, humidity)
+				}
+			}
+			if report.Rows != tt.rows {
+				t.Errorf("%d linhas de medição, esperado %d", report.Rows, tt.rows)
+			}
+			var reasons []string
+			for _, issue := range report.Skipped {
+				reasons = append(reasons, issue.Reason)
+			}
+			if len(reasons) != len(tt.skipped) {
+				t.Fatalf("linhas descartadas por %q, esperado %q", reasons, tt.skipped)
+			}
+			for i := range reasons {
+				if reasons[i] != tt.skipped[i] {
+					t.Errorf("linha descartada %d por '%s', esperado '%s'", i, reasons[i], tt.skipped[i])
+				}
+			}
+
+			strict := tt.cfg
+			strict.ParseMode = "strict"
+			var parseErr *ParseError
+			if _, _, err := ReadClimate(tt.file, strict); !errors.As(err, &parseErr) || len(parseErr.Report.Skipped) != len(tt.skipped) {
+				t.Errorf("modo estrito retornou %v, esperado ParseError com %d linhas", err, len(tt.skipped))
+			}
+		})
+	}
+}
+
+func TestISDValue(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    float64
+		missing bool
+		invalid bool
+	}{
+		{raw: "+02351", want: 23.5},
+		{raw: "-00155", want: -1.5},
+		{raw: "+0240A", want: 24.0},
+		{raw: "+99999", missing: true},
+		{raw: "+99991", missing: true},
+		{raw: "+02352", invalid: true},
+		{raw: "+02353", invalid: true},
+		{raw: "+02356", invalid: true},
+		{raw: "+02357", invalid: true},
+		{raw: "002351", invalid: true},
+		{raw: "+0x351", invalid: true},
+		{raw: "+0235", invalid: true},
+	}
+	for _, tt := range tests {
+		got, err := isdValue(tt.raw)
+		switch {
+		case tt.missing:
+			if !errors.Is(err, errMissing) {
+				t.Errorf("isdValue(%q) = %v, %v; esperado ausente", tt.raw, got, err)
+			}
+		case tt.invalid:
+			if err == nil || errors.Is(err, errMissing) {
+				t.Errorf("isdValue(%q) = %v, %v; esperado inválido", tt.raw, got, err)
+			}
+		case err != nil || got != tt.want:
+			t.Errorf("isdValue(%q) = %v, %v; esperado %v", tt.raw, got, err, tt.want)
+		}
+	}
+}
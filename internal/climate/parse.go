@@ -0,0 +1,187 @@
+package climate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SourceConfig descreve a leitura do arquivo climático do cenário.
+type SourceConfig struct {
+	ParseMode string `json:"parseMode"` // lenient (padrão): pula as linhas inválidas com aviso; strict: falha com o relatório delas
+	File      string `json:"file"`      // Arquivo climático (.csv, .zip ou .gz; padrão: o do INMET em data/inmet)
+	Format    string `json:"format"`    // inmet (padrão, CSV com o mapeamento de colunas), isd ou lcd (NOAA), ou era5 (reanálise em NetCDF ou GRIB)
+	CacheDir  string `json:"cacheDir"`  // Diretório do cache das séries lidas (desativado se vazio)
+	Station   string `json:"station"`   // Estação lida de um .zip com várias (código, ex: A701; padrão: a primeira)
+	Workers   int    `json:"workers"`   // Leituras em paralelo dos CSVs de um .zip (padrão: número de CPUs)
+
+	Latitude  *float64 `json:"latitude"`  // Ponto da série extraída da grade do ERA5 (graus, obrigatório no era5)
+	Longitude *float64 `json:"longitude"` // Longitude do ponto (graus, de -180 a 360)
+
+	// Columns mapeia o nome de cada coluna do cabeçalho para o campo lido: date, time, dateTime,
+	// temperature ou humidity. Os nomes são comparados sem diferenciar maiúsculas e sem a unidade
+	// entre parênteses. Sem mapeamento, o arquivo é lido no layout do INMET.
+	Columns         map[string]string `json:"columns"`
+	PreambleLines   *int              `json:"preambleLines"`   // Linhas não vazias antes do cabeçalho (padrão: 9 no INMET, 0 com mapeamento)
+	Delimiter       string            `json:"delimiter"`       // Separador das colunas (padrão: ";" no INMET, "," com mapeamento)
+	TimeLayout      string            `json:"timeLayout"`      // Layout Go de "data hora" ou da coluna dateTime (padrão: 2006-01-02 15:04)
+	Location        string            `json:"location"`        // Fuso dos instantes sem fuso explícito, no CSV e no LCD (padrão: UTC)
+	TemperatureUnit string            `json:"temperatureUnit"` // C (padrão) ou F, convertida para °C
+}
+
+// csvLayout monta a estrutura do CSV descrita pela configuração sobre a do INMET.
+func (c SourceConfig) csvLayout() (csvLayout, error) {
+	layout := inmetLayout
+	if len(c.Columns) > 0 {
+		layout.columns = make(map[string]string, len(c.Columns))
+		for name, field := range c.Columns {
+			switch field {
+			case fieldDate, fieldTime, fieldDateTime, fieldTemperature, fieldHumidity:
+			default:
+				return csvLayout{}, fmt.Errorf("campo '%s' da coluna '%s' desconhecido (use date, time, dateTime, temperature ou humidity)", field, name)
+			}
+			layout.columns[normalizeColumn(name)] = field
+		}
+		layout.preambleLines = 0
+		layout.delimiter = ','
+	}
+	if c.PreambleLines != nil {
+		if *c.PreambleLines < 0 {
+			return csvLayout{}, fmt.Errorf("linhas de preâmbulo não podem ser negativas, recebido %d", *c.PreambleLines)
+		}
+		layout.preambleLines = *c.PreambleLines
+	}
+	if c.Delimiter != "" {
+		runes := []rune(c.Delimiter)
+		if len(runes) != 1 || runes[0] == '"' || runes[0] == '\n' || runes[0] == '\r' {
+			return csvLayout{}, fmt.Errorf("separador '%s' inválido: use um único caractere", c.Delimiter)
+		}
+		layout.delimiter = runes[0]
+	}
+	if c.TimeLayout != "" {
+		layout.timeLayout = c.TimeLayout
+	}
+	location, err := c.location()
+	if err != nil {
+		return csvLayout{}, err
+	}
+	layout.location = location
+	switch strings.ToUpper(c.TemperatureUnit) {
+	case "", "C":
+	case "F":
+		layout.fahrenheit = true
+	default:
+		return csvLayout{}, fmt.Errorf("unidade de temperatura '%s' desconhecida (use C ou F)", c.TemperatureUnit)
+	}
+	return layout, nil
+}
+
+// location retorna o fuso dos instantes sem fuso explícito.
+func (c SourceConfig) location() (*time.Location, error) {
+	if c.Location == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(c.Location)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar o fuso horário '%s' do arquivo climático: %w", c.Location, err)
+	}
+	return location, nil
+}
+
+// ParseOptions valida a configuração e retorna as opções de leitura.
+func (c SourceConfig) ParseOptions() (ParseOptions, error) {
+	switch c.ParseMode {
+	case "", "lenient":
+		return ParseOptions{}, nil
+	case "strict":
+		return ParseOptions{Strict: true}, nil
+	}
+	return ParseOptions{}, fmt.Errorf("modo de leitura '%s' desconhecido (use lenient ou strict)", c.ParseMode)
+}
+
+// ParseOptions ajusta a leitura dos arquivos climáticos.
+type ParseOptions struct {
+	// Strict falha a leitura se alguma linha de medição não puder ser interpretada, com o relatório
+	// de todas elas; no modo tolerante (padrão), as linhas são puladas com aviso.
+	Strict bool
+
+	quiet bool // Omite os avisos de cada linha (leituras em paralelo, com o relatório depois)
+}
+
+// ParseIssue é uma linha de medição descartada na leitura.
+type ParseIssue struct {
+	File    string // CSV de origem, nos .zip lidos com vários CSVs
+	Line    int    // Linha do arquivo (a partir de 1)
+	Reason  string // Categoria do problema (ex: temperatura ausente, hora inválida)
+	Message string // Descrição com o valor encontrado
+}
+
+// ParseReport resume a leitura de um arquivo climático: as linhas de medição lidas e as descartadas.
+type ParseReport struct {
+	Rows    int          // Linhas de medição lidas, válidas ou não
+	Skipped []ParseIssue // Linhas descartadas, na ordem do arquivo
+	Station *Station     // Metadados da estação lidos do preâmbulo, se houver
+}
+
+func (r *ParseReport) skip(line int, reason, message string) {
+	r.Skipped = append(r.Skipped, ParseIssue{Line: line, Reason: reason, Message: message})
+}
+
+// Counts conta as linhas descartadas por categoria.
+func (r *ParseReport) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range r.Skipped {
+		counts[issue.Reason]++
+	}
+	return counts
+}
+
+// Summary descreve as linhas descartadas em uma frase, com as categorias da mais à menos frequente.
+func (r *ParseReport) Summary() string {
+	if len(r.Skipped) == 0 {
+		return fmt.Sprintf("%d linhas de medição lidas, nenhuma inválida", r.Rows)
+	}
+	counts := r.Counts()
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%d com %s", counts[reason], reason)
+	}
+	return fmt.Sprintf("%d de %d linhas de medição inválidas (%.1f%%): %s", len(r.Skipped), r.Rows,
+		100*float64(len(r.Skipped))/float64(max(r.Rows, 1)), strings.Join(parts, ", "))
+}
+
+// maxReportedIssues limita as linhas listadas na mensagem de ParseError.
+const maxReportedIssues = 20
+
+// ParseError é a falha do modo estrito, com o relatório de todas as linhas inválidas.
+type ParseError struct {
+	Report *ParseReport
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "modo estrito: %s", e.Report.Summary())
+	for i, issue := range e.Report.Skipped {
+		if i == maxReportedIssues {
+			fmt.Fprintf(&b, "\n  ... e mais %d linhas", len(e.Report.Skipped)-i)
+			break
+		}
+		if issue.File != "" {
+			fmt.Fprintf(&b, "\n  %s, linha %d: %s", issue.File, issue.Line, issue.Message)
+			continue
+		}
+		fmt.Fprintf(&b, "\n  linha %d: %s", issue.Line, issue.Message)
+	}
+	return b.String()
+}
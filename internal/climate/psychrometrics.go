@@ -0,0 +1,65 @@
+package climate
+
+import "math"
+
+// StandardPressureKPa é a pressão atmosférica ao nível do mar (kPa), usada quando a altitude do
+// local não é conhecida.
+const StandardPressureKPa = 101.325
+
+// SaturationPressure é a pressão de saturação do vapor d'água à temperatura (°C), pela fórmula de
+// Magnus (kPa).
+func SaturationPressure(temp float64) float64 {
+	return 0.61094 * math.Exp(17.625*temp/(temp+243.04))
+}
+
+// clampHumidity limita a umidade relativa a (0, 100] %: umidade nula levaria o ponto de orvalho a
+// -∞, e valores acima de 100 % são erro de medição.
+func clampHumidity(humidity float64) float64 {
+	return math.Max(0.1, math.Min(100.0, humidity))
+}
+
+// DewPoint é o ponto de orvalho (°C) do ar à temperatura (°C) e umidade relativa (%), inverso da
+// fórmula de Magnus.
+func DewPoint(temp, humidity float64) float64 {
+	gamma := math.Log(clampHumidity(humidity)/100.0) + 17.625*temp/(temp+243.04)
+	return 243.04 * gamma / (17.625 - gamma)
+}
+
+// HumidityRatio é a umidade absoluta do ar (kg de vapor por kg de ar seco) à temperatura (°C),
+// umidade relativa (%) e pressão atmosférica (kPa).
+func HumidityRatio(temp, humidity, pressure float64) float64 {
+	vapor := clampHumidity(humidity) / 100.0 * SaturationPressure(temp)
+	return 0.622 * vapor / (pressure - vapor)
+}
+
+// Enthalpy é a entalpia do ar úmido (kJ/kg de ar seco) à temperatura (°C), umidade relativa (%) e
+// pressão atmosférica (kPa).
+func Enthalpy(temp, humidity, pressure float64) float64 {
+	ratio := HumidityRatio(temp, humidity, pressure)
+	return 1.006*temp + ratio*(2501.0+1.86*temp)
+}
+
+// WetBulb é a temperatura de bulbo úmido termodinâmica (°C) à temperatura (°C), umidade relativa
+// (%) e pressão atmosférica (kPa): a temperatura em que a saturação adiabática do ar reproduz a
+// umidade absoluta medida (equação psicrométrica do ASHRAE Fundamentals, resolvida por bisseção
+// entre o ponto de orvalho e a temperatura de bulbo seco).
+func WetBulb(temp, humidity, pressure float64) float64 {
+	ratio := HumidityRatio(temp, humidity, pressure)
+	low, high := DewPoint(temp, humidity), temp
+	for range 50 {
+		wetBulb := (low + high) / 2
+		saturated := 0.622 * SaturationPressure(wetBulb) / (pressure - SaturationPressure(wetBulb))
+		estimated := ((2501.0-2.326*wetBulb)*saturated - 1.006*(temp-wetBulb)) / (2501.0 + 1.86*temp - 4.186*wetBulb)
+		if estimated > ratio {
+			high = wetBulb
+		} else {
+			low = wetBulb
+		}
+	}
+	return (low + high) / 2
+}
+
+// PressureAtAltitude é a pressão atmosférica (kPa) na altitude (m), pela atmosfera padrão.
+func PressureAtAltitude(altitude float64) float64 {
+	return StandardPressureKPa * math.Pow(1-2.25577e-5*altitude, 5.25588)
+}
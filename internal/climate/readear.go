@@ -2,11 +2,16 @@ package climate
 
 import (
 	"archive/zip"
+	"compress/gzip"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
+	"path"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,65 +21,234 @@ type InmetClimateData struct {
 	Timestamp        time.Time
 	TemperatureAir   float64
 	RelativeHumidity float64
+	ExtremeEvent     string     // Nome do evento extremo injetado, se houver
+	Stress           float64    // Estresse imposto aos equipamentos pelo evento (0 a 1)
+	Forecasts        []Forecast // Previsões de temperatura externa, se habilitadas
 }
 
+// Campos de um CSV climático, usados no mapeamento de colunas.
+const (
+	fieldDate        = "date"        // Data da medição
+	fieldTime        = "time"        // Hora da medição (HHMM do INMET ou no layout configurado)
+	fieldDateTime    = "dateTime"    // Data e hora na mesma coluna
+	fieldTemperature = "temperature" // Temperatura do ar
+	fieldHumidity    = "humidity"    // Umidade relativa do ar (%)
+)
+
+// csvLayout descreve a estrutura de um CSV climático: o preâmbulo antes do cabeçalho, o separador,
+// as colunas de cada campo e a interpretação dos valores.
+type csvLayout struct {
+	preambleLines int               // Linhas não vazias antes do cabeçalho
+	delimiter     rune              // Separador das colunas
+	columns       map[string]string // Nome normalizado da coluna -> campo
+	timeLayout    string            // Layout de "data hora" ou da coluna dateTime
+	location      *time.Location    // Fuso dos instantes sem fuso explícito
+	fahrenheit    bool              // Temperatura em °F, convertida para °C
+}
+
+// inmetLayout é o CSV horário do INMET: nove linhas de metadados da estação, o cabeçalho na
+// décima e as medições em seguida, em UTC.
+var inmetLayout = csvLayout{
+	preambleLines: 9,
+	delimiter:     ';',
+	columns: map[string]string{
+		"data medicao": fieldDate,
+		"hora medicao": fieldTime,
+		"temperatura do ar - bulbo seco, horaria": fieldTemperature,
+		"umidade relativa do ar, horaria":         fieldHumidity,
+	},
+	timeLayout: "2006-01-02 15:04",
+	location:   time.UTC,
+}
+
+// ReadInmetCSV lê o arquivo do INMET (.csv, ou .zip ou .gz com o CSV) no modo tolerante.
 func ReadInmetCSV(filepath string) ([]InmetClimateData, error) {
-	var reader io.Reader
-	var closer io.Closer
+	data, _, err := ReadInmetCSVReport(filepath, ParseOptions{})
+	return data, err
+}
+
+// ReadInmetCSVReport lê o arquivo do INMET e retorna também o relatório das linhas descartadas. No
+// modo estrito, qualquer linha inválida resulta em *ParseError, com o relatório completo.
+func ReadInmetCSVReport(filepath string, opts ParseOptions) ([]InmetClimateData, *ParseReport, error) {
+	return readCSVFile(filepath, inmetLayout, opts, "", 0)
+}
+
+// ReadClimate lê o arquivo climático no formato da configuração: o CSV do INMET ou de outra fonte
+// mapeada, os arquivos horários da NOAA ou a série de um ponto da reanálise ERA5. Com CacheDir, a
+// série lida fica em cache e as leituras seguintes do mesmo arquivo com a mesma configuração não o
+// interpretam de novo.
+func ReadClimate(filepath string, cfg SourceConfig) ([]InmetClimateData, *ParseReport, error) {
+	if cfg.CacheDir != "" {
+		return readClimateCached(filepath, cfg)
+	}
+	return readClimate(filepath, cfg)
+}
+
+func readClimate(filepath string, cfg SourceConfig) ([]InmetClimateData, *ParseReport, error) {
+	switch cfg.Format {
+	case "", "inmet":
+		return ReadClimateCSV(filepath, cfg)
+	case "isd", "lcd":
+		opts, err := cfg.ParseOptions()
+		if err != nil {
+			return nil, nil, err
+		}
+		location, err := cfg.location()
+		if err != nil {
+			return nil, nil, err
+		}
+		return readNOAA(filepath, cfg.Format, location, opts)
+	case "era5":
+		opts, err := cfg.ParseOptions()
+		if err != nil {
+			return nil, nil, err
+		}
+		if cfg.Latitude == nil || cfg.Longitude == nil {
+			return nil, nil, fmt.Errorf("o formato era5 exige latitude e longitude do ponto")
+		}
+		if *cfg.Latitude < -90 || *cfg.Latitude > 90 || *cfg.Longitude < -180 || *cfg.Longitude > 360 {
+			return nil, nil, fmt.Errorf("ponto %.3f, %.3f fora do globo", *cfg.Latitude, *cfg.Longitude)
+		}
+		return readERA5(filepath, *cfg.Latitude, *cfg.Longitude, opts)
+	}
+	return nil, nil, fmt.Errorf("formato de arquivo climático '%s' desconhecido (use inmet, isd, lcd ou era5)", cfg.Format)
+}
+
+// ReadClimateCSV lê o arquivo climático com a estrutura da configuração: a do INMET por padrão, ou
+// a de outra fonte descrita pelo mapeamento de colunas.
+func ReadClimateCSV(filepath string, cfg SourceConfig) ([]InmetClimateData, *ParseReport, error) {
+	opts, err := cfg.ParseOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	layout, err := cfg.csvLayout()
+	if err != nil {
+		return nil, nil, err
+	}
+	return readCSVFile(filepath, layout, opts, cfg.Station, cfg.Workers)
+}
+
+func readCSVFile(filepath string, layout csvLayout, opts ParseOptions, station string, workers int) ([]InmetClimateData, *ParseReport, error) {
+	if strings.EqualFold(path.Ext(filepath), ".zip") {
+		return readArchiveStation(filepath, layout, opts, station, workers)
+	}
+	reader, closeFile, err := openClimateFile(filepath, "csv")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeFile()
+	return parseCSV(reader, layout, opts)
+}
 
-	ext := strings.ToLower(filepath[strings.LastIndex(filepath, ".")+1:])
+// openClimateFile abre o arquivo climático: direto, se tiver uma das extensões aceitas, ou dentro de
+// um .zip (a primeira entrada com extensão aceita) ou de um .gz. A função retornada fecha o arquivo.
+func openClimateFile(filepath string, extensions ...string) (io.Reader, func(), error) {
+	var closers []io.Closer
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			if cerr := closers[i].Close(); cerr != nil {
+				log.Printf("Aviso: Erro ao fechar o leitor: %v", cerr)
+			}
+		}
+	}
+	accepted := func(name string) bool {
+		return slices.Contains(extensions, strings.ToLower(strings.TrimPrefix(path.Ext(name), ".")))
+	}
 
-	if ext == "zip" {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filepath), "."))
+	switch {
+	case ext == "zip":
 		zipReader, err := zip.OpenReader(filepath)
 		if err != nil {
-			return nil, fmt.Errorf("erro ao abrir arquivo ZIP '%s': %w", filepath, err)
+			return nil, nil, fmt.Errorf("erro ao abrir arquivo ZIP '%s': %w", filepath, err)
 		}
-		closer = zipReader
+		closers = append(closers, zipReader)
 
-		var csvFile *zip.File
+		var entry *zip.File
 		for _, f := range zipReader.File {
-			if strings.HasSuffix(strings.ToLower(f.Name), ".csv") {
-				csvFile = f
+			if !f.FileInfo().IsDir() && accepted(f.Name) {
+				entry = f
 				break
 			}
 		}
-
-		if csvFile == nil {
-			return nil, fmt.Errorf("nenhum arquivo CSV encontrado dentro do ZIP '%s'", filepath)
+		if entry == nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("nenhum arquivo %s encontrado dentro do ZIP '%s'", describeExtensions(extensions), filepath)
 		}
 
-		rc, err := csvFile.Open()
+		rc, err := entry.Open()
 		if err != nil {
-			return nil, fmt.Errorf("erro ao abrir arquivo CSV dentro do ZIP '%s': %w", csvFile.Name, err)
+			closeAll()
+			return nil, nil, fmt.Errorf("erro ao abrir arquivo '%s' dentro do ZIP '%s': %w", entry.Name, filepath, err)
 		}
-		reader = rc
-		defer rc.Close()
-	} else if ext == "csv" {
+		closers = append(closers, rc)
+		return rc, closeAll, nil
+	case ext == "gz" && accepted(strings.TrimSuffix(filepath, path.Ext(filepath))):
 		file, err := os.Open(filepath)
 		if err != nil {
-			return nil, fmt.Errorf("erro ao abrir o arquivo CSV '%s': %w", filepath, err)
+			return nil, nil, fmt.Errorf("erro ao abrir o arquivo '%s': %w", filepath, err)
 		}
-		reader = file
-		closer = file
-	} else {
-		return nil, fmt.Errorf("formato de arquivo não suportado: '%s'. Esperado .csv ou .zip", ext)
+		closers = append(closers, file)
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("erro ao descompactar o arquivo '%s': %w", filepath, err)
+		}
+		closers = append(closers, gzipReader)
+		return gzipReader, closeAll, nil
+	case accepted(filepath):
+		file, err := os.Open(filepath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao abrir o arquivo '%s': %w", filepath, err)
+		}
+		closers = append(closers, file)
+		return file, closeAll, nil
 	}
+	return nil, nil, fmt.Errorf("formato de arquivo não suportado: '%s'. Esperado %s, .zip ou .gz", ext, describeExtensions(extensions))
+}
 
-	if closer != nil {
-		defer func() {
-			if cerr := closer.Close(); cerr != nil {
-				log.Printf("Aviso: Erro ao fechar o leitor: %v", cerr)
-			}
-		}()
+// describeExtensions lista as extensões aceitas para as mensagens de erro (ex: ".csv ou sem extensão").
+func describeExtensions(extensions []string) string {
+	names := make([]string, len(extensions))
+	for i, ext := range extensions {
+		names[i] = "." + ext
+		if ext == "" {
+			names[i] = "sem extensão"
+		}
 	}
+	return strings.Join(names, " ou ")
+}
+
+// normalizeColumn normaliza o nome de uma coluna do cabeçalho: minúsculas, sem espaços nas bordas
+// e sem a unidade entre parênteses (ex: "TEMPERATURA DO AR - BULBO SECO, HORARIA(°C)").
+func normalizeColumn(name string) string {
+	name = strings.TrimSpace(strings.ToLower(name))
+	if before, _, found := strings.Cut(name, "("); found {
+		name = strings.TrimSpace(before)
+	}
+	return name
+}
 
+// parseCSV lê as medições do CSV climático. Linhas malformadas ou com valores fora do domínio
+// (hora, data, números não finitos) entram no relatório e, no modo tolerante, são puladas com aviso.
+func parseCSV(reader io.Reader, layout csvLayout, opts ParseOptions) ([]InmetClimateData, *ParseReport, error) {
 	csvReader := csv.NewReader(reader)
-	csvReader.Comma = ';'
+	csvReader.Comma = layout.delimiter
 	csvReader.FieldsPerRecord = -1
 
 	var climateData []InmetClimateData
-	headerMap := make(map[string]int)
+	index := make(map[string]int) // Campo -> posição da coluna
 	headerFound := false
+	columns := 0 // Colunas necessárias em cada linha de medição
+	report := &ParseReport{}
+	station := &Station{}
+	skip := func(reason, message string) {
+		line, _ := csvReader.FieldPos(0)
+		report.skip(line, reason, message)
+		if !opts.Strict && !opts.quiet {
+			log.Printf("Aviso: %s na linha %d. Pulando linha.", message, line)
+		}
+	}
 
 	for i := 0; ; i++ {
 		record, err := csvReader.Read()
@@ -82,68 +256,72 @@ func ReadInmetCSV(filepath string) ([]InmetClimateData, error) {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("erro ao ler linha %d do CSV: %w", i+1, err)
+			return nil, report, fmt.Errorf("erro ao ler linha %d do CSV: %w", i+1, err)
 		}
 
-		if i < 9 {
+		if i < layout.preambleLines {
+			station.parsePreambleLine(record)
 			continue
-		} else if i == 9 {
+		} else if i == layout.preambleLines {
+			headerMap := make(map[string]int)
 			for idx, colName := range record {
-				normalizedColName := strings.TrimSpace(strings.ToLower(colName))
-				if strings.Contains(normalizedColName, "(") {
-					normalizedColName = strings.Split(normalizedColName, "(")[0]
-					normalizedColName = strings.TrimSpace(normalizedColName)
+				headerMap[normalizeColumn(colName)] = idx
+			}
+			for name, field := range layout.columns {
+				if idx, ok := headerMap[name]; ok {
+					index[field] = idx
+					columns = max(columns, idx+1)
 				}
-				headerMap[normalizedColName] = idx
 			}
 			headerFound = true
 
-			_, hasDate := headerMap["data medicao"]
-			_, hasTime := headerMap["hora medicao"]
-			_, hasTemp := headerMap["temperatura do ar - bulbo seco, horaria"]
-			_, hasHum := headerMap["umidade relativa do ar, horaria"]
-
-			if !hasDate || !hasTime || !hasTemp || !hasHum {
-				return nil, fmt.Errorf("cabeçalho do CSV não contém todas as colunas esperadas. Verifique os nomes das colunas no CSV e no código: %v", headerMap)
+			if missing := layout.missingFields(index); len(missing) > 0 {
+				return nil, report, fmt.Errorf("cabeçalho do CSV não contém todas as colunas esperadas (faltando: %s). Verifique os nomes das colunas no CSV e na configuração: %v", strings.Join(missing, ", "), headerMap)
 			}
 			continue
 		}
 
 		if !headerFound {
-			return nil, fmt.Errorf("dados encontrados antes do cabeçalho ser mapeado. Verifique a estrutura do CSV. %v", headerFound)
+			return nil, report, fmt.Errorf("dados encontrados antes do cabeçalho ser mapeado. Verifique a estrutura do CSV. %v", headerFound)
 		}
 
-		dateStr := record[headerMap["data medicao"]]
-		timeStrRaw := record[headerMap["hora medicao"]]
-
-		var timeStrFormatted string
-		if len(timeStrRaw) == 4 {
-			timeStrFormatted = timeStrRaw[:2] + ":" + timeStrRaw[2:]
-		} else if len(timeStrRaw) == 3 {
-			timeStrFormatted = "0" + timeStrRaw[:1] + ":" + timeStrRaw[1:]
-		} else {
-			log.Printf("Aviso: Formato de hora inesperado '%s' na linha %d. Pulando linha.", timeStrRaw, i+1)
+		report.Rows++
+		if len(record) < columns {
+			skip("colunas faltando", fmt.Sprintf("%d colunas, esperadas ao menos %d", len(record), columns))
 			continue
 		}
 
-		dateTimeStr := fmt.Sprintf("%s %s", dateStr, timeStrFormatted)
-		timestamp, err := time.Parse("2006-01-02 15:04", dateTimeStr)
+		var dateTimeStr string
+		if idx, combined := index[fieldDateTime]; combined {
+			dateTimeStr = record[idx]
+		} else {
+			timeStr, ok := normalizeHour(record[index[fieldTime]])
+			if !ok {
+				skip("hora inválida", fmt.Sprintf("Formato de hora inesperado '%s'", record[index[fieldTime]]))
+				continue
+			}
+			dateTimeStr = fmt.Sprintf("%s %s", record[index[fieldDate]], timeStr)
+		}
+		timestamp, err := time.ParseInLocation(layout.timeLayout, strings.TrimSpace(dateTimeStr), layout.location)
 		if err != nil {
-			log.Printf("Aviso: Erro ao fazer parse do timestamp '%s' na linha %d: %v. Pulando linha.", dateTimeStr, i+1, err)
+			skip("data inválida", fmt.Sprintf("Erro ao fazer parse do timestamp '%s': %v", dateTimeStr, err))
 			continue
 		}
 
-		tempAirStr := strings.Replace(record[headerMap["temperatura do ar - bulbo seco, horaria"]], ",", ".", -1)
-		tempAir, err := strconv.ParseFloat(tempAirStr, 64)
+		tempAirStr := strings.Replace(record[index[fieldTemperature]], ",", ".", -1)
+		tempAir, err := parseMeasurement(tempAirStr)
 		if err != nil {
-			log.Printf("Aviso: Erro ao fazer parse da temperatura do ar '%s' na linha %d: %v. Pulando linha.", tempAirStr, i+1, err)
+			skip(missingOrInvalid(tempAirStr, "temperatura do ar"), fmt.Sprintf("Erro ao fazer parse da temperatura do ar '%s': %v", tempAirStr, err))
 			continue
 		}
+		if layout.fahrenheit {
+			tempAir = (tempAir - 32) * 5 / 9
+		}
 
-		humidityStr := strings.Replace(record[headerMap["umidade relativa do ar, horaria"]], ",", ".", -1)
-		humidity, err := strconv.ParseFloat(humidityStr, 64)
+		humidityStr := strings.Replace(record[index[fieldHumidity]], ",", ".", -1)
+		humidity, err := parseMeasurement(humidityStr)
 		if err != nil {
-			log.Printf("Aviso: Erro ao fazer parse da umidade relativa '%s' na linha %d: %v. Pulando linha.", humidityStr, i+1, err)
+			skip(missingOrInvalid(humidityStr, "umidade relativa"), fmt.Sprintf("Erro ao fazer parse da umidade relativa '%s': %v", humidityStr, err))
 			continue
 		}
 
@@ -154,5 +332,65 @@ func ReadInmetCSV(filepath string) ([]InmetClimateData, error) {
 		})
 	}
 
-	return climateData, nil
+	if !station.empty() {
+		report.Station = station
+	}
+	if opts.Strict && len(report.Skipped) > 0 {
+		return nil, report, &ParseError{Report: report}
+	}
+	return climateData, report, nil
+}
+
+// missingFields lista os campos obrigatórios sem coluna no cabeçalho: temperatura, umidade e a
+// data e hora, juntas ou em colunas separadas.
+func (l csvLayout) missingFields(index map[string]int) []string {
+	var missing []string
+	for _, field := range []string{fieldTemperature, fieldHumidity} {
+		if _, ok := index[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if _, ok := index[fieldDateTime]; !ok {
+		for _, field := range []string{fieldDate, fieldTime} {
+			if _, ok := index[field]; !ok {
+				missing = append(missing, field)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// normalizeHour converte a hora HHMM do INMET (ex: 0100, 100) para HH:MM. Horas em outro formato
+// (ex: 01:00) seguem como estão, para o layout configurado.
+func normalizeHour(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if strings.Trim(raw, "0123456789") != "" {
+		return raw, raw != ""
+	}
+	switch len(raw) {
+	case 4:
+		return raw[:2] + ":" + raw[2:], true
+	case 3:
+		return "0" + raw[:1] + ":" + raw[1:], true
+	}
+	return raw, false
+}
+
+// parseMeasurement interpreta uma medição numérica, rejeitando valores não finitos.
+func parseMeasurement(raw string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err == nil && (math.IsNaN(value) || math.IsInf(value, 0)) {
+		err = fmt.Errorf("valor não finito")
+	}
+	return value, err
+}
+
+// missingOrInvalid separa os campos vazios ou "null" (medição ausente na estação) dos valores
+// ilegíveis.
+func missingOrInvalid(value, field string) string {
+	if value = strings.TrimSpace(value); value == "" || strings.EqualFold(value, "null") {
+		return field + " ausente"
+	}
+	return field + " inválida"
 }
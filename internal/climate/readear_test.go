@@ -0,0 +1,83 @@
+package climate
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// inmetSample é um trecho do CSV do INMET com o cabeçalho e duas medições.
+const inmetSample = `Nome: SAO PAULO - MIRANTE
+Codigo Estacao: A701
+Latitude: -23.4962888
+Longitude: -46.6200666
+Altitude: 785.64
+Situacao: Operante
+Data Inicial: 2024-01-01
+Data Final: 2025-01-01
+Periodicidade da Medicao: Horaria
+
+Data Medicao;Hora Medicao;TEMPERATURA DO AR - BULBO SECO, HORARIA(°C);UMIDADE RELATIVA DO AR, HORARIA(%);
+2024-01-01;0000;19,5;76;
+2024-01-01;100;19,3;
+`
+
+func FuzzParseInmetCSV(f *testing.F) {
+	log.SetOutput(io.Discard) // Cada linha inválida gera um aviso
+	f.Cleanup(func() { log.SetOutput(os.Stderr) })
+	f.Add([]byte(inmetSample))
+	f.Add([]byte(inmetSample + "2024-01-01;0200;NaN;Inf;\n2024-13-45;0300;1;2\n\"aberto;0400\n"))
+	f.Add([]byte("\n\n\n\n\n\n\n\n\nData Medicao;Hora Medicao\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		records, report, err := parseCSV(bytes.NewReader(data), inmetLayout, ParseOptions{})
+		if err != nil {
+			return
+		}
+		if len(records)+len(report.Skipped) != report.Rows {
+			t.Fatalf("%d registros e %d linhas descartadas de %d lidas", len(records), len(report.Skipped), report.Rows)
+		}
+		if _, _, err := parseCSV(bytes.NewReader(data), inmetLayout, ParseOptions{Strict: true}); (err != nil) != (len(report.Skipped) > 0) {
+			t.Fatalf("modo estrito com %d linhas descartadas retornou erro %v", len(report.Skipped), err)
+		}
+		for _, record := range records {
+			if math.IsNaN(record.TemperatureAir) || math.IsInf(record.TemperatureAir, 0) || math.IsNaN(record.RelativeHumidity) || math.IsInf(record.RelativeHumidity, 0) {
+				t.Fatalf("medição não finita aceita: %+v", record)
+			}
+		}
+	})
+}
+
+func TestReadInmetCSVExtension(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"dados", "dados.txt", "dir.v2/dados"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(inmetSample), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadInmetCSV(path); err == nil {
+			t.Errorf("%s: esperado erro de formato não suportado", name)
+		}
+	}
+}
+
+func BenchmarkReadInmetCSV(b *testing.B) {
+	log.SetOutput(io.Discard) // Linhas com dados faltantes geram avisos a cada leitura
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+	b.ReportAllocs()
+	records := 0
+	for range b.N {
+		data, err := ReadInmetCSV("../../data/inmet/dados-202401-202501.zip")
+		if err != nil {
+			b.Fatalf("ReadInmetCSV: %v", err)
+		}
+		records += len(data)
+	}
+	b.ReportMetric(float64(records)/b.Elapsed().Seconds(), "registros/s")
+}
@@ -0,0 +1,74 @@
+package climate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Station são os metadados da estação meteorológica lidos do preâmbulo do CSV do INMET.
+// Coordenadas e altitude ausentes ou ilegíveis ficam nulas.
+type Station struct {
+	Code      string   `json:"code,omitempty"`      // Código da estação (ex: A701)
+	Name      string   `json:"name,omitempty"`      // Nome da estação (ex: SAO PAULO - MIRANTE)
+	Latitude  *float64 `json:"latitude,omitempty"`  // Latitude (graus decimais)
+	Longitude *float64 `json:"longitude,omitempty"` // Longitude (graus decimais)
+	Altitude  *float64 `json:"altitude,omitempty"`  // Altitude da estação (m)
+}
+
+// String descreve a estação para os logs (ex: "A701 SAO PAULO - MIRANTE, -23.4963, -46.6201, 785.6 m").
+func (s *Station) String() string {
+	parts := []string{strings.TrimSpace(s.Code + " " + s.Name)}
+	if s.Latitude != nil && s.Longitude != nil {
+		parts = append(parts, fmt.Sprintf("%.4f, %.4f", *s.Latitude, *s.Longitude))
+	}
+	if s.Altitude != nil {
+		parts = append(parts, fmt.Sprintf("%.1f m", *s.Altitude))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// empty indica se nenhum metadado foi encontrado no preâmbulo.
+func (s *Station) empty() bool {
+	return s.Code == "" && s.Name == "" && s.Latitude == nil && s.Longitude == nil && s.Altitude == nil
+}
+
+// parsePreambleLine interpreta uma linha "Chave: valor" do preâmbulo. Aceita tanto o formato com a
+// chave e o valor no mesmo campo ("Codigo Estacao: A701") quanto o dos arquivos anuais do portal,
+// com o valor na coluna seguinte ("CODIGO (WMO):;A701") e vírgula decimal. Chaves desconhecidas
+// (região, situação, datas) são ignoradas.
+func (s *Station) parsePreambleLine(record []string) {
+	if len(record) == 0 {
+		return
+	}
+	key, value, found := strings.Cut(record[0], ":")
+	if !found {
+		return
+	}
+	if strings.TrimSpace(value) == "" && len(record) > 1 {
+		value = record[1]
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	coordinate := func() *float64 {
+		v, err := parseMeasurement(strings.Replace(value, ",", ".", 1))
+		if err != nil {
+			return nil
+		}
+		return &v
+	}
+	switch normalizeColumn(key) {
+	case "nome", "estacao", "estação":
+		s.Name = value
+	case "codigo estacao", "código estação", "codigo", "código":
+		s.Code = value
+	case "latitude":
+		s.Latitude = coordinate()
+	case "longitude":
+		s.Longitude = coordinate()
+	case "altitude":
+		s.Altitude = coordinate()
+	}
+}
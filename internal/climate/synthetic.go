@@ -0,0 +1,24 @@
+package climate
+
+import (
+	"math"
+	"time"
+)
+
+// Synthetic gera dias de leituras horárias com ciclo diário de temperatura e umidade, de um inverno
+// ameno a um verão quente e úmido. Serve de carga reproduzível para testes e medições de desempenho,
+// sem depender de um arquivo do INMET.
+func Synthetic(start time.Time, days int) []InmetClimateData {
+	records := make([]InmetClimateData, 0, days*24)
+	for h := 0; h < days*24; h++ {
+		t := start.Add(time.Duration(h) * time.Hour)
+		season := math.Sin(2 * math.Pi * float64(h) / float64(days*24))
+		daily := math.Sin(2 * math.Pi * float64(t.Hour()-9) / 24)
+		records = append(records, InmetClimateData{
+			Timestamp:        t,
+			TemperatureAir:   22 + 10*season + 6*daily,
+			RelativeHumidity: 70 - 20*daily + 10*season,
+		})
+	}
+	return records
+}
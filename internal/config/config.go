@@ -0,0 +1,165 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+	"github.com/patrik-rangel/mock-data-hvac/internal/iot"
+	"github.com/patrik-rangel/mock-data-hvac/internal/mongodb"
+	"github.com/patrik-rangel/mock-data-hvac/internal/opensearch"
+	"github.com/patrik-rangel/mock-data-hvac/internal/stream"
+)
+
+// Scenario reúne os parâmetros opcionais de simulação carregados de um arquivo JSON.
+type Scenario struct {
+	Climate         *climate.SourceConfig       `json:"climate"`         // Leitura do arquivo climático (modo tolerante se ausente)
+	ExtremeEvents   []climate.ExtremeEvent      `json:"extremeEvents"`   // Eventos climáticos extremos a injetar
+	Forecast        *climate.ForecastConfig     `json:"forecast"`        // Previsões de temperatura externa (desativado se ausente)
+	Tenant          string                      `json:"tenant"`          // Namespace do tenant: prefixa as chaves no S3, os destinos de streaming e os IDs dos dispositivos (TENANT sobrepõe)
+	Seed            int64                       `json:"seed"`            // Semente dos geradores aleatórios (0 usa o relógio)
+	WarmUpHours     float64                     `json:"warmUpHours"`     // Horas simuladas sem emitir registros antes do primeiro registro emitido (padrão: 0, ou o mesmo dia de --from)
+	Altitude        *float64                    `json:"altitude"`        // Altitude do site (m) (padrão: a da estação no arquivo climático, ou o nível do mar)
+	Devices         []hvac.Device               `json:"devices"`         // Frota simulada (padrão: SALA-1 a SALA-10 na Zona-A)
+	AssetModels     []hvac.AssetModelSpec       `json:"assetModels"`     // Curvas de eficiência (COP por temperatura externa e carga parcial) por modelo de equipamento
+	Hydronic        *hvac.HydronicConfig        `json:"hydronic"`        // Serpentinas de água gelada (chiller) e quente (caldeira) com telemetria do lado de água (desativadas se ausente)
+	VRF             *hvac.VRFConfig             `json:"vrf"`             // Sistemas VRF multi-split: unidades externas com capacidade compartilhada entre as salas (desativados se ausente)
+	ERV             *hvac.ERVConfig             `json:"erv"`             // Recuperador de calor (HRV/ERV) no ar de renovação de cada sala (desativado se ausente)
+	Defrost         *hvac.DefrostConfig         `json:"defrost"`         // Ciclos de degelo das bombas de calor com ar externo frio e úmido (desativados se ausente)
+	Filter          *hvac.FilterConfig          `json:"filter"`          // Carga do filtro por horas de ventilador e eventos de poeira externa (colmatação sazonal se ausente)
+	Maintenance     *hvac.MaintenanceConfig     `json:"maintenance"`     // Ordens de serviço de manutenção preventivas e corretivas, gravadas em arquivo próprio (desativadas se ausente)
+	Lifecycle       *hvac.LifecycleConfig       `json:"lifecycle"`       // Instalação, troca e retirada de equipamentos, gravadas em arquivo próprio (desativadas se ausente)
+	Economizer      *hvac.EconomizerConfig      `json:"economizer"`      // Economizador de ar externo com limite alto pela zona climática ASHRAE (desativado se ausente)
+	CorrelatedNoise *hvac.CorrelatedNoiseConfig `json:"correlatedNoise"` // Ruído de medição correlacionado entre campos por matriz de correlação (desativado se ausente)
+	Missingness     *hvac.MissingnessConfig     `json:"missingness"`     // Ausência de campos por padrão aleatório, em rajadas ou por dispositivo (desativada se ausente)
+	Boundary        *hvac.BoundaryConfig        `json:"boundary"`        // Leituras levadas aos limites das faixas (temperaturas, umidade, consumo, falhas longas) para testar os consumidores (desativadas se ausente)
+	Zones           []hvac.Zone                 `json:"zones"`           // Área e volume das zonas, para métricas de intensidade
+	Precooling      *hvac.PrecoolingConfig      `json:"precooling"`      // Estratégia de pré-resfriamento (desativada se ausente)
+	G36             *hvac.G36Config             `json:"g36"`             // Sequência G36 no lugar do termostato simples (desativada se ausente)
+	ControlStrategy string                      `json:"controlStrategy"` // Estratégia de controle das salas: thermostat (padrão), scheduled, g36 ou registrada pela biblioteca
+	BASSchedule     *hvac.ScheduleImportConfig  `json:"basSchedule"`     // Programações semanais e exceções exportadas da automação, aplicadas às zonas no lugar de controlStrategy
+	FaultModel      *hvac.FaultModelConfig      `json:"faultModel"`      // Modelo de desgaste e alarmes do equipamento (padrão: sazonal)
+	Consistency     *hvac.ConsistencyConfig     `json:"consistency"`     // Verificação da coerência entre modo e grandezas de cada registro (desativada se ausente)
+	EnergyBalance   *hvac.EnergyBalanceConfig   `json:"energyBalance"`   // Balanço de energia diário por zona, com aviso quando violado (desativado se ausente)
+	Timestamps      *hvac.TimestampConfig       `json:"timestamps"`      // Defasagem e jitter do instante de leitura dos dispositivos (todos no início do passo se ausente)
+	FddBaseline     bool                        `json:"fddBaseline"`     // Emite os valores esperados pelo modelo físico em cada leitura
+	PointCatalog    bool                        `json:"pointCatalog"`    // Exporta a lista de pontos (CSV) junto dos dados
+	DeviceRegistry  string                      `json:"deviceRegistry"`  // Exporta o registro de dispositivos da frota no formato json ou csv (desativado se vazio)
+	RecordRunID     bool                        `json:"recordRunId"`     // Grava o ID da execução (runId) em cada registro
+	Outages         *hvac.OutageConfig          `json:"outages"`         // Quedas de energia do site (desativadas se ausente)
+	Brownouts       *hvac.BrownoutConfig        `json:"brownouts"`       // Afundamentos de tensão do site (desativados se ausente)
+	Overrides       *hvac.OverrideConfig        `json:"overrides"`       // Ajustes de setpoint pelos ocupantes (desativados se ausente)
+	Sensors         []hvac.WirelessSensor       `json:"sensors"`         // Sensores de ambiente a bateria (sem fio) instalados nas salas
+	Dialects        map[string]hvac.Dialect     `json:"dialects"`        // Formatos de payload por grupo de dispositivos (devices[].dialect)
+	Templates       map[string]string           `json:"templates"`       // Modelos de payload (Go templates) adicionais ou substitutos (devices[].template)
+	Envelope        *hvac.EnvelopeConfig        `json:"envelope"`        // Envelope de gateway em volta de cada registro (desativado se ausente)
+	Batching        *hvac.BatchConfig           `json:"batching"`        // Envio das leituras em lotes por gateway (desativado se ausente)
+	Edge            *hvac.EdgeConfig            `json:"edge"`            // Gateway de borda com os fluxos local e encaminhado para a nuvem (desativado se ausente)
+	Transforms      []hvac.TransformConfig      `json:"transforms"`      // Pipeline de pós-processamento dos payloads (unidades, nomes, arredondamento, anonimização), em ordem
+	Precision       *hvac.PrecisionConfig       `json:"precision"`       // Casas decimais por campo ou unidade em todas as saídas (precisão total se ausente)
+	Rollups         *hvac.RollupConfig          `json:"rollups"`         // Exporta agregados por janela (média, mínimo e máximo) junto dos dados brutos
+	TrendLogs       *hvac.TrendLogConfig        `json:"trendLogs"`       // Exporta um trend log CSV por ponto, no estilo de BAS (Niagara/ALC)
+	MLDataset       *hvac.MLDatasetConfig       `json:"mlDataset"`       // Exporta divisões de treino, validação e teste (Parquet) e janelas deslizantes com rótulo (NPZ) para ML
+	Features        *hvac.FeatureConfig         `json:"features"`        // Exporta um conjunto de dados complementar com atributos derivados (médias móveis, diferenças, tempo no modo, graus-hora)
+	GreenButton     *hvac.GreenButtonConfig     `json:"greenButton"`     // Exporta o consumo total do prédio em Green Button XML (ESPI)
+	Shadows         *hvac.ShadowConfig          `json:"shadows"`         // Atualizações de estado reportado (AWS IoT Device Shadow) por dispositivo
+	IoTProvisioning *iot.ProvisionConfig        `json:"iotProvisioning"` // Cadastro dos dispositivos como things do IoT Core antes da publicação (desativado se ausente)
+	Badges          *hvac.BadgeConfig           `json:"badges"`          // Passagens de crachá sintéticas por zona, coerentes com a ocupação (desativadas se ausente)
+	OpenSearch      *opensearch.Config          `json:"openSearch"`      // Índices e lotes da indexação no OpenSearch (com OPENSEARCH_URL definido)
+	Stream          *stream.Config              `json:"stream"`          // Taxa e destinos dos sinks de streaming (com REDIS_URL, NATS_URL, PULSAR_URL ou AMQP_URL definidos)
+	MongoDB         *mongodb.Config             `json:"mongodb"`         // Banco e coleção time-series do MongoDB (com MONGODB_URI definido)
+	Output          hvac.OutputConfig           `json:"output"`          // Formato do arquivo principal de dados (padrão: JSON)
+}
+
+// Load lê e valida o cenário do arquivo informado. Um caminho vazio retorna o cenário padrão. As
+// chaves desconhecidas são registradas como avisos; valores do tipo errado e os problemas
+// apontados por Validate são retornados juntos, um por linha.
+func Load(path string) (Scenario, error) {
+	scenario, _, err := load(path)
+	if err != nil {
+		return scenario, err
+	}
+	if err := scenario.Validate(); err != nil {
+		return scenario, fmt.Errorf("arquivo de cenário '%s' inválido:\n%w", path, err)
+	}
+	return scenario, nil
+}
+
+// load lê o cenário do arquivo, conferindo-o contra o esquema, sem validar os valores. Retorna
+// também o documento JSON, para que Resolve saiba quais opções o arquivo define.
+func load(path string) (Scenario, any, error) {
+	var scenario Scenario
+	if path == "" {
+		return scenario, nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return scenario, nil, fmt.Errorf("erro ao ler o arquivo de cenário '%s': %w", path, err)
+	}
+	var document any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return scenario, nil, fmt.Errorf("erro ao interpretar o arquivo de cenário '%s': %w", path, err)
+	}
+	warnings, err := checkSchema(document)
+	for _, warning := range warnings {
+		log.Printf("Aviso: %s", warning)
+	}
+	if err != nil {
+		return scenario, nil, fmt.Errorf("arquivo de cenário '%s' inválido:\n%w", path, err)
+	}
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return scenario, nil, fmt.Errorf("erro ao interpretar o arquivo de cenário '%s': %w", path, err)
+	}
+	return scenario, document, nil
+}
+
+// SimulatesFleet indica se o cenário pede a simulação da frota a cada hora, com o estado térmico de
+// cada sala: alguma opção do simulador (dispositivos, pré-resfriamento, G36, falhas etc.), uma
+// estratégia de controle, um modelo de falhas ou horas de aquecimento. Sem nenhuma delas, o
+// gerador mantém a saída original: uma leitura isolada por hora, de uma sala sorteada.
+func (s Scenario) SimulatesFleet() bool {
+	cfg := s.SimulatorConfig()
+	cfg.Seed = 0 // A semente só torna a execução reprodutível
+	return !reflect.ValueOf(cfg).IsZero() || s.ControlStrategy != "" || s.BASSchedule != nil || s.FaultModel != nil || s.WarmUpHours > 0
+}
+
+// SimulatorConfig monta a configuração do simulador com as opções do cenário. A estratégia de
+// controle, o modelo de falhas, a estação e a altitude dependem de arquivos e da leitura do clima,
+// e ficam a cargo de quem chama.
+func (s Scenario) SimulatorConfig() hvac.SimulatorConfig {
+	return hvac.SimulatorConfig{
+		Devices:     s.Devices,
+		Zones:       s.Zones,
+		Seed:        s.Seed,
+		Precooling:  s.Precooling,
+		G36:         s.G36,
+		FddBaseline: s.FddBaseline,
+		Outages:     s.Outages,
+		Brownouts:   s.Brownouts,
+		Overrides:   s.Overrides,
+		Sensors:     s.Sensors,
+		Consistency: s.Consistency,
+		Energy:      s.EnergyBalance,
+		Timestamps:  s.Timestamps,
+		AssetModels: s.AssetModels,
+		Hydronic:    s.Hydronic,
+		VRF:         s.VRF,
+		ERV:         s.ERV,
+		Defrost:     s.Defrost,
+		Filter:      s.Filter,
+		Maintenance: s.Maintenance,
+		Lifecycle:   s.Lifecycle,
+		Economizer:  s.Economizer,
+		Noise:       s.CorrelatedNoise,
+		Missingness: s.Missingness,
+		Boundary:    s.Boundary,
+	}
+}
@@ -0,0 +1,399 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/secrets"
+)
+
+// EnvPrefix é o prefixo das variáveis de ambiente do gerador: HVACMOCK_<CAMINHO> ajusta uma opção
+// do cenário (ex: HVACMOCK_OUTPUT_LOCAL_DIR para output.localDir) e HVACMOCK_<NOME> tem precedência
+// sobre as variáveis de execução sem prefixo (ex: HVACMOCK_S3_BUCKET_NAME sobre S3_BUCKET_NAME).
+const EnvPrefix = "HVACMOCK_"
+
+// RuntimeEnv são as variáveis de execução lidas pelo gerador fora do cenário: conexões, credenciais
+// e opções do S3. Aceitam o nome sem prefixo, mantido por compatibilidade, ou com EnvPrefix.
+var RuntimeEnv = []string{
+	"SCENARIO_FILE", "RUN_ID", "S3_BUCKET_NAME", "AWS_REGION", "ENDPOINT_URL", "S3_ADDRESSING_STYLE",
+	"S3_INSECURE_SKIP_VERIFY", "S3_CA_BUNDLE", "S3_ANONYMOUS", "S3_BOOTSTRAP", "S3_EXPIRATION_DAYS",
+	"OUTPUT_ENCRYPTION_KEY", "DYNAMODB_STATE_TABLE", "IOT_DATA_ENDPOINT", "OPENSEARCH_URL",
+	"OPENSEARCH_USERNAME", "OPENSEARCH_PASSWORD", "REDIS_URL", "NATS_URL", "PULSAR_URL", "AMQP_URL",
+	"MONGODB_URI",
+}
+
+// legacyEnv são as variáveis sem prefixo que já ajustavam opções do cenário, abaixo das
+// HVACMOCK_ na precedência.
+var legacyEnv = map[string]string{"tenant": "TENANT"}
+
+// resolvedSecrets guarda os valores das variáveis de execução que referenciam segredos, lidos por
+// ResolveSecrets, pelo nome da variável.
+var resolvedSecrets = make(map[string]string)
+
+// LookupEnv lê a variável de execução: HVACMOCK_<nome>, senão <nome>. Retorna também a variável
+// encontrada (vazia se nenhuma está definida). Uma referência a segredo já lida por ResolveSecrets
+// volta com o valor do segredo.
+func LookupEnv(name string) (value, variable string) {
+	value, variable = lookupRawEnv(name)
+	if resolved, ok := resolvedSecrets[variable]; ok {
+		return resolved, variable
+	}
+	return value, variable
+}
+
+// ResolveSecrets lê os segredos referenciados pelas variáveis de execução (ex:
+// REDIS_URL=secretsmanager:hvac/redis#url, ver o pacote secrets), ou só pelas informadas em names,
+// e passa a retorná-los em LookupEnv e Getenv. O valor lido não volta para o ambiente do processo,
+// que mantém só a referência. Retorna o número de variáveis resolvidas.
+func ResolveSecrets(ctx context.Context, resolver *secrets.Resolver, names ...string) (int, error) {
+	if len(names) == 0 {
+		names = RuntimeEnv
+	}
+	count := 0
+	for _, name := range names {
+		value, variable := lookupRawEnv(name)
+		if !secrets.IsReference(value) {
+			continue
+		}
+		resolved, err := resolver.Resolve(ctx, value)
+		if err != nil {
+			return count, fmt.Errorf("erro ao resolver %s: %w", variable, err)
+		}
+		resolvedSecrets[variable] = resolved
+		count++
+	}
+	return count, nil
+}
+
+// lookupRawEnv lê a variável de execução como está no ambiente, sem resolver segredos.
+func lookupRawEnv(name string) (value, variable string) {
+	if value, ok := os.LookupEnv(EnvPrefix + name); ok {
+		return value, EnvPrefix + name
+	}
+	if value, ok := os.LookupEnv(name); ok {
+		return value, name
+	}
+	return "", ""
+}
+
+// Getenv lê a variável de execução como LookupEnv.
+func Getenv(name string) string {
+	value, _ := LookupEnv(name)
+	return value
+}
+
+// Setting é o valor efetivo de uma opção do cenário e a camada de onde ele veio.
+type Setting struct {
+	Key    string // Caminho da opção no cenário (ex: output.localDir)
+	Value  string // Valor efetivo (vazio quando vale o padrão do gerador)
+	Source string // Camada do valor: padrão, arquivo, variável de ambiente ou flag
+	// Disabled marca uma seção desativada (nula), listada no lugar das suas opções; Enabled, uma
+	// seção ativada, listada antes delas com a camada que a ativou (ex: "trendLogs": {} no arquivo).
+	Disabled bool
+	Enabled  bool
+}
+
+// Fontes dos valores nas Settings.
+const (
+	SourceDefault = "padrão"
+	SourceFile    = "arquivo"
+	SourceEnv     = "ambiente"
+	SourceFlag    = "flag --set"
+)
+
+// option é uma opção do cenário que pode ser ajustada por variável de ambiente ou --set: um campo
+// escalar, uma lista, um mapa ou um tipo com decodificação própria, alcançado pelos structs.
+// sections são os caminhos das seções opcionais (ponteiros) que a contêm, da mais externa.
+type option struct {
+	path     string
+	index    [][]int
+	sections []string
+}
+
+// envName é a variável de ambiente da opção: EnvPrefix mais o caminho em maiúsculas, com '_' entre
+// as palavras (ex: output.localDir vira HVACMOCK_OUTPUT_LOCAL_DIR).
+func (o option) envName() string {
+	var b strings.Builder
+	b.WriteString(EnvPrefix)
+	previous := rune(0)
+	for _, r := range o.path {
+		switch {
+		case r == '.':
+			b.WriteByte('_')
+		case unicode.IsUpper(r) && (unicode.IsLower(previous) || unicode.IsDigit(previous)):
+			b.WriteByte('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToUpper(r))
+		}
+		previous = r
+	}
+	return b.String()
+}
+
+// scenarioOptions lista as opções do cenário, na ordem dos campos.
+func scenarioOptions() []option {
+	var options []option
+	var walk func(t reflect.Type, path string, index [][]int, sections []string)
+	walk = func(t reflect.Type, path string, index [][]int, sections []string) {
+		for _, field := range reflect.VisibleFields(t) {
+			if !field.IsExported() || field.Anonymous && field.Tag.Get("json") == "" {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fieldPath := joinPath(path, name)
+			fieldIndex := append(slices.Clone(index), field.Index)
+			elem := field.Type
+			fieldSections := sections
+			if elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+				fieldSections = append(slices.Clone(sections), fieldPath)
+			}
+			if elem.Kind() == reflect.Struct && !customDecoding(elem) && len(index) < 4 {
+				walk(elem, fieldPath, fieldIndex, fieldSections)
+				continue
+			}
+			options = append(options, option{path: fieldPath, index: fieldIndex, sections: sections})
+		}
+	}
+	walk(reflect.TypeFor[Scenario](), "", nil, nil)
+	return options
+}
+
+// customDecoding indica se o tipo tem decodificação JSON própria.
+func customDecoding(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(unmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// field retorna o campo da opção no cenário. Com allocate, cria as seções nulas no caminho; sem
+// ele, para na primeira seção desativada (nula) e retorna o seu caminho.
+func (o option) field(scenario *Scenario, allocate bool) (value reflect.Value, disabled string) {
+	value = reflect.ValueOf(scenario).Elem()
+	for i, index := range o.index {
+		value = value.FieldByIndex(index)
+		if i == len(o.index)-1 {
+			break
+		}
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !allocate {
+					return value, strings.Join(strings.Split(o.path, ".")[:i+1], ".")
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+	}
+	return value, ""
+}
+
+// set atribui o valor textual à opção: texto como está, booleano como em strconv.ParseBool e os
+// demais tipos em JSON (números, listas, objetos), com o texto sem aspas aceito nos tipos de
+// decodificação própria.
+func (o option) set(scenario *Scenario, raw string) error {
+	field, _ := o.field(scenario, true)
+	target := field
+	if target.Kind() == reflect.Pointer {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+	switch {
+	case target.Kind() == reflect.String && !customDecoding(target.Type()):
+		target.SetString(raw)
+	case target.Kind() == reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("use true ou false")
+		}
+		target.SetBool(value)
+	default:
+		err := json.Unmarshal([]byte(raw), target.Addr().Interface())
+		if err != nil && !json.Valid([]byte(raw)) {
+			quoted, _ := json.Marshal(raw)
+			err = json.Unmarshal(quoted, target.Addr().Interface())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if field.Kind() == reflect.Pointer {
+		field.Set(target.Addr())
+	}
+	return nil
+}
+
+// inDocument indica se o arquivo de cenário define a opção ou a seção no caminho, mesmo como um
+// objeto vazio.
+func inDocument(document any, path string) bool {
+	for _, key := range strings.Split(path, ".") {
+		object, ok := document.(map[string]any)
+		if !ok {
+			return false
+		}
+		var found bool
+		for name, value := range object {
+			if strings.EqualFold(name, key) {
+				document, found = value, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return document != nil
+}
+
+// Resolve monta o cenário efetivo pelas camadas, da menor para a maior precedência: os padrões do
+// gerador, o arquivo de cenário (como em Load), as variáveis de ambiente HVACMOCK_<CAMINHO> (e as
+// antigas sem prefixo, como TENANT) e os ajustes de --set, no formato caminho=valor (ex:
+// output.format=parquet). O cenário final é validado, e as Settings trazem o valor efetivo e a
+// camada de cada opção. As variáveis HVACMOCK_ que não correspondem a nenhuma opção geram avisos.
+func Resolve(path string, sets []string) (Scenario, []Setting, error) {
+	scenario, document, err := load(path)
+	if err != nil {
+		return scenario, nil, err
+	}
+
+	options := scenarioOptions()
+	sources := make(map[string]string)
+	byEnv := make(map[string]option)
+	for _, opt := range options {
+		byEnv[opt.envName()] = opt
+		for _, key := range append(slices.Clone(opt.sections), opt.path) {
+			if inDocument(document, key) {
+				sources[key] = SourceFile + " " + path
+			}
+		}
+	}
+
+	for _, opt := range options {
+		for _, variable := range []string{legacyEnv[opt.path], opt.envName()} {
+			raw, ok := os.LookupEnv(variable)
+			if variable == "" || !ok || raw == "" && variable == legacyEnv[opt.path] {
+				continue
+			}
+			if err := opt.set(&scenario, raw); err != nil {
+				return scenario, nil, fmt.Errorf("valor inválido '%s' em %s para %s: %w", raw, variable, opt.path, err)
+			}
+			opt.record(sources, SourceEnv+" "+variable)
+		}
+	}
+	for _, entry := range os.Environ() {
+		variable, _, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(variable, EnvPrefix) {
+			continue
+		}
+		if _, ok := byEnv[variable]; ok || slices.Contains(RuntimeEnv, strings.TrimPrefix(variable, EnvPrefix)) {
+			continue
+		}
+		known := slices.Concat(slices.Collect(maps.Keys(byEnv)), prefixed(RuntimeEnv))
+		log.Printf("Aviso: variável de ambiente %s não corresponde a nenhuma opção, ignorada%s", variable, suggestion(variable, known))
+	}
+
+	for _, set := range sets {
+		key, raw, ok := strings.Cut(set, "=")
+		if !ok {
+			return scenario, nil, fmt.Errorf("ajuste '%s' inválido em --set (use caminho=valor, ex: output.format=parquet)", set)
+		}
+		index := slices.IndexFunc(options, func(o option) bool { return strings.EqualFold(o.path, key) })
+		if index < 0 {
+			paths := make([]string, len(options))
+			for i, opt := range options {
+				paths[i] = opt.path
+			}
+			return scenario, nil, fmt.Errorf("opção '%s' desconhecida em --set%s", key, suggestion(key, paths))
+		}
+		opt := options[index]
+		if err := opt.set(&scenario, raw); err != nil {
+			return scenario, nil, fmt.Errorf("valor inválido '%s' em --set para %s: %w", raw, opt.path, err)
+		}
+		opt.record(sources, SourceFlag)
+	}
+
+	if err := scenario.Validate(); err != nil {
+		return scenario, nil, fmt.Errorf("cenário inválido:\n%w", err)
+	}
+	return scenario, describe(&scenario, options, sources), nil
+}
+
+// record registra a camada da opção e, nas seções que ela ativou (nulas até então), a mesma camada.
+func (o option) record(sources map[string]string, source string) {
+	sources[o.path] = source
+	for _, section := range o.sections {
+		if _, ok := sources[section]; !ok {
+			sources[section] = source
+		}
+	}
+}
+
+// describe lista os valores efetivos das opções. As opções de uma seção desativada (nula) são
+// omitidas, e a seção aparece uma vez, sem valor; uma seção ativada aparece antes das suas opções,
+// com a camada que a ativou.
+func describe(scenario *Scenario, options []option, sources map[string]string) []Setting {
+	var settings []Setting
+	listed := make(map[string]bool)
+	for _, opt := range options {
+		source := cmp.Or(sources[opt.path], SourceDefault)
+		field, disabled := opt.field(scenario, false)
+		for _, section := range opt.sections {
+			if !listed[section] {
+				listed[section] = true
+				settings = append(settings, Setting{Key: section, Source: cmp.Or(sources[section], SourceDefault), Disabled: section == disabled, Enabled: section != disabled})
+			}
+			if section == disabled {
+				break
+			}
+		}
+		if disabled != "" {
+			continue
+		}
+		var value string
+		if !field.IsZero() {
+			if field.Kind() == reflect.String {
+				value = field.String()
+			} else if data, err := json.Marshal(field.Interface()); err == nil {
+				value = string(data)
+			}
+		}
+		settings = append(settings, Setting{Key: opt.path, Value: value, Source: source})
+	}
+	return settings
+}
+
+// suggestion sugere o nome conhecido mais parecido com o informado, se houver um próximo.
+func suggestion(name string, known []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range slices.Sorted(slices.Values(known)) {
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (quis dizer '%s'?)", best)
+}
+
+func prefixed(names []string) []string {
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = EnvPrefix + name
+	}
+	return result
+}
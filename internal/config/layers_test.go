@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSectionSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cenario.json")
+	if err := os.WriteFile(path, []byte(`{"trendLogs": {}, "mlDataset": {"windows": {}}, "rollups": null}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HVACMOCK_GREEN_BUTTON_TITLE", "Prédio A")
+
+	_, settings, err := Resolve(path, []string{"features.location=UTC"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	byKey := make(map[string]Setting)
+	for _, setting := range settings {
+		byKey[setting.Key] = setting
+	}
+
+	file := SourceFile + " " + path
+	tests := []struct {
+		key      string
+		source   string
+		enabled  bool
+		disabled bool
+	}{
+		{key: "trendLogs", source: file, enabled: true}, // Objeto vazio: ativa a seção com os padrões
+		{key: "trendLogs.style", source: SourceDefault},
+		{key: "mlDataset", source: file, enabled: true},
+		{key: "mlDataset.windows", source: file, enabled: true},
+		{key: "greenButton", source: SourceEnv + " HVACMOCK_GREEN_BUTTON_TITLE", enabled: true},
+		{key: "greenButton.title", source: SourceEnv + " HVACMOCK_GREEN_BUTTON_TITLE"},
+		{key: "features", source: SourceFlag, enabled: true},
+		{key: "rollups", source: SourceDefault, disabled: true},
+		{key: "badges", source: SourceDefault, disabled: true},
+	}
+	for _, tt := range tests {
+		setting, ok := byKey[tt.key]
+		if !ok {
+			t.Errorf("%s não listado", tt.key)
+			continue
+		}
+		if setting.Source != tt.source || setting.Enabled != tt.enabled || setting.Disabled != tt.disabled {
+			t.Errorf("%s = %+v, esperado fonte '%s', ativada %v e desativada %v", tt.key, setting, tt.source, tt.enabled, tt.disabled)
+		}
+	}
+	if _, ok := byKey["rollups.intervalsMinutes"]; ok {
+		t.Error("opções da seção desativada listadas")
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// repoRoot é a raiz do repositório, de onde o gerador resolve os arquivos citados no cenário.
+const repoRoot = "../.."
+
+// readmeScenario extrai o cenário de exemplo do README para um arquivo temporário.
+func readmeScenario(t *testing.T) string {
+	t.Helper()
+	readme, err := os.ReadFile(filepath.Join(repoRoot, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, section, ok := strings.Cut(string(readme), "### Cenário de simulação")
+	if !ok {
+		t.Fatal("seção 'Cenário de simulação' não encontrada no README")
+	}
+	_, block, ok := strings.Cut(section, "```json\n")
+	if !ok {
+		t.Fatal("cenário de exemplo não encontrado no README")
+	}
+	block, _, _ = strings.Cut(block, "```")
+	path := filepath.Join(t.TempDir(), "cenario.json")
+	if err := os.WriteFile(path, []byte(block), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newReadmeSimulator monta o simulador do cenário como o gerador, lendo a programação da
+// automação citada nele.
+func newReadmeSimulator(t *testing.T, scenario Scenario) (*hvac.Simulator, error) {
+	t.Helper()
+	cfg := scenario.SimulatorConfig()
+	if scenario.BASSchedule != nil {
+		format, err := scenario.BASSchedule.ScheduleFormat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(filepath.Join(repoRoot, scenario.BASSchedule.File))
+		if err != nil {
+			t.Fatalf("programação da automação do cenário: %v", err)
+		}
+		defer file.Close()
+		schedules, err := hvac.ReadBASSchedules(file, format)
+		if err != nil {
+			t.Fatalf("ReadBASSchedules: %v", err)
+		}
+		if cfg.Control, err = hvac.NewBASScheduleStrategy(schedules, scenario.BASSchedule.SetbackOffset); err != nil {
+			t.Fatalf("NewBASScheduleStrategy: %v", err)
+		}
+	}
+	if scenario.FaultModel != nil {
+		var err error
+		if cfg.Faults, err = hvac.NewFaultModel(*scenario.FaultModel, nil); err != nil {
+			t.Fatalf("NewFaultModel: %v", err)
+		}
+	}
+	return hvac.NewSimulator(cfg)
+}
+
+func TestReadmeScenario(t *testing.T) {
+	scenario, err := Load(readmeScenario(t))
+	if err != nil {
+		t.Fatalf("cenário do README inválido: %v", err)
+	}
+	if scenario.Climate != nil && scenario.Climate.File != "" {
+		if _, err := os.Stat(filepath.Join(repoRoot, scenario.Climate.File)); err != nil {
+			t.Errorf("arquivo climático do cenário: %v", err)
+		}
+	}
+	if _, err := newReadmeSimulator(t, scenario); err != nil {
+		t.Errorf("NewSimulator com o cenário do README: %v", err)
+	}
+	if _, err := hvac.NewPayloadRenderer(hvac.PayloadConfig{Dialects: scenario.Dialects, Templates: scenario.Templates, Sensors: scenario.Sensors, Transforms: scenario.Transforms}, scenario.Devices); err != nil {
+		t.Errorf("NewPayloadRenderer com o cenário do README: %v", err)
+	}
+}
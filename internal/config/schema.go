@@ -0,0 +1,213 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	unmarshalerType     = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// checkSchema confere o JSON do cenário (decodificado com UseNumber) contra os tipos de Scenario antes da decodificação, para
+// que um valor do tipo errado seja apontado pelo caminho completo (ex: devices[3].capacityKw) em
+// vez da mensagem genérica do encoding/json. As chaves que não existem nos tipos voltam como
+// avisos: o encoding/json as ignora, e um erro de digitação desligaria a opção em silêncio.
+func checkSchema(document any) (warnings []string, err error) {
+	var errs []error
+	checkValue(document, reflect.TypeFor[Scenario](), "", &warnings, &errs)
+	return warnings, errors.Join(errs...)
+}
+
+// checkValue confere um valor JSON contra o tipo Go que o recebe. Os tipos com decodificação
+// própria (UnmarshalJSON ou UnmarshalText) não são percorridos.
+func checkValue(value any, t reflect.Type, path string, warnings *[]string, errs *[]error) {
+	if value == nil {
+		return
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return
+	}
+	mismatch := func(expected string) {
+		*errs = append(*errs, fmt.Errorf("%s deve ser %s, recebido %s", displayPath(path), expected, jsonKind(value)))
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		checkValue(value, t.Elem(), path, warnings, errs)
+	case reflect.Interface:
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			mismatch("objeto")
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			field, ok := lookupField(fields, key)
+			if !ok {
+				*warnings = append(*warnings, unknownKeyWarning(joinPath(path, key), key, fields))
+				continue
+			}
+			checkValue(object[key], field.Type, joinPath(path, key), warnings, errs)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			mismatch("objeto")
+			return
+		}
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			checkValue(object[key], t.Elem(), joinPath(path, key), warnings, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if _, ok := value.(string); !ok {
+				mismatch("texto em base64")
+			}
+			return
+		}
+		list, ok := value.([]any)
+		if !ok {
+			mismatch("lista")
+			return
+		}
+		for i, item := range list {
+			checkValue(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), warnings, errs)
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			mismatch("texto")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			mismatch("booleano (true ou false)")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := value.(json.Number)
+		if !ok {
+			mismatch("número inteiro")
+			return
+		}
+		if _, err := strconv.ParseInt(number.String(), 10, 64); err != nil {
+			mismatch("número inteiro")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			mismatch("número")
+		}
+	}
+}
+
+// jsonFields lista os campos do struct pelo nome no JSON, incluindo os de structs embutidos.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous && field.Tag.Get("json") == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// lookupField encontra o campo da chave como o encoding/json: pelo nome exato ou, senão, sem
+// diferenciar maiúsculas de minúsculas.
+func lookupField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if field, ok := fields[key]; ok {
+		return field, true
+	}
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// unknownKeyWarning descreve a chave desconhecida, sugerindo a chave válida mais parecida.
+func unknownKeyWarning(path, key string, fields map[string]reflect.StructField) string {
+	best, bestDistance := "", 3
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(name)); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("chave desconhecida '%s' no cenário, ignorada (quis dizer '%s'?)", path, best)
+	}
+	return fmt.Sprintf("chave desconhecida '%s' no cenário, ignorada", path)
+}
+
+// editDistance é a distância de Levenshtein entre a e b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	current := make([]int, len(rb)+1)
+	for i := range ra {
+		current[0] = i + 1
+		for j := range rb {
+			cost := 1
+			if ra[i] == rb[j] {
+				cost = 0
+			}
+			current[j+1] = min(previous[j+1]+1, current[j]+1, previous[j]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// jsonKind nomeia o tipo do valor JSON nas mensagens de erro.
+func jsonKind(value any) string {
+	switch v := value.(type) {
+	case string:
+		if utf8.RuneCountInString(v) > 20 {
+			return "texto"
+		}
+		return fmt.Sprintf("texto \"%s\"", v)
+	case bool:
+		return fmt.Sprintf("booleano %t", v)
+	case json.Number:
+		return "número " + v.String()
+	case map[string]any:
+		return "objeto"
+	case []any:
+		return "lista"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "cenário"
+	}
+	return path
+}
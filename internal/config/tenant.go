@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// tenantPattern restringe o tenant a caracteres válidos em chaves S3, nomes de streams e tópicos e
+// identificadores de dispositivos.
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ApplyTenant isola o cenário no namespace do tenant: os dispositivos e os sensores sem fio passam a
+// se chamar <tenant>-<id>, e as referências a eles no cenário (sensores, unidades internas VRF, ciclo
+// de vida, manutenção e ausência de campos) são ajustadas, para que o mesmo arquivo de cenário sirva a todos os tenants.
+// Sem dispositivos no cenário, a frota padrão é copiada para o cenário antes de ser renomeada. As
+// chaves no S3 e os destinos de streaming são prefixados pelo gerador.
+func (s *Scenario) ApplyTenant(tenant string) error {
+	if !tenantPattern.MatchString(tenant) {
+		return fmt.Errorf("tenant '%s' inválido (use até 63 letras minúsculas, dígitos ou '-', começando por letra ou dígito)", tenant)
+	}
+	s.Tenant = tenant
+	qualify := func(id string) string {
+		if id == "" {
+			return id
+		}
+		return tenant + "-" + id
+	}
+
+	if len(s.Devices) == 0 {
+		s.Devices = hvac.DefaultDevices()
+	}
+	for i := range s.Devices {
+		s.Devices[i].ID = qualify(s.Devices[i].ID)
+	}
+	for i := range s.Sensors {
+		s.Sensors[i].ID = qualify(s.Sensors[i].ID)
+		s.Sensors[i].Device = qualify(s.Sensors[i].Device)
+	}
+	if s.VRF != nil {
+		for i := range s.VRF.Systems {
+			for j, device := range s.VRF.Systems[i].IndoorUnits {
+				s.VRF.Systems[i].IndoorUnits[j] = qualify(device)
+			}
+		}
+	}
+	if s.Lifecycle != nil {
+		for i := range s.Lifecycle.Events {
+			s.Lifecycle.Events[i].DeviceId = qualify(s.Lifecycle.Events[i].DeviceId)
+			s.Lifecycle.Events[i].NewDeviceId = qualify(s.Lifecycle.Events[i].NewDeviceId)
+		}
+	}
+	if s.Maintenance != nil {
+		for i := range s.Maintenance.Orders {
+			s.Maintenance.Orders[i].DeviceId = qualify(s.Maintenance.Orders[i].DeviceId)
+		}
+	}
+	if s.Missingness != nil {
+		for i := range s.Missingness.Fields {
+			for j, device := range s.Missingness.Fields[i].Devices {
+				s.Missingness.Fields[i].Devices[j] = qualify(device)
+			}
+		}
+	}
+	return nil
+}
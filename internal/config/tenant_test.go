@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestApplyTenantReadmeScenario(t *testing.T) {
+	scenario, err := Load(readmeScenario(t))
+	if err != nil {
+		t.Fatalf("cenário do README inválido: %v", err)
+	}
+	if scenario.Tenant == "" {
+		t.Fatal("o cenário do README não define o tenant")
+	}
+	if err := scenario.ApplyTenant(scenario.Tenant); err != nil {
+		t.Fatalf("ApplyTenant: %v", err)
+	}
+	// Toda referência a um dispositivo precisa seguir o novo nome, ou o simulador a rejeita
+	simulator, err := newReadmeSimulator(t, scenario)
+	if err != nil {
+		t.Fatalf("NewSimulator com o tenant '%s': %v", scenario.Tenant, err)
+	}
+	vrfUnits := make(map[string]string)
+	for _, entry := range simulator.DeviceRegistry() {
+		vrfUnits[entry.DeviceId] = entry.VrfOutdoorUnit
+	}
+	for _, device := range []string{"SALA-1", "SALA-4"} {
+		if id := scenario.Tenant + "-" + device; vrfUnits[id] != "VRF-1" {
+			t.Errorf("%s atendido pela unidade externa '%s', esperado VRF-1", id, vrfUnits[id])
+		}
+	}
+}
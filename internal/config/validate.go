@@ -0,0 +1,269 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// failFunc registra um problema no campo indicado pelo caminho.
+type failFunc func(path, format string, args ...any)
+
+// Validate confere os valores do cenário que a simulação trocaria pelo padrão ou ignoraria sem
+// aviso (ex: uma capacidade negativa vira a capacidade padrão), e os da saída, dos sinks e das
+// exportações, que de outro modo só falhariam depois da geração. Retorna todos os problemas de uma
+// vez, cada um com o caminho do campo (ex: devices[3].capacityKw). As demais opções são validadas
+// pelos pacotes que as usam, na criação do simulador.
+func (s Scenario) Validate() error {
+	var errs []error
+	fail := func(path, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	if s.WarmUpHours < 0 {
+		fail("warmUpHours", "não pode ser negativo, recebido %g", s.WarmUpHours)
+	}
+
+	switch s.DeviceRegistry {
+	case "", "json", "csv":
+	default:
+		fail("deviceRegistry", "deve ser json ou csv, recebido '%s'", s.DeviceRegistry)
+	}
+
+	ids := make(map[string]int)
+	for i, device := range s.Devices {
+		path := fmt.Sprintf("devices[%d]", i)
+		if device.ID == "" {
+			fail(path+".id", "é obrigatório")
+		} else if first, ok := ids[device.ID]; ok {
+			fail(path+".id", "repete '%s', já usado em devices[%d]", device.ID, first)
+		} else {
+			ids[device.ID] = i
+		}
+		if device.CapacityKw < 0 {
+			fail(path+".capacityKw", "deve ser maior que 0, recebido %g", device.CapacityKw)
+		}
+		if device.SizingRatio < 0 {
+			fail(path+".sizingRatio", "deve ser maior que 0, recebido %g", device.SizingRatio)
+		}
+		switch device.SensorPlacement {
+		case "", hvac.SensorPlacementHeatSource, hvac.SensorPlacementSupplyDiffuser:
+		default:
+			fail(path+".sensorPlacement", "deve ser %s ou %s, recebido '%s'", hvac.SensorPlacementHeatSource, hvac.SensorPlacementSupplyDiffuser, device.SensorPlacement)
+		}
+		if device.PhaseOffsetSeconds < 0 {
+			fail(path+".phaseOffsetSeconds", "não pode ser negativo, recebido %g", device.PhaseOffsetSeconds)
+		}
+		if device.JitterSeconds < 0 {
+			fail(path+".jitterSeconds", "não pode ser negativo, recebido %g", device.JitterSeconds)
+		}
+		if device.Setpoint != 0 && (device.Setpoint < 10 || device.Setpoint > 35) {
+			fail(path+".setpoint", "deve estar entre 10 e 35 °C, recebido %g", device.Setpoint)
+		}
+	}
+
+	for i, sensor := range s.Sensors {
+		path := fmt.Sprintf("sensors[%d]", i)
+		if sensor.ID == "" {
+			fail(path+".id", "é obrigatório")
+		}
+		if sensor.ReportEveryHours < 0 {
+			fail(path+".reportEveryHours", "deve ser maior que 0, recebido %d", sensor.ReportEveryHours)
+		}
+		if sensor.InitialBatteryPct < 0 || sensor.InitialBatteryPct > 100 {
+			fail(path+".initialBatteryPct", "deve estar entre 0 e 100, recebido %g", sensor.InitialBatteryPct)
+		}
+		if sensor.DrainPctPerReport < 0 {
+			fail(path+".drainPctPerReport", "não pode ser negativo, recebido %g", sensor.DrainPctPerReport)
+		}
+	}
+
+	for i, zone := range s.Zones {
+		path := fmt.Sprintf("zones[%d]", i)
+		if zone.ID == "" {
+			fail(path+".id", "é obrigatório")
+		}
+		if zone.AreaM2 < 0 {
+			fail(path+".areaM2", "não pode ser negativa, recebido %g", zone.AreaM2)
+		}
+		if zone.VolumeM3 < 0 {
+			fail(path+".volumeM3", "não pode ser negativo, recebido %g", zone.VolumeM3)
+		}
+	}
+
+	if s.Timestamps != nil {
+		if s.Timestamps.RandomPhaseSeconds < 0 {
+			fail("timestamps.randomPhaseSeconds", "não pode ser negativo, recebido %g", s.Timestamps.RandomPhaseSeconds)
+		}
+		if s.Timestamps.JitterSeconds < 0 {
+			fail("timestamps.jitterSeconds", "não pode ser negativo, recebido %g", s.Timestamps.JitterSeconds)
+		}
+	}
+
+	s.validateOutput(fail)
+	s.validateSinks(fail)
+	s.validateExports(fail)
+	return errors.Join(errs...)
+}
+
+// outputFormats são os formatos do arquivo principal.
+var outputFormats = []string{"", "json", "arrow", "orc", "delta", "sqlite"}
+
+func (s Scenario) validateOutput(fail failFunc) {
+	if !slices.Contains(outputFormats, s.Output.Format) {
+		fail("output.format", "deve ser json, arrow, orc, delta ou sqlite, recebido '%s'", s.Output.Format)
+	} else if err := hvac.CheckCompression(s.Output.Format, s.Output.Compression); err != nil {
+		fail("output.compression", "%v", err)
+	}
+	if err := hvac.CheckPartition(s.Output.Format, s.Output.Partition); err != nil {
+		fail("output.partition", "%v", err)
+	}
+	if s.Output.Table != "" && !s.Output.IsTable() {
+		fail("output.table", "só se aplica ao formato delta, recebido o formato %s", outputFormat(s.Output.Format))
+	}
+}
+
+func (s Scenario) validateSinks(fail failFunc) {
+	if s.Stream != nil {
+		if s.Stream.RatePerSecond < 0 {
+			fail("stream.ratePerSecond", "não pode ser negativo, recebido %g", s.Stream.RatePerSecond)
+		}
+		if s.Stream.Batch < 0 {
+			fail("stream.batch", "não pode ser negativo, recebido %d", s.Stream.Batch)
+		}
+		if s.Stream.Redis.MaxLen < 0 {
+			fail("stream.redis.maxLen", "não pode ser negativo, recebido %d", s.Stream.Redis.MaxLen)
+		}
+		oneOf(fail, "stream.pulsar.schema", s.Stream.Pulsar.Schema, "bytes", "json", "avro")
+		oneOf(fail, "stream.amqp.exchangeType", s.Stream.AMQP.ExchangeType, "topic", "direct", "fanout", "headers")
+	}
+	if s.OpenSearch != nil {
+		oneOf(fail, "openSearch.interval", s.OpenSearch.Interval, "day", "month")
+		if s.OpenSearch.BulkSize < 0 {
+			fail("openSearch.bulkSize", "não pode ser negativo, recebido %d", s.OpenSearch.BulkSize)
+		}
+	}
+	if s.MongoDB != nil {
+		oneOf(fail, "mongodb.granularity", s.MongoDB.Granularity, "seconds", "minutes", "hours")
+	}
+}
+
+func (s Scenario) validateExports(fail failFunc) {
+	if s.Rollups != nil {
+		for i, minutes := range s.Rollups.IntervalsMinutes {
+			if minutes <= 0 {
+				fail(fmt.Sprintf("rollups.intervalsMinutes[%d]", i), "deve ser maior que 0, recebido %d", minutes)
+			}
+		}
+	}
+	if s.TrendLogs != nil {
+		oneOf(fail, "trendLogs.style", s.TrendLogs.Style, "niagara", "alc")
+		validLocation(fail, "trendLogs.location", s.TrendLogs.Location)
+	}
+	if s.MLDataset != nil {
+		s.validateMLDataset(fail)
+	}
+	if s.Features != nil {
+		for i, hours := range s.Features.WindowsHours {
+			if hours <= 0 {
+				fail(fmt.Sprintf("features.windowsHours[%d]", i), "deve ser maior que 0, recebido %d", hours)
+			}
+		}
+		for i, field := range s.Features.Fields {
+			if !hvac.IsRecordField(field) {
+				fail(fmt.Sprintf("features.fields[%d]", i), "não é um campo numérico do registro, recebido '%s'", field)
+			}
+		}
+		validLocation(fail, "features.location", s.Features.Location)
+	}
+	if s.GreenButton != nil {
+		if s.GreenButton.IntervalMinutes < 0 {
+			fail("greenButton.intervalMinutes", "deve ser maior que 0, recebido %d", s.GreenButton.IntervalMinutes)
+		}
+		validLocation(fail, "greenButton.location", s.GreenButton.Location)
+	}
+	if s.Badges != nil {
+		if s.Badges.OccupantsPerRoom != 0 && s.Badges.OccupantsPerRoom < 1 {
+			fail("badges.occupantsPerRoom", "deve ser ao menos 1, recebido %g", s.Badges.OccupantsPerRoom)
+		}
+		if s.Badges.DoorsPerZone < 0 {
+			fail("badges.doorsPerZone", "não pode ser negativo, recebido %d", s.Badges.DoorsPerZone)
+		}
+	}
+}
+
+func (s Scenario) validateMLDataset(fail failFunc) {
+	ml := s.MLDataset
+	oneOf(fail, "mlDataset.splitBy", ml.SplitBy, "time", "device")
+	if ml.Train != 0 || ml.Validation != 0 || ml.Test != 0 {
+		switch {
+		case ml.Train <= 0:
+			fail("mlDataset.train", "deve ser maior que 0, recebido %g", ml.Train)
+		case ml.Validation < 0:
+			fail("mlDataset.validation", "não pode ser negativo, recebido %g", ml.Validation)
+		case ml.Test < 0:
+			fail("mlDataset.test", "não pode ser negativo, recebido %g", ml.Test)
+		case math.Abs(ml.Train+ml.Validation+ml.Test-1) > 1e-6:
+			fail("mlDataset", "train, validation e test devem somar 1, recebido %g", ml.Train+ml.Validation+ml.Test)
+		}
+	}
+	windows := ml.Windows
+	if windows == nil {
+		return
+	}
+	if windows.Length < 0 {
+		fail("mlDataset.windows.length", "deve ser maior que 0, recebido %d", windows.Length)
+	}
+	if windows.Stride < 0 {
+		fail("mlDataset.windows.stride", "deve ser maior que 0, recebido %d", windows.Stride)
+	}
+	if windows.Horizon < 0 {
+		fail("mlDataset.windows.horizon", "não pode ser negativo, recebido %d", windows.Horizon)
+	}
+	for i, feature := range windows.Features {
+		if !hvac.IsRecordField(feature) {
+			fail(fmt.Sprintf("mlDataset.windows.features[%d]", i), "não é um campo numérico do registro, recebido '%s'", feature)
+		}
+	}
+	switch {
+	case windows.Label == "", windows.Label == "fault", windows.Label == "activeFault", hvac.IsRecordField(windows.Label):
+	default:
+		fail("mlDataset.windows.label", "deve ser fault, activeFault ou um campo numérico do registro, recebido '%s'", windows.Label)
+	}
+}
+
+// oneOf confere um campo de escolha; o valor vazio fica com o padrão.
+func oneOf(fail failFunc, path, value string, options ...string) {
+	if value != "" && !slices.Contains(options, value) {
+		fail(path, "deve ser %s, recebido '%s'", orList(options), value)
+	}
+}
+
+// validLocation confere o fuso horário IANA de um campo; o valor vazio fica com o padrão.
+func validLocation(fail failFunc, path, name string) {
+	if name == "" {
+		return
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		fail(path, "não é um fuso horário conhecido, recebido '%s'", name)
+	}
+}
+
+// orList junta as opções como "a, b ou c".
+func orList(options []string) string {
+	if len(options) == 1 {
+		return options[0]
+	}
+	return strings.Join(options[:len(options)-1], ", ") + " ou " + options[len(options)-1]
+}
+
+// outputFormat nomeia o formato do arquivo principal, com o padrão para o valor vazio.
+func outputFormat(format string) string {
+	return cmp.Or(format, "json")
+}
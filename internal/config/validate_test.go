@@ -0,0 +1,98 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+	"github.com/patrik-rangel/mock-data-hvac/internal/mongodb"
+	"github.com/patrik-rangel/mock-data-hvac/internal/opensearch"
+	"github.com/patrik-rangel/mock-data-hvac/internal/stream"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario Scenario
+		want     []string // Caminhos dos campos com problema, na ordem do relatório
+	}{
+		{name: "padrão", scenario: Scenario{}},
+		{
+			name: "válido",
+			scenario: Scenario{
+				Output:      hvac.OutputConfig{Format: "arrow", Compression: "lz4", Partition: "day"},
+				Timestamps:  &hvac.TimestampConfig{RandomPhaseSeconds: 30, JitterSeconds: 2},
+				Stream:      &stream.Config{Pulsar: stream.PulsarConfig{Schema: "avro"}, AMQP: stream.AMQPConfig{ExchangeType: "fanout"}},
+				OpenSearch:  &opensearch.Config{Interval: "month"},
+				MongoDB:     &mongodb.Config{Granularity: "hours"},
+				TrendLogs:   &hvac.TrendLogConfig{Style: "alc", Location: "America/Chicago"},
+				MLDataset:   &hvac.MLDatasetConfig{Train: 0.8, Validation: 0.1, Test: 0.1, Windows: &hvac.WindowsConfig{Features: []string{"co2LevelPpm"}, Label: "internalTemperature"}},
+				Features:    &hvac.FeatureConfig{Fields: []string{"powerConsumptionKwH"}},
+				GreenButton: &hvac.GreenButtonConfig{IntervalMinutes: 15},
+			},
+		},
+		{name: "formato desconhecido", scenario: Scenario{Output: hvac.OutputConfig{Format: "parquet"}}, want: []string{"output.format"}},
+		{name: "compressão de outro formato", scenario: Scenario{Output: hvac.OutputConfig{Format: "orc", Compression: "lz4"}}, want: []string{"output.compression"}},
+		{name: "compressão no JSON", scenario: Scenario{Output: hvac.OutputConfig{Compression: "gzip"}}, want: []string{"output.compression"}},
+		{name: "partição no JSON", scenario: Scenario{Output: hvac.OutputConfig{Partition: "day"}}, want: []string{"output.partition"}},
+		{name: "partição desconhecida", scenario: Scenario{Output: hvac.OutputConfig{Format: "orc", Partition: "hour"}}, want: []string{"output.partition"}},
+		{name: "tabela fora do Delta", scenario: Scenario{Output: hvac.OutputConfig{Format: "sqlite", Table: "delta/x"}}, want: []string{"output.table"}},
+		{
+			name:     "timestamps negativos",
+			scenario: Scenario{Timestamps: &hvac.TimestampConfig{RandomPhaseSeconds: -1, JitterSeconds: -5}},
+			want:     []string{"timestamps.randomPhaseSeconds", "timestamps.jitterSeconds"},
+		},
+		{
+			name: "sinks",
+			scenario: Scenario{
+				Stream:     &stream.Config{RatePerSecond: -1, Redis: stream.RedisConfig{MaxLen: -10}, Pulsar: stream.PulsarConfig{Schema: "protobuf"}, AMQP: stream.AMQPConfig{ExchangeType: "x-delayed"}},
+				OpenSearch: &opensearch.Config{Interval: "week", BulkSize: -1},
+				MongoDB:    &mongodb.Config{Granularity: "days"},
+			},
+			want: []string{"stream.ratePerSecond", "stream.redis.maxLen", "stream.pulsar.schema", "stream.amqp.exchangeType", "openSearch.interval", "openSearch.bulkSize", "mongodb.granularity"},
+		},
+		{
+			name: "exportações",
+			scenario: Scenario{
+				Rollups:     &hvac.RollupConfig{IntervalsMinutes: []int{15, 0}},
+				TrendLogs:   &hvac.TrendLogConfig{Style: "metasys", Location: "America/Nowhere"},
+				Features:    &hvac.FeatureConfig{WindowsHours: []int{-3}, Fields: []string{"temperatura"}},
+				GreenButton: &hvac.GreenButtonConfig{IntervalMinutes: -60},
+				Badges:      &hvac.BadgeConfig{OccupantsPerRoom: 0.5, DoorsPerZone: -1},
+			},
+			want: []string{"rollups.intervalsMinutes[1]", "trendLogs.style", "trendLogs.location", "features.windowsHours[0]", "features.fields[0]", "greenButton.intervalMinutes", "badges.occupantsPerRoom", "badges.doorsPerZone"},
+		},
+		{
+			name:     "frações de ML",
+			scenario: Scenario{MLDataset: &hvac.MLDatasetConfig{SplitBy: "zone", Train: 0.5, Validation: 0.1, Test: 0.1}},
+			want:     []string{"mlDataset.splitBy", "mlDataset"},
+		},
+		{
+			name:     "janelas de ML",
+			scenario: Scenario{MLDataset: &hvac.MLDatasetConfig{Windows: &hvac.WindowsConfig{Length: -1, Stride: -1, Horizon: -1, Features: []string{"co2LevelPpm", "co2"}, Label: "alarme"}}},
+			want:     []string{"mlDataset.windows.length", "mlDataset.windows.stride", "mlDataset.windows.horizon", "mlDataset.windows.features[1]", "mlDataset.windows.label"},
+		},
+		{
+			name: "dispositivos e saída juntos",
+			scenario: Scenario{
+				Devices: []hvac.Device{{ID: "SALA-1", JitterSeconds: -1}},
+				Output:  hvac.OutputConfig{Format: "delta", Compression: "lz4"},
+			},
+			want: []string{"devices[0].jitterSeconds", "output.compression"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := tt.scenario.Validate(); err != nil {
+				for _, line := range strings.Split(err.Error(), "\n") {
+					path, _, _ := strings.Cut(line, " ")
+					got = append(got, path)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Validate apontou %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// UpsertLatestState grava a leitura mais recente de cada dispositivo na tabela de estado atual,
+// com deviceId como chave de partição. Os itens passam pelo renderer, como nos demais destinos:
+// formato do dispositivo, transformações, anonimização e ausência de campos. Cada item substitui o
+// anterior do mesmo dispositivo, a não ser que a tabela já tenha uma leitura mais nova (ex: de uma
+// execução sobre um período posterior).
+func UpsertLatestState(cfg aws.Config, tableName string, renderer *hvac.PayloadRenderer, readings []hvac.HvacSensorData) error {
+	log.Printf("Atualizando o estado atual de %d dispositivos na tabela DynamoDB '%s'...", len(readings), tableName)
+	client := dynamodb.NewFromConfig(cfg)
+
+	skipped := 0
+	for _, reading := range readings {
+		document, err := renderer.Document(reading)
+		if err != nil {
+			return fmt.Errorf("falha ao renderizar o estado de '%s': %w", reading.DeviceId, err)
+		}
+		// O dialeto do dispositivo pode renomear o campo: a chave de partição é sempre deviceId,
+		// com o valor já anonimizado quando o pipeline o transforma
+		if _, ok := document["deviceId"].(string); !ok {
+			document["deviceId"] = reading.DeviceId
+		}
+		item, err := attributevalue.MarshalMap(document)
+		if err != nil {
+			return fmt.Errorf("falha ao converter a leitura de '%s' para item DynamoDB: %w", reading.DeviceId, err)
+		}
+		updatedAt := strconv.FormatInt(reading.Timestamp.UnixMilli(), 10)
+		item["updatedAtEpochMs"] = &types.AttributeValueMemberN{Value: updatedAt}
+
+		_, err = client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+			TableName:                 aws.String(tableName),
+			Item:                      item,
+			ConditionExpression:       aws.String("attribute_not_exists(deviceId) OR updatedAtEpochMs <= :updatedAt"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{":updatedAt": &types.AttributeValueMemberN{Value: updatedAt}},
+		})
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("falha ao gravar o estado de '%s' no DynamoDB: %w", reading.DeviceId, err)
+		}
+	}
+
+	if skipped > 0 {
+		log.Printf("%d dispositivos mantidos: a tabela já tinha leituras mais recentes.", skipped)
+	}
+	log.Printf("Estado atual de %d dispositivos gravado no DynamoDB com sucesso!", len(readings)-skipped)
+	return nil
+}
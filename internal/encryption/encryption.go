@@ -0,0 +1,264 @@
+// Package encryption cifra os arquivos de saída do gerador com AES-256-GCM e uma chave informada,
+// para ambientes em que até o layout dos dados simulados é sensível. O conteúdo é cifrado em blocos
+// de 64 KiB, cada um autenticado separadamente, para que arquivos grandes sejam cifrados e
+// decifrados em fluxo, sem carregá-los em memória:
+//
+//	cabeçalho: "HVACGCM1" | tamanho do bloco (uint32, big-endian) | prefixo do nonce (7 bytes aleatórios)
+//	blocos:    AES-GCM(bloco), com nonce = prefixo | número do bloco (uint32) | 1 no último bloco, 0 nos demais
+//
+// O cabeçalho entra como dado autenticado de todos os blocos, e a marcação do último bloco faz um
+// arquivo truncado falhar na leitura em vez de parecer completo.
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Algorithm identifica o esquema nos metadados dos objetos cifrados.
+const Algorithm = "AES-256-GCM"
+
+// KeySize é o tamanho da chave em bytes (AES-256).
+const KeySize = 32
+
+const (
+	magic       = "HVACGCM1"
+	prefixSize  = 7
+	headerSize  = len(magic) + 4 + prefixSize
+	chunkSize   = 64 * 1024
+	maxChunk    = 16 * 1024 * 1024
+	maxChunkNum = 1<<32 - 1
+)
+
+// ParseKey interpreta a chave de 32 bytes codificada em base64 (padrão ou URL) ou em hexadecimal,
+// como as geradas por `openssl rand -base64 32` ou `openssl rand -hex 32`.
+func ParseKey(raw string) ([]byte, error) {
+	raw = strings.TrimSpace(raw)
+	for _, decode := range []func(string) ([]byte, error){
+		hex.DecodeString,
+		base64.StdEncoding.DecodeString,
+		base64.URLEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		base64.RawURLEncoding.DecodeString,
+	} {
+		if key, err := decode(raw); err == nil && len(key) == KeySize {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("chave de cifragem inválida: esperados %d bytes em base64 ou hexadecimal (ex: openssl rand -base64 32)", KeySize)
+}
+
+// PrefixSize é o número de bytes iniciais que IsEncrypted precisa para reconhecer um arquivo cifrado.
+const PrefixSize = len(magic)
+
+// IsEncrypted indica se o conteúdo começa com o cabeçalho dos arquivos cifrados.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// Seal cifra o conteúdo inteiro.
+func Seal(key, plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(headerSize + len(plaintext) + (len(plaintext)/chunkSize+1)*16)
+	writer, err := NewWriter(&buf, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Open decifra o conteúdo inteiro cifrado por Seal ou NewWriter.
+func Open(key, data []byte) ([]byte, error) {
+	reader, err := NewReader(bytes.NewReader(data), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("chave de cifragem com %d bytes, esperados %d", len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao preparar a cifra AES: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce monta o nonce do bloco: prefixo aleatório, número do bloco e marcação do último.
+func nonce(prefix []byte, counter uint32, last bool) []byte {
+	n := make([]byte, 12)
+	copy(n, prefix)
+	binary.BigEndian.PutUint32(n[prefixSize:], counter)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
+
+// Writer cifra em blocos o que recebe. Close grava o último bloco e precisa ser chamado.
+type Writer struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	buf     []byte
+	counter uint32
+	closed  bool
+}
+
+// NewWriter grava o cabeçalho em w e retorna o Writer que cifra o conteúdo.
+func NewWriter(w io.Writer, key []byte) (*Writer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	binary.BigEndian.PutUint32(header[len(magic):], chunkSize)
+	if _, err := rand.Read(header[len(magic)+4:]); err != nil {
+		return nil, fmt.Errorf("erro ao sortear o nonce da cifragem: %w", err)
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("erro ao gravar o cabeçalho do arquivo cifrado: %w", err)
+	}
+	return &Writer{w: w, aead: aead, header: header, prefix: header[len(magic)+4:], buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("escrita em arquivo cifrado já fechado")
+	}
+	written := 0
+	for len(p) > 0 {
+		// O bloco cheio só é cifrado quando chega mais conteúdo, para que o último bloco seja
+		// sempre o gravado por Close, com a marcação de fim
+		if len(w.buf) == chunkSize {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *Writer) flush(last bool) error {
+	if w.counter == maxChunkNum {
+		return errors.New("arquivo grande demais para a cifragem em blocos")
+	}
+	sealed := w.aead.Seal(nil, nonce(w.prefix, w.counter, last), w.buf, w.header)
+	w.counter++
+	w.buf = w.buf[:0]
+	if _, err := w.w.Write(sealed); err != nil {
+		return fmt.Errorf("erro ao gravar o arquivo cifrado: %w", err)
+	}
+	return nil
+}
+
+// Close cifra o último bloco. Não fecha o io.Writer de destino.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+// Reader decifra e autentica os blocos de um arquivo cifrado.
+type Reader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	chunk   []byte
+	plain   []byte
+	counter uint32
+	done    bool
+}
+
+// NewReader lê o cabeçalho de r e retorna o Reader do conteúdo decifrado.
+func NewReader(r io.Reader, key []byte) (*Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil || !IsEncrypted(header) {
+		return nil, errors.New("o conteúdo não é um arquivo cifrado pelo gerador")
+	}
+	size := binary.BigEndian.Uint32(header[len(magic):])
+	if size == 0 || size > maxChunk {
+		return nil, fmt.Errorf("tamanho de bloco %d inválido no cabeçalho do arquivo cifrado", size)
+	}
+	return &Reader{
+		r:      bufio.NewReaderSize(r, int(size)+aead.Overhead()),
+		aead:   aead,
+		header: header,
+		prefix: header[len(magic)+4:],
+		chunk:  make([]byte, int(size)+aead.Overhead()),
+	}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next decifra o próximo bloco. Um bloco menor que o tamanho cheio só pode ser o último; um bloco
+// cheio é o último se o arquivo acaba logo depois dele.
+func (r *Reader) next() error {
+	n, err := io.ReadFull(r.r, r.chunk)
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+	case err != nil:
+		return fmt.Errorf("erro ao ler o arquivo cifrado: %w", err)
+	}
+	last := n < len(r.chunk)
+	if !last {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			last = true
+		}
+	}
+	plain, err := r.aead.Open(r.chunk[:0], nonce(r.prefix, r.counter, last), r.chunk[:n], r.header)
+	if err != nil {
+		if last {
+			return errors.New("arquivo cifrado corrompido, truncado ou cifrado com outra chave")
+		}
+		return fmt.Errorf("bloco %d do arquivo cifrado corrompido ou cifrado com outra chave", r.counter)
+	}
+	r.counter++
+	r.plain = plain
+	r.done = last
+	return nil
+}
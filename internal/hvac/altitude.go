@@ -0,0 +1,35 @@
+package hvac
+
+import (
+	"fmt"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+const (
+	fanPowerKw      = 0.35   // Potência do ventilador com ar na densidade do nível do mar (kW)
+	minSiteAltitude = -500.0 // Faixa de altitude aceita para o site (m)
+	maxSiteAltitude = 6000.0
+)
+
+// setAltitude calcula a pressão atmosférica do site, usada nas propriedades psicrométricas, e a
+// densidade do ar relativa à do nível do mar. Pelas leis dos ventiladores, com a mesma vazão
+// volumétrica, a pressão estática nos dutos e a potência do ventilador são proporcionais à
+// densidade: um site a 1600 m, como Campos do Jordão, tem cerca de 18% menos de ambas.
+func (s *Simulator) setAltitude(altitude float64) error {
+	if altitude < minSiteAltitude || altitude > maxSiteAltitude {
+		return fmt.Errorf("altitude do site deve estar entre %.0f e %.0f m, recebido %.1f m", minSiteAltitude, maxSiteAltitude, altitude)
+	}
+	s.pressure = climate.PressureAtAltitude(altitude)
+	s.airDensity = s.pressure / climate.StandardPressureKPa
+	return nil
+}
+
+// fanPowerCorrection é a variação da potência do ventilador pela densidade do ar (kW), somada ao
+// consumo dos modos com o ventilador ligado.
+func (s *Simulator) fanPowerCorrection(systemStatus string) float64 {
+	if !fanRunning(systemStatus) {
+		return 0
+	}
+	return fanPowerKw * (s.airDensity - 1.0)
+}
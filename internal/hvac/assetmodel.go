@@ -0,0 +1,172 @@
+package hvac
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// Curve é uma curva de desempenho por pontos [x, y], com x crescente, interpolada linearmente e
+// mantida constante fora da faixa informada.
+type Curve [][2]float64
+
+// At interpola a curva em x.
+func (c Curve) At(x float64) float64 {
+	if x <= c[0][0] {
+		return c[0][1]
+	}
+	for i := 1; i < len(c); i++ {
+		if x <= c[i][0] {
+			x0, y0, x1, y1 := c[i-1][0], c[i-1][1], c[i][0], c[i][1]
+			return y0 + (y1-y0)*(x-x0)/(x1-x0)
+		}
+	}
+	return c[len(c)-1][1]
+}
+
+func (c Curve) validate(name string) error {
+	for i, point := range c {
+		if point[1] <= 0 {
+			return fmt.Errorf("curva %s com valor não positivo no ponto %d", name, i+1)
+		}
+		if i > 0 && point[0] <= c[i-1][0] {
+			return fmt.Errorf("curva %s com x fora de ordem crescente no ponto %d", name, i+1)
+		}
+	}
+	return nil
+}
+
+// AssetModelSpec descreve a eficiência de um modelo de equipamento. Com as curvas, o consumo do
+// compressor passa a ser a carga térmica entregue dividida pelo COP na temperatura externa e na
+// carga parcial do passo, em vez das potências base fixas do modelo simplificado. Com heating
+// "gas" o aquecimento vem de um queimador (fornalha ou RTU a gás): o consumo elétrico é só o do
+// ventilador e o combustível sai em gasConsumptionM3 ou gasConsumptionTherms. Com stages, o
+// equipamento tem vários compressores (ou estágios) iguais, ligados em degraus.
+type AssetModelSpec struct {
+	Name                 string  `json:"name"`                 // Modelo, como em devices[].assetModel
+	CoolingCOP           Curve   `json:"coolingCop"`           // COP de resfriamento por temperatura externa (°C)
+	HeatingCOP           Curve   `json:"heatingCop"`           // COP de aquecimento (bomba de calor) por temperatura externa (°C)
+	PartLoad             Curve   `json:"partLoad"`             // Multiplicador do COP por fração de carga (PLR de 0 a 1), no estilo IPLV (padrão: 1)
+	FanKw                float64 `json:"fanKw"`                // Potência do ventilador somada ao compressor (kW, padrão: 0.35)
+	Heating              string  `json:"heating"`              // Fonte de aquecimento: heatPump (padrão) ou gas
+	FurnaceEfficiency    float64 `json:"furnaceEfficiency"`    // Eficiência do queimador (AFUE de 0 a 1, padrão: 0.8)
+	GasUnit              string  `json:"gasUnit"`              // Unidade do consumo de gás: m3 (padrão) ou therm
+	Stages               int     `json:"stages"`               // Compressores ou estágios de capacidade iguais (padrão: 1)
+	LeadLagRotationHours float64 `json:"leadLagRotationHours"` // Horas de operação entre trocas do compressor líder (0: líder fixo)
+}
+
+const (
+	gasKwhPerM3  = 10.55   // Poder calorífico superior do gás natural (kWh/m³)
+	gasKwhPerThm = 29.3071 // kWh por therm (100.000 BTU)
+)
+
+func (m AssetModelSpec) withDefaults() AssetModelSpec {
+	if m.FanKw == 0 {
+		m.FanKw = 0.35
+	}
+	if m.Heating == "" {
+		m.Heating = "heatPump"
+	}
+	if m.FurnaceEfficiency == 0 {
+		m.FurnaceEfficiency = 0.8
+	}
+	if m.GasUnit == "" {
+		m.GasUnit = "m3"
+	}
+	if m.Stages == 0 {
+		m.Stages = 1
+	}
+	return m
+}
+
+func (m AssetModelSpec) validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("modelo de equipamento sem name")
+	}
+	switch m.Heating {
+	case "", "heatPump":
+		if len(m.CoolingCOP) == 0 && len(m.HeatingCOP) == 0 && m.Stages <= 1 {
+			return fmt.Errorf("modelo de equipamento '%s' sem coolingCop, heatingCop nem stages", m.Name)
+		}
+	case "gas":
+		if len(m.HeatingCOP) > 0 {
+			return fmt.Errorf("modelo de equipamento '%s' com aquecimento a gás não usa heatingCop", m.Name)
+		}
+	default:
+		return fmt.Errorf("modelo de equipamento '%s' com heating desconhecido '%s': use heatPump ou gas", m.Name, m.Heating)
+	}
+	if m.Stages < 0 || m.Stages > maxCompressorStages {
+		return fmt.Errorf("modelo de equipamento '%s' com stages fora de 1 a %d", m.Name, maxCompressorStages)
+	}
+	if m.LeadLagRotationHours < 0 {
+		return fmt.Errorf("modelo de equipamento '%s' com leadLagRotationHours negativo", m.Name)
+	}
+	if m.FurnaceEfficiency < 0 || m.FurnaceEfficiency > 1 {
+		return fmt.Errorf("modelo de equipamento '%s' com furnaceEfficiency fora de 0 a 1", m.Name)
+	}
+	if m.GasUnit != "" && m.GasUnit != "m3" && m.GasUnit != "therm" {
+		return fmt.Errorf("modelo de equipamento '%s' com gasUnit desconhecida '%s': use m3 ou therm", m.Name, m.GasUnit)
+	}
+	for name, curve := range map[string]Curve{"coolingCop": m.CoolingCOP, "heatingCop": m.HeatingCOP, "partLoad": m.PartLoad} {
+		if err := curve.validate(name); err != nil {
+			return fmt.Errorf("modelo de equipamento '%s': %w", m.Name, err)
+		}
+	}
+	if m.FanKw < 0 {
+		return fmt.Errorf("modelo de equipamento '%s' com fanKw negativo", m.Name)
+	}
+	return nil
+}
+
+// curvePower calcula o consumo do compressor pelas curvas do modelo do equipamento. Retorna false
+// quando o modelo não tem curva para o modo, e o consumo segue o modelo simplificado.
+func (s *Simulator) curvePower(device *deviceState, climateData climate.InmetClimateData, internalTemp float64, systemStatus string) (float64, bool) {
+	spec, ok := s.assetModels[device.AssetModel]
+	if !ok {
+		return 0, false
+	}
+	cooling := systemStatus == "COOLING" || systemStatus == "PRE_COOLING"
+	if !cooling && spec.Heating == "gas" {
+		// Queimador: da rede elétrica só sai o ventilador
+		return spec.FanKw * periodHours(device, climateData.Timestamp), true
+	}
+	cop := spec.CoolingCOP
+	capacity := device.CapacityKw
+	if !cooling {
+		cop = spec.HeatingCOP
+		capacity *= heatingCapacityRatio
+	}
+	if len(cop) == 0 {
+		return 0, false
+	}
+
+	plr := 1.0
+	if !device.recovering && !device.saturated {
+		plr = device.partLoadRatio(climateData.TemperatureAir, internalTemp, device.occupied, cooling)
+	}
+	efficiency := cop.At(climateData.TemperatureAir)
+	if len(spec.PartLoad) > 0 {
+		efficiency *= spec.PartLoad.At(plr)
+	}
+	hours := periodHours(device, climateData.Timestamp)
+	return (plr*capacity/math.Max(efficiency, 0.1) + spec.FanKw) * hours, true
+}
+
+// gasFired indica se o equipamento aquece com queimador a gás.
+func (s *Simulator) gasFired(device *deviceState) bool {
+	spec, ok := s.assetModels[device.AssetModel]
+	return ok && spec.Heating == "gas"
+}
+
+// gasConsumption converte o calor entregue pelo queimador no passo (kWh térmicos) em consumo de
+// gás na unidade do modelo, descontada a eficiência do queimador e somadas as perdas do passo.
+func (s *Simulator) gasConsumption(device *deviceState, deliveredKwh, wasteKwh float64, data *HvacSensorData) {
+	spec := s.assetModels[device.AssetModel]
+	fuelKwh := (deliveredKwh + wasteKwh) / spec.FurnaceEfficiency
+	if spec.GasUnit == "therm" {
+		data.GasConsumptionTherms = fuelKwh / gasKwhPerThm
+	} else {
+		data.GasConsumptionM3 = fuelKwh / gasKwhPerM3
+	}
+}
@@ -0,0 +1,95 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type avroField struct {
+	Name    string `json:"name"`
+	Type    any    `json:"type"`
+	Default any    `json:"default,omitempty"`
+}
+
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Fields    []avroField `json:"fields"`
+}
+
+func avroType(kind columnKind) any {
+	switch kind {
+	case kindFloat:
+		return "double"
+	case kindNullableFloat:
+		return []any{"null", "double"}
+	case kindInt:
+		return "long"
+	case kindNullableInt:
+		return []any{"null", "long"}
+	case kindBool:
+		return "boolean"
+	case kindNullableString:
+		return []any{"null", "string"}
+	case kindTime:
+		return map[string]string{"type": "long", "logicalType": "timestamp-millis"}
+	case kindStringList:
+		return map[string]any{"type": "array", "items": "string"}
+	}
+	return "string"
+}
+
+// FlatRecords achata os registros no esquema colunar dos formatos Arrow/ORC/Delta, para sinks com
+// esquema registrado (ex: Pulsar JSON/Avro), e retorna a definição Avro desse esquema. Campos
+// ausentes ficam nil; o timestamp fica como time.Time.
+func FlatRecords(data []HvacSensorData) (string, []map[string]any, error) {
+	columns := tabularColumns(data)
+	record := avroRecord{Type: "record", Name: "HvacSensorData", Namespace: "hvac", Fields: make([]avroField, len(columns))}
+	for i, c := range columns {
+		record.Fields[i] = avroField{Name: c.name, Type: avroType(c.kind)}
+	}
+	schema, err := json.Marshal(record)
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao serializar o esquema Avro: %w", err)
+	}
+
+	records := make([]map[string]any, len(data))
+	for i := range data {
+		row := make(map[string]any, len(columns))
+		for _, c := range columns {
+			row[c.name] = flatValue(c.kind, c.value(&data[i]))
+		}
+		records[i] = row
+	}
+	return string(schema), records, nil
+}
+
+// flatValue desreferencia os ponteiros das colunas anuláveis e garante listas não nulas.
+func flatValue(kind columnKind, value any) any {
+	switch kind {
+	case kindNullableFloat:
+		if v := value.(*float64); v != nil {
+			return *v
+		}
+		return nil
+	case kindNullableInt:
+		if v := value.(*int64); v != nil {
+			return *v
+		}
+		return nil
+	case kindNullableString:
+		if v := value.(*string); v != nil {
+			return *v
+		}
+		return nil
+	case kindTime:
+		return value.(time.Time).UTC().Truncate(time.Millisecond)
+	case kindStringList:
+		if value.([]string) == nil {
+			return []string{}
+		}
+	}
+	return value
+}
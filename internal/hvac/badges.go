@@ -0,0 +1,184 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Sentidos das passagens pelos leitores de crachá.
+const (
+	BadgeEntry = "ENTRY"
+	BadgeExit  = "EXIT"
+)
+
+// BadgeConfig ativa o fluxo sintético de passagens de crachá por zona, coerente com a ocupação das
+// leituras, para testar a fusão de dados de ocupação com a telemetria HVAC.
+type BadgeConfig struct {
+	OccupantsPerRoom float64 `json:"occupantsPerRoom"` // Média de pessoas numa sala ocupada (padrão: 4)
+	DoorsPerZone     int     `json:"doorsPerZone"`     // Leitores de crachá por zona (padrão: 1)
+}
+
+func (c BadgeConfig) withDefaults() BadgeConfig {
+	if c.OccupantsPerRoom == 0 {
+		c.OccupantsPerRoom = 4
+	}
+	if c.DoorsPerZone == 0 {
+		c.DoorsPerZone = 1
+	}
+	return c
+}
+
+// BadgeEvent é uma passagem de crachá na porta de uma zona. Os crachás são sintéticos (SYN-...),
+// sem relação com pessoas reais: cada zona tem o seu conjunto, e o mesmo crachá volta em dias
+// diferentes, como um ocupante habitual.
+type BadgeEvent struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Zone           string    `json:"zone"`
+	ReaderId       string    `json:"readerId"`       // Leitor da porta (<zona>-DOOR-<n>)
+	BadgeId        string    `json:"badgeId"`        // Crachá sintético
+	Direction      string    `json:"direction"`      // ENTRY ou EXIT
+	OccupancyCount int       `json:"occupancyCount"` // Pessoas na zona depois da passagem
+}
+
+// badgeZone acompanha os crachás dentro e fora de uma zona.
+type badgeZone struct {
+	index   int
+	inside  []string
+	outside []string
+	issued  int
+	count   int
+}
+
+// pendingBadge é uma passagem sorteada, antes de receber o crachá e a contagem.
+type pendingBadge struct {
+	timestamp time.Time
+	zone      string
+	direction string
+	door      int
+}
+
+// BuildBadgeEvents gera as passagens de crachá a partir das leituras. Cada sala ocupada tem um
+// número de pessoas sorteado em torno de OccupantsPerRoom quando passa a ser ocupada, que varia
+// em uma pessoa de vez em quando enquanto segue ocupada, e zera quando fica desocupada; cada
+// mudança vira entradas ou saídas em instantes sorteados entre a leitura anterior da sala e a
+// atual. A contagem de cada zona é a soma das suas salas, então uma zona com sala ocupada nunca
+// está vazia na leitura. O sorteio usa a semente informada, e o resultado é ordenado pelo instante.
+func BuildBadgeEvents(cfg BadgeConfig, data []HvacSensorData, seed int64) ([]BadgeEvent, error) {
+	cfg = cfg.withDefaults()
+	if cfg.OccupantsPerRoom < 1 {
+		return nil, fmt.Errorf("média de pessoas por sala deve ser ao menos 1, recebido %.2f", cfg.OccupantsPerRoom)
+	}
+	if cfg.DoorsPerZone < 0 {
+		return nil, fmt.Errorf("número de leitores por zona não pode ser negativo, recebido %d", cfg.DoorsPerZone)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	headcount := make(map[string]int)
+	previous := make(map[string]time.Time)
+	var pending []pendingBadge
+	for _, record := range data {
+		before := headcount[record.DeviceId]
+		after := 0
+		if record.OccupancyStatus {
+			after = before
+			if before == 0 {
+				after = 1 + poisson(rng, cfg.OccupantsPerRoom-1)
+			} else if rng.Float64() < 0.3 {
+				after = max(1, before+rng.Intn(3)-1)
+			}
+		}
+		headcount[record.DeviceId] = after
+
+		window := time.Hour
+		if last, ok := previous[record.DeviceId]; ok && record.Timestamp.After(last) {
+			window = record.Timestamp.Sub(last)
+		}
+		previous[record.DeviceId] = record.Timestamp
+
+		direction, n := BadgeEntry, after-before
+		if n < 0 {
+			direction, n = BadgeExit, -n
+		}
+		for range n {
+			pending = append(pending, pendingBadge{
+				timestamp: record.Timestamp.Add(-time.Duration(rng.Int63n(int64(window)))).Truncate(time.Second),
+				zone:      record.LocationZone,
+				direction: direction,
+				door:      1 + rng.Intn(cfg.DoorsPerZone),
+			})
+		}
+	}
+	// Entradas antes das saídas no mesmo segundo, para a contagem nunca ficar negativa
+	sort.SliceStable(pending, func(i, j int) bool {
+		if !pending[i].timestamp.Equal(pending[j].timestamp) {
+			return pending[i].timestamp.Before(pending[j].timestamp)
+		}
+		return pending[i].direction == BadgeEntry && pending[j].direction == BadgeExit
+	})
+
+	zones := make(map[string]*badgeZone)
+	events := make([]BadgeEvent, 0, len(pending))
+	for _, p := range pending {
+		zone := zones[p.zone]
+		if zone == nil {
+			zone = &badgeZone{index: len(zones) + 1}
+			zones[p.zone] = zone
+		}
+		var badge string
+		if p.direction == BadgeEntry {
+			if len(zone.outside) > 0 {
+				i := rng.Intn(len(zone.outside))
+				badge = zone.outside[i]
+				zone.outside = append(zone.outside[:i], zone.outside[i+1:]...)
+			} else {
+				zone.issued++
+				badge = fmt.Sprintf("SYN-Z%02d-%04d", zone.index, zone.issued)
+			}
+			zone.inside = append(zone.inside, badge)
+			zone.count++
+		} else {
+			i := rng.Intn(len(zone.inside))
+			badge = zone.inside[i]
+			zone.inside = append(zone.inside[:i], zone.inside[i+1:]...)
+			zone.outside = append(zone.outside, badge)
+			zone.count--
+		}
+		events = append(events, BadgeEvent{
+			Timestamp:      p.timestamp,
+			Zone:           p.zone,
+			ReaderId:       fmt.Sprintf("%s-DOOR-%d", p.zone, p.door),
+			BadgeId:        badge,
+			Direction:      p.direction,
+			OccupancyCount: zone.count,
+		})
+	}
+	return events, nil
+}
+
+// poisson sorteia uma contagem de Poisson com a média informada (algoritmo de Knuth, adequado às
+// médias pequenas de pessoas por sala).
+func poisson(rng *rand.Rand, mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	limit := math.Exp(-mean)
+	n, product := 0, rng.Float64()
+	for product > limit {
+		n++
+		product *= rng.Float64()
+	}
+	return n
+}
+
+// WriteBadgeEventsJSON serializa as passagens de crachá.
+func WriteBadgeEventsJSON(events []BadgeEvent) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar as passagens de crachá para JSON: %w", err)
+	}
+	return jsonData, nil
+}
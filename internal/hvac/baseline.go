@@ -0,0 +1,83 @@
+package hvac
+
+import (
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// ExpectedRange é o valor previsto pelo modelo físico e a faixa considerada normal.
+type ExpectedRange struct {
+	Expected float64 `json:"expected"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+}
+
+// ExpectedValues é a linha de base para FDD: o que o próprio simulador espera medir em um
+// equipamento saudável, permitindo comparar medido vs. modelado (resíduos).
+type ExpectedValues struct {
+	SupplyAirTemperature   ExpectedRange `json:"supplyAirTemperature"`   // Temperatura de insuflamento esperada (°C)
+	PowerConsumptionKwH    ExpectedRange `json:"powerConsumptionKwH"`    // Consumo esperado (kWh)
+	RefrigerantPressurePsi ExpectedRange `json:"refrigerantPressurePsi"` // Pressão de refrigerante esperada (psi)
+	DuctStaticPressurePa   ExpectedRange `json:"ductStaticPressurePa"`   // Pressão estática esperada com filtro limpo (Pa)
+}
+
+// powerTolerance cobre o ruído de medição de ±5% aplicado ao consumo.
+const powerTolerance = 0.05
+
+func (s *Simulator) expectedValues(device *deviceState, climateData climate.InmetClimateData, systemStatus string, setPoint, internalTemp float64) *ExpectedValues {
+	if !s.fddBaseline {
+		return nil
+	}
+
+	power := s.modeledPower(device, climateData, systemStatus, setPoint, internalTemp)
+	expected := &ExpectedValues{
+		PowerConsumptionKwH:    ExpectedRange{Expected: power, Min: power * (1 - powerTolerance), Max: power * (1 + powerTolerance)},
+		SupplyAirTemperature:   ExpectedRange{Expected: internalTemp, Min: internalTemp - 1.5, Max: internalTemp + 1.5},
+		RefrigerantPressurePsi: ExpectedRange{Expected: 82.5, Min: 80.0, Max: 85.0},
+		DuctStaticPressurePa:   ExpectedRange{Expected: 11.0, Min: 10.0, Max: 12.0},
+	}
+
+	switch systemStatus {
+	case "COOLING", "PRE_COOLING":
+		expected.SupplyAirTemperature = ExpectedRange{Expected: internalTemp - 10.0, Min: internalTemp - 12.0, Max: internalTemp - 8.0}
+		if systemStatus == "COOLING" {
+			pressure := 160.0 + climateData.Stress*40.0
+			expected.RefrigerantPressurePsi = ExpectedRange{Expected: pressure, Min: pressure - 10.0, Max: pressure + 10.0}
+		} else {
+			expected.RefrigerantPressurePsi = ExpectedRange{Expected: 142.5, Min: 135.0, Max: 150.0}
+		}
+	case "HEATING":
+		if s.gasFired(device) {
+			// Queimador: insuflamento mais quente e refrigerante equalizado
+			expected.SupplyAirTemperature = ExpectedRange{Expected: internalTemp + 20.0, Min: internalTemp + 15.0, Max: internalTemp + 25.0}
+			break
+		}
+		expected.SupplyAirTemperature = ExpectedRange{Expected: internalTemp + 6.5, Min: internalTemp + 5.0, Max: internalTemp + 8.0}
+		expected.RefrigerantPressurePsi = ExpectedRange{Expected: 102.5, Min: 100.0, Max: 105.0}
+	case "NIGHT_PURGE":
+		outdoor := climateData.TemperatureAir
+		expected.SupplyAirTemperature = ExpectedRange{Expected: outdoor + 1.25, Min: outdoor + 1.0, Max: outdoor + 1.5}
+	}
+
+	if ahu := device.airHandler; ahu != nil {
+		if systemStatus == "COOLING" {
+			sat := ahu.supplyAirTempSetpoint
+			expected.SupplyAirTemperature = ExpectedRange{Expected: sat, Min: sat - 0.3, Max: sat + 0.3}
+		}
+		sp := ahu.staticPressureSetpoint
+		expected.DuctStaticPressurePa = ExpectedRange{Expected: sp, Min: sp - 0.3, Max: sp + 0.3}
+	}
+	return expected
+}
+
+// shiftDefrost ajusta os valores esperados de um passo com degelo: insuflamento mais frio e a
+// resistência auxiliar no consumo.
+func (e *ExpectedValues) shiftDefrost(internalTemp, auxHeatKwh float64) {
+	dip := func(v float64) float64 { return v - (v-internalTemp)*defrostSupplyDip }
+	e.SupplyAirTemperature = ExpectedRange{Expected: dip(e.SupplyAirTemperature.Expected), Min: dip(e.SupplyAirTemperature.Min), Max: dip(e.SupplyAirTemperature.Max)}
+	e.PowerConsumptionKwH.Expected += auxHeatKwh
+	e.PowerConsumptionKwH.Min += auxHeatKwh
+	e.PowerConsumptionKwH.Max += auxHeatKwh
+	e.RefrigerantPressurePsi.Expected += defrostPressureRise
+	e.RefrigerantPressurePsi.Min += defrostPressureRise
+	e.RefrigerantPressurePsi.Max += defrostPressureRise
+}
@@ -0,0 +1,93 @@
+package hvac
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BatchConfig agrupa as leituras em lotes por gateway: cada mensagem enviada contém todas as
+// leituras recebidas pelo gateway na janela.
+type BatchConfig struct {
+	IntervalMinutes int `json:"intervalMinutes"` // Janela de acumulação do gateway (min, padrão: 60)
+	MaxReadings     int `json:"maxReadings"`     // Máximo de leituras por mensagem; lotes maiores são divididos (0 = sem limite)
+}
+
+// Batch é uma mensagem do gateway com várias leituras de dispositivos.
+type Batch struct {
+	GatewayId    string    `json:"gatewayId"`    // Gateway que acumulou as leituras
+	BatchId      string    `json:"batchId"`      // Identificador da mensagem (gateway, janela e parte)
+	WindowStart  time.Time `json:"windowStart"`  // Início da janela de acumulação
+	SentAt       time.Time `json:"sentAt"`       // Envio da mensagem, ao fim da janela
+	ReadingCount int       `json:"readingCount"` // Quantidade de leituras na mensagem
+	Readings     []any     `json:"readings"`     // Leituras no formato de cada dispositivo
+}
+
+func (c BatchConfig) withDefaults() BatchConfig {
+	if c.IntervalMinutes == 0 {
+		c.IntervalMinutes = 60
+	}
+	return c
+}
+
+// batchItem é uma leitura já renderizada, com os dados necessários para agrupá-la.
+type batchItem struct {
+	gatewayId string
+	timestamp time.Time
+	payload   any
+}
+
+// gatewayFor retorna o gateway que atende a zona (um por zona).
+func gatewayFor(zone string) string {
+	if zone == "" {
+		zone = defaultZone
+	}
+	return "GW-" + zone
+}
+
+// buildBatches agrupa as leituras por gateway e janela, preservando a ordem de chegada em cada lote.
+func buildBatches(cfg BatchConfig, items []batchItem) []Batch {
+	interval := time.Duration(cfg.IntervalMinutes) * time.Minute
+
+	type key struct {
+		gatewayId string
+		window    time.Time
+	}
+	groups := make(map[key][]any)
+	var keys []key
+	for _, item := range items {
+		k := key{item.gatewayId, item.timestamp.Truncate(interval)}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], item.payload)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if !keys[i].window.Equal(keys[j].window) {
+			return keys[i].window.Before(keys[j].window)
+		}
+		return keys[i].gatewayId < keys[j].gatewayId
+	})
+
+	var batches []Batch
+	for _, k := range keys {
+		readings := groups[k]
+		size := len(readings)
+		if cfg.MaxReadings > 0 {
+			size = cfg.MaxReadings
+		}
+		for part := 0; len(readings) > 0; part++ {
+			n := min(size, len(readings))
+			batches = append(batches, Batch{
+				GatewayId:    k.gatewayId,
+				BatchId:      fmt.Sprintf("%s-%s-%d", k.gatewayId, k.window.UTC().Format("20060102T1504"), part),
+				WindowStart:  k.window,
+				SentAt:       k.window.Add(interval),
+				ReadingCount: n,
+				Readings:     readings[:n],
+			})
+			readings = readings[n:]
+		}
+	}
+	return batches
+}
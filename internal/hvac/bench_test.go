@@ -0,0 +1,101 @@
+package hvac
+
+import "testing"
+
+// benchmarkRecords gera a carga dos benchmarks dos codificadores: 30 dias da frota padrão.
+func benchmarkRecords(b *testing.B) []HvacSensorData {
+	b.Helper()
+	simulator, err := NewSimulator(SimulatorConfig{Seed: 42})
+	if err != nil {
+		b.Fatalf("NewSimulator: %v", err)
+	}
+	var records []HvacSensorData
+	for _, record := range syntheticClimate(30) {
+		records = append(records, simulator.Step(record)...)
+	}
+	return records
+}
+
+func BenchmarkSimulatorStep(b *testing.B) {
+	scenarios := map[string]SimulatorConfig{
+		"padrão": {},
+		"completo": {
+			G36:        &G36Config{},
+			Economizer: &EconomizerConfig{},
+			Noise:      &CorrelatedNoiseConfig{},
+			Outages:    &OutageConfig{RandomPerYear: 12},
+		},
+	}
+	climateData := syntheticClimate(30)
+	for name, cfg := range scenarios {
+		b.Run(name, func(b *testing.B) {
+			cfg.Seed = 42
+			b.ReportAllocs()
+			records := 0
+			for range b.N {
+				simulator, err := NewSimulator(cfg)
+				if err != nil {
+					b.Fatalf("NewSimulator: %v", err)
+				}
+				for _, record := range climateData {
+					records += len(simulator.Step(record))
+				}
+			}
+			b.ReportMetric(float64(records)/b.Elapsed().Seconds(), "registros/s")
+		})
+	}
+}
+
+func BenchmarkSimulatorStepInto(b *testing.B) {
+	climateData := syntheticClimate(30)
+	var pool RecordPool
+	b.ReportAllocs()
+	records := 0
+	for range b.N {
+		simulator, err := NewSimulator(SimulatorConfig{Seed: 42})
+		if err != nil {
+			b.Fatalf("NewSimulator: %v", err)
+		}
+		for _, record := range climateData {
+			step := simulator.StepInto(pool.Get(), record)
+			records += len(step)
+			pool.Put(step)
+		}
+	}
+	b.ReportMetric(float64(records)/b.Elapsed().Seconds(), "registros/s")
+}
+
+func BenchmarkWriteOutput(b *testing.B) {
+	records := benchmarkRecords(b)
+	renderer, err := NewPayloadRenderer(PayloadConfig{}, nil)
+	if err != nil {
+		b.Fatalf("NewPayloadRenderer: %v", err)
+	}
+	for _, format := range []string{"json", "arrow", "orc", "sqlite"} {
+		b.Run(format, func(b *testing.B) {
+			b.ReportAllocs()
+			size := 0
+			for range b.N {
+				encoded, err := WriteOutput(OutputConfig{Format: format}, renderer, records)
+				if err != nil {
+					b.Fatalf("WriteOutput: %v", err)
+				}
+				size = len(encoded)
+			}
+			b.ReportMetric(float64(len(records)*b.N)/b.Elapsed().Seconds(), "registros/s")
+			b.ReportMetric(float64(size)/float64(len(records)), "bytes/registro")
+		})
+	}
+}
+
+func BenchmarkFlatRecords(b *testing.B) {
+	records := benchmarkRecords(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, _, err := FlatRecords(records); err != nil {
+			b.Fatalf("FlatRecords: %v", err)
+		}
+	}
+	b.ReportMetric(float64(len(records)*b.N)/b.Elapsed().Seconds(), "registros/s")
+}
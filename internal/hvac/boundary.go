@@ -0,0 +1,132 @@
+package hvac
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// Casos de borda do modo de valores extremos.
+const (
+	BoundaryTemperature = "temperature" // Temperaturas interna e externa no mínimo ou no máximo da faixa
+	BoundaryHumidity    = "humidity"    // Umidade externa em 0% ou 100%
+	BoundaryPower       = "power"       // Consumo no máximo da faixa
+	BoundaryCO2         = "co2"         // CO2 no mínimo ou no máximo da faixa
+	BoundaryPressure    = "pressure"    // Pressões de refrigerante e estática no mínimo ou no máximo da faixa
+	BoundaryFault       = "fault"       // Código de falha mantido pela duração mais longa (FaultHours)
+)
+
+var (
+	boundaryCases  = []string{BoundaryTemperature, BoundaryHumidity, BoundaryPower, BoundaryCO2, BoundaryPressure, BoundaryFault}
+	boundaryRanges = pointRanges()
+)
+
+// BoundaryConfig leva uma fração das leituras aos limites das faixas do catálogo de pontos, para
+// testar a validação e a exibição dos consumidores com valores que a simulação normal raramente
+// alcança. Os limites são aplicados depois da verificação de coerência, que não os corrige.
+type BoundaryConfig struct {
+	Rate       float64  `json:"rate"`       // Fração das leituras levadas a um caso de borda (padrão: 0.05)
+	Cases      []string `json:"cases"`      // Casos sorteados: temperature, humidity, power, co2, pressure e fault (padrão: todos)
+	FaultHours float64  `json:"faultHours"` // fault: duração da falha mantida no dispositivo (h, padrão: 168)
+	FaultCode  string   `json:"faultCode"`  // fault: código mantido (padrão: HP-AL-01)
+}
+
+func (c BoundaryConfig) withDefaults() BoundaryConfig {
+	if c.Rate == 0 {
+		c.Rate = 0.05
+	}
+	if len(c.Cases) == 0 {
+		c.Cases = boundaryCases
+	}
+	if c.FaultHours == 0 {
+		c.FaultHours = 168.0
+	}
+	if c.FaultCode == "" {
+		c.FaultCode = "HP-AL-01"
+	}
+	return c
+}
+
+func (c BoundaryConfig) validate() error {
+	if c.Rate < 0 || c.Rate > 1 {
+		return fmt.Errorf("fração de leituras nos casos de borda deve estar entre 0 e 1, recebido %.2f", c.Rate)
+	}
+	for _, name := range c.Cases {
+		if !slices.Contains(boundaryCases, name) {
+			return fmt.Errorf("caso de borda '%s' desconhecido (use temperature, humidity, power, co2, pressure ou fault)", name)
+		}
+	}
+	if c.FaultHours < 0 {
+		return fmt.Errorf("duração da falha dos casos de borda não pode ser negativa, recebido %.1f", c.FaultHours)
+	}
+	return nil
+}
+
+// boundaryFault acompanha a falha mantida pelos casos de borda em um dispositivo.
+type boundaryFault struct {
+	until time.Time // Fim da falha corrente ou da última
+	last  time.Time // Instante da leitura anterior do dispositivo
+}
+
+// applyBoundaries sorteia os casos de borda das leituras do passo. Uma falha sorteada continua
+// nas leituras seguintes do dispositivo até o fim de FaultHours, e as falhas começam com a
+// probabilidade que mantém a fração das leituras com falha perto da dos demais casos.
+func (s *Simulator) applyBoundaries(records []HvacSensorData) {
+	bound := func(field string, upper bool) float64 {
+		if upper {
+			return boundaryRanges[field][1]
+		}
+		return boundaryRanges[field][0]
+	}
+
+	for i := range records {
+		record := &records[i]
+		fault, ok := s.boundaryFaults[record.DeviceId]
+		if !ok {
+			fault = &boundaryFault{}
+			s.boundaryFaults[record.DeviceId] = fault
+		}
+		hours := 1.0
+		if !fault.last.IsZero() && record.Timestamp.After(fault.last) {
+			hours = record.Timestamp.Sub(fault.last).Hours()
+		}
+		fault.last = record.Timestamp
+		faulted := record.Timestamp.Before(fault.until)
+		if faulted {
+			record.FaultCode = s.boundary.FaultCode
+		}
+		if s.rng.Float64() >= s.boundary.Rate {
+			continue
+		}
+		upper := s.rng.Intn(2) == 1
+		switch s.boundary.Cases[s.rng.Intn(len(s.boundary.Cases))] {
+		case BoundaryTemperature:
+			record.InternalTemperature = bound("internalTemperature", upper)
+			record.OutdoorTemperature = bound("outdoorTemperature", upper)
+			s.boundaryAirState(record)
+		case BoundaryHumidity:
+			record.OutdoorHumidity = bound("outdoorHumidity", upper)
+			s.boundaryAirState(record)
+		case BoundaryPower:
+			record.PowerConsumptionKwH = bound("powerConsumptionKwH", true)
+		case BoundaryCO2:
+			record.CO2LevelPpm = bound("co2LevelPpm", upper)
+		case BoundaryPressure:
+			record.RefrigerantPressurePsi = bound("refrigerantPressurePsi", upper)
+			record.DuctStaticPressurePa = bound("ductStaticPressurePa", upper)
+		case BoundaryFault:
+			if !faulted && s.rng.Float64() < hours/s.boundary.FaultHours {
+				fault.until = record.Timestamp.Add(time.Duration(s.boundary.FaultHours * float64(time.Hour)))
+				record.FaultCode = s.boundary.FaultCode
+			}
+		}
+	}
+}
+
+// boundaryAirState recalcula o ponto de orvalho, o bulbo úmido e a entalpia do ar externo depois
+// que o caso de borda muda a temperatura ou a umidade externa.
+func (s *Simulator) boundaryAirState(record *HvacSensorData) {
+	record.OutdoorDewPoint, record.OutdoorWetBulb, record.OutdoorEnthalpy = s.outdoorAirState(climate.InmetClimateData{TemperatureAir: record.OutdoorTemperature, RelativeHumidity: record.OutdoorHumidity})
+}
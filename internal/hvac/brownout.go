@@ -0,0 +1,76 @@
+package hvac
+
+import (
+	"math"
+	"time"
+)
+
+// BrownoutWindow é um afundamento de tensão programado no site.
+type BrownoutWindow struct {
+	Start         Date    `json:"start"`         // Início do afundamento (AAAA-MM-DD ou RFC 3339)
+	DurationHours float64 `json:"durationHours"` // Duração do afundamento (h)
+	VoltagePct    float64 `json:"voltagePct"`    // Tensão disponível relativa à nominal (%, padrão: 80)
+}
+
+// BrownoutConfig descreve os afundamentos de tensão do site. O evento atinge todos os
+// dispositivos ao mesmo tempo; os compressores cujo relé de subtensão atua abaixo da
+// tensão disponível desarmam.
+type BrownoutConfig struct {
+	Events            []BrownoutWindow `json:"events"`            // Afundamentos programados
+	RandomPerYear     float64          `json:"randomPerYear"`     // Afundamentos aleatórios esperados por ano
+	MeanDurationHours float64          `json:"meanDurationHours"` // Duração média dos afundamentos aleatórios (padrão: 1 h)
+	NominalVoltage    float64          `json:"nominalVoltage"`    // Tensão nominal de alimentação (V, padrão: 220)
+	TripVoltagePct    float64          `json:"tripVoltagePct"`    // Atuação média do relé de subtensão (%, padrão: 85)
+}
+
+const (
+	undervoltageAlarmPct   = 90.0 // Abaixo desta tensão todos os dispositivos sinalizam subtensão (%)
+	undervoltageTripSpread = 6.0  // Dispersão do ponto de atuação do relé entre as unidades (± %)
+	undervoltageTripRun    = 0.7  // Fração do período com o compressor desarmado (retardo anti-ciclagem)
+	undervoltageAlarmFault = "UV-AL-01"
+	undervoltageTripFault  = "UV-TR-01"
+)
+
+func (c BrownoutConfig) withDefaults() BrownoutConfig {
+	if c.MeanDurationHours == 0 {
+		c.MeanDurationHours = 1.0
+	}
+	if c.NominalVoltage == 0 {
+		c.NominalVoltage = 220.0
+	}
+	if c.TripVoltagePct == 0 {
+		c.TripVoltagePct = 85.0
+	}
+	for i := range c.Events {
+		if c.Events[i].VoltagePct == 0 {
+			c.Events[i].VoltagePct = 80.0
+		}
+	}
+	return c
+}
+
+// updateBrownout calcula a tensão disponível no site (% da nominal) no instante t.
+func (s *Simulator) updateBrownout(t time.Time, hours float64) {
+	s.voltagePct = 100.0
+	for _, e := range s.brownouts.Events {
+		end := e.Start.Add(time.Duration(e.DurationHours * float64(time.Hour)))
+		if !t.Before(e.Start.Time) && !t.After(end) {
+			s.voltagePct = math.Min(s.voltagePct, e.VoltagePct)
+		}
+	}
+	if s.brownouts.RandomPerYear > 0 {
+		if t.After(s.sagUntil) && s.rng.Float64() < s.brownouts.RandomPerYear/8760.0*hours {
+			duration := s.rng.ExpFloat64() * s.brownouts.MeanDurationHours
+			s.sagUntil = t.Add(time.Duration(math.Max(0.25, duration) * float64(time.Hour)))
+			s.sagVoltagePct = 70.0 + s.rng.Float64()*20.0
+		}
+		if !t.After(s.sagUntil) && !s.sagUntil.IsZero() {
+			s.voltagePct = math.Min(s.voltagePct, s.sagVoltagePct)
+		}
+	}
+}
+
+// supplyVoltage é a tensão medida no dispositivo, com pequena queda no cabo de alimentação.
+func (s *Simulator) supplyVoltage() float64 {
+	return s.brownouts.NominalVoltage * s.voltagePct / 100.0 * (0.99 + s.rng.Float64()*0.01)
+}
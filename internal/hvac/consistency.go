@@ -0,0 +1,92 @@
+package hvac
+
+import "fmt"
+
+// ConsistencyConfig liga a verificação, em tempo de execução, da coerência entre o modo de
+// operação e as grandezas de cada registro (ex: OFF consumindo como compressor ligado).
+type ConsistencyConfig struct {
+	Mode string `json:"mode"` // report (padrão): só registra; fix: corrige o registro e registra
+}
+
+const (
+	standbyMaxKwh        = 0.2  // Consumo máximo por leitura com a unidade parada (OFF/IDLE)
+	fanMaxKwh            = 1.0  // Consumo máximo por leitura só com o ventilador (FAN_ONLY/NIGHT_PURGE)
+	standbyPowerKwh      = 0.01 // Consumo de standby usado na correção
+	fanPowerKwh          = 0.35 // Consumo do ventilador usado na correção
+	equalizedMaxPsi      = 130  // Pressão máxima de refrigerante com o compressor parado
+	equalizedPressurePsi = 85   // Pressão equalizada usada na correção
+)
+
+// compressorRunning indica os modos em que o compressor pode estar ligado.
+func compressorRunning(status string) bool {
+	return status == "COOLING" || status == "HEATING" || status == "PRE_COOLING"
+}
+
+// CheckConsistency verifica a coerência entre o modo de operação e as grandezas dos registros:
+// consumo compatível com unidade parada ou só ventilando, pressão de refrigerante equalizada e
+// compressor sem tempo ligado nem partidas fora dos modos de compressor.
+func CheckConsistency(data []HvacSensorData) []Violation {
+	var violations []Violation
+	for i := range data {
+		record := data[i]
+		violations = append(violations, checkConsistency(&record, false)...)
+	}
+	return violations
+}
+
+// checkConsistency verifica um registro e, com fix, corrige as grandezas incoerentes com o modo.
+func checkConsistency(record *HvacSensorData, fix bool) []Violation {
+	var violations []Violation
+	violate := func(rule, format string, args ...any) {
+		violations = append(violations, Violation{DeviceId: record.DeviceId, Timestamp: record.Timestamp, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+	status := record.SystemStatus
+	if compressorRunning(status) {
+		return nil
+	}
+
+	switch status {
+	case "OFF", "IDLE":
+		if record.PowerConsumptionKwH > standbyMaxKwh {
+			violate("mode-power", "%s com consumo de %.3f kWh (máximo em standby: %.2f kWh)", status, record.PowerConsumptionKwH, standbyMaxKwh)
+			if fix {
+				record.PowerConsumptionKwH = standbyPowerKwh
+			}
+		}
+	case "FAN_ONLY", "NIGHT_PURGE":
+		if record.PowerConsumptionKwH > fanMaxKwh {
+			violate("mode-power", "%s com consumo de %.3f kWh (máximo só com ventilador: %.2f kWh)", status, record.PowerConsumptionKwH, fanMaxKwh)
+			if fix {
+				record.PowerConsumptionKwH = fanPowerKwh
+			}
+		}
+	}
+	if record.RefrigerantPressurePsi > equalizedMaxPsi {
+		violate("mode-pressure", "%s com pressão de refrigerante de %.1f psi, de compressor ligado (máximo equalizado: %d psi)", status, record.RefrigerantPressurePsi, equalizedMaxPsi)
+		if fix {
+			record.RefrigerantPressurePsi = equalizedPressurePsi
+		}
+	}
+	if record.CompressorRuntimeFraction > 0 || record.CompressorCycles > 0 {
+		violate("mode-runtime", "%s com compressor ligado %.0f%% do período e %d partidas", status, record.CompressorRuntimeFraction*100, record.CompressorCycles)
+		if fix {
+			record.CompressorRuntimeFraction, record.CompressorCycles = 0, 0
+		}
+	}
+	return violations
+}
+
+// assertConsistency verifica os registros do passo conforme o modo configurado e acumula as
+// violações encontradas.
+func (s *Simulator) assertConsistency(records []HvacSensorData) {
+	fix := s.consistency.Mode == "fix"
+	for i := range records {
+		s.inconsistencies = append(s.inconsistencies, checkConsistency(&records[i], fix)...)
+	}
+}
+
+// Inconsistencies retorna os registros incoerentes encontrados desde o início da simulação, com
+// ConsistencyConfig ativo. No modo fix os registros já saíram corrigidos.
+func (s *Simulator) Inconsistencies() []Violation {
+	return s.inconsistencies
+}
@@ -0,0 +1,87 @@
+package hvac
+
+import (
+	"testing"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// syntheticClimate gera o clima sintético de climate.Synthetic a partir de janeiro de 2024.
+func syntheticClimate(days int) []climate.InmetClimateData {
+	return climate.Synthetic(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), days)
+}
+
+func TestSimulatorRecordsAreConsistent(t *testing.T) {
+	faulty := []Device{{ID: "SALA-1"}}
+	for _, fault := range []string{FaultSimultaneousHeatCool, FaultDamperStuckOpen, FaultDamperStuckClosed, FaultAirflowDegradation, FaultCondenserFouling} {
+		faulty = append(faulty, Device{ID: "SALA-" + fault, Faults: []FaultScenario{{Type: fault}}})
+	}
+
+	scenarios := map[string]SimulatorConfig{
+		"padrão":       {},
+		"precooling":   {Precooling: &PrecoolingConfig{}},
+		"g36":          {G36: &G36Config{}, FddBaseline: true},
+		"outages":      {Outages: &OutageConfig{RandomPerYear: 50}},
+		"brownouts":    {Brownouts: &BrownoutConfig{RandomPerYear: 50}},
+		"overrides":    {Overrides: &OverrideConfig{}},
+		"falhas":       {Devices: faulty},
+		"scheduled":    {Control: ScheduledStrategy{}},
+		"g36Strategy":  {Control: G36Strategy{}},
+		"reliability":  {Faults: &ReliabilityFaultModel{MTBFHours: 200, MTTRHours: 12, Codes: []string{"HP-AL-01"}, devices: make(map[string]*reliabilityState)}},
+		"semRuído":     {Model: &ModelParams{BaseSetpoint: 22, Deadband: 1.5, Occupancy: DefaultOccupancy(), FaultRate: 1}},
+		"subdimensão":  {Devices: []Device{{ID: "SALA-1", SizingRatio: 0.5}, {ID: "SALA-2", SizingRatio: 2}}},
+		"termostatoMP": {Devices: []Device{{ID: "SALA-1", SensorPlacement: "HEAT_SOURCE"}, {ID: "SALA-2", SensorPlacement: "SUPPLY_DIFFUSER"}}},
+	}
+	climateData := syntheticClimate(120)
+	for name, cfg := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			cfg.Seed = 42
+			cfg.Consistency = &ConsistencyConfig{Mode: "report"}
+			simulator, err := NewSimulator(cfg)
+			if err != nil {
+				t.Fatalf("NewSimulator: %v", err)
+			}
+			var records []HvacSensorData
+			for _, record := range climateData {
+				records = append(records, simulator.Step(record)...)
+			}
+			for i, violation := range simulator.Inconsistencies() {
+				if i == 10 {
+					t.Errorf("... e mais %d", len(simulator.Inconsistencies())-i)
+					break
+				}
+				t.Error(violation)
+			}
+			if violations := CheckConsistency(records); len(violations) != len(simulator.Inconsistencies()) {
+				t.Errorf("CheckConsistency encontrou %d violações, o simulador registrou %d", len(violations), len(simulator.Inconsistencies()))
+			}
+		})
+	}
+}
+
+func TestConsistencyFixMode(t *testing.T) {
+	records := []HvacSensorData{
+		{DeviceId: "A", SystemStatus: "OFF", PowerConsumptionKwH: 4, RefrigerantPressurePsi: 82},
+		{DeviceId: "A", SystemStatus: "FAN_ONLY", PowerConsumptionKwH: 0.3, RefrigerantPressurePsi: 170, CompressorRuntimeFraction: 0.5, CompressorCycles: 2},
+		{DeviceId: "A", SystemStatus: "COOLING", PowerConsumptionKwH: 6, RefrigerantPressurePsi: 170, CompressorRuntimeFraction: 1},
+	}
+	if got := CheckConsistency(records); len(got) != 3 {
+		t.Fatalf("esperadas 3 violações, encontradas %d: %v", len(got), got)
+	}
+
+	simulator := &Simulator{consistency: &ConsistencyConfig{Mode: "fix"}}
+	simulator.assertConsistency(records)
+	if len(simulator.Inconsistencies()) != 3 {
+		t.Errorf("esperadas 3 violações registradas, encontradas %d", len(simulator.Inconsistencies()))
+	}
+	if got := CheckConsistency(records); len(got) != 0 {
+		t.Errorf("registros corrigidos ainda incoerentes: %v", got)
+	}
+	if records[0].PowerConsumptionKwH != standbyPowerKwh || records[1].RefrigerantPressurePsi != equalizedPressurePsi || records[1].CompressorCycles != 0 {
+		t.Errorf("correção inesperada: %+v", records[:2])
+	}
+	if records[2].RefrigerantPressurePsi != 170 {
+		t.Errorf("registro coerente alterado: %+v", records[2])
+	}
+}
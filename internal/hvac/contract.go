@@ -0,0 +1,241 @@
+package hvac
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// Categorias das fixtures de contrato.
+const (
+	ContractBase        = "base"        // Registro com os campos obrigatórios e registro com todos os opcionais
+	ContractEnum        = "enum"        // Um registro por valor de cada campo enumerado
+	ContractFaultCode   = "faultCode"   // Um registro por código de falha emitido pelo gerador
+	ContractActiveFault = "activeFault" // Um registro por falha injetada (rótulo de FDD)
+	ContractExtreme     = "extreme"     // Campos numéricos nos limites da faixa do catálogo de pontos
+	ContractNull        = "null"        // Um campo por registro gravado como null, como um sensor que não reportou
+)
+
+// ContractFixture é um registro de exemplo para os testes de contrato dos consumidores dos dados.
+type ContractFixture struct {
+	Name        string         // Nome único da fixture (ex: systemStatus-HEATING)
+	Category    string         // Categoria: base, enum, faultCode, activeFault, extreme ou null
+	Description string         // O que a fixture exercita
+	Record      HvacSensorData // Registro canônico; os campos de Missing saem como null
+}
+
+// ContractOptions complementa as fixtures com valores que vêm do cenário.
+type ContractOptions struct {
+	FaultCodes []string // Códigos de falha além dos embutidos no gerador (ex: faultModel.codes)
+}
+
+// contractFaultCodes são os códigos de falha que o gerador emite em faultCode.
+var contractFaultCodes = []string{"OK", "HP-AL-01", "HT-FL-02", "FP-AL-01", "FP-AL-02", undervoltageAlarmFault, undervoltageTripFault, powerRestoreFault}
+
+// ContractFixtures gera o conjunto de fixtures do esquema atual do registro: os valores de cada
+// campo enumerado, os códigos de falha, os limites de cada faixa e cada campo nulo. As fixtures são
+// deterministas e derivadas das mesmas definições usadas pelo gerador, para que os payloads de
+// teste não se afastem dos dados gerados quando o esquema muda.
+func ContractFixtures(opts ContractOptions) []ContractFixture {
+	var fixtures []ContractFixture
+	add := func(name, category, description string, record HvacSensorData) {
+		fixtures = append(fixtures, ContractFixture{Name: name, Category: category, Description: description, Record: record})
+	}
+
+	add("required-only", ContractBase, "somente os campos obrigatórios", contractBaseRecord())
+	add("all-optional-fields", ContractBase, "todos os blocos e campos opcionais presentes, sem coerência física entre eles", contractFullRecord())
+
+	for _, status := range []string{"OFF", "COOLING", "HEATING", "FAN_ONLY", "IDLE", "NIGHT_PURGE", "PRE_COOLING", "STARTUP"} {
+		add("systemStatus-"+status, ContractEnum, "systemStatus = "+status, contractStatusRecord(status))
+	}
+	for _, occupied := range []bool{true, false} {
+		record := contractBaseRecord()
+		record.OccupancyStatus = occupied
+		add(fmt.Sprintf("occupancyStatus-%t", occupied), ContractEnum, fmt.Sprintf("occupancyStatus = %t", occupied), record)
+	}
+	for _, mode := range []string{"COOLING", "HEATING", "MIXED"} {
+		record := contractFullRecord()
+		record.Vrf.OutdoorUnitMode = mode
+		add("vrf.outdoorUnitMode-"+mode, ContractEnum, "vrf.outdoorUnitMode = "+mode, record)
+	}
+	for _, mode := range []string{"OFF", "RECOVERY", "BYPASS", "FROST_PROTECTION"} {
+		record := contractFullRecord()
+		record.Erv.Mode = mode
+		record.Erv.Running = mode != "OFF"
+		record.Erv.BypassActive = mode == "BYPASS"
+		record.Erv.FrostProtection = mode == "FROST_PROTECTION"
+		add("erv.mode-"+mode, ContractEnum, "erv.mode = "+mode, record)
+	}
+	for _, highLimit := range []string{HighLimitFixedDryBulb, HighLimitDifferentialDryBulb, HighLimitFixedEnthalpy, HighLimitDifferentialEnthalpy} {
+		record := contractFullRecord()
+		record.Economizer.HighLimit = highLimit
+		add("economizer.highLimit-"+highLimit, ContractEnum, "economizer.highLimit = "+highLimit, record)
+	}
+	for _, reason := range []string{"DRY_BULB", "ENTHALPY", "DIFFERENTIAL_DRY_BULB", "DIFFERENTIAL_ENTHALPY"} {
+		record := contractFullRecord()
+		record.Economizer.LockoutReason = reason
+		add("economizer.lockoutReason-"+reason, ContractEnum, "economizer.lockoutReason = "+reason, record)
+	}
+
+	codes := slices.Clone(contractFaultCodes)
+	for _, code := range opts.FaultCodes {
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	for _, code := range codes {
+		record := contractBaseRecord()
+		record.FaultCode = code
+		add("faultCode-"+code, ContractFaultCode, "faultCode = "+code, record)
+	}
+
+	for _, fault := range []string{FaultSimultaneousHeatCool, FaultDamperStuckOpen, FaultDamperStuckClosed, FaultAirflowDegradation, FaultCondenserFouling} {
+		record := contractBaseRecord()
+		record.ActiveFaults = []string{fault}
+		add("activeFaults-"+fault, ContractActiveFault, "activeFaults = ["+fault+"]", record)
+	}
+
+	ranges := pointRanges()
+	full := contractFullRecord()
+	var paths []string
+	for _, field := range recordFloatFields(&full) {
+		if _, ok := ranges[field.path]; ok && !slices.Contains(paths, field.path) {
+			paths = append(paths, field.path)
+		}
+	}
+	for _, path := range paths {
+		bounds := ranges[path]
+		for _, bound := range []struct {
+			name  string
+			value float64
+		}{{"min", bounds[0]}, {"max", bounds[1]}} {
+			record := contractFullRecord()
+			for _, field := range recordFloatFields(&record) {
+				if field.path == path {
+					*field.value = bound.value
+				}
+			}
+			add(path+"-"+bound.name, ContractExtreme, fmt.Sprintf("%s = %g, limite %s da faixa", path, bound.value, map[string]string{"min": "inferior", "max": "superior"}[bound.name]), record)
+		}
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(recordFieldPaths())) {
+		// Os valores esperados não passam pela ausência de campos, e os campos dentro de listas
+		// (como as previsões) não têm caminho no payload
+		if strings.HasPrefix(path, "expected.") || strings.HasPrefix(path, "outdoorTemperatureForecast.") {
+			continue
+		}
+		record := contractFullRecord()
+		record.Missing = []string{path}
+		add(path+"-null", ContractNull, path+" = null (campo não reportado)", record)
+	}
+	return fixtures
+}
+
+// contractBaseRecord é o registro de referência das fixtures: uma sala resfriando, sem falhas e sem
+// os blocos opcionais.
+func contractBaseRecord() HvacSensorData {
+	return HvacSensorData{
+		Timestamp:              time.Date(2024, time.January, 15, 15, 0, 0, 0, time.UTC),
+		InternalTemperature:    23.4,
+		SetPointTemperature:    22.5,
+		SystemStatus:           "COOLING",
+		OccupancyStatus:        true,
+		PowerConsumptionKwH:    2.35,
+		OutdoorTemperature:     31.2,
+		OutdoorHumidity:        58,
+		OutdoorDewPoint:        22.1,
+		OutdoorWetBulb:         24.3,
+		OutdoorEnthalpy:        72.4,
+		DeviceId:               "SALA-1",
+		SupplyAirTemperature:   13.2,
+		ReturnAirTemperature:   23.9,
+		DuctStaticPressurePa:   24.5,
+		CO2LevelPpm:            780,
+		RefrigerantPressurePsi: 265,
+		FaultCode:              "OK",
+		AssetModel:             defaultAssetModel,
+		LocationZone:           defaultZone,
+		StationId:              "A701",
+	}
+}
+
+// contractFullRecord é o registro de referência com todos os campos opcionais preenchidos e
+// diferentes de zero, para que nenhum seja omitido do JSON.
+func contractFullRecord() HvacSensorData {
+	record := contractBaseRecord()
+	trueZoneTemperature, filterPressure := 24.1, 185.0
+	record.RunId = "contract-fixtures"
+	record.ExtremeEvent = "ONDA-DE-CALOR"
+	record.OutdoorTemperatureForecast = []climate.Forecast{{HorizonHours: 1, Temperature: 31.5}, {HorizonHours: 6, Temperature: 27.8}, {HorizonHours: 24, Temperature: 30.9}}
+	record.G36 = &G36Points{AhuId: "AHU-1", SupplyAirTempSetpoint: 12.8, DuctStaticPressureSetpointPa: 25, DamperPositionPct: 62.5, ZoneCoolingRequests: 1, ZonePressureRequests: 1, AhuCoolingRequests: 3, AhuPressureRequests: 2}
+	record.Expected = &ExpectedValues{
+		SupplyAirTemperature:   ExpectedRange{Expected: 13.0, Min: 12.0, Max: 14.0},
+		PowerConsumptionKwH:    ExpectedRange{Expected: 2.3, Min: 2.19, Max: 2.42},
+		RefrigerantPressurePsi: ExpectedRange{Expected: 260, Min: 245, Max: 275},
+		DuctStaticPressurePa:   ExpectedRange{Expected: 24, Min: 22, Max: 26},
+	}
+	record.Intensity = &IntensityMetrics{ServedAreaM2: 40, ServedVolumeM3: 120, PowerDensityWm2: 58.75, PowerDensityWm3: 19.58, EnergyIntensityKwhM2Day: 0.42}
+	record.RecoveryActive = true
+	record.CapacitySaturated = true
+	record.CompressorRuntimeFraction = 0.82
+	record.CompressorCycles = 3
+	record.TrueZoneTemperature = &trueZoneTemperature
+	record.ActiveFaults = []string{FaultCondenserFouling}
+	record.InrushPowerKw = 11.2
+	record.SupplyVoltageV = 214.5
+	record.OverrideActive = true
+	record.GasConsumptionM3 = 0.8
+	record.GasConsumptionTherms = 0.283
+	record.Hydronic = &HydronicPoints{
+		CoilId:      "AHU-1",
+		CoolingCoil: CoilPoints{ValvePositionPct: 68, FlowLps: 0.9, PumpSpeedPct: 72, SupplyWaterTemp: 7, ReturnWaterTemp: 12.5, DeltaT: 5.5},
+		HeatingCoil: CoilPoints{ValvePositionPct: 5, FlowLps: 0.05, PumpSpeedPct: 30, SupplyWaterTemp: 60, ReturnWaterTemp: 52, DeltaT: 8},
+	}
+	record.Vrf = &VRFPoints{OutdoorUnitId: "ODU-1", OutdoorUnitMode: "COOLING", CapacityFactor: 0.87, ModeConflict: true}
+	record.Erv = &ERVPoints{Running: true, Mode: "RECOVERY", OutdoorAirTemp: 31.2, SupplyAirTemp: 26.0, ReturnAirTemp: 23.9, ExhaustAirTemp: 29.1, Effectiveness: 0.7, RecoveredKw: -1.85, FanEnergyKwh: 0.06, BypassActive: true, FrostProtection: true}
+	record.DefrostCycles = 1
+	record.AuxHeatKwh = 0.4
+	record.StagesActive = 2
+	record.LeadCompressor = 1
+	record.StageRuntimeFractions = []float64{1, 0.64}
+	record.FilterDifferentialPressurePa = &filterPressure
+	record.Economizer = &EconomizerPoints{HighLimit: HighLimitDifferentialEnthalpy, Lockout: true, LockoutReason: "DIFFERENTIAL_ENTHALPY", Active: true, OutdoorAirDamperPct: 20, OutdoorAirEnthalpy: 72.4, ReturnAirEnthalpy: 48.6, FreeCoolingFraction: 0.1}
+	return record
+}
+
+// contractStatusRecord ajusta o registro de referência ao modo de operação, com as grandezas que
+// CheckConsistency e Validate esperam do modo: insuflamento mais quente no aquecimento e consumo de
+// standby ou só de ventilador, com a pressão equalizada, nos modos sem compressor.
+func contractStatusRecord(status string) HvacSensorData {
+	record := contractBaseRecord()
+	record.SystemStatus = status
+	switch status {
+	case "HEATING":
+		record.InternalTemperature, record.SetPointTemperature = 19.6, 21
+		record.OutdoorTemperature = 6.5
+		record.SupplyAirTemperature, record.ReturnAirTemperature = 35.4, 19.9
+	case "OFF", "IDLE":
+		record.PowerConsumptionKwH, record.RefrigerantPressurePsi = standbyPowerKwh, equalizedPressurePsi
+		record.SupplyAirTemperature = record.ReturnAirTemperature
+	case "FAN_ONLY", "NIGHT_PURGE":
+		record.PowerConsumptionKwH, record.RefrigerantPressurePsi = fanPowerKwh, equalizedPressurePsi
+		record.SupplyAirTemperature = record.ReturnAirTemperature - 0.3
+	case "STARTUP":
+		// Como o registro de religamento após uma queda de energia
+		record.PowerConsumptionKwH, record.RefrigerantPressurePsi = bootRecordEnergy, equalizedPressurePsi
+		record.SupplyAirTemperature, record.ReturnAirTemperature = record.InternalTemperature, record.InternalTemperature
+		record.FaultCode = powerRestoreFault
+		record.InrushPowerKw = 11.2
+	}
+	if status == "NIGHT_PURGE" {
+		record.OccupancyStatus = false
+		record.Timestamp = time.Date(2024, time.January, 15, 4, 0, 0, 0, time.UTC)
+		record.OutdoorTemperature = 19.5
+	}
+	return record
+}
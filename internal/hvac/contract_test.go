@@ -0,0 +1,74 @@
+package hvac
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestContractFixturesAreCoherent(t *testing.T) {
+	fixtures := ContractFixtures(ContractOptions{FaultCodes: []string{"HP-AL-01", "CUSTOM-01"}})
+	names := make(map[string]bool)
+	for _, fixture := range fixtures {
+		if names[fixture.Name] {
+			t.Errorf("fixture '%s' repetida", fixture.Name)
+		}
+		names[fixture.Name] = true
+
+		// Os limites das faixas e os campos nulos não precisam ser fisicamente coerentes
+		if fixture.Category != ContractBase && fixture.Category != ContractEnum && fixture.Category != ContractFaultCode {
+			continue
+		}
+		records := []HvacSensorData{fixture.Record}
+		for _, violation := range append(Validate(records, DefaultValidationLimits()), CheckConsistency(records)...) {
+			t.Errorf("fixture '%s': %s", fixture.Name, violation)
+		}
+	}
+	for _, name := range []string{"systemStatus-NIGHT_PURGE", "faultCode-CUSTOM-01", "erv.mode-FROST_PROTECTION", "co2LevelPpm-max", "stageRuntimeFractions-null"} {
+		if !names[name] {
+			t.Errorf("fixture '%s' ausente", name)
+		}
+	}
+}
+
+func TestContractNullFixturesRenderNull(t *testing.T) {
+	renderer, err := NewPayloadRenderer(PayloadConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fixture := range ContractFixtures(ContractOptions{}) {
+		if fixture.Category != ContractNull {
+			continue
+		}
+		payload, err := renderer.Render(fixture.Record)
+		if err != nil {
+			t.Fatalf("fixture '%s': %v", fixture.Name, err)
+		}
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := make(map[string]any)
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		path := fixture.Record.Missing[0]
+		if value, ok := lookupKey(decoded, path); !ok || value != nil {
+			t.Errorf("fixture '%s': %s = %v, esperado null", fixture.Name, path, value)
+		}
+	}
+}
+
+// lookupKey retorna o valor do caminho no payload e se a chave existe.
+func lookupKey(payload map[string]any, path string) (any, bool) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := payload[part].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		payload = next
+	}
+	value, ok := payload[parts[len(parts)-1]]
+	return value, ok
+}
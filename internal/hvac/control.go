@@ -0,0 +1,190 @@
+package hvac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ZoneState é o que a estratégia de controle enxerga de uma sala a cada passo.
+type ZoneState struct {
+	DeviceID           string
+	Zone               string
+	Timestamp          time.Time
+	Occupied           bool    // Presença detectada na sala
+	Temperature        float64 // Temperatura lida pelo termostato (°C)
+	Setpoint           float64 // Setpoint programado, já com o ajuste do ocupante (°C)
+	Deadband           float64 // Banda morta do modelo (°C)
+	OutdoorTemperature float64 // °C
+	OutdoorHumidity    float64 // %
+	PreviousMode       string  // Modo do passo anterior ("" no primeiro passo)
+}
+
+// ControlDecision é o modo de operação escolhido para o passo e o setpoint efetivo, que a sala
+// persegue e que sai em setPointTemperature.
+type ControlDecision struct {
+	Mode     string  // OFF, COOLING, HEATING, IDLE, FAN_ONLY, NIGHT_PURGE ou PRE_COOLING
+	Setpoint float64 // Setpoint efetivo (°C); zero mantém o setpoint programado
+}
+
+// ControlStrategy decide o modo de operação e o setpoint de uma sala. Com PrecoolingConfig, o
+// simulador ainda pode trocar um OFF de sala desocupada por NIGHT_PURGE ou PRE_COOLING.
+type ControlStrategy interface {
+	Decide(state ZoneState) ControlDecision
+}
+
+// ThermostatStrategy é o termostato simples, o padrão do simulador: com a sala ocupada resfria ou
+// aquece fora da banda morta e fica em IDLE perto do setpoint; desocupada, desliga.
+type ThermostatStrategy struct{}
+
+// Decide implementa ControlStrategy.
+func (ThermostatStrategy) Decide(state ZoneState) ControlDecision {
+	decision := ControlDecision{Mode: "OFF", Setpoint: state.Setpoint}
+	if !state.Occupied {
+		return decision
+	}
+	diff := state.Temperature - state.Setpoint
+	if diff > state.Deadband {
+		decision.Mode = "COOLING"
+	} else if diff < -state.Deadband {
+		decision.Mode = "HEATING"
+	} else if math.Abs(diff) < state.Deadband*idleBandFraction {
+		decision.Mode = "IDLE"
+	}
+	return decision
+}
+
+// ScheduledStrategy segue a programação horária da automação, e não a presença: nos dias úteis,
+// dentro do expediente, controla no setpoint; fora dele, só atua para manter os setpoints de
+// setback (setpoint ± SetbackOffset).
+type ScheduledStrategy struct {
+	StartHour     int     // Início do expediente (padrão: 8)
+	EndHour       int     // Fim do expediente (padrão: 18)
+	SetbackOffset float64 // Afastamento dos setpoints fora do expediente (°C, padrão: 4)
+}
+
+func (c ScheduledStrategy) withDefaults() ScheduledStrategy {
+	if c.StartHour == 0 && c.EndHour == 0 {
+		c.StartHour, c.EndHour = 8, 18
+	}
+	if c.SetbackOffset == 0 {
+		c.SetbackOffset = 4.0
+	}
+	return c
+}
+
+// Decide implementa ControlStrategy.
+func (c ScheduledStrategy) Decide(state ZoneState) ControlDecision {
+	c = c.withDefaults()
+	if scheduledHours(state.Timestamp, c.StartHour, c.EndHour) {
+		decision := ThermostatStrategy{}.Decide(ZoneState{Occupied: true, Temperature: state.Temperature, Setpoint: state.Setpoint, Deadband: state.Deadband})
+		if decision.Mode == "OFF" {
+			decision.Mode = "IDLE" // Dentro do expediente o ventilador segue ligado
+		}
+		return decision
+	}
+	return setback(state, c.SetbackOffset)
+}
+
+// G36Strategy segue os modos de zona do ASHRAE Guideline 36: no expediente, setpoints separados
+// de resfriamento e de aquecimento (setpoint ± banda morta) com ventilação contínua entre eles;
+// antes do expediente, cooldown/warmup até a banda ocupada; fora dele, setback. Complementa a
+// sequência de AHU do cenário g36, que continua opcional.
+type G36Strategy struct {
+	StartHour        int     // Início do modo ocupado (padrão: 8)
+	EndHour          int     // Fim do modo ocupado (padrão: 18)
+	WarmupHours      int     // Antecedência do cooldown/warmup antes do modo ocupado (h, padrão: 2)
+	UnoccupiedOffset float64 // Afastamento dos setpoints desocupados (°C, padrão: 5)
+}
+
+func (c G36Strategy) withDefaults() G36Strategy {
+	if c.StartHour == 0 && c.EndHour == 0 {
+		c.StartHour, c.EndHour = 8, 18
+	}
+	if c.WarmupHours == 0 {
+		c.WarmupHours = 2
+	}
+	if c.UnoccupiedOffset == 0 {
+		c.UnoccupiedOffset = 5.0
+	}
+	return c
+}
+
+// Decide implementa ControlStrategy.
+func (c G36Strategy) Decide(state ZoneState) ControlDecision {
+	c = c.withDefaults()
+	coolingSetpoint := state.Setpoint + state.Deadband/2
+	heatingSetpoint := state.Setpoint - state.Deadband/2
+	switch {
+	case scheduledHours(state.Timestamp, c.StartHour, c.EndHour):
+		if state.Temperature > coolingSetpoint {
+			return ControlDecision{Mode: "COOLING", Setpoint: coolingSetpoint}
+		}
+		if state.Temperature < heatingSetpoint {
+			return ControlDecision{Mode: "HEATING", Setpoint: heatingSetpoint}
+		}
+		return ControlDecision{Mode: "FAN_ONLY", Setpoint: state.Setpoint}
+	case scheduledHours(state.Timestamp, c.StartHour-c.WarmupHours, c.StartHour):
+		// Cooldown/warmup: a sala chega à banda ocupada no início do expediente
+		if state.Temperature > coolingSetpoint {
+			return ControlDecision{Mode: "COOLING", Setpoint: coolingSetpoint}
+		}
+		if state.Temperature < heatingSetpoint {
+			return ControlDecision{Mode: "HEATING", Setpoint: heatingSetpoint}
+		}
+	}
+	return setback(state, c.UnoccupiedOffset)
+}
+
+// setback mantém a sala entre os setpoints desocupados, afastados do setpoint programado.
+func setback(state ZoneState, offset float64) ControlDecision {
+	if state.Temperature > state.Setpoint+offset {
+		return ControlDecision{Mode: "COOLING", Setpoint: state.Setpoint + offset}
+	}
+	if state.Temperature < state.Setpoint-offset {
+		return ControlDecision{Mode: "HEATING", Setpoint: state.Setpoint - offset}
+	}
+	return ControlDecision{Mode: "OFF", Setpoint: state.Setpoint}
+}
+
+// scheduledHours indica se o instante cai num dia útil entre as horas informadas.
+func scheduledHours(t time.Time, startHour, endHour int) bool {
+	weekday := t.Weekday()
+	hour := t.Hour()
+	return weekday >= time.Monday && weekday <= time.Friday && hour >= startHour && hour < endHour
+}
+
+var (
+	controlStrategiesMu sync.RWMutex
+	controlStrategies   = map[string]func() ControlStrategy{
+		"thermostat": func() ControlStrategy { return ThermostatStrategy{} },
+		"scheduled":  func() ControlStrategy { return ScheduledStrategy{} },
+		"g36":        func() ControlStrategy { return G36Strategy{} },
+	}
+)
+
+// RegisterControlStrategy registra uma estratégia de controle pelo nome, para que o cenário a
+// escolha em controlStrategy. Um nome já registrado é substituído.
+func RegisterControlStrategy(name string, factory func() ControlStrategy) {
+	controlStrategiesMu.Lock()
+	defer controlStrategiesMu.Unlock()
+	controlStrategies[name] = factory
+}
+
+// LookupControlStrategy cria a estratégia de controle registrada com o nome informado.
+func LookupControlStrategy(name string) (ControlStrategy, error) {
+	controlStrategiesMu.RLock()
+	defer controlStrategiesMu.RUnlock()
+	factory, ok := controlStrategies[name]
+	if !ok {
+		names := make([]string, 0, len(controlStrategies))
+		for registered := range controlStrategies {
+			names = append(names, registered)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("estratégia de controle '%s' não registrada (disponíveis: %v)", name, names)
+	}
+	return factory(), nil
+}
@@ -0,0 +1,295 @@
+package hvac
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/google/uuid"
+)
+
+// DeltaLogDir é o diretório do log de transações dentro da tabela Delta.
+const DeltaLogDir = "_delta_log/"
+
+// deltaField é uma coluna do schemaString da tabela Delta. Type é um nome primitivo ("double")
+// ou um objeto para tipos aninhados (array).
+type deltaField struct {
+	Name     string            `json:"name"`
+	Type     json.RawMessage   `json:"type"`
+	Nullable bool              `json:"nullable"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+type deltaSchema struct {
+	Type   string       `json:"type"`
+	Fields []deltaField `json:"fields"`
+}
+
+type deltaFormat struct {
+	Provider string            `json:"provider"`
+	Options  map[string]string `json:"options"`
+}
+
+type deltaMetadata struct {
+	ID               string            `json:"id"`
+	Format           deltaFormat       `json:"format"`
+	SchemaString     string            `json:"schemaString"`
+	PartitionColumns []string          `json:"partitionColumns"`
+	Configuration    map[string]string `json:"configuration"`
+	CreatedTime      int64             `json:"createdTime"`
+}
+
+type deltaProtocol struct {
+	MinReaderVersion int `json:"minReaderVersion"`
+	MinWriterVersion int `json:"minWriterVersion"`
+}
+
+type deltaAdd struct {
+	Path             string            `json:"path"`
+	PartitionValues  map[string]string `json:"partitionValues"`
+	Size             int64             `json:"size"`
+	ModificationTime int64             `json:"modificationTime"`
+	DataChange       bool              `json:"dataChange"`
+	Stats            string            `json:"stats"`
+}
+
+type deltaCommitInfo struct {
+	Timestamp           int64             `json:"timestamp"`
+	Operation           string            `json:"operation"`
+	OperationParameters map[string]string `json:"operationParameters"`
+	EngineInfo          string            `json:"engineInfo"`
+}
+
+// deltaAction é uma linha do arquivo de commit; só um dos campos vem preenchido.
+type deltaAction struct {
+	CommitInfo *deltaCommitInfo `json:"commitInfo,omitempty"`
+	Protocol   *deltaProtocol   `json:"protocol,omitempty"`
+	MetaData   *deltaMetadata   `json:"metaData,omitempty"`
+	Add        *deltaAdd        `json:"add,omitempty"`
+}
+
+// DeltaTable é o estado de uma tabela Delta existente, reconstruído a partir dos commits do log.
+type DeltaTable struct {
+	Version  int64 // Última versão commitada (-1 quando a tabela ainda não existe)
+	metadata *deltaMetadata
+	fields   []deltaField
+}
+
+// DeltaCommitVersion extrai a versão de uma chave do log (ex: _delta_log/00000000000000000003.json).
+// Checkpoints e demais arquivos do log retornam false.
+func DeltaCommitVersion(key string) (int64, bool) {
+	name := path.Base(key)
+	if !strings.HasSuffix(name, ".json") || !strings.Contains(key, DeltaLogDir) {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// ReadDeltaLog reconstrói o estado da tabela a partir dos commits JSON, em ordem de versão. Sem
+// commits, a tabela é nova e o próximo commit será a versão 0.
+func ReadDeltaLog(commits [][]byte) (DeltaTable, error) {
+	table := DeltaTable{Version: int64(len(commits)) - 1}
+	for version, commit := range commits {
+		scanner := bufio.NewScanner(bytes.NewReader(commit))
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var action deltaAction
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+				return DeltaTable{}, fmt.Errorf("erro ao ler o commit %d do log Delta: %w", version, err)
+			}
+			if action.MetaData != nil {
+				table.metadata = action.MetaData
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return DeltaTable{}, fmt.Errorf("erro ao ler o commit %d do log Delta: %w", version, err)
+		}
+	}
+	if len(commits) > 0 && table.metadata == nil {
+		return DeltaTable{}, fmt.Errorf("log Delta sem metaData: a tabela precisa dos commits JSON desde a versão 0")
+	}
+	if table.metadata != nil {
+		var schema deltaSchema
+		if err := json.Unmarshal([]byte(table.metadata.SchemaString), &schema); err != nil {
+			return DeltaTable{}, fmt.Errorf("erro ao ler o esquema da tabela Delta: %w", err)
+		}
+		table.fields = schema.Fields
+	}
+	return table, nil
+}
+
+// DeltaWrite é o resultado de um append na tabela: o arquivo Parquet de dados e o commit que o
+// registra, com chaves relativas à raiz da tabela. O commit deve ser gravado depois dos dados.
+type DeltaWrite struct {
+	DataKey   string
+	Data      []byte
+	CommitKey string
+	Commit    []byte
+}
+
+func deltaType(kind columnKind) json.RawMessage {
+	switch kind {
+	case kindFloat, kindNullableFloat:
+		return json.RawMessage(`"double"`)
+	case kindInt, kindNullableInt:
+		return json.RawMessage(`"long"`)
+	case kindBool:
+		return json.RawMessage(`"boolean"`)
+	case kindTime:
+		return json.RawMessage(`"timestamp"`)
+	case kindStringList:
+		return json.RawMessage(`{"type":"array","elementType":"string","containsNull":true}`)
+	}
+	return json.RawMessage(`"string"`)
+}
+
+// mergeDeltaSchema acrescenta ao esquema da tabela as colunas novas dos dados (evolução de
+// esquema). Todas as colunas são anuláveis, pois os arquivos antigos não têm as colunas novas.
+func mergeDeltaSchema(existing []deltaField, columns []column) ([]deltaField, bool, error) {
+	merged := append([]deltaField(nil), existing...)
+	index := make(map[string]int, len(existing))
+	for i, f := range existing {
+		index[f.Name] = i
+	}
+	changed := false
+	for _, c := range columns {
+		typ := deltaType(c.kind)
+		if i, ok := index[c.name]; ok {
+			if !bytes.Equal(merged[i].Type, typ) {
+				return nil, false, fmt.Errorf("coluna '%s' já existe na tabela Delta com tipo %s, incompatível com %s", c.name, merged[i].Type, typ)
+			}
+			continue
+		}
+		merged = append(merged, deltaField{Name: c.name, Type: typ, Nullable: true, Metadata: map[string]string{}})
+		changed = true
+	}
+	return merged, changed, nil
+}
+
+// WriteDeltaCommit grava os registros como um arquivo Parquet e monta o próximo commit da tabela.
+// A primeira versão cria a tabela (protocol e metaData); as seguintes só publicam metaData quando
+// os dados trazem colunas novas. compression aceita zstd ou snappy (o padrão).
+func WriteDeltaCommit(table DeltaTable, data []HvacSensorData, compression Compression, now time.Time) (DeltaWrite, error) {
+	if err := CheckCompression("delta", compression); err != nil {
+		return DeltaWrite{}, fmt.Errorf("compressão Delta: %w", err)
+	}
+	columns := tabularColumns(data)
+	fields, changed, err := mergeDeltaSchema(table.fields, columns)
+	if err != nil {
+		return DeltaWrite{}, err
+	}
+
+	codec := compress.Codecs.Snappy
+	if compression == CompressionZstd {
+		codec = compress.Codecs.Zstd
+	}
+
+	parquetData, err := writeParquet(data, columns, codec)
+	if err != nil {
+		return DeltaWrite{}, err
+	}
+
+	version := table.Version + 1
+	nowMillis := now.UnixMilli()
+	dataKey := fmt.Sprintf("part-00000-%s-c000.%s.parquet", uuid.NewString(), strings.ToLower(codec.String()))
+	stats, err := json.Marshal(map[string]int{"numRecords": len(data)})
+	if err != nil {
+		return DeltaWrite{}, fmt.Errorf("erro ao serializar as estatísticas do commit Delta: %w", err)
+	}
+
+	actions := []deltaAction{{CommitInfo: &deltaCommitInfo{
+		Timestamp:           nowMillis,
+		Operation:           "WRITE",
+		OperationParameters: map[string]string{"mode": "Append"},
+		EngineInfo:          "mock-data-hvac",
+	}}}
+	if version == 0 {
+		actions = append(actions, deltaAction{Protocol: &deltaProtocol{MinReaderVersion: 1, MinWriterVersion: 2}})
+	}
+	if version == 0 || changed {
+		metadata := deltaMetadata{
+			ID:               uuid.NewString(),
+			Format:           deltaFormat{Provider: "parquet", Options: map[string]string{}},
+			PartitionColumns: []string{},
+			Configuration:    map[string]string{},
+			CreatedTime:      nowMillis,
+		}
+		if table.metadata != nil {
+			metadata.ID, metadata.CreatedTime = table.metadata.ID, table.metadata.CreatedTime
+			metadata.PartitionColumns, metadata.Configuration = table.metadata.PartitionColumns, table.metadata.Configuration
+		}
+		schemaString, err := json.Marshal(deltaSchema{Type: "struct", Fields: fields})
+		if err != nil {
+			return DeltaWrite{}, fmt.Errorf("erro ao serializar o esquema da tabela Delta: %w", err)
+		}
+		metadata.SchemaString = string(schemaString)
+		actions = append(actions, deltaAction{MetaData: &metadata})
+	}
+	actions = append(actions, deltaAction{Add: &deltaAdd{
+		Path:             dataKey,
+		PartitionValues:  map[string]string{},
+		Size:             int64(len(parquetData)),
+		ModificationTime: nowMillis,
+		DataChange:       true,
+		Stats:            string(stats),
+	}})
+
+	var commit bytes.Buffer
+	for _, action := range actions {
+		line, err := json.Marshal(action)
+		if err != nil {
+			return DeltaWrite{}, fmt.Errorf("erro ao serializar o commit Delta: %w", err)
+		}
+		commit.Write(line)
+		commit.WriteByte('\n')
+	}
+
+	return DeltaWrite{
+		DataKey:   dataKey,
+		Data:      parquetData,
+		CommitKey: fmt.Sprintf("%s%020d.json", DeltaLogDir, version),
+		Commit:    commit.Bytes(),
+	}, nil
+}
+
+// writeParquet serializa os registros em Parquet com o esquema colunar achatado. Os timestamps são
+// gravados em microssegundos, a precisão do tipo timestamp do Delta.
+func writeParquet(data []HvacSensorData, columns []column, codec compress.Compression) ([]byte, error) {
+	schema := arrowSchema(columns)
+	var buf bytes.Buffer
+	writer, err := pqarrow.NewFileWriter(schema, &buf,
+		parquet.NewWriterProperties(parquet.WithCompression(codec)),
+		pqarrow.NewArrowWriterProperties(pqarrow.WithCoerceTimestamps(arrow.Microsecond)))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o arquivo Parquet: %w", err)
+	}
+	err = eachArrowRecord(data, columns, schema, func(record arrow.Record) error {
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("erro ao escrever o row group Parquet: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar o arquivo Parquet: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,441 @@
+package hvac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Dialect descreve o formato de payload de um grupo de dispositivos (ex: outro fabricante):
+// nomes e aninhamento dos campos, unidades e formato do timestamp. Campos não mapeados
+// mantêm o nome canônico do HvacSensorData.
+type Dialect struct {
+	// Fields mapeia o campo canônico (ex: internalTemperature, g36.damperPositionPct) para o
+	// caminho no payload; pontos criam objetos aninhados (ex: sensors.zoneTemp) e "-" remove o campo.
+	Fields map[string]string `json:"fields"`
+	// Units converte campos canônicos para outra unidade: F ou K (°C), kPa ou bar (psi),
+	// inH2O (Pa), Wh ou MJ (kWh).
+	Units map[string]string `json:"units"`
+	// TimestampFormat define o formato do timestamp: rfc3339 (padrão), unix ou unixMs.
+	TimestampFormat string `json:"timestampFormat"`
+}
+
+type unitConversion struct {
+	from    string
+	convert func(float64) float64
+}
+
+var unitConversions = map[string]unitConversion{
+	"F":     {"°C", func(v float64) float64 { return v*9.0/5.0 + 32.0 }},
+	"K":     {"°C", func(v float64) float64 { return v + 273.15 }},
+	"kPa":   {"psi", func(v float64) float64 { return v * 6.894757 }},
+	"bar":   {"psi", func(v float64) float64 { return v * 0.06894757 }},
+	"inH2O": {"Pa", func(v float64) float64 { return v / 249.0889 }},
+	"Wh":    {"kWh", func(v float64) float64 { return v * 1000.0 }},
+	"MJ":    {"kWh", func(v float64) float64 { return v * 3.6 }},
+}
+
+// fieldUnits indexa a unidade canônica de cada campo pelas definições do catálogo de pontos.
+func fieldUnits() map[string]string {
+	units := make(map[string]string)
+	for _, defs := range [][]pointDefinition{basePointDefinitions, g36PointDefinitions, expectedPointDefinitions, intensityPointDefinitions} {
+		for _, def := range defs {
+			units[def.field] = def.unit
+		}
+	}
+	return units
+}
+
+// PayloadConfig reúne os formatos de saída dos registros.
+type PayloadConfig struct {
+	Dialects  map[string]Dialect // Dialetos disponíveis, selecionados por Device.Dialect
+	Templates map[string]string  // Modelos de payload adicionais ou substitutos, selecionados por Device.Template
+	Envelope  *EnvelopeConfig    // Envelope de gateway em volta de cada registro (desativado se nil)
+	Sensors   []WirelessSensor   // Sensores sem fio, para o protocolo de rádio no envelope
+	Batching  *BatchConfig       // Envio em lotes por gateway (desativado se nil)
+	Seed      int64              // Semente das variações do envelope (latência, RSSI)
+	// Transforms é o pipeline de pós-processamento do cenário, aplicado a cada payload antes do
+	// envelope; Hooks são transformações próprias da biblioteca, aplicadas em seguida.
+	Transforms []TransformConfig
+	Hooks      []Transformer
+	// OmitMissing omite as chaves dos campos ausentes (Missingness) em vez de gravá-las como null.
+	// Os modelos de fabricante não passam pela ausência de campos.
+	OmitMissing bool
+}
+
+// PayloadRenderer converte os registros canônicos no modelo de fabricante ou no dialeto
+// configurado para cada dispositivo.
+type PayloadRenderer struct {
+	byDevice   map[string]*Dialect
+	templateOf map[string]*template.Template
+	envelope   *envelopeBuilder
+	batching   *BatchConfig
+
+	omitMissing  bool
+	transformers []Transformer
+}
+
+// NewPayloadRenderer valida os dialetos e modelos e associa cada dispositivo ao seu formato.
+// Device.Template tem precedência sobre Device.Dialect; dispositivos sem nenhum dos dois são
+// emitidos no formato canônico.
+func NewPayloadRenderer(cfg PayloadConfig, devices []Device) (*PayloadRenderer, error) {
+	dialects := cfg.Dialects
+	units := fieldUnits()
+	for name, dialect := range dialects {
+		for field, unit := range dialect.Units {
+			conversion, ok := unitConversions[unit]
+			if !ok {
+				return nil, fmt.Errorf("unidade '%s' não suportada no dialeto '%s'", unit, name)
+			}
+			if units[field] != conversion.from {
+				return nil, fmt.Errorf("campo '%s' do dialeto '%s' não pode ser convertido para '%s'", field, name, unit)
+			}
+		}
+		switch dialect.TimestampFormat {
+		case "", "rfc3339", "unix", "unixMs":
+		default:
+			return nil, fmt.Errorf("formato de timestamp '%s' desconhecido no dialeto '%s'", dialect.TimestampFormat, name)
+		}
+	}
+
+	parsed, err := parsePayloadTemplates(cfg.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PayloadRenderer{byDevice: make(map[string]*Dialect), templateOf: make(map[string]*template.Template), omitMissing: cfg.OmitMissing}
+	for _, device := range devices {
+		if device.Template != "" {
+			tmpl, ok := parsed[device.Template]
+			if !ok {
+				return nil, fmt.Errorf("modelo de payload '%s' do dispositivo '%s' não existe", device.Template, device.ID)
+			}
+			r.templateOf[device.ID] = tmpl
+			continue
+		}
+		if device.Dialect == "" {
+			continue
+		}
+		dialect, ok := dialects[device.Dialect]
+		if !ok {
+			return nil, fmt.Errorf("dialeto '%s' do dispositivo '%s' não foi definido", device.Dialect, device.ID)
+		}
+		r.byDevice[device.ID] = &dialect
+	}
+	if cfg.Envelope != nil {
+		r.envelope = newEnvelopeBuilder(*cfg.Envelope, devices, cfg.Sensors, cfg.Seed)
+	}
+	if cfg.Batching != nil {
+		batching := cfg.Batching.withDefaults()
+		r.batching = &batching
+	}
+	if r.transformers, err = NewTransformers(cfg.Transforms); err != nil {
+		return nil, err
+	}
+	r.transformers = append(r.transformers, cfg.Hooks...)
+	return r, nil
+}
+
+// Render retorna o payload do registro no formato do seu dispositivo, dentro do envelope de
+// gateway quando configurado.
+func (r *PayloadRenderer) Render(record HvacSensorData) (any, error) {
+	payload, err := r.transformedPayload(record)
+	if err != nil || r.envelope == nil {
+		return payload, err
+	}
+	return r.envelope.wrap(record, payload), nil
+}
+
+// Document retorna o payload do registro como objeto JSON, no formato do dispositivo e com o
+// pipeline de transformações e a ausência de campos aplicados, mas sem o envelope de gateway: é o
+// estado do dispositivo, para os sinks que guardam um item por dispositivo.
+func (r *PayloadRenderer) Document(record HvacSensorData) (map[string]any, error) {
+	payload, err := r.transformedPayload(record)
+	if err != nil {
+		return nil, err
+	}
+	if document, ok := payload.(map[string]any); ok {
+		return document, nil
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar o registro de '%s': %w", record.DeviceId, err)
+	}
+	var document map[string]any
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("o payload de '%s' não é um objeto JSON: %w", record.DeviceId, err)
+	}
+	return document, nil
+}
+
+// transformedPayload renderiza o payload do registro e aplica o pipeline de transformações.
+func (r *PayloadRenderer) transformedPayload(record HvacSensorData) (any, error) {
+	payload, err := r.renderPayload(record)
+	if err == nil && len(r.transformers) > 0 {
+		payload, err = applyTransformers(r.transformers, record.DeviceId, payload)
+	}
+	return payload, err
+}
+
+func (r *PayloadRenderer) renderPayload(record HvacSensorData) (any, error) {
+	if tmpl, ok := r.templateOf[record.DeviceId]; ok {
+		return renderTemplate(tmpl, record)
+	}
+	dialect, ok := r.byDevice[record.DeviceId]
+	if !ok && len(record.Missing) == 0 {
+		return record, nil
+	}
+
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar o registro de '%s': %w", record.DeviceId, err)
+	}
+	payload := make(map[string]any)
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("erro ao converter o registro de '%s': %w", record.DeviceId, err)
+	}
+	if !ok {
+		applyMissing(payload, record.Missing, r.omitMissing)
+		return payload, nil
+	}
+
+	for field, unit := range dialect.Units {
+		if v, ok := lookupPath(payload, field).(float64); ok {
+			setPath(payload, field, unitConversions[unit].convert(v))
+		}
+	}
+	switch dialect.TimestampFormat {
+	case "unix":
+		payload["timestamp"] = record.Timestamp.Unix()
+	case "unixMs":
+		payload["timestamp"] = record.Timestamp.UnixMilli()
+	default:
+		payload["timestamp"] = record.Timestamp.Format(time.RFC3339)
+	}
+
+	renameFields(payload, dialect.Fields)
+	applyMissing(payload, dialect.paths(record.Missing), r.omitMissing)
+	return payload, nil
+}
+
+// paths traduz os caminhos canônicos para os do dialeto; campos removidos ficam de fora.
+func (d *Dialect) paths(fields []string) []string {
+	paths := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if target, ok := d.Fields[field]; ok {
+			field = target
+		}
+		if field != "-" {
+			paths = append(paths, field)
+		}
+	}
+	return paths
+}
+
+// WriteJSON serializa os registros aplicando o formato de cada dispositivo e, se configurado,
+// agrupando-os nos lotes enviados pelos gateways.
+func (r *PayloadRenderer) WriteJSON(data []HvacSensorData) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(data) * estimatedJSONRecordBytes)
+	if err := r.EncodeJSON(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeJSON grava em w o mesmo JSON de WriteJSON, um registro por vez. Sem lotes, cada payload é
+// renderizado e codificado logo em seguida, e os registros canônicos são codificados direto, sem
+// cópia nem conversão para mapa.
+func (r *PayloadRenderer) EncodeJSON(w io.Writer, data []HvacSensorData) error {
+	if r.batching != nil {
+		items := make([]batchItem, 0, len(data))
+		for _, record := range data {
+			payload, err := r.Render(record)
+			if err != nil {
+				return err
+			}
+			items = append(items, batchItem{gatewayFor(record.LocationZone), record.Timestamp, payload})
+		}
+		batches := buildBatches(*r.batching, items)
+		if err := encodeJSONArray(w, len(batches), func(i int) (any, error) { return batches[i], nil }); err != nil {
+			return fmt.Errorf("erro ao serializar dados HVAC para JSON: %w", err)
+		}
+		return nil
+	}
+	stream, err := r.NewJSONStream(w)
+	if err != nil {
+		return err
+	}
+	if err := stream.Write(data); err != nil {
+		return err
+	}
+	return stream.Close()
+}
+
+// JSONStream grava o array JSON dos registros em partes, conforme são gerados, com a mesma saída
+// de WriteJSON. Não há lotes: o agrupamento por gateway precisa de todos os registros.
+type JSONStream struct {
+	renderer *PayloadRenderer
+	array    *jsonArrayWriter
+}
+
+// NewJSONStream abre o array JSON em w.
+func (r *PayloadRenderer) NewJSONStream(w io.Writer) (*JSONStream, error) {
+	if r.batching != nil {
+		return nil, fmt.Errorf("o envio em lotes precisa de todos os registros e não pode ser gravado em partes")
+	}
+	return &JSONStream{renderer: r, array: newJSONArrayWriter(w)}, nil
+}
+
+// Write acrescenta os registros ao array.
+func (s *JSONStream) Write(data []HvacSensorData) error {
+	for i := range data {
+		var payload any = &data[i]
+		if !s.renderer.canonical(&data[i]) {
+			var err error
+			if payload, err = s.renderer.Render(data[i]); err != nil {
+				return err
+			}
+		}
+		if err := s.array.add(payload); err != nil {
+			return fmt.Errorf("erro ao serializar dados HVAC para JSON: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close fecha o array e descarrega o buffer em w.
+func (s *JSONStream) Close() error {
+	if err := s.array.close(); err != nil {
+		return fmt.Errorf("erro ao gravar dados HVAC em JSON: %w", err)
+	}
+	return nil
+}
+
+// canonical indica se o registro sai no formato canônico, sem nenhuma conversão do renderizador.
+func (r *PayloadRenderer) canonical(record *HvacSensorData) bool {
+	if r.envelope != nil || len(r.transformers) > 0 || len(record.Missing) > 0 {
+		return false
+	}
+	_, templated := r.templateOf[record.DeviceId]
+	_, dialect := r.byDevice[record.DeviceId]
+	return !templated && !dialect
+}
+
+// WriteSensorsJSON serializa as leituras dos sensores sem fio, dentro do envelope de gateway
+// e agrupadas em lotes quando configurado.
+func (r *PayloadRenderer) WriteSensorsJSON(readings []WirelessSensorReading) ([]byte, error) {
+	items := make([]batchItem, 0, len(readings))
+	for _, reading := range readings {
+		var payload any = reading
+		if r.envelope != nil {
+			payload = r.envelope.wrapSensor(reading)
+		}
+		items = append(items, batchItem{gatewayFor(reading.LocationZone), reading.Timestamp, payload})
+	}
+	jsonData, err := json.MarshalIndent(r.messages(items), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar leituras dos sensores sem fio para JSON: %w", err)
+	}
+	return jsonData, nil
+}
+
+// StreamMessage é uma mensagem pronta para um sink de streaming: a chave de particionamento
+// (deviceId, ou gatewayId nos lotes), a zona de origem e o JSON compacto.
+type StreamMessage struct {
+	Key     string
+	Zone    string
+	Payload []byte
+}
+
+// StreamMessages renderiza os registros como mensagens individuais de streaming, com o mesmo
+// formato (dialetos, modelos, envelope e lotes) do arquivo JSON.
+func (r *PayloadRenderer) StreamMessages(data []HvacSensorData) ([]StreamMessage, error) {
+	items := make([]batchItem, 0, len(data))
+	zoneOf := make(map[string]string)
+	for _, record := range data {
+		payload, err := r.Render(record)
+		if err != nil {
+			return nil, err
+		}
+		gatewayId := gatewayFor(record.LocationZone)
+		items = append(items, batchItem{gatewayId, record.Timestamp, payload})
+		zoneOf[gatewayId] = record.LocationZone
+	}
+
+	var messages []StreamMessage
+	if r.batching != nil {
+		for _, batch := range buildBatches(*r.batching, items) {
+			payload, err := json.Marshal(batch)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao serializar o lote '%s': %w", batch.BatchId, err)
+			}
+			messages = append(messages, StreamMessage{Key: batch.GatewayId, Zone: zoneOf[batch.GatewayId], Payload: payload})
+		}
+		return messages, nil
+	}
+	for i, item := range items {
+		payload, err := json.Marshal(item.payload)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao serializar o registro de '%s': %w", data[i].DeviceId, err)
+		}
+		messages = append(messages, StreamMessage{Key: data[i].DeviceId, Zone: data[i].LocationZone, Payload: payload})
+	}
+	return messages, nil
+}
+
+// messages retorna as mensagens finais: os lotes dos gateways ou uma mensagem por leitura.
+func (r *PayloadRenderer) messages(items []batchItem) any {
+	if r.batching != nil {
+		return buildBatches(*r.batching, items)
+	}
+	payloads := make([]any, 0, len(items))
+	for _, item := range items {
+		payloads = append(payloads, item.payload)
+	}
+	return payloads
+}
+
+func lookupPath(payload map[string]any, path string) any {
+	parts := strings.Split(path, ".")
+	current := payload
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return current[parts[len(parts)-1]]
+}
+
+func setPath(payload map[string]any, path string, value any) {
+	parts := strings.Split(path, ".")
+	current := payload
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
+
+func deletePath(payload map[string]any, path string) {
+	parts := strings.Split(path, ".")
+	current := payload
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]any)
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, parts[len(parts)-1])
+}
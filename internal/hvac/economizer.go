@@ -0,0 +1,165 @@
+package hvac
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// Lógicas de limite alto do economizador (ASHRAE 90.1, tabela 6.5.1.1.3).
+const (
+	HighLimitFixedDryBulb         = "fixedDryBulb"         // Bloqueia com o ar externo acima de um limite fixo, que depende da zona climática
+	HighLimitDifferentialDryBulb  = "differentialDryBulb"  // Bloqueia com o ar externo mais quente que o de retorno
+	HighLimitFixedEnthalpy        = "fixedEnthalpy"        // Bloqueia com a entalpia externa acima de 47 kJ/kg ou o ar acima de 23.9 °C
+	HighLimitDifferentialEnthalpy = "differentialEnthalpy" // Bloqueia com a entalpia externa acima da de retorno ou o ar acima de 23.9 °C
+)
+
+// EconomizerConfig liga o economizador de ar externo das unidades: em resfriamento, o damper
+// abre para resfriar com ar externo enquanto o limite alto da zona climática permite, e o
+// compressor só completa o que o ar externo não entrega.
+type EconomizerConfig struct {
+	ClimateZone      string  `json:"climateZone"`      // Zona climática ASHRAE 169 (1A a 8, padrão: 2A)
+	HighLimit        string  `json:"highLimit"`        // Lógica de limite alto (padrão: fixedEnthalpy nas zonas úmidas 1A a 4A, differentialDryBulb nas demais)
+	MinOutdoorAirPct float64 `json:"minOutdoorAirPct"` // Abertura mínima do damper de ar externo para renovação (%, padrão: 20)
+	SupplyAirTemp    float64 `json:"supplyAirTemp"`    // Temperatura de mistura buscada pelo damper (°C, padrão: 13)
+}
+
+const (
+	economizerFixedDryBulbMax = 23.9 // Limite fixo de bulbo seco das zonas secas e dos limites de entalpia (°C, 75 °F)
+	economizerEnthalpyLimit   = 47.0 // Limite fixo de entalpia (kJ/kg, 28 Btu/lb)
+	returnAirHumidity         = 50.0 // Umidade relativa admitida no ar de retorno (%)
+)
+
+func (c EconomizerConfig) withDefaults() EconomizerConfig {
+	if c.ClimateZone == "" {
+		c.ClimateZone = "2A"
+	}
+	if c.HighLimit == "" {
+		c.HighLimit = HighLimitDifferentialDryBulb
+		if humidClimateZone(c.ClimateZone) {
+			c.HighLimit = HighLimitFixedEnthalpy
+		}
+	}
+	if c.MinOutdoorAirPct == 0 {
+		c.MinOutdoorAirPct = 20.0
+	}
+	if c.SupplyAirTemp == 0 {
+		c.SupplyAirTemp = 13.0
+	}
+	return c
+}
+
+func (c EconomizerConfig) validate() error {
+	if len(c.ClimateZone) == 0 || c.ClimateZone[0] < '1' || c.ClimateZone[0] > '8' || len(c.ClimateZone) > 2 ||
+		(len(c.ClimateZone) == 2 && (c.ClimateZone[1] < 'A' || c.ClimateZone[1] > 'C')) {
+		return fmt.Errorf("zona climática '%s' inválida (use 1A a 8, ex: 2A, 3B, 4C)", c.ClimateZone)
+	}
+	switch c.HighLimit {
+	case HighLimitFixedDryBulb, HighLimitFixedEnthalpy, HighLimitDifferentialEnthalpy:
+	case HighLimitDifferentialDryBulb:
+		if humidClimateZone(c.ClimateZone) {
+			return fmt.Errorf("limite alto differentialDryBulb não é permitido na zona climática úmida %s", c.ClimateZone)
+		}
+	default:
+		return fmt.Errorf("limite alto do economizador '%s' desconhecido (use fixedDryBulb, differentialDryBulb, fixedEnthalpy ou differentialEnthalpy)", c.HighLimit)
+	}
+	if c.MinOutdoorAirPct < 0 || c.MinOutdoorAirPct > 100 {
+		return fmt.Errorf("abertura mínima do damper deve estar entre 0 e 100%%, recebido %.1f", c.MinOutdoorAirPct)
+	}
+	return nil
+}
+
+// humidClimateZone indica as zonas quentes e úmidas (1A a 4A), onde o bulbo seco diferencial não
+// protege contra o ar externo úmido.
+func humidClimateZone(zone string) bool {
+	return len(zone) == 2 && zone[1] == 'A' && zone[0] >= '1' && zone[0] <= '4'
+}
+
+// fixedDryBulbLimit é o limite fixo de bulbo seco da zona climática (°C).
+func fixedDryBulbLimit(zone string) float64 {
+	switch {
+	case humidClimateZone(zone):
+		return 18.3 // 65 °F
+	case zone == "5A" || zone == "6A":
+		return 21.1 // 70 °F
+	}
+	return economizerFixedDryBulbMax
+}
+
+// EconomizerPoints são os pontos do economizador no passo.
+type EconomizerPoints struct {
+	HighLimit           string  `json:"highLimit"`                     // Lógica de limite alto em uso
+	Lockout             bool    `json:"lockout"`                       // Economizador bloqueado pelo limite alto
+	LockoutReason       string  `json:"lockoutReason,omitempty"`       // DRY_BULB, ENTHALPY, DIFFERENTIAL_DRY_BULB ou DIFFERENTIAL_ENTHALPY
+	Active              bool    `json:"active"`                        // Damper aberto além do mínimo para resfriar com ar externo
+	OutdoorAirDamperPct float64 `json:"outdoorAirDamperPct"`           // Abertura do damper de ar externo (%)
+	OutdoorAirEnthalpy  float64 `json:"outdoorAirEnthalpy"`            // Entalpia do ar externo (kJ/kg)
+	ReturnAirEnthalpy   float64 `json:"returnAirEnthalpy"`             // Entalpia do ar de retorno (kJ/kg)
+	FreeCoolingFraction float64 `json:"freeCoolingFraction,omitempty"` // Fração do resfriamento entregue pelo ar externo
+}
+
+// lockout aplica o limite alto e retorna o motivo do bloqueio, ou "".
+func (c EconomizerConfig) lockout(outdoorTemp, outdoorEnthalpy, returnTemp, returnEnthalpy float64) string {
+	switch c.HighLimit {
+	case HighLimitFixedDryBulb:
+		if outdoorTemp > fixedDryBulbLimit(c.ClimateZone) {
+			return "DRY_BULB"
+		}
+	case HighLimitDifferentialDryBulb:
+		if outdoorTemp > returnTemp {
+			return "DIFFERENTIAL_DRY_BULB"
+		}
+	case HighLimitFixedEnthalpy:
+		if outdoorTemp > economizerFixedDryBulbMax {
+			return "DRY_BULB"
+		}
+		if outdoorEnthalpy > economizerEnthalpyLimit {
+			return "ENTHALPY"
+		}
+	case HighLimitDifferentialEnthalpy:
+		if outdoorTemp > economizerFixedDryBulbMax {
+			return "DRY_BULB"
+		}
+		if outdoorEnthalpy > returnEnthalpy {
+			return "DIFFERENTIAL_ENTHALPY"
+		}
+	}
+	return ""
+}
+
+// economizerPoints decide o economizador no passo. Em resfriamento e sem bloqueio, o damper mistura
+// o ar externo para chegar a SupplyAirTemp; com o ar externo acima dela, abre totalmente e o
+// compressor completa. Retorna a fração do resfriamento entregue pelo ar externo.
+func (c EconomizerConfig) economizerPoints(systemStatus string, outdoorTemp, outdoorHumidity, returnTemp, pressure float64) *EconomizerPoints {
+	points := &EconomizerPoints{
+		HighLimit:          c.HighLimit,
+		OutdoorAirEnthalpy: climate.Enthalpy(outdoorTemp, outdoorHumidity, pressure),
+		ReturnAirEnthalpy:  climate.Enthalpy(returnTemp, returnAirHumidity, pressure),
+	}
+	points.LockoutReason = c.lockout(outdoorTemp, points.OutdoorAirEnthalpy, returnTemp, points.ReturnAirEnthalpy)
+	points.Lockout = points.LockoutReason != ""
+	if fanRunning(systemStatus) {
+		points.OutdoorAirDamperPct = c.MinOutdoorAirPct
+	}
+	if systemStatus != "COOLING" || points.Lockout || outdoorTemp >= returnTemp || returnTemp <= c.SupplyAirTemp {
+		return points
+	}
+
+	mix := math.Min(1.0, (returnTemp-c.SupplyAirTemp)/(returnTemp-outdoorTemp)) // Fração de ar externo na mistura
+	points.OutdoorAirDamperPct = math.Max(c.MinOutdoorAirPct, mix*100.0)
+	points.FreeCoolingFraction = math.Min(1.0, (returnTemp-outdoorTemp)/(returnTemp-c.SupplyAirTemp))
+	points.Active = points.OutdoorAirDamperPct > c.MinOutdoorAirPct
+	return points
+}
+
+// economizerFanShare é a parcela do consumo em resfriamento que não vem do compressor (ventilador).
+const economizerFanShare = 0.1
+
+// shiftEconomizer reduz o consumo esperado do passo pelo resfriamento gratuito.
+func (e *ExpectedValues) shiftEconomizer(freeCooling float64) {
+	factor := 1.0 - freeCooling*(1.0-economizerFanShare)
+	e.PowerConsumptionKwH.Expected *= factor
+	e.PowerConsumptionKwH.Min *= factor
+	e.PowerConsumptionKwH.Max *= factor
+}
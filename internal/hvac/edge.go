@@ -0,0 +1,185 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Tipos das mensagens do gateway de borda.
+const (
+	EdgeReading   = "READING"   // Leitura recebida do dispositivo (fluxo local)
+	EdgeChange    = "CHANGE"    // Leitura encaminhada por mudança além da banda morta (fluxo para a nuvem)
+	EdgeHeartbeat = "HEARTBEAT" // Leitura encaminhada sem mudança, para indicar que o dispositivo segue vivo
+	EdgeSummary   = "SUMMARY"   // Agregado da janela encaminhado no lugar das leituras
+)
+
+// EdgeConfig simula um gateway de borda (ex: AWS IoT Greengrass) que recebe as leituras dos
+// dispositivos e encaminha para a nuvem só parte delas: as que mudaram (report by exception) ou
+// agregados por janela.
+type EdgeConfig struct {
+	GatewayId           string  `json:"gatewayId"`           // Gateway único que agrega todos os dispositivos (padrão: um por zona, GW-<zona>)
+	Forward             string  `json:"forward"`             // exception (padrão): leituras que mudaram e heartbeats; summary: agregados por janela
+	TemperatureDeadband float64 `json:"temperatureDeadband"` // Variação da temperatura interna ou do setpoint que leva ao encaminhamento (°C, padrão: 0.5)
+	HeartbeatMinutes    int     `json:"heartbeatMinutes"`    // Intervalo máximo sem encaminhar leituras de um dispositivo (min, padrão: 360)
+	SummaryMinutes      int     `json:"summaryMinutes"`      // Janela dos agregados no modo summary (min, padrão: 360)
+}
+
+func (c EdgeConfig) withDefaults() EdgeConfig {
+	if c.Forward == "" {
+		c.Forward = "exception"
+	}
+	if c.TemperatureDeadband == 0 {
+		c.TemperatureDeadband = 0.5
+	}
+	if c.HeartbeatMinutes == 0 {
+		c.HeartbeatMinutes = 360
+	}
+	if c.SummaryMinutes == 0 {
+		c.SummaryMinutes = 360
+	}
+	return c
+}
+
+func (c EdgeConfig) validate() error {
+	switch c.Forward {
+	case "exception", "summary":
+	default:
+		return fmt.Errorf("modo de encaminhamento '%s' desconhecido (use exception ou summary)", c.Forward)
+	}
+	if c.TemperatureDeadband < 0 || c.HeartbeatMinutes < 0 || c.SummaryMinutes < 0 {
+		return fmt.Errorf("banda morta, heartbeat e janela dos agregados não podem ser negativos")
+	}
+	return nil
+}
+
+// EdgeMessage é uma mensagem de um dos lados do gateway de borda.
+type EdgeMessage struct {
+	GatewayId string    `json:"gatewayId"`         // Gateway que recebeu ou encaminhou a mensagem
+	Type      string    `json:"type"`              // READING, CHANGE, HEARTBEAT ou SUMMARY
+	Timestamp time.Time `json:"timestamp"`         // Instante da leitura ou, no SUMMARY, fim da janela
+	DeviceId  string    `json:"deviceId"`          // Dispositivo de origem
+	Changed   []string  `json:"changed,omitempty"` // CHANGE: campos que levaram ao encaminhamento
+	Payload   any       `json:"payload"`           // Leitura no formato do dispositivo ou, no SUMMARY, o agregado da janela
+}
+
+// EdgeStreams são os dois lados do gateway: tudo o que chegou dos dispositivos (local) e o que foi
+// encaminhado para a nuvem (cloud).
+type EdgeStreams struct {
+	Local []EdgeMessage
+	Cloud []EdgeMessage
+}
+
+// edgeState é o último estado encaminhado de um dispositivo.
+type edgeState struct {
+	forwardedAt         time.Time
+	internalTemperature float64
+	setPointTemperature float64
+	systemStatus        string
+	occupancyStatus     bool
+	faultCode           string
+}
+
+// EdgeStreams simula o gateway de borda sobre os registros, na ordem da série. As leituras saem no
+// formato de cada dispositivo, como no arquivo de dados.
+func (r *PayloadRenderer) EdgeStreams(cfg EdgeConfig, data []HvacSensorData) (EdgeStreams, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return EdgeStreams{}, err
+	}
+	gatewayOf := func(zone string) string {
+		if cfg.GatewayId != "" {
+			return cfg.GatewayId
+		}
+		return gatewayFor(zone)
+	}
+
+	streams := EdgeStreams{Local: make([]EdgeMessage, 0, len(data))}
+	heartbeat := time.Duration(cfg.HeartbeatMinutes) * time.Minute
+	forwarded := make(map[string]*edgeState)
+	for _, record := range data {
+		payload, err := r.Render(record)
+		if err != nil {
+			return EdgeStreams{}, err
+		}
+		message := EdgeMessage{
+			GatewayId: gatewayOf(record.LocationZone),
+			Type:      EdgeReading,
+			Timestamp: record.Timestamp,
+			DeviceId:  record.DeviceId,
+			Payload:   payload,
+		}
+		streams.Local = append(streams.Local, message)
+		if cfg.Forward != "exception" {
+			continue
+		}
+
+		last, seen := forwarded[record.DeviceId]
+		switch changed := edgeChanges(last, record, cfg.TemperatureDeadband); {
+		case !seen || len(changed) > 0:
+			message.Type, message.Changed = EdgeChange, changed
+		case heartbeat > 0 && record.Timestamp.Sub(last.forwardedAt) >= heartbeat:
+			message.Type = EdgeHeartbeat
+		default:
+			continue
+		}
+		forwarded[record.DeviceId] = &edgeState{
+			forwardedAt:         record.Timestamp,
+			internalTemperature: record.InternalTemperature,
+			setPointTemperature: record.SetPointTemperature,
+			systemStatus:        record.SystemStatus,
+			occupancyStatus:     record.OccupancyStatus,
+			faultCode:           record.FaultCode,
+		}
+		streams.Cloud = append(streams.Cloud, message)
+	}
+
+	if cfg.Forward == "summary" {
+		window := time.Duration(cfg.SummaryMinutes) * time.Minute
+		for _, rollup := range BuildRollups(data, window) {
+			streams.Cloud = append(streams.Cloud, EdgeMessage{
+				GatewayId: gatewayOf(rollup.LocationZone),
+				Type:      EdgeSummary,
+				Timestamp: rollup.WindowStart.Add(window),
+				DeviceId:  rollup.DeviceId,
+				Payload:   rollup,
+			})
+		}
+	}
+	return streams, nil
+}
+
+// edgeChanges lista os campos do registro que mudaram desde o último encaminhamento: a temperatura
+// interna e o setpoint além da banda morta e qualquer mudança de modo, ocupação ou falha.
+func edgeChanges(last *edgeState, record HvacSensorData, deadband float64) []string {
+	if last == nil {
+		return nil
+	}
+	var changed []string
+	if math.Abs(record.InternalTemperature-last.internalTemperature) >= deadband {
+		changed = append(changed, "internalTemperature")
+	}
+	if math.Abs(record.SetPointTemperature-last.setPointTemperature) >= deadband {
+		changed = append(changed, "setPointTemperature")
+	}
+	if record.SystemStatus != last.systemStatus {
+		changed = append(changed, "systemStatus")
+	}
+	if record.OccupancyStatus != last.occupancyStatus {
+		changed = append(changed, "occupancyStatus")
+	}
+	if record.FaultCode != last.faultCode {
+		changed = append(changed, "faultCode")
+	}
+	return changed
+}
+
+// WriteEdgeStreamJSON serializa as mensagens de um dos lados do gateway de borda.
+func WriteEdgeStreamJSON(messages []EdgeMessage) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar as mensagens do gateway de borda para JSON: %w", err)
+	}
+	return jsonData, nil
+}
@@ -0,0 +1,107 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// EnergyBalanceConfig liga o acompanhamento do balanço de energia de cada zona: o calor armazenado
+// na massa térmica das salas deve bater com o que entrou pela envoltória e pelos ganhos internos e
+// com o que o equipamento retirou ou adicionou.
+type EnergyBalanceConfig struct {
+	Tolerance float64 `json:"tolerance"` // Resíduo diário tolerado, como fração do fluxo total de energia da zona (padrão: 0.5)
+}
+
+func (c EnergyBalanceConfig) withDefaults() EnergyBalanceConfig {
+	if c.Tolerance == 0 {
+		c.Tolerance = 0.5
+	}
+	return c
+}
+
+// ZoneEnergyBalance é o balanço de energia de uma zona em um dia, em kWh térmicos. Valores
+// positivos aquecem a zona: Stored ≈ Envelope + InternalGains + Hvac, e Residual é a diferença.
+type ZoneEnergyBalance struct {
+	Zone             string    `json:"zone"`
+	Day              time.Time `json:"day"`
+	StoredKwh        float64   `json:"storedKwh"`        // Variação do calor armazenado na massa térmica das salas
+	EnvelopeKwh      float64   `json:"envelopeKwh"`      // Troca de calor pela envoltória
+	InternalGainsKwh float64   `json:"internalGainsKwh"` // Ganhos internos com as salas ocupadas
+	HvacKwh          float64   `json:"hvacKwh"`          // Calor retirado (negativo) ou adicionado pelo equipamento, incluindo a purga noturna
+	ResidualKwh      float64   `json:"residualKwh"`      // Energia sem origem: Stored − (Envelope + InternalGains + Hvac)
+	Violated         bool      `json:"violated"`         // Resíduo acima da tolerância
+}
+
+type zoneDay struct {
+	zone string
+	day  time.Time
+}
+
+// recordEnergyBalance acumula no balanço da zona os fluxos de energia do passo de um dispositivo.
+func (s *Simulator) recordEnergyBalance(device *deviceState, t time.Time, hours, outdoorTemp, previousTemp, finalTemp float64, occupied bool, systemStatus string, runtimeFraction, powerConsumption float64) {
+	key := zoneDay{device.Zone, time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())}
+	balance, ok := s.energyBalances[key]
+	if !ok {
+		balance = &ZoneEnergyBalance{Zone: key.zone, Day: key.day}
+		s.energyBalances[key] = balance
+	}
+
+	averageTemp := (previousTemp + finalTemp) / 2
+	balance.StoredKwh += device.thermalMass() * (finalTemp - previousTemp)
+	balance.EnvelopeKwh += envelopeUaKwPerK * (outdoorTemp - averageTemp) * hours
+	if occupied {
+		balance.InternalGainsKwh += occupiedGainsKw * hours
+	}
+
+	switch systemStatus {
+	case "COOLING", "HEATING", "PRE_COOLING":
+		delivered := powerConsumption * ratedCop // Sem fração de compressor (pré-resfriamento), estima pela potência elétrica
+		if runtimeFraction > 0 {
+			capacity := device.CapacityKw
+			if systemStatus == "HEATING" {
+				capacity *= heatingCapacityRatio
+			}
+			delivered = runtimeFraction * capacity * hours
+		}
+		if systemStatus == "HEATING" {
+			balance.HvacKwh += delivered
+		} else {
+			balance.HvacKwh -= delivered
+		}
+	case "NIGHT_PURGE":
+		// Só ar externo: a renovação troca calor como uma envoltória extra
+		balance.HvacKwh += outdoorAirLoadKwPerK * (outdoorTemp - averageTemp) * hours
+	}
+}
+
+// EnergyBalance retorna o balanço de energia diário de cada zona desde o início da simulação,
+// ordenado por zona e dia, com EnergyBalanceConfig ativo.
+func (s *Simulator) EnergyBalance() []ZoneEnergyBalance {
+	balances := make([]ZoneEnergyBalance, 0, len(s.energyBalances))
+	for _, balance := range s.energyBalances {
+		b := *balance
+		b.ResidualKwh = b.StoredKwh - (b.EnvelopeKwh + b.InternalGainsKwh + b.HvacKwh)
+		throughput := math.Abs(b.EnvelopeKwh) + b.InternalGainsKwh + math.Abs(b.HvacKwh)
+		b.Violated = math.Abs(b.ResidualKwh) > s.energyBalance.Tolerance*math.Max(throughput, 1.0)
+		balances = append(balances, b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].Zone != balances[j].Zone {
+			return balances[i].Zone < balances[j].Zone
+		}
+		return balances[i].Day.Before(balances[j].Day)
+	})
+	return balances
+}
+
+// WriteEnergyBalanceJSON serializa os balanços de energia das zonas.
+func WriteEnergyBalanceJSON(balances []ZoneEnergyBalance) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(balances, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar o balanço de energia para JSON: %w", err)
+	}
+	return jsonData, nil
+}
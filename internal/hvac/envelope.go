@@ -0,0 +1,138 @@
+package hvac
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// EnvelopeConfig envolve cada registro em um envelope de gateway, com metadados de transporte
+// separados do instante da medição.
+type EnvelopeConfig struct {
+	Protocol              string  `json:"protocol"`              // Protocolo dos dispositivos sem Device.Protocol (padrão: BACnet/IP)
+	MeanLatencySeconds    float64 `json:"meanLatencySeconds"`    // Atraso médio entre a medição e a chegada ao gateway (s, padrão: 2)
+	BatteryDrainPctPerDay float64 `json:"batteryDrainPctPerDay"` // Descarga da bateria dos dispositivos sem fio (%/dia, padrão: 0.05)
+}
+
+// Envelope é a mensagem entregue pelo gateway, com o payload do dispositivo dentro.
+type Envelope struct {
+	GatewayId      string    `json:"gatewayId"`                // Gateway que recebeu a leitura (um por zona)
+	Protocol       string    `json:"protocol"`                 // Protocolo entre o dispositivo e o gateway
+	ReceivedAt     time.Time `json:"receivedAt"`               // Instante de chegada ao gateway (após o timestamp da medição)
+	Rssi           *float64  `json:"rssi,omitempty"`           // Intensidade do sinal de rádio (dBm), apenas dispositivos sem fio
+	BatteryPercent *float64  `json:"batteryPercent,omitempty"` // Carga da bateria (%), apenas dispositivos sem fio
+	Payload        any       `json:"payload"`                  // Registro no formato do dispositivo
+}
+
+// wirelessProtocols são os protocolos de rádio, que acrescentam RSSI e bateria ao envelope.
+var wirelessProtocols = map[string]bool{
+	"LoRaWAN": true,
+	"Zigbee":  true,
+	"BLE":     true,
+	"EnOcean": true,
+	"Wi-Fi":   true,
+	"Thread":  true,
+}
+
+func (c EnvelopeConfig) withDefaults() EnvelopeConfig {
+	if c.Protocol == "" {
+		c.Protocol = "BACnet/IP"
+	}
+	if c.MeanLatencySeconds == 0 {
+		c.MeanLatencySeconds = 2.0
+	}
+	if c.BatteryDrainPctPerDay == 0 {
+		c.BatteryDrainPctPerDay = 0.05
+	}
+	return c
+}
+
+// deviceLink guarda o enlace de um dispositivo com o seu gateway.
+type deviceLink struct {
+	gatewayId string
+	protocol  string
+	wireless  bool
+	baseRssi  float64   // Sinal médio, definido pela distância até o gateway (dBm)
+	firstSeen time.Time // Primeira leitura, referência para a descarga da bateria
+}
+
+// envelopeBuilder monta os envelopes de gateway de cada dispositivo.
+type envelopeBuilder struct {
+	cfg   EnvelopeConfig
+	rng   *rand.Rand
+	links map[string]*deviceLink
+}
+
+func newEnvelopeBuilder(cfg EnvelopeConfig, devices []Device, sensors []WirelessSensor, seed int64) *envelopeBuilder {
+	b := &envelopeBuilder{
+		cfg:   cfg.withDefaults(),
+		rng:   rand.New(rand.NewSource(seed)),
+		links: make(map[string]*deviceLink),
+	}
+	zones := make(map[string]string, len(devices))
+	for _, device := range devices {
+		b.link(device.ID, device.Zone, device.Protocol)
+		zones[device.ID] = device.Zone
+	}
+	for _, sensor := range sensors {
+		b.link(sensor.ID, zones[sensor.Device], sensor.withDefaults().Protocol)
+	}
+	return b
+}
+
+// link retorna o enlace do dispositivo, criando-o na primeira leitura se não foi configurado.
+func (b *envelopeBuilder) link(deviceId, zone, protocol string) *deviceLink {
+	if l, ok := b.links[deviceId]; ok {
+		return l
+	}
+	if protocol == "" {
+		protocol = b.cfg.Protocol
+	}
+	l := &deviceLink{
+		gatewayId: gatewayFor(zone),
+		protocol:  protocol,
+		wireless:  wirelessProtocols[protocol],
+		baseRssi:  -60.0 - b.rng.Float64()*30.0,
+	}
+	b.links[deviceId] = l
+	return l
+}
+
+// wrap envolve o payload de um registro no envelope do gateway.
+func (b *envelopeBuilder) wrap(record HvacSensorData, payload any) Envelope {
+	return b.envelope(record.DeviceId, record.LocationZone, record.Timestamp, nil, payload)
+}
+
+// wrapSensor envolve a leitura de um sensor sem fio, com a carga de bateria medida pelo sensor.
+func (b *envelopeBuilder) wrapSensor(reading WirelessSensorReading) Envelope {
+	return b.envelope(reading.SensorId, reading.LocationZone, reading.Timestamp, &reading.BatteryPercent, reading)
+}
+
+// envelope monta o envelope de uma leitura. Sem carga informada, a bateria dos dispositivos
+// sem fio descarrega a partir da primeira leitura.
+func (b *envelopeBuilder) envelope(id, zone string, timestamp time.Time, battery *float64, payload any) Envelope {
+	l := b.link(id, zone, "")
+	latency := b.rng.ExpFloat64() * b.cfg.MeanLatencySeconds
+	if l.wireless {
+		latency += 0.5 + b.rng.Float64() // Janela de transmissão do rádio
+	}
+	envelope := Envelope{
+		GatewayId:  l.gatewayId,
+		Protocol:   l.protocol,
+		ReceivedAt: timestamp.Add(time.Duration(latency * float64(time.Second))),
+		Payload:    payload,
+	}
+	if l.wireless {
+		if l.firstSeen.IsZero() {
+			l.firstSeen = timestamp
+		}
+		rssi := math.Round(l.baseRssi + b.rng.NormFloat64()*3.0)
+		if battery == nil {
+			days := timestamp.Sub(l.firstSeen).Hours() / 24.0
+			drained := math.Round(math.Max(0, 100.0-days*b.cfg.BatteryDrainPctPerDay)*10) / 10
+			battery = &drained
+		}
+		envelope.Rssi, envelope.BatteryPercent = &rssi, battery
+	}
+	return envelope
+}
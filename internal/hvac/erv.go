@@ -0,0 +1,82 @@
+package hvac
+
+import "math"
+
+// ERVConfig liga um recuperador de calor (HRV/ERV) em cada sala: o ar externo de renovação troca
+// calor com o ar de exaustão antes de entrar, com bypass para resfriamento gratuito e proteção
+// contra congelamento no frio.
+type ERVConfig struct {
+	Effectiveness      float64 `json:"effectiveness"`      // Efetividade sensível nominal do trocador (0 a 1, padrão: 0.7)
+	AirflowLps         float64 `json:"airflowLps"`         // Vazão de ar externo (L/s, padrão: 150)
+	FanKw              float64 `json:"fanKw"`              // Potência dos ventiladores de insuflamento e exaustão (kW, padrão: 0.15)
+	BypassMinTemp      float64 `json:"bypassMinTemp"`      // Temperatura externa mínima para o bypass de resfriamento gratuito (°C, padrão: 12)
+	FrostThresholdTemp float64 `json:"frostThresholdTemp"` // Temperatura mínima do ar de exaustão na saída do trocador (°C, padrão: -1)
+	Schedule           string  `json:"schedule"`           // occupied (padrão): liga com a sala ocupada; always: ventilação contínua
+}
+
+func (c ERVConfig) withDefaults() ERVConfig {
+	if c.Effectiveness == 0 {
+		c.Effectiveness = 0.7
+	}
+	if c.AirflowLps == 0 {
+		c.AirflowLps = 150.0
+	}
+	if c.FanKw == 0 {
+		c.FanKw = 0.15
+	}
+	if c.BypassMinTemp == 0 {
+		c.BypassMinTemp = 12.0
+	}
+	if c.FrostThresholdTemp == 0 {
+		c.FrostThresholdTemp = -1.0
+	}
+	if c.Schedule == "" {
+		c.Schedule = "occupied"
+	}
+	return c
+}
+
+// ERVPoints são os pontos do recuperador de calor da sala no passo.
+type ERVPoints struct {
+	Running         bool    `json:"running"`                   // Recuperador ligado
+	Mode            string  `json:"mode"`                      // OFF, RECOVERY, BYPASS ou FROST_PROTECTION
+	OutdoorAirTemp  float64 `json:"outdoorAirTemp"`            // Ar externo na entrada do trocador (°C)
+	SupplyAirTemp   float64 `json:"supplyAirTemp"`             // Ar externo após o trocador, insuflado na sala (°C)
+	ReturnAirTemp   float64 `json:"returnAirTemp"`             // Ar da sala na entrada do lado de exaustão (°C)
+	ExhaustAirTemp  float64 `json:"exhaustAirTemp"`            // Ar de exaustão na saída do trocador (°C)
+	Effectiveness   float64 `json:"effectiveness"`             // Efetividade sensível efetiva no passo (0 com bypass)
+	RecoveredKw     float64 `json:"recoveredKw"`               // Calor recuperado do ar de exaustão (kW, negativo quando o trocador resfria o ar externo)
+	FanEnergyKwh    float64 `json:"fanEnergyKwh"`              // Consumo dos ventiladores do recuperador no período (kWh)
+	BypassActive    bool    `json:"bypassActive,omitempty"`    // Bypass do trocador aberto para resfriamento gratuito
+	FrostProtection bool    `json:"frostProtection,omitempty"` // Efetividade reduzida para não congelar o lado de exaustão
+}
+
+const airHeatCapacityKwPerLps = 1.2 * 1.006 / 1000 // ρ·cp do ar (kW por L/s e por K)
+
+// ervPoints calcula o recuperador da sala no passo. O bypass abre quando o ar externo, acima de
+// BypassMinTemp, está mais frio que a sala e ela não está aquecendo; a proteção contra
+// congelamento reduz a efetividade para manter a exaustão acima de FrostThresholdTemp.
+func (c ERVConfig) ervPoints(systemStatus string, occupied bool, outdoorTemp, roomTemp, hours float64) *ERVPoints {
+	points := &ERVPoints{Mode: "OFF", OutdoorAirTemp: outdoorTemp, SupplyAirTemp: outdoorTemp, ReturnAirTemp: roomTemp, ExhaustAirTemp: roomTemp}
+	if c.Schedule == "occupied" && !occupied {
+		return points
+	}
+	points.Running = true
+	points.Mode = "RECOVERY"
+	points.FanEnergyKwh = c.FanKw * hours
+
+	effectiveness := c.Effectiveness
+	if outdoorTemp >= c.BypassMinTemp && outdoorTemp < roomTemp && systemStatus != "HEATING" {
+		effectiveness = 0
+		points.Mode, points.BypassActive = "BYPASS", true
+	} else if exhaust := roomTemp - effectiveness*(roomTemp-outdoorTemp); exhaust < c.FrostThresholdTemp && roomTemp > outdoorTemp {
+		effectiveness = math.Max(0, (roomTemp-c.FrostThresholdTemp)/(roomTemp-outdoorTemp))
+		points.Mode, points.FrostProtection = "FROST_PROTECTION", true
+	}
+
+	points.Effectiveness = effectiveness
+	points.SupplyAirTemp = outdoorTemp + effectiveness*(roomTemp-outdoorTemp)
+	points.ExhaustAirTemp = roomTemp - effectiveness*(roomTemp-outdoorTemp)
+	points.RecoveredKw = c.AirflowLps * airHeatCapacityKwPerLps * (points.SupplyAirTemp - outdoorTemp)
+	return points
+}
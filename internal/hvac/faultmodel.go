@@ -0,0 +1,269 @@
+package hvac
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// FaultState identifica o dispositivo e o passo consultados pelo modelo de falhas.
+type FaultState struct {
+	DeviceID  string
+	Zone      string
+	Timestamp time.Time
+	Mode      string  // Modo de operação do passo (vazio em Condition, decidido depois)
+	Stress    float64 // Estresse do evento climático extremo em curso (0 a 1)
+}
+
+// EquipmentCondition é o estado de conservação do equipamento no passo.
+type EquipmentCondition struct {
+	Health     float64 // Saúde do compressor, de 0.4 (degradado) a 1 (novo)
+	FilterClog float64 // Colmatação do filtro, de 0 (limpo) a 1 (obstruído)
+}
+
+// FaultModel define a confiabilidade do equipamento: o desgaste a cada passo (que pesa no consumo
+// e na pressão dos dutos) e os alarmes emitidos em faultCode. Alarmes físicos, como pressão de
+// dutos alta ou desarme por subtensão, continuam a cargo do simulador.
+type FaultModel interface {
+	// Condition é chamado no início do passo, antes da decisão de controle.
+	Condition(state FaultState, rng *rand.Rand) EquipmentCondition
+	// Alarm retorna o código de alarme do passo, ou "" quando não há.
+	Alarm(state FaultState, condition EquipmentCondition, rng *rand.Rand) string
+}
+
+// SeasonalFaultModel é o modelo padrão: a saúde cai e o filtro entope ao longo do ano até a
+// manutenção preventiva de setembro, e os alarmes de compressor e filtro são sorteados pelo desgaste.
+type SeasonalFaultModel struct {
+	AlarmRate float64 // Multiplicador da taxa de alarmes (1 mantém, 0 desliga)
+}
+
+// Condition implementa FaultModel.
+func (m SeasonalFaultModel) Condition(state FaultState, rng *rand.Rand) EquipmentCondition {
+	month := state.Timestamp.Month()
+	floatMonth := float64(month)
+
+	var equipmentHealth float64
+	var currentFilterClogLevel float64
+
+	if month == time.September {
+		equipmentHealth = 0.8 + (rng.Float64() * 0.2)
+		currentFilterClogLevel = rng.Float64() * 0.05
+	} else if month > time.September {
+		equipmentHealth = 0.8 - ((floatMonth - 9.0) / 3.0 * 0.2)
+		currentFilterClogLevel = 0.05 + ((floatMonth - 9.0) / 3.0 * 0.4)
+	} else {
+		equipmentHealth = 1.0 - (floatMonth / 9.0 * 0.4)
+		currentFilterClogLevel = floatMonth / 9.0 * 0.8
+	}
+
+	equipmentHealth += (rng.Float64() - 0.5) * 0.1
+	equipmentHealth -= state.Stress * 0.3 // Eventos extremos aceleram o desgaste e as falhas
+	equipmentHealth = math.Max(0.4, math.Min(1.0, equipmentHealth))
+	currentFilterClogLevel += (rng.Float64() - 0.5) * 0.1
+	currentFilterClogLevel = math.Max(0.0, math.Min(1.0, currentFilterClogLevel))
+	return EquipmentCondition{Health: equipmentHealth, FilterClog: currentFilterClogLevel}
+}
+
+// Alarm implementa FaultModel.
+func (m SeasonalFaultModel) Alarm(state FaultState, condition EquipmentCondition, rng *rand.Rand) string {
+	alarm := ""
+	alarmThreshold := 1.0 - (1.0-condition.Health)*m.AlarmRate
+	if state.Mode == "COOLING" && rng.Float64() > alarmThreshold {
+		alarm = "HP-AL-01"
+	} else if state.Mode == "HEATING" && rng.Float64() > alarmThreshold {
+		alarm = "HT-FL-02"
+	}
+	if condition.FilterClog > 0.8 && rng.Float64() > 1.0-0.5*m.AlarmRate {
+		alarm = "FP-AL-01"
+	}
+	return alarm
+}
+
+// ReliabilityFaultModel sorteia quebras a partir de estatísticas de confiabilidade do cliente: o
+// tempo até a falha segue uma exponencial com média MTBFHours e o reparo leva MTTRHours. Durante
+// o reparo o dispositivo reporta um dos Codes e opera degradado. O desgaste sazonal do filtro e
+// da saúde continua vindo do modelo padrão, sem os seus alarmes sorteados.
+type ReliabilityFaultModel struct {
+	MTBFHours float64  // Tempo médio entre falhas (h)
+	MTTRHours float64  // Tempo médio de reparo (h, padrão: 24)
+	Codes     []string // Códigos sorteados a cada quebra (padrão: HP-AL-01)
+
+	devices map[string]*reliabilityState
+}
+
+type reliabilityState struct {
+	nextFailure time.Time
+	repairUntil time.Time
+	code        string
+}
+
+// NewReliabilityFaultModel cria o modelo com o tempo médio entre falhas e de reparo informados.
+func NewReliabilityFaultModel(mtbfHours, mttrHours float64, codes ...string) (*ReliabilityFaultModel, error) {
+	if mtbfHours <= 0 {
+		return nil, fmt.Errorf("mtbfHours deve ser positivo, recebido %.1f", mtbfHours)
+	}
+	if mttrHours <= 0 {
+		mttrHours = 24
+	}
+	if len(codes) == 0 {
+		codes = []string{"HP-AL-01"}
+	}
+	return &ReliabilityFaultModel{
+		MTBFHours: mtbfHours,
+		MTTRHours: mttrHours,
+		Codes:     codes,
+		devices:   make(map[string]*reliabilityState),
+	}, nil
+}
+
+// Condition implementa FaultModel.
+func (m *ReliabilityFaultModel) Condition(state FaultState, rng *rand.Rand) EquipmentCondition {
+	condition := SeasonalFaultModel{}.Condition(state, rng)
+	device, ok := m.devices[state.DeviceID]
+	if !ok {
+		device = &reliabilityState{nextFailure: state.Timestamp.Add(m.exponential(m.MTBFHours, rng))}
+		m.devices[state.DeviceID] = device
+	}
+	if !state.Timestamp.Before(device.nextFailure) && !state.Timestamp.Before(device.repairUntil) {
+		// Nova quebra: reparo a partir de agora e próxima falha contada após o reparo
+		device.code = m.Codes[rng.Intn(len(m.Codes))]
+		device.repairUntil = state.Timestamp.Add(m.exponential(m.MTTRHours, rng))
+		device.nextFailure = device.repairUntil.Add(m.exponential(m.MTBFHours, rng))
+	}
+	if state.Timestamp.Before(device.repairUntil) {
+		condition.Health = 0.4
+	}
+	return condition
+}
+
+// Alarm implementa FaultModel.
+func (m *ReliabilityFaultModel) Alarm(state FaultState, condition EquipmentCondition, rng *rand.Rand) string {
+	if device, ok := m.devices[state.DeviceID]; ok && state.Timestamp.Before(device.repairUntil) {
+		return device.code
+	}
+	return ""
+}
+
+func (m *ReliabilityFaultModel) exponential(meanHours float64, rng *rand.Rand) time.Duration {
+	return time.Duration(rng.ExpFloat64() * meanHours * float64(time.Hour))
+}
+
+// FaultLogEntry é um alarme registrado num log real de falhas.
+type FaultLogEntry struct {
+	DeviceID  string
+	Start     time.Time
+	End       time.Time
+	FaultCode string
+}
+
+// ReadFaultLogCSV lê um log de falhas com o cabeçalho deviceId,start,end,faultCode e instantes em
+// RFC 3339. Um end vazio mantém o alarme até o fim da série.
+func ReadFaultLogCSV(r io.Reader) ([]FaultLogEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o cabeçalho do log de falhas: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"deviceId", "start", "end", "faultCode"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("coluna '%s' ausente no log de falhas", name)
+		}
+	}
+
+	var entries []FaultLogEntry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler a linha %d do log de falhas: %w", line, err)
+		}
+		entry := FaultLogEntry{
+			DeviceID:  record[columns["deviceId"]],
+			FaultCode: record[columns["faultCode"]],
+		}
+		if entry.Start, err = time.Parse(time.RFC3339, record[columns["start"]]); err != nil {
+			return nil, fmt.Errorf("início inválido na linha %d do log de falhas: %w", line, err)
+		}
+		if raw := record[columns["end"]]; raw != "" {
+			if entry.End, err = time.Parse(time.RFC3339, raw); err != nil {
+				return nil, fmt.Errorf("fim inválido na linha %d do log de falhas: %w", line, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// FaultLogReplay reproduz um log real de falhas: cada dispositivo reporta os alarmes registrados
+// no período em que estiveram ativos, e opera degradado enquanto isso. Nenhum alarme é sorteado.
+type FaultLogReplay struct {
+	byDevice map[string][]FaultLogEntry
+}
+
+// NewFaultLogReplay indexa o log por dispositivo.
+func NewFaultLogReplay(entries []FaultLogEntry) *FaultLogReplay {
+	replay := &FaultLogReplay{byDevice: make(map[string][]FaultLogEntry)}
+	for _, entry := range entries {
+		replay.byDevice[entry.DeviceID] = append(replay.byDevice[entry.DeviceID], entry)
+	}
+	return replay
+}
+
+// Condition implementa FaultModel.
+func (m *FaultLogReplay) Condition(state FaultState, rng *rand.Rand) EquipmentCondition {
+	condition := SeasonalFaultModel{}.Condition(state, rng)
+	if m.active(state) != "" {
+		condition.Health = 0.4
+	}
+	return condition
+}
+
+// Alarm implementa FaultModel.
+func (m *FaultLogReplay) Alarm(state FaultState, condition EquipmentCondition, rng *rand.Rand) string {
+	return m.active(state)
+}
+
+// active retorna o último alarme do log ativo no instante, ou "".
+func (m *FaultLogReplay) active(state FaultState) string {
+	code := ""
+	for _, entry := range m.byDevice[state.DeviceID] {
+		if !state.Timestamp.Before(entry.Start) && (entry.End.IsZero() || state.Timestamp.Before(entry.End)) {
+			code = entry.FaultCode
+		}
+	}
+	return code
+}
+
+// FaultModelConfig escolhe o modelo de falhas no arquivo de cenário.
+type FaultModelConfig struct {
+	Type      string   `json:"type"`      // seasonal (padrão), reliability ou replay
+	MTBFHours float64  `json:"mtbfHours"` // reliability: tempo médio entre falhas (h)
+	MTTRHours float64  `json:"mttrHours"` // reliability: tempo médio de reparo (h, padrão: 24)
+	Codes     []string `json:"codes"`     // reliability: códigos sorteados a cada quebra (padrão: HP-AL-01)
+	LogFile   string   `json:"logFile"`   // replay: CSV com deviceId,start,end,faultCode
+}
+
+// NewFaultModel cria o modelo de falhas configurado. O log só é usado no tipo replay.
+func NewFaultModel(cfg FaultModelConfig, log []FaultLogEntry) (FaultModel, error) {
+	switch cfg.Type {
+	case "", "seasonal":
+		return SeasonalFaultModel{AlarmRate: 1.0}, nil
+	case "reliability":
+		return NewReliabilityFaultModel(cfg.MTBFHours, cfg.MTTRHours, cfg.Codes...)
+	case "replay":
+		return NewFaultLogReplay(log), nil
+	}
+	return nil, fmt.Errorf("modelo de falhas '%s' desconhecido (use seasonal, reliability ou replay)", cfg.Type)
+}
@@ -0,0 +1,177 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Tipos de falha injetáveis por cenário.
+const (
+	FaultSimultaneousHeatCool = "SIMULTANEOUS_HEAT_COOL" // Reaquecimento ligado enquanto a unidade resfria a mesma sala
+	FaultDamperStuckOpen      = "DAMPER_STUCK_OPEN"      // Damper de ar externo travado aberto: excesso de ventilação
+	FaultDamperStuckClosed    = "DAMPER_STUCK_CLOSED"    // Damper de ar externo travado fechado: CO2 acumula
+	FaultAirflowDegradation   = "AIRFLOW_DEGRADATION"    // Correia patinando ou serpentina obstruída: vazão de ar cai
+	FaultCondenserFouling     = "CONDENSER_FOULING"      // Condensador sujo: pressão de descarga sobe com o calor externo
+)
+
+// Date aceita datas no formato AAAA-MM-DD (ou RFC 3339) nos arquivos de cenário.
+type Date struct {
+	time.Time
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("data deve ser uma string: %w", err)
+	}
+	if raw == "" {
+		d.Time = time.Time{}
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			d.Time = t
+			return nil
+		}
+	}
+	return fmt.Errorf("data inválida '%s': use AAAA-MM-DD ou RFC 3339", raw)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return json.Marshal("")
+	}
+	return json.Marshal(d.Format("2006-01-02"))
+}
+
+// FaultScenario injeta uma falha em um dispositivo durante um período, com severidade que pode
+// crescer gradualmente (rampDays) para simular degradação.
+type FaultScenario struct {
+	Type     string  `json:"type"`     // Tipo da falha (ex: SIMULTANEOUS_HEAT_COOL)
+	Start    Date    `json:"start"`    // Início da falha (vazio: início da série)
+	End      Date    `json:"end"`      // Fim da falha (vazio: até o fim da série)
+	Severity float64 `json:"severity"` // Severidade máxima de 0 a 1 (padrão: 1)
+	RampDays float64 `json:"rampDays"` // Dias até atingir a severidade máxima (0: imediata)
+}
+
+// severityAt retorna a severidade da falha no instante t (0 quando inativa).
+func (f FaultScenario) severityAt(t time.Time) float64 {
+	if !f.Start.IsZero() && t.Before(f.Start.Time) {
+		return 0
+	}
+	if !f.End.IsZero() && !t.Before(f.End.Time) {
+		return 0
+	}
+	severity := f.Severity
+	if severity == 0 {
+		severity = 1.0
+	}
+	if f.RampDays > 0 && !f.Start.IsZero() {
+		elapsedDays := t.Sub(f.Start.Time).Hours() / 24.0
+		severity *= math.Min(1.0, elapsedDays/f.RampDays)
+	}
+	return math.Max(0, math.Min(1.0, severity))
+}
+
+// activeFaults calcula a severidade de cada tipo de falha configurado no dispositivo para o passo.
+func (d *deviceState) activeFaults(t time.Time) map[string]float64 {
+	if len(d.Faults) == 0 {
+		return nil
+	}
+	active := make(map[string]float64)
+	for _, f := range d.Faults {
+		if repairedAt, ok := d.repairedFaults[f.Type]; ok && !t.Before(repairedAt) && f.Start.Before(repairedAt) {
+			continue // Falha encerrada por uma ordem de serviço
+		}
+		if severity := f.severityAt(t); severity > 0 {
+			active[f.Type] = math.Max(active[f.Type], severity)
+		}
+	}
+	return active
+}
+
+// faultLabels lista os tipos de falha ativos, na ordem configurada, como rótulo de verdade para FDD.
+func (d *deviceState) faultLabels(active map[string]float64) []string {
+	var labels []string
+	seen := make(map[string]bool)
+	for _, f := range d.Faults {
+		if active[f.Type] > 0 && !seen[f.Type] {
+			labels = append(labels, f.Type)
+			seen[f.Type] = true
+		}
+	}
+	return labels
+}
+
+const reheatPowerKw = 2.5 // Potência do reaquecimento em falha com severidade máxima (kW)
+
+// simultaneousHeatCool força o resfriamento quando o reaquecimento aquece a sala ocupada e
+// devolve a energia desperdiçada (reaquecimento + compressor removendo esse calor).
+func simultaneousHeatCool(severity float64, systemStatus string, occupied bool) (string, float64) {
+	if severity == 0 || !occupied || systemStatus == "HEATING" || systemStatus == "OFF" {
+		return systemStatus, 0
+	}
+	reheat := reheatPowerKw * severity
+	return "COOLING", reheat + reheat/ratedCop
+}
+
+const (
+	outdoorAirLoadKwPerK = 0.8    // Carga sensível extra com 100% de ar externo (kW/K)
+	outdoorAirLatentKw   = 3.0    // Carga latente extra com ar externo saturado (kW)
+	co2BuildupPpmPerHour = 450.0  // Acúmulo de CO2 por hora com a sala ocupada e sem renovação
+	co2Ceiling           = 3000.0 // Limite de acúmulo de CO2 (ppm)
+	outdoorCO2           = 420.0
+)
+
+// outdoorAirLoad é a carga extra (kW térmicos) imposta pelo damper travado aberto que o
+// compressor precisa remover: ar externo quente e úmido no verão, frio no inverno. Só ventilando,
+// a carga não custa energia.
+func outdoorAirLoad(severity float64, systemStatus string, outdoorTemp, humidity, internalTemp float64) float64 {
+	if severity == 0 || !compressorRunning(systemStatus) {
+		return 0
+	}
+	load := outdoorAirLoadKwPerK * math.Abs(outdoorTemp-internalTemp)
+	if outdoorTemp > internalTemp && humidity > 60.0 {
+		load += (humidity - 60.0) / 40.0 * outdoorAirLatentKw
+	}
+	return load * severity
+}
+
+// stuckClosedCO2 acumula CO2 na sala enquanto o damper está fechado e ela está ocupada,
+// decaindo lentamente por infiltração quando desocupada.
+func (d *deviceState) stuckClosedCO2(severity float64, occupied bool, hours float64) float64 {
+	level := d.lastCO2
+	if level < outdoorCO2 {
+		level = 600.0
+	}
+	if occupied {
+		level += co2BuildupPpmPerHour * severity * hours
+	} else {
+		level -= (level - outdoorCO2) * 0.15 * hours
+	}
+	return math.Min(co2Ceiling, level)
+}
+
+const maxAirflowLoss = 0.6 // Perda de vazão com severidade máxima
+
+// airflowFraction é a vazão de ar relativa à nominal com a degradação (correia, serpentina).
+func airflowFraction(severity float64) float64 {
+	return 1.0 - maxAirflowLoss*severity
+}
+
+const (
+	foulingReferenceTemp    = 20.0  // Temperatura externa a partir da qual a sujeira no condensador pesa (°C)
+	foulingPressurePerK     = 5.0   // Aumento extra da pressão de descarga por °C acima da referência (psi)
+	foulingPowerPerK        = 0.035 // Aumento relativo extra do consumo por °C acima da referência
+	highPressureCutoutPsi   = 230.0 // Pressostato de alta: desarma o compressor
+	highPressureTripRuntime = 0.5   // Fração do período com o compressor desarmado após o trip
+)
+
+// condenserFouling retorna a pressão extra (psi) e o fator de consumo extra do condensador sujo,
+// que crescem com a temperatura externa mais rápido do que em um equipamento limpo.
+func condenserFouling(severity, outdoorTemp float64) (float64, float64) {
+	excess := math.Max(0, outdoorTemp-foulingReferenceTemp) * severity
+	return excess * foulingPressurePerK, 1.0 + excess*foulingPowerPerK
+}
@@ -0,0 +1,190 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// FeatureConfig ativa o conjunto de dados complementar com atributos derivados das leituras, para
+// modelos de ML de referência e como documentação do significado dos campos brutos.
+type FeatureConfig struct {
+	WindowsHours    []int    `json:"windowsHours"`    // Janelas das médias móveis (h, padrão: 3 e 24)
+	Fields          []string `json:"fields"`          // Campos das médias móveis (padrão: temperaturas interna, externa e de insuflamento e consumo)
+	BaseTemperature float64  `json:"baseTemperature"` // Temperatura base dos graus-hora (°C, padrão: 18)
+	Location        string   `json:"location"`        // Fuso horário da meia-noite dos graus-hora (padrão: America/Sao_Paulo)
+}
+
+var defaultFeatureFields = []string{"internalTemperature", "outdoorTemperature", "supplyAirTemperature", "powerConsumptionKwH"}
+
+func (c FeatureConfig) withDefaults() FeatureConfig {
+	if len(c.WindowsHours) == 0 {
+		c.WindowsHours = []int{3, 24}
+	}
+	if len(c.Fields) == 0 {
+		c.Fields = defaultFeatureFields
+	}
+	if c.BaseTemperature == 0 {
+		c.BaseTemperature = 18.0
+	}
+	if c.Location == "" {
+		c.Location = "America/Sao_Paulo"
+	}
+	return c
+}
+
+// FeatureRecord são os atributos derivados de uma leitura, calculados só com ela e as leituras
+// anteriores do mesmo dispositivo. Atributos que dependem de um campo ausente (Missingness) ficam
+// de fora.
+type FeatureRecord struct {
+	Timestamp time.Time `json:"timestamp"` // Instante da leitura
+	DeviceId  string    `json:"deviceId"`  // Dispositivo
+
+	// RollingMeans são as médias das leituras dos últimos N h, incluindo a atual, por "<campo>_<N>h".
+	RollingMeans map[string]float64 `json:"rollingMeans"`
+
+	SetpointDelta             *float64 `json:"setpointDelta,omitempty"`             // internalTemperature − setPointTemperature: positivo pede resfriamento (°C)
+	SupplyReturnDelta         *float64 `json:"supplyReturnDelta,omitempty"`         // returnAirTemperature − supplyAirTemperature: positivo resfria a sala (°C)
+	OutdoorIndoorDelta        *float64 `json:"outdoorIndoorDelta,omitempty"`        // outdoorTemperature − internalTemperature: a carga térmica pelo envelope (°C)
+	InternalTemperatureChange *float64 `json:"internalTemperatureChange,omitempty"` // Variação de internalTemperature desde a leitura anterior (°C)
+	PowerChange               *float64 `json:"powerChange,omitempty"`               // Variação de powerConsumptionKwH desde a leitura anterior (kWh)
+
+	SystemStatus       string  `json:"systemStatus"`       // Modo de operação da leitura
+	ModeRuntimeHours   float64 `json:"modeRuntimeHours"`   // Horas no modo atual desde a última troca de modo
+	CoolingDegreeHours float64 `json:"coolingDegreeHours"` // Graus-hora de resfriamento desde a meia-noite local (°C·h acima da base)
+	HeatingDegreeHours float64 `json:"heatingDegreeHours"` // Graus-hora de aquecimento desde a meia-noite local (°C·h abaixo da base)
+}
+
+// BuildFeatures calcula os atributos derivados de cada leitura, na ordem dos registros.
+func BuildFeatures(cfg FeatureConfig, data []HvacSensorData) ([]FeatureRecord, error) {
+	cfg = cfg.withDefaults()
+	location, err := time.LoadLocation(cfg.Location)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar o fuso horário '%s' dos atributos derivados: %w", cfg.Location, err)
+	}
+	known := recordFieldPaths()
+	for _, field := range cfg.Fields {
+		if !known[field] {
+			return nil, fmt.Errorf("campo '%s' desconhecido nas médias móveis", field)
+		}
+	}
+	for _, hours := range cfg.WindowsHours {
+		if hours <= 0 {
+			return nil, fmt.Errorf("janela das médias móveis deve ser positiva, recebido %d h", hours)
+		}
+	}
+
+	byDevice := make(map[string][]int)
+	for i, record := range data {
+		byDevice[record.DeviceId] = append(byDevice[record.DeviceId], i)
+	}
+	features := make([]FeatureRecord, len(data))
+	for _, indexes := range byDevice {
+		sort.SliceStable(indexes, func(a, b int) bool { return data[indexes[a]].Timestamp.Before(data[indexes[b]].Timestamp) })
+		series := make([]map[string]float64, len(indexes))
+		for i, index := range indexes {
+			series[i] = presentValues(&data[index])
+		}
+		var previous *HvacSensorData
+		var modeHours, cooling, heating float64
+		for i, index := range indexes {
+			record := &data[index]
+			values := series[i]
+			feature := FeatureRecord{Timestamp: record.Timestamp, DeviceId: record.DeviceId, SystemStatus: record.SystemStatus, RollingMeans: make(map[string]float64)}
+
+			for _, hours := range cfg.WindowsHours {
+				since := record.Timestamp.Add(-time.Duration(hours) * time.Hour)
+				for _, field := range cfg.Fields {
+					sum, n := 0.0, 0
+					for j := i; j >= 0 && data[indexes[j]].Timestamp.After(since); j-- {
+						if v, ok := series[j][field]; ok {
+							sum += v
+							n++
+						}
+					}
+					if n > 0 {
+						feature.RollingMeans[fmt.Sprintf("%s_%dh", field, hours)] = sum / float64(n)
+					}
+				}
+			}
+			feature.SetpointDelta = difference(values, "internalTemperature", "setPointTemperature")
+			feature.SupplyReturnDelta = difference(values, "returnAirTemperature", "supplyAirTemperature")
+			feature.OutdoorIndoorDelta = difference(values, "outdoorTemperature", "internalTemperature")
+
+			local := record.Timestamp.In(location)
+			midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+			elapsed := 0.0
+			if previous != nil {
+				elapsed = record.Timestamp.Sub(previous.Timestamp).Hours()
+				feature.InternalTemperatureChange = change(values, series[i-1], "internalTemperature")
+				feature.PowerChange = change(values, series[i-1], "powerConsumptionKwH")
+				if previous.SystemStatus == record.SystemStatus {
+					modeHours += elapsed
+				} else {
+					modeHours = 0
+				}
+				if previous.Timestamp.Before(midnight) {
+					cooling, heating = 0, 0
+					elapsed = record.Timestamp.Sub(midnight).Hours()
+				}
+			}
+			if outdoor, ok := values["outdoorTemperature"]; ok {
+				cooling += math.Max(0, outdoor-cfg.BaseTemperature) * elapsed
+				heating += math.Max(0, cfg.BaseTemperature-outdoor) * elapsed
+			}
+			feature.ModeRuntimeHours = modeHours
+			feature.CoolingDegreeHours, feature.HeatingDegreeHours = cooling, heating
+
+			features[index] = feature
+			previous = record
+		}
+	}
+	return features, nil
+}
+
+// presentValues indexa os campos numéricos reportados na leitura, sem os ausentes.
+func presentValues(record *HvacSensorData) map[string]float64 {
+	values := make(map[string]float64)
+	for _, field := range recordFloatFields(record) {
+		if _, ok := values[field.path]; !ok {
+			values[field.path] = *field.value
+		}
+	}
+	for _, missing := range record.Missing {
+		delete(values, missing)
+	}
+	return values
+}
+
+// difference retorna a − b, ou nil se algum dos campos não foi reportado.
+func difference(values map[string]float64, a, b string) *float64 {
+	va, okA := values[a]
+	vb, okB := values[b]
+	if !okA || !okB {
+		return nil
+	}
+	d := va - vb
+	return &d
+}
+
+// change retorna a variação do campo entre duas leituras, ou nil se alguma delas não o reportou.
+func change(current, previous map[string]float64, field string) *float64 {
+	a, okA := current[field]
+	b, okB := previous[field]
+	if !okA || !okB {
+		return nil
+	}
+	d := a - b
+	return &d
+}
+
+// WriteFeaturesJSON serializa os atributos derivados.
+func WriteFeaturesJSON(features []FeatureRecord) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(features, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar os atributos derivados para JSON: %w", err)
+	}
+	return jsonData, nil
+}
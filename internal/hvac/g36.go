@@ -0,0 +1,166 @@
+package hvac
+
+import "math"
+
+// G36Config ativa a sequência simplificada do ASHRAE Guideline 36: cada zona (LocationZone) é
+// atendida por uma AHU cujos setpoints de insuflamento e de pressão estática são ajustados por
+// Trim & Respond a partir das requisições das salas (caixas VAV).
+type G36Config struct {
+	MinSupplyAirTemp   float64 `json:"minSupplyAirTemp"`   // Limite inferior do setpoint de insuflamento (°C, padrão: 12.8)
+	MaxSupplyAirTemp   float64 `json:"maxSupplyAirTemp"`   // Limite superior do setpoint de insuflamento (°C, padrão: 18.3)
+	MinStaticPressure  float64 `json:"minStaticPressure"`  // Limite inferior do setpoint de pressão estática (Pa, padrão: 8)
+	MaxStaticPressure  float64 `json:"maxStaticPressure"`  // Limite superior do setpoint de pressão estática (Pa, padrão: 18)
+	IgnoredRequests    int     `json:"ignoredRequests"`    // Requisições ignoradas antes de responder (padrão: 2)
+	SupplyTempTrim     float64 `json:"supplyTempTrim"`     // Acréscimo do setpoint de insuflamento a cada passo sem demanda (°C, padrão: 0.3)
+	SupplyTempRespond  float64 `json:"supplyTempRespond"`  // Redução por requisição excedente (°C, padrão: -0.4)
+	SupplyTempMaxStep  float64 `json:"supplyTempMaxStep"`  // Resposta máxima por passo (°C, padrão: -1.2)
+	PressureTrim       float64 `json:"pressureTrim"`       // Redução do setpoint de pressão a cada passo sem demanda (Pa, padrão: -0.4)
+	PressureRespond    float64 `json:"pressureRespond"`    // Aumento por requisição excedente (Pa, padrão: 0.6)
+	PressureMaxStep    float64 `json:"pressureMaxStep"`    // Resposta máxima por passo (Pa, padrão: 1.8)
+	DamperRequestLimit float64 `json:"damperRequestLimit"` // Abertura do damper que gera requisição de pressão (%, padrão: 95)
+}
+
+// G36Points são os pontos da sequência G36 emitidos junto de cada leitura.
+type G36Points struct {
+	AhuId                        string  `json:"ahuId"`                        // AHU que atende a sala
+	SupplyAirTempSetpoint        float64 `json:"supplyAirTempSetpoint"`        // Setpoint de insuflamento da AHU após Trim & Respond (°C)
+	DuctStaticPressureSetpointPa float64 `json:"ductStaticPressureSetpointPa"` // Setpoint de pressão estática da AHU (Pa)
+	DamperPositionPct            float64 `json:"damperPositionPct"`            // Abertura do damper da caixa VAV (%)
+	ZoneCoolingRequests          int     `json:"zoneCoolingRequests"`          // Requisições de resfriamento geradas pela sala neste passo
+	ZonePressureRequests         int     `json:"zonePressureRequests"`         // Requisições de pressão estática geradas pela sala neste passo
+	AhuCoolingRequests           int     `json:"ahuCoolingRequests"`           // Requisições de resfriamento consideradas pela AHU neste passo
+	AhuPressureRequests          int     `json:"ahuPressureRequests"`          // Requisições de pressão consideradas pela AHU neste passo
+}
+
+func (c G36Config) withDefaults() G36Config {
+	if c.MinSupplyAirTemp == 0 {
+		c.MinSupplyAirTemp = 12.8
+	}
+	if c.MaxSupplyAirTemp == 0 {
+		c.MaxSupplyAirTemp = 18.3
+	}
+	if c.MinStaticPressure == 0 {
+		c.MinStaticPressure = 8.0
+	}
+	if c.MaxStaticPressure == 0 {
+		c.MaxStaticPressure = 18.0
+	}
+	if c.IgnoredRequests == 0 {
+		c.IgnoredRequests = 2
+	}
+	if c.SupplyTempTrim == 0 {
+		c.SupplyTempTrim = 0.3
+	}
+	if c.SupplyTempRespond == 0 {
+		c.SupplyTempRespond = -0.4
+	}
+	if c.SupplyTempMaxStep == 0 {
+		c.SupplyTempMaxStep = -1.2
+	}
+	if c.PressureTrim == 0 {
+		c.PressureTrim = -0.4
+	}
+	if c.PressureRespond == 0 {
+		c.PressureRespond = 0.6
+	}
+	if c.PressureMaxStep == 0 {
+		c.PressureMaxStep = 1.8
+	}
+	if c.DamperRequestLimit == 0 {
+		c.DamperRequestLimit = 95.0
+	}
+	return c
+}
+
+// airHandler guarda os setpoints de uma AHU e as requisições acumuladas no passo.
+type airHandler struct {
+	id                     string
+	supplyAirTempSetpoint  float64
+	staticPressureSetpoint float64
+	coolingRequests        int // Requisições recebidas no passo corrente
+	pressureRequests       int
+	lastCoolingRequests    int // Requisições consideradas no último Trim & Respond
+	lastPressureRequests   int
+}
+
+func newAirHandler(id string, cfg *G36Config) *airHandler {
+	return &airHandler{
+		id:                     id,
+		supplyAirTempSetpoint:  cfg.MaxSupplyAirTemp,
+		staticPressureSetpoint: cfg.MinStaticPressure,
+	}
+}
+
+// trimAndRespond ajusta os setpoints com base nas requisições do passo anterior e zera os contadores.
+func (a *airHandler) trimAndRespond(cfg *G36Config) {
+	a.supplyAirTempSetpoint = trimRespond(a.supplyAirTempSetpoint, a.coolingRequests, cfg.IgnoredRequests,
+		cfg.SupplyTempTrim, cfg.SupplyTempRespond, cfg.SupplyTempMaxStep, cfg.MinSupplyAirTemp, cfg.MaxSupplyAirTemp)
+	a.staticPressureSetpoint = trimRespond(a.staticPressureSetpoint, a.pressureRequests, cfg.IgnoredRequests,
+		cfg.PressureTrim, cfg.PressureRespond, cfg.PressureMaxStep, cfg.MinStaticPressure, cfg.MaxStaticPressure)
+
+	a.lastCoolingRequests, a.lastPressureRequests = a.coolingRequests, a.pressureRequests
+	a.coolingRequests, a.pressureRequests = 0, 0
+}
+
+func trimRespond(setpoint float64, requests, ignored int, trim, respond, maxStep, min, max float64) float64 {
+	if requests > ignored {
+		change := float64(requests-ignored) * respond
+		if math.Abs(change) > math.Abs(maxStep) {
+			change = maxStep
+		}
+		setpoint += change
+	} else {
+		setpoint += trim
+	}
+	return math.Max(min, math.Min(max, setpoint))
+}
+
+// zoneCoolingRequests segue a tabela de requisições de resfriamento da zona no G36.
+func zoneCoolingRequests(zoneTemp, setPoint float64, systemStatus string) int {
+	diff := zoneTemp - setPoint
+	switch {
+	case diff >= 3.0:
+		return 3
+	case diff >= 2.0:
+		return 2
+	case systemStatus == "COOLING" && diff > 0.3:
+		return 1
+	}
+	return 0
+}
+
+// damperPosition estima a abertura do damper da caixa VAV pela carga térmica da sala.
+func damperPosition(load float64, systemStatus string) float64 {
+	if systemStatus != "COOLING" {
+		return 20.0 // Vazão mínima de ventilação
+	}
+	return math.Min(100.0, 30.0+load*25.0)
+}
+
+// g36Points registra as requisições da sala na AHU e monta os pontos emitidos na leitura.
+func (s *Simulator) g36Points(device *deviceState, load, zoneTemp, setPoint float64, systemStatus string) *G36Points {
+	ahu := device.airHandler
+	if ahu == nil {
+		return nil
+	}
+
+	damper := damperPosition(load, systemStatus)
+	coolingRequests := zoneCoolingRequests(zoneTemp, setPoint, systemStatus)
+	pressureRequests := 0
+	if damper > s.g36.DamperRequestLimit {
+		pressureRequests = 1
+	}
+	ahu.coolingRequests += coolingRequests
+	ahu.pressureRequests += pressureRequests
+
+	return &G36Points{
+		AhuId:                        ahu.id,
+		SupplyAirTempSetpoint:        ahu.supplyAirTempSetpoint,
+		DuctStaticPressureSetpointPa: ahu.staticPressureSetpoint,
+		DamperPositionPct:            damper,
+		ZoneCoolingRequests:          coolingRequests,
+		ZonePressureRequests:         pressureRequests,
+		AhuCoolingRequests:           ahu.lastCoolingRequests,
+		AhuPressureRequests:          ahu.lastPressureRequests,
+	}
+}
@@ -1,10 +1,9 @@
 package hvac
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"math"
-	"math/rand"
 	"time"
 
 	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
@@ -14,11 +13,14 @@ type HvacSensorData struct {
 	Timestamp              time.Time `json:"timestamp"`              // Momento exato em que os dados foram coletados
 	InternalTemperature    float64   `json:"internalTemperature"`    // Temperatura interna medida dentro do espaço (°C)
 	SetPointTemperature    float64   `json:"setPointTemperature"`    // Temperatura alvo configurada para o sistema HVAC manter (°C)
-	SystemStatus           string    `json:"systemStatus"`           // Estado operacional do sistema: OFF, COOLING, HEATING, FAN_ONLY ou IDLE
+	SystemStatus           string    `json:"systemStatus"`           // Estado operacional do sistema: OFF, COOLING, HEATING, FAN_ONLY, IDLE, NIGHT_PURGE, PRE_COOLING ou STARTUP
 	OccupancyStatus        bool      `json:"occupancyStatus"`        // Indica se o espaço está ocupado (true) ou desocupado (false)
 	PowerConsumptionKwH    float64   `json:"powerConsumptionKwH"`    // Consumo de energia elétrica do sistema no período (kWh)
 	OutdoorTemperature     float64   `json:"outdoorTemperature"`     // Temperatura do ar externo (°C)
 	OutdoorHumidity        float64   `json:"outdoorHumidity"`        // Umidade relativa do ar externo (%)
+	OutdoorDewPoint        float64   `json:"outdoorDewPoint"`        // Ponto de orvalho do ar externo (°C)
+	OutdoorWetBulb         float64   `json:"outdoorWetBulb"`         // Temperatura de bulbo úmido do ar externo (°C)
+	OutdoorEnthalpy        float64   `json:"outdoorEnthalpy"`        // Entalpia do ar externo (kJ/kg de ar seco)
 	DeviceId               string    `json:"deviceId"`               // Identificador único do dispositivo ou unidade HVAC (ex: HVAC-UNIT-1)
 	SupplyAirTemperature   float64   `json:"supplyAirTemperature"`   // Temperatura do ar de saída do sistema (°C)
 	ReturnAirTemperature   float64   `json:"returnAirTemperature"`   // Temperatura do ar de retorno para o sistema (°C)
@@ -28,63 +30,142 @@ type HvacSensorData struct {
 	FaultCode              string    `json:"faultCode"`              // Código de falha, se houver
 	AssetModel             string    `json:"assetModel"`             // Modelo do equipamento ou ativo
 	LocationZone           string    `json:"locationZone"`           // Zona ou localização do dispositivo
+	StationId              string    `json:"stationId,omitempty"`    // Estação meteorológica de onde vêm as condições externas (ex: A701)
+	RunId                  string    `json:"runId,omitempty"`        // Execução do gerador que produziu o registro, quando recordRunId está ligado
+	ExtremeEvent           string    `json:"extremeEvent,omitempty"` // Evento climático extremo em curso, se houver
+
+	OutdoorTemperatureForecast   []climate.Forecast `json:"outdoorTemperatureForecast,omitempty"`   // Previsões da temperatura externa (°C)
+	G36                          *G36Points         `json:"g36,omitempty"`                          // Pontos da sequência G36, quando ativa
+	Expected                     *ExpectedValues    `json:"expected,omitempty"`                     // Valores esperados pelo modelo físico, sem falhas nem ruído
+	Intensity                    *IntensityMetrics  `json:"intensity,omitempty"`                    // Consumo normalizado por área e volume, quando a zona tem geometria
+	RecoveryActive               bool               `json:"recoveryActive,omitempty"`               // Equipamento em plena carga retomando o setpoint após o setback
+	CapacitySaturated            bool               `json:"capacitySaturated,omitempty"`            // Carga acima da capacidade: o setpoint não é mantido
+	CompressorRuntimeFraction    float64            `json:"compressorRuntimeFraction,omitempty"`    // Fração do período com compressor ligado
+	CompressorCycles             int                `json:"compressorCycles,omitempty"`             // Partidas do compressor no período
+	TrueZoneTemperature          *float64           `json:"trueZoneTemperature,omitempty"`          // Temperatura real da sala quando o termostato está mal posicionado (°C)
+	ActiveFaults                 []string           `json:"activeFaults,omitempty"`                 // Falhas injetadas ativas no passo (rótulo de verdade para FDD)
+	InrushPowerKw                float64            `json:"inrushPowerKw,omitempty"`                // Pico de potência na partida após queda de energia (kW)
+	SupplyVoltageV               float64            `json:"supplyVoltageV,omitempty"`               // Tensão de alimentação medida na unidade (V), com afundamentos de tensão habilitados
+	OverrideActive               bool               `json:"overrideActive,omitempty"`               // Setpoint alterado manualmente por um ocupante
+	GasConsumptionM3             float64            `json:"gasConsumptionM3,omitempty"`             // Gás natural queimado no período pelo aquecimento a gás (m³)
+	GasConsumptionTherms         float64            `json:"gasConsumptionTherms,omitempty"`         // Gás queimado no período pelo aquecimento a gás (therms)
+	Hydronic                     *HydronicPoints    `json:"hydronic,omitempty"`                     // Lado de água das serpentinas (vazão, bomba, ΔT e válvula), com serpentinas hidrônicas
+	Vrf                          *VRFPoints         `json:"vrf,omitempty"`                          // Unidade externa VRF e capacidade liberada para a unidade interna
+	Erv                          *ERVPoints         `json:"erv,omitempty"`                          // Recuperador de calor do ar de renovação (temperaturas, efetividade, bypass e degelo)
+	DefrostCycles                int                `json:"defrostCycles,omitempty"`                // Degelos da serpentina externa no período (ciclo invertido)
+	AuxHeatKwh                   float64            `json:"auxHeatKwh,omitempty"`                   // Consumo da resistência auxiliar nos degelos, incluído em powerConsumptionKwH (kWh)
+	StagesActive                 int                `json:"stagesActive,omitempty"`                 // Estágios de compressor ligados no passo, em equipamentos com vários estágios
+	LeadCompressor               int                `json:"leadCompressor,omitempty"`               // Compressor líder, o primeiro a ligar (1 a N)
+	StageRuntimeFractions        []float64          `json:"stageRuntimeFractions,omitempty"`        // Fração do período ligado de cada compressor, na ordem física
+	FilterDifferentialPressurePa *float64           `json:"filterDifferentialPressurePa,omitempty"` // Perda de carga medida no filtro (Pa), com o modelo de filtro habilitado
+	Economizer                   *EconomizerPoints  `json:"economizer,omitempty"`                   // Damper de ar externo, limite alto e bloqueio do economizador
+	// Missing lista os caminhos dos campos que o dispositivo não reportou na leitura. O valor
+	// simulado continua no registro; os formatos de saída o gravam como nulo ou omitem a chave.
+	Missing []string `json:"-"`
 }
 
-var (
-	randSource rand.Source // Fonte de aleatoriedade
-	rng        *rand.Rand  // Gerador de números aleatórios
+const (
+	baseInternalTemp = 22.0
+	setPointDelta    = 1.5
+	defaultDeadband  = 1.5
+	idleBandFraction = 2.0 / 3.0 // Desvio abaixo do qual a unidade fica em IDLE, relativo à banda morta
+	thermalResponse  = 0.35      // Fração do desequilíbrio térmico corrigida a cada hora (inércia do ambiente)
+
+	indoorDesignHumidity = 50.0 // Umidade relativa de projeto da sala no resfriamento (%)
+	latentLoadKwPerKJ    = 0.04 // Potência extra do compressor por kJ/kg de entalpia latente a remover (kW)
 )
 
+var defaultGenerator *Generator // Gerador com os parâmetros padrão usado por GenerateHvacData
+
 func init() {
-	randSource = rand.NewSource(time.Now().UnixNano())
-	rng = rand.New(randSource)
+	generator, err := NewGenerator()
+	if err != nil {
+		panic(fmt.Sprintf("erro ao criar o gerador padrão: %v", err))
+	}
+	defaultGenerator = generator
 }
 
+// GenerateHvacData gera uma leitura isolada, sem estado térmico anterior, para um dispositivo aleatório.
+// Para ajustar o modelo, use NewGenerator.
 func GenerateHvacData(climateData climate.InmetClimateData) HvacSensorData {
-	const baseInternalTemp = 22.0
-	const setPointDelta = 1.5
-
-	month := climateData.Timestamp.Month()
-	floatMonth := float64(month)
+	return defaultGenerator.Generate(climateData)
+}
 
-	var equipmentHealth float64
-	var currentFilterClogLevel float64
+// step simula um passo de tempo de um dispositivo, partindo do estado térmico deixado pelo passo anterior.
+func (s *Simulator) step(device *deviceState, climateData climate.InmetClimateData) HvacSensorData {
+	rng := s.rng
+	noise := s.model.NoiseScale
+	device.wasRecovering = device.recovering
+	device.recovering = false
+	device.saturated = false
 
-	if month == time.September {
-		equipmentHealth = 0.8 + (rng.Float64() * 0.2)
-		currentFilterClogLevel = rng.Float64() * 0.05
-	} else if month > time.September {
-		equipmentHealth = 0.8 - ((floatMonth - 9.0) / 3.0 * 0.2)
-		currentFilterClogLevel = 0.05 + ((floatMonth - 9.0) / 3.0 * 0.4)
-	} else {
-		equipmentHealth = 1.0 - (floatMonth / 9.0 * 0.4)
-		currentFilterClogLevel = floatMonth / 9.0 * 0.8
+	if s.maintenance != nil {
+		s.performMaintenance(device, climateData.Timestamp)
 	}
+	faultState := FaultState{DeviceID: device.ID, Zone: device.Zone, Timestamp: climateData.Timestamp, Stress: climateData.Stress}
+	condition := s.faultModel.Condition(faultState, rng)
+	if s.filter != nil {
+		// Colmatação pela carga acumulada até o passo anterior, no lugar da sazonal
+		condition.FilterClog = s.filter.clog(device.filterLoad)
+	}
+	condition = device.serviceCondition(condition, climateData.Timestamp, s.filter != nil)
+	equipmentHealth, currentFilterClogLevel := condition.Health, condition.FilterClog
 
-	equipmentHealth += (rng.Float64() - 0.5) * 0.1
-	equipmentHealth = math.Max(0.4, math.Min(1.0, equipmentHealth))
-	currentFilterClogLevel += (rng.Float64() - 0.5) * 0.1
-	currentFilterClogLevel = math.Max(0.0, math.Min(1.0, currentFilterClogLevel))
-
-	isOccupied := simulateOccupancy(climateData.Timestamp, rng)
-	setPoint := baseInternalTemp + setPointDelta*(rng.Float64()-0.5)
+	faults := device.activeFaults(climateData.Timestamp)
+	isOccupied := s.model.Occupancy.Occupied(climateData.Timestamp, rng)
+	device.occupied = isOccupied
+	baseSetpoint := s.model.BaseSetpoint
+	if device.Setpoint != 0 {
+		baseSetpoint = device.Setpoint
+	}
+	setPoint := baseSetpoint + setPointDelta*(rng.Float64()-0.5)*noise
+	overrideActive := false
+	if s.overrides != nil {
+		setPoint, overrideActive = device.applyOverride(s.overrides, climateData, isOccupied, setPoint, rng)
+	}
 
-	uncontrolledInternalTemp := baseInternalTemp + (climateData.TemperatureAir-baseInternalTemp)*0.4 + (rng.Float64()-0.5)*1.5
-	internalTempDiff := uncontrolledInternalTemp - setPoint
+	equilibriumTemp := zoneEquilibriumTemp(climateData.TemperatureAir)
+	previousTemp := equilibriumTemp
+	if device.hasState {
+		previousTemp = device.internalTemp
+	}
+	uncontrolledInternalTemp := previousTemp + (equilibriumTemp-previousTemp)*thermalResponse + (rng.Float64()-0.5)*1.5*noise
+	sensedInternalTemp := uncontrolledInternalTemp + device.decisionBias(isOccupied)
 
-	systemStatus := "OFF"
-	if isOccupied {
-		if internalTempDiff > 1.5 {
-			systemStatus = "COOLING"
-		} else if internalTempDiff < -1.5 {
-			systemStatus = "HEATING"
-		} else if math.Abs(internalTempDiff) < 1.0 {
-			systemStatus = "IDLE"
+	decision := s.control.Decide(ZoneState{
+		DeviceID:           device.ID,
+		Zone:               device.Zone,
+		Timestamp:          climateData.Timestamp,
+		Occupied:           isOccupied,
+		Temperature:        sensedInternalTemp,
+		Setpoint:           setPoint,
+		Deadband:           s.model.Deadband,
+		OutdoorTemperature: climateData.TemperatureAir,
+		OutdoorHumidity:    climateData.RelativeHumidity,
+		PreviousMode:       device.lastStatus,
+	})
+	systemStatus := decision.Mode
+	if decision.Setpoint != 0 {
+		setPoint = decision.Setpoint
+	}
+	if systemStatus == "OFF" && !isOccupied && s.precooling != nil {
+		systemStatus = s.precooling.decide(climateData.Timestamp, sensedInternalTemp, climateData.TemperatureAir, setPoint)
+	}
+	systemStatus, reheatWaste := simultaneousHeatCool(faults[FaultSimultaneousHeatCool], systemStatus, isOccupied)
+	modeConflict := false
+	if device.vrf != nil {
+		device.vrf.request(device, systemStatus, climateData.TemperatureAir, sensedInternalTemp, setPoint)
+		if device.vrf.conflicts(systemStatus) {
+			// Unidade externa em outro modo: a unidade interna só ventila e a sala deriva
+			systemStatus, modeConflict = "FAN_ONLY", true
 		}
 	}
 
 	supplyTemp := uncontrolledInternalTemp
-	ductPressure := 10.0 + rng.Float64()*2.0
+	ductPressure := (10.0 + rng.Float64()*2.0) * s.airDensity
+	if device.airHandler != nil {
+		ductPressure = device.airHandler.staticPressureSetpoint + (rng.Float64()-0.5)*0.6
+	}
 	co2Level := 450.0 + (rng.Float64() * 50.0)
 	refrigerantPressure := 80.0 + rng.Float64()*5.0
 	faultCode := "OK"
@@ -99,96 +180,401 @@ func GenerateHvacData(climateData climate.InmetClimateData) HvacSensorData {
 	finalInternalTemp := uncontrolledInternalTemp
 	if systemStatus == "COOLING" {
 		finalInternalTemp = setPoint + rng.Float64()*0.5
+		// Retomada após o setback: em plena capacidade, a sala leva algumas horas até o setpoint
+		pulldown := device.maxTempChange(climateData.TemperatureAir, setPoint, isOccupied, true, periodHours(device, climateData.Timestamp))
+		if sensedInternalTemp-pulldown > finalInternalTemp {
+			finalInternalTemp = sensedInternalTemp - pulldown
+			device.markCapacityLimited()
+		}
+		// Saturação de capacidade: sob estresse o equipamento não consegue segurar o setpoint
+		finalInternalTemp += climateData.Stress * math.Max(0, uncontrolledInternalTemp-setPoint) * 0.6
 		supplyTemp = finalInternalTemp - (rng.Float64()*4.0 + 8.0)
-		refrigerantPressure = 150.0 + (rng.Float64() * 20.0)
+		refrigerantPressure = 150.0 + (rng.Float64() * 20.0) + climateData.Stress*40.0
+		if ahu := device.airHandler; ahu != nil {
+			// Insuflamento mais quente (após trim) reduz a capacidade da caixa VAV
+			shortfall := (ahu.supplyAirTempSetpoint - s.g36.MinSupplyAirTemp) / (s.g36.MaxSupplyAirTemp - s.g36.MinSupplyAirTemp)
+			finalInternalTemp += shortfall * math.Max(0, uncontrolledInternalTemp-setPoint) * 0.8
+			supplyTemp = ahu.supplyAirTempSetpoint + (rng.Float64()-0.5)*0.6
+		}
 	} else if systemStatus == "HEATING" {
 		finalInternalTemp = setPoint - rng.Float64()*0.5
-		supplyTemp = finalInternalTemp + (rng.Float64()*3.0 + 5.0)
-		refrigerantPressure = 100.0 + (rng.Float64() * 5.0)
-	} else if systemStatus == "IDLE" || systemStatus == "FAN_ONLY" {
+		warmup := device.maxTempChange(climateData.TemperatureAir, setPoint, isOccupied, false, periodHours(device, climateData.Timestamp))
+		if sensedInternalTemp+warmup < finalInternalTemp {
+			finalInternalTemp = sensedInternalTemp + warmup
+			device.markCapacityLimited()
+		}
+		finalInternalTemp -= climateData.Stress * math.Max(0, setPoint-uncontrolledInternalTemp) * 0.6
+		if s.gasFired(device) {
+			// Trocador do queimador insufla bem mais quente; o circuito de refrigerante fica parado
+			supplyTemp = finalInternalTemp + (rng.Float64()*10.0 + 15.0)
+			refrigerantPressure = 80.0 + (rng.Float64() * 5.0)
+		} else {
+			supplyTemp = finalInternalTemp + (rng.Float64()*3.0 + 5.0)
+			refrigerantPressure = 100.0 + (rng.Float64() * 5.0)
+		}
+	} else if (systemStatus == "IDLE" || systemStatus == "FAN_ONLY") && !modeConflict {
 		finalInternalTemp = setPoint + (rng.Float64()-0.5)*0.5
+	} else if systemStatus == "NIGHT_PURGE" {
+		// Ar externo frio renova e resfria o ambiente, sem compressor
+		target := setPoint - s.precooling.TargetOffset
+		finalInternalTemp = math.Max(target, uncontrolledInternalTemp+(climateData.TemperatureAir-uncontrolledInternalTemp)*0.5)
+		supplyTemp = climateData.TemperatureAir + 1.0 + rng.Float64()*0.5
+		co2Level = 420.0 + rng.Float64()*30.0
+	} else if systemStatus == "PRE_COOLING" {
+		finalInternalTemp = setPoint - s.precooling.TargetOffset + rng.Float64()*0.3
+		supplyTemp = finalInternalTemp - (rng.Float64()*4.0 + 8.0)
+		refrigerantPressure = 135.0 + (rng.Float64() * 15.0)
+	}
+
+	if device.vrf != nil && compressorRunning(systemStatus) {
+		// Pouco refrigerante na unidade interna: a serpentina troca menos e o insuflamento se aproxima da sala
+		supplyTemp += (1.0 - device.vrfCapacityFactor) * (finalInternalTemp - supplyTemp) * 0.5
+	}
+
+	foulingPowerFactor, highPressureTrip := 1.0, false
+	if severity := faults[FaultCondenserFouling]; severity > 0 && (systemStatus == "COOLING" || systemStatus == "PRE_COOLING") {
+		var extraPressure float64
+		extraPressure, foulingPowerFactor = condenserFouling(severity, climateData.TemperatureAir)
+		refrigerantPressure += extraPressure
+		if refrigerantPressure > highPressureCutoutPsi {
+			// Pressostato de alta desarma o compressor por parte do período: a sala esquenta
+			highPressureTrip = true
+			supplyTemp += (finalInternalTemp - supplyTemp) * highPressureTripRuntime
+			finalInternalTemp += math.Max(0, uncontrolledInternalTemp-finalInternalTemp) * highPressureTripRuntime
+		}
 	}
 
+	undervoltageTrip := false
+	if s.brownouts != nil && s.voltagePct < device.undervoltageTripPct && (systemStatus == "COOLING" || systemStatus == "HEATING" || systemStatus == "PRE_COOLING") {
+		// Relé de subtensão desarma o compressor, que só religa após o retardo anti-ciclagem
+		undervoltageTrip = true
+		supplyTemp += (finalInternalTemp - supplyTemp) * undervoltageTripRun
+		finalInternalTemp += (uncontrolledInternalTemp - finalInternalTemp) * undervoltageTripRun
+		refrigerantPressure = 90.0 + (refrigerantPressure-90.0)*(1.0-undervoltageTripRun)
+	}
+
+	if reheatWaste > 0 {
+		// Sensor de insuflamento após a serpentina de reaquecimento
+		supplyTemp += faults[FaultSimultaneousHeatCool] * 3.0
+	}
+
+	if severity := faults[FaultDamperStuckOpen]; severity > 0 && systemStatus != "OFF" {
+		// Mistura com muito ar externo: insuflamento acompanha a temperatura externa e o CO2 cai
+		supplyTemp += severity * (climateData.TemperatureAir - finalInternalTemp) * 0.3
+		co2Level = outdoorCO2 + (co2Level-outdoorCO2)*(1.0-severity*0.8)
+	}
+	if severity := faults[FaultDamperStuckClosed]; severity > 0 {
+		co2Level = device.stuckClosedCO2(severity, isOccupied, periodHours(device, climateData.Timestamp))
+	}
+
+	// A partir daqui finalInternalTemp é a temperatura real da sala; o termostato lê thermostatTemp
+	finalInternalTemp, thermostatTemp := device.placeSensor(systemStatus, finalInternalTemp, supplyTemp, isOccupied)
+
 	// Simulação de falhas
-	if systemStatus == "COOLING" && rng.Float64() > equipmentHealth {
-		faultCode = "HP-AL-01"
-	} else if systemStatus == "HEATING" && rng.Float64() > equipmentHealth {
-		faultCode = "HT-FL-02"
+	faultState.Mode = systemStatus
+	if alarm := s.faultModel.Alarm(faultState, condition, rng); alarm != "" {
+		faultCode = alarm
 	}
-	ductPressure += currentFilterClogLevel * 5.0
-	if currentFilterClogLevel > 0.8 && rng.Float64() > 0.5 {
-		faultCode = "FP-AL-01"
+	ductPressure += currentFilterClogLevel * 5.0 * s.airDensity
+	airflow := airflowFraction(faults[FaultAirflowDegradation])
+	if airflow < 1.0 {
+		// Menos vazão: pressão estática cai e o ar passa mais tempo na serpentina (ΔT maior)
+		ductPressure *= 0.5 + 0.5*airflow
+		if systemStatus == "COOLING" || systemStatus == "PRE_COOLING" {
+			supplyTemp -= (1.0 - airflow) * 6.0
+		} else if systemStatus == "HEATING" {
+			supplyTemp += (1.0 - airflow) * 6.0
+		}
 	}
 	if ductPressure > 20.0 {
 		faultCode = "FP-AL-02"
 	}
-
-	powerConsumption := 0.01
+	if highPressureTrip {
+		faultCode = "HP-AL-01"
+	}
+	if undervoltageTrip {
+		faultCode = undervoltageTripFault
+	} else if s.brownouts != nil && s.voltagePct < undervoltageAlarmPct {
+		faultCode = undervoltageAlarmFault
+	}
 
 	inefficiencyCost := (1.0-equipmentHealth)*1.0 + (currentFilterClogLevel * 0.4)
 
-	if systemStatus == "COOLING" {
-		basePower := 3.0
-		tempLoad := math.Max(0, climateData.TemperatureAir-setPoint) * 0.4
-		humidityLoad := 0.0
-		if climateData.RelativeHumidity > 75.0 {
-			humidityLoad = (climateData.RelativeHumidity - 75.0) / 100.0 * 8.0
+	// Com queimador a gás, as perdas do equipamento e a carga extra saem no combustível (kWh térmicos)
+	fuelFired := systemStatus == "HEATING" && s.gasFired(device)
+	fuelWasteKwh := 0.0
+
+	powerConsumption := s.modeledPower(device, climateData, systemStatus, setPoint, finalInternalTemp)
+	if fuelFired {
+		fuelWasteKwh += inefficiencyCost
+	} else if systemStatus == "COOLING" || systemStatus == "HEATING" || systemStatus == "PRE_COOLING" {
+		powerConsumption += inefficiencyCost
+	} else if systemStatus == "FAN_ONLY" || systemStatus == "NIGHT_PURGE" {
+		powerConsumption += (rng.Float64() - 0.5) * 0.1 * noise
+	}
+
+	powerConsumption += reheatWaste
+	powerConsumption *= foulingPowerFactor
+	if highPressureTrip {
+		powerConsumption *= 1.0 - highPressureTripRuntime
+	}
+	if undervoltageTrip {
+		powerConsumption *= 1.0 - undervoltageTripRun
+	} else if s.brownouts != nil && s.voltagePct < 100.0 && (systemStatus == "COOLING" || systemStatus == "HEATING" || systemStatus == "PRE_COOLING") {
+		// Motores sob tensão reduzida puxam mais corrente e aquecem: perdas maiores
+		powerConsumption *= 1.0 + (100.0-s.voltagePct)/100.0*0.3
+	}
+	if airflow < 1.0 && (systemStatus == "COOLING" || systemStatus == "HEATING") {
+		powerConsumption *= 1.0 + (1.0-airflow)*0.5
+	}
+	oaLoad := outdoorAirLoad(faults[FaultDamperStuckOpen], systemStatus, climateData.TemperatureAir, climateData.RelativeHumidity, finalInternalTemp)
+	if fuelFired {
+		fuelWasteKwh += oaLoad
+	} else {
+		powerConsumption += oaLoad / ratedCop
+	}
+
+	hours := periodHours(device, climateData.Timestamp) // Antes de atualizar o estado, que redefine o último passo
+	staged := s.stages(device) > 1 && !fuelFired
+	runtimeFraction, cycles := 0.0, 0
+	if systemStatus == "COOLING" || systemStatus == "HEATING" {
+		runtimeFraction = 1.0
+		if !device.recovering && !device.saturated {
+			runtimeFraction = device.partLoadRatio(climateData.TemperatureAir, finalInternalTemp, isOccupied, systemStatus == "COOLING")
+			// Com menos vazão a capacidade entregue cai e o compressor precisa ficar mais tempo ligado
+			runtimeFraction = math.Min(1.0, runtimeFraction/(0.4+0.6*airflow))
+			cycling := runtimeFraction
+			if staged {
+				cycling = cyclingFraction(runtimeFraction, s.stages(device)) // Só o último estágio parte e para
+			}
+			cycles = compressorCycles(cycling, hours)
+			if !fuelFired {
+				powerConsumption += float64(cycles) * startPenaltyKwh
+			}
 		}
-		powerConsumption = (basePower + tempLoad + humidityLoad) + inefficiencyCost
+	}
+	if undervoltageTrip && fuelFired {
+		runtimeFraction *= 1.0 - undervoltageTripRun
+	}
 
-	} else if systemStatus == "HEATING" {
-		basePower := 2.2
-		tempLoad := math.Max(0, setPoint-climateData.TemperatureAir) * 0.15
-		powerConsumption = (basePower + tempLoad) + inefficiencyCost
+	defrosts, auxHeatKwh := 0, 0.0
+	if s.defrost != nil && systemStatus == "HEATING" && !fuelFired && s.hydronic == nil {
+		defrosts = s.defrost.defrostCycles(device, climateData.TemperatureAir, climateData.RelativeHumidity, runtimeFraction, hours)
+		if defrosts > 0 {
+			// Ciclo invertido: a serpentina interna vira evaporador e a resistência auxiliar só tempera o insuflamento
+			auxHeatKwh = float64(defrosts) * s.defrost.DurationMinutes / 60.0 * s.defrost.AuxHeatKw
+			powerConsumption += auxHeatKwh
+			supplyTemp -= (supplyTemp - finalInternalTemp) * defrostSupplyDip
+			refrigerantPressure += defrostPressureRise
+		}
+	}
 
-	} else if systemStatus == "FAN_ONLY" {
-		powerConsumption = 0.3 + (rng.Float64() * 0.1)
+	var economizer *EconomizerPoints
+	if s.economizer != nil {
+		economizer = s.economizer.economizerPoints(systemStatus, climateData.TemperatureAir, climateData.RelativeHumidity, finalInternalTemp, s.pressure)
+		if free := economizer.FreeCoolingFraction; free > 0 {
+			// O ar externo entrega parte do resfriamento: o compressor só completa o restante
+			powerConsumption *= 1.0 - free*(1.0-economizerFanShare)
+			refrigerantPressure -= (refrigerantPressure - 80.0) * free
+			if free >= 1.0 {
+				cycles = 0
+			}
+		}
 	}
 
-	powerConsumption *= (1.0 + (rng.Float64()-0.5)*0.1)
+	measurementNoise := 1.0 + (rng.Float64()-0.5)*0.1*noise
+	powerConsumption *= measurementNoise
 	powerConsumption = math.Max(0.01, powerConsumption)
 
-	return HvacSensorData{
+	expected := s.expectedValues(device, climateData, systemStatus, setPoint, finalInternalTemp)
+	if expected != nil && defrosts > 0 {
+		// O degelo faz parte da operação normal da bomba de calor
+		expected.shiftDefrost(finalInternalTemp, auxHeatKwh)
+	}
+	if expected != nil && economizer != nil && economizer.FreeCoolingFraction > 0 {
+		expected.shiftEconomizer(economizer.FreeCoolingFraction)
+	}
+	g36 := s.g36Points(device, math.Max(0, uncontrolledInternalTemp-setPoint), thermostatTemp, setPoint, systemStatus)
+
+	intensity := s.intensity(device, climateData.Timestamp, powerConsumption)
+
+	if s.energyBalance != nil {
+		s.recordEnergyBalance(device, climateData.Timestamp, hours, climateData.TemperatureAir, previousTemp, finalInternalTemp, isOccupied, systemStatus, runtimeFraction, powerConsumption)
+	}
+
+	// O queimador não é compressor: sua fração ligada e partidas ficam fora dos campos do compressor
+	compressorRuntime, compressorStarts := runtimeFraction, cycles
+	if fuelFired {
+		compressorRuntime, compressorStarts = 0, 0
+	}
+	if economizer != nil {
+		compressorRuntime *= 1.0 - economizer.FreeCoolingFraction
+	}
+
+	device.internalTemp = finalInternalTemp
+	device.hasState = true
+	dewPoint, wetBulb, enthalpy := s.outdoorAirState(climateData)
+	device.lastTimestamp = climateData.Timestamp
+	device.lastStatus = systemStatus
+	device.lastCO2 = co2Level
+
+	data := HvacSensorData{
 		Timestamp:              climateData.Timestamp,
-		InternalTemperature:    finalInternalTemp,
+		InternalTemperature:    thermostatTemp,
 		SetPointTemperature:    setPoint,
 		SystemStatus:           systemStatus,
 		OccupancyStatus:        isOccupied,
 		PowerConsumptionKwH:    powerConsumption,
 		OutdoorTemperature:     climateData.TemperatureAir,
 		OutdoorHumidity:        climateData.RelativeHumidity,
-		DeviceId:               fmt.Sprintf("SALA-%d", rng.Intn(10)+1),
+		OutdoorDewPoint:        dewPoint,
+		OutdoorWetBulb:         wetBulb,
+		OutdoorEnthalpy:        enthalpy,
+		DeviceId:               device.ID,
 		SupplyAirTemperature:   supplyTemp,
 		ReturnAirTemperature:   finalInternalTemp,
 		DuctStaticPressurePa:   ductPressure,
 		CO2LevelPpm:            co2Level,
 		RefrigerantPressurePsi: refrigerantPressure,
 		FaultCode:              faultCode,
-		AssetModel:             "HVAC-Model-B",
-		LocationZone:           "Zona-A",
+		AssetModel:             device.AssetModel,
+		LocationZone:           device.Zone,
+		StationId:              s.station,
+		ExtremeEvent:           climateData.ExtremeEvent,
+
+		OutdoorTemperatureForecast: climateData.Forecasts,
+		G36:                        g36,
+		Expected:                   expected,
+		Intensity:                  intensity,
+		RecoveryActive:             device.recovering,
+		CapacitySaturated:          device.saturated,
+		CompressorRuntimeFraction:  compressorRuntime,
+		CompressorCycles:           compressorStarts,
+		DefrostCycles:              defrosts,
+		AuxHeatKwh:                 auxHeatKwh,
+		ActiveFaults:               device.faultLabels(faults),
+		OverrideActive:             overrideActive,
+	}
+	if fuelFired {
+		delivered := runtimeFraction * device.CapacityKw * heatingCapacityRatio * hours
+		s.gasConsumption(device, delivered*measurementNoise, fuelWasteKwh, &data)
+	}
+	if staged && (systemStatus == "COOLING" || systemStatus == "HEATING") {
+		s.applyStages(device, runtimeFraction, hours, &data)
+	}
+	if device.vrf != nil {
+		data.Vrf = device.vrfPoints(modeConflict)
+	}
+	if s.erv != nil {
+		data.Erv = s.erv.ervPoints(systemStatus, isOccupied, climateData.TemperatureAir, finalInternalTemp, hours)
+	}
+	if s.hydronic != nil {
+		data.Hydronic = s.hydronicPoints(device, systemStatus, climateData.TemperatureAir, finalInternalTemp, runtimeFraction, fuelFired)
+	}
+	if economizer != nil {
+		data.Economizer = economizer
 	}
+	if s.brownouts != nil {
+		data.SupplyVoltageV = s.supplyVoltage()
+	}
+	if s.filter != nil {
+		filterPressure := s.differentialPressure(device, systemStatus, airflow)
+		data.FilterDifferentialPressurePa = &filterPressure
+		s.filter.loadFilter(device, systemStatus, s.dustEvent(climateData.Timestamp), hours)
+	}
+	if s.maintenance != nil {
+		s.openWorkOrders(device, climateData.Timestamp, condition, faults, highPressureTrip)
+	}
+	if device.SensorPlacement != "" {
+		data.TrueZoneTemperature = &finalInternalTemp
+	}
+	return data
 }
 
-// simulateOccupancy simula a ocupação baseada no dia da semana e hora.
-func simulateOccupancy(t time.Time, r *rand.Rand) bool {
-	hour := t.Hour()
-	weekday := t.Weekday()
+// outdoorAirState calcula o ponto de orvalho e a temperatura de bulbo úmido (°C) e a entalpia
+// (kJ/kg) do ar externo, na pressão atmosférica do site.
+func (s *Simulator) outdoorAirState(climateData climate.InmetClimateData) (dewPoint, wetBulb, enthalpy float64) {
+	temp, humidity := climateData.TemperatureAir, climateData.RelativeHumidity
+	return climate.DewPoint(temp, humidity), climate.WetBulb(temp, humidity, s.pressure), climate.Enthalpy(temp, humidity, s.pressure)
+}
+
+// latentCoolingLoad estima a carga latente do resfriamento (kW): a diferença entre a entalpia do
+// ar externo e a do mesmo ar seco até a umidade absoluta da sala no setpoint, com a umidade
+// relativa de projeto. Ar frio e úmido carrega menos vapor que ar quente com a mesma umidade
+// relativa, e ar mais seco que o da sala não acrescenta carga.
+func latentCoolingLoad(outdoorTemp, outdoorHumidity, setPoint, pressure float64) float64 {
+	outdoorRatio := climate.HumidityRatio(outdoorTemp, outdoorHumidity, pressure)
+	indoorRatio := climate.HumidityRatio(setPoint, indoorDesignHumidity, pressure)
+	latentEnthalpy := math.Max(0, outdoorRatio-indoorRatio) * (2501.0 + 1.86*outdoorTemp) // kJ/kg
+	return latentEnthalpy * latentLoadKwPerKJ
+}
+
+// zoneEquilibriumTemp é a temperatura para a qual a sala tende sem climatização.
+func zoneEquilibriumTemp(outdoorTemp float64) float64 {
+	return baseInternalTemp + (outdoorTemp-baseInternalTemp)*0.4
+}
+
+// modeledPower calcula o consumo previsto pela física do simulador para um equipamento saudável,
+// sem ineficiências nem ruído de medição.
+func (s *Simulator) modeledPower(device *deviceState, climateData climate.InmetClimateData, systemStatus string, setPoint, internalTemp float64) float64 {
+	powerConsumption := 0.01
+
+	curvePower, fromCurve := 0.0, false
+	if systemStatus == "COOLING" || systemStatus == "HEATING" || systemStatus == "PRE_COOLING" {
+		curvePower, fromCurve = s.curvePower(device, climateData, internalTemp, systemStatus)
+	}
+
+	if fromCurve {
+		powerConsumption = curvePower
+	} else if systemStatus == "COOLING" {
+		basePower := 3.0
+		tempLoad := math.Max(0, climateData.TemperatureAir-setPoint) * 0.4
+		humidityLoad := latentCoolingLoad(climateData.TemperatureAir, climateData.RelativeHumidity, setPoint, s.pressure)
+		powerConsumption = basePower + tempLoad + humidityLoad
+
+	} else if systemStatus == "HEATING" {
+		basePower := 2.2
+		tempLoad := math.Max(0, setPoint-climateData.TemperatureAir) * 0.15
+		powerConsumption = basePower + tempLoad
 
-	if hour >= 12 && hour < 14 { // Hora do almoço
-		return r.Float64() < 0.30
+	} else if systemStatus == "PRE_COOLING" {
+		// Madrugada mais fresca melhora o COP do compressor
+		basePower := 2.6
+		tempLoad := math.Max(0, climateData.TemperatureAir-internalTemp) * 0.3
+		powerConsumption = basePower + tempLoad
+
+	} else if systemStatus == "FAN_ONLY" || systemStatus == "NIGHT_PURGE" {
+		powerConsumption = fanPowerKw
+	}
+	powerConsumption += s.fanPowerCorrection(systemStatus)
+
+	if (device.recovering || device.saturated) && !fromCurve {
+		// Plena carga, sem ciclagem
+		powerConsumption = math.Max(powerConsumption, device.ratedPower(systemStatus == "COOLING"))
+	} else if stages := s.stages(device); stages > 1 && !fromCurve && (systemStatus == "COOLING" || (systemStatus == "HEATING" && !s.gasFired(device))) {
+		// Compressores em degraus: só os estágios necessários para a carga consomem
+		plr := device.partLoadRatio(climateData.TemperatureAir, internalTemp, device.occupied, systemStatus == "COOLING")
+		powerConsumption *= float64(stagesFor(plr, stages)) / float64(stages)
 	}
-	if weekday >= time.Monday && weekday <= time.Friday {
-		if hour >= 8 && hour < 18 { // Horário comercial
-			return r.Float64() < 0.90
+	if systemStatus == "COOLING" || (systemStatus == "HEATING" && !s.gasFired(device)) {
+		powerConsumption += climateData.Stress * 1.5 // Compressor operando em carga máxima
+	}
+	if ahu := device.airHandler; ahu != nil {
+		if systemStatus == "COOLING" {
+			powerConsumption *= 1.0 + (s.g36.MaxSupplyAirTemp-ahu.supplyAirTempSetpoint)*0.03
+		}
+		if systemStatus != "OFF" {
+			powerConsumption += (ahu.staticPressureSetpoint - s.g36.MinStaticPressure) * 0.02
 		}
 	}
-	return r.Float64() < 0.10 // Fora do horário comercial / Fim de semana
+	return powerConsumption
 }
 
 func WriteJSON(data []HvacSensorData) ([]byte, error) {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
+	var buf bytes.Buffer
+	buf.Grow(len(data) * estimatedJSONRecordBytes)
+	if err := encodeJSONArray(&buf, len(data), func(i int) (any, error) { return &data[i], nil }); err != nil {
 		return nil, fmt.Errorf("erro ao serializar dados HVAC para JSON: %w", err)
 	}
-	return jsonData, nil
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,228 @@
+package hvac
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// GreenButtonConfig ativa a exportação do consumo total do prédio em Green Button (NAESB ESPI).
+type GreenButtonConfig struct {
+	Title           string `json:"title"`           // Título do ponto de consumo (padrão: A701 HVAC)
+	IntervalMinutes int    `json:"intervalMinutes"` // Duração de cada intervalo (min, padrão: 60; múltiplos do passo dos dados)
+	Location        string `json:"location"`        // Fuso horário local do medidor (padrão: America/Sao_Paulo)
+}
+
+func (c GreenButtonConfig) withDefaults() GreenButtonConfig {
+	if c.Title == "" {
+		c.Title = "A701 HVAC"
+	}
+	if c.IntervalMinutes == 0 {
+		c.IntervalMinutes = 60
+	}
+	if c.Location == "" {
+		c.Location = "America/Sao_Paulo"
+	}
+	return c
+}
+
+// Códigos do ReadingType do ESPI para energia elétrica ativa consumida, em Wh.
+const (
+	espiDeltaData           = 4   // accumulationBehaviour: consumo do intervalo
+	espiElectricity         = 1   // commodity: eletricidade (medidor primário)
+	espiCurrencyBRL         = 986 // ISO 4217
+	espiForward             = 1   // flowDirection: energia entregue ao cliente
+	espiEnergy              = 12  // kind
+	espiPhaseAll            = 769 // phase: ABC
+	espiWattHours           = 72  // uom: Wh
+	espiElectricityService  = 0   // ServiceCategory.kind
+	espiNoDaylightSavingHex = "00000000"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Links     []atomLink  `xml:"link"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomContent struct {
+	UsagePoint          *espiUsagePoint          `xml:"http://naesb.org/espi UsagePoint,omitempty"`
+	LocalTimeParameters *espiLocalTimeParameters `xml:"http://naesb.org/espi LocalTimeParameters,omitempty"`
+	MeterReading        *struct{}                `xml:"http://naesb.org/espi MeterReading,omitempty"`
+	ReadingType         *espiReadingType         `xml:"http://naesb.org/espi ReadingType,omitempty"`
+	IntervalBlock       *espiIntervalBlock       `xml:"http://naesb.org/espi IntervalBlock,omitempty"`
+}
+
+type espiUsagePoint struct {
+	ServiceCategoryKind int `xml:"ServiceCategory>kind"`
+}
+
+type espiLocalTimeParameters struct {
+	DstEndRule   string `xml:"dstEndRule"`
+	DstOffset    int    `xml:"dstOffset"`
+	DstStartRule string `xml:"dstStartRule"`
+	TzOffset     int    `xml:"tzOffset"`
+}
+
+type espiReadingType struct {
+	AccumulationBehaviour int `xml:"accumulationBehaviour"`
+	Commodity             int `xml:"commodity"`
+	Currency              int `xml:"currency"`
+	FlowDirection         int `xml:"flowDirection"`
+	IntervalLength        int `xml:"intervalLength"`
+	Kind                  int `xml:"kind"`
+	Phase                 int `xml:"phase"`
+	PowerOfTenMultiplier  int `xml:"powerOfTenMultiplier"`
+	TimeAttribute         int `xml:"timeAttribute"`
+	Uom                   int `xml:"uom"`
+}
+
+type espiInterval struct {
+	Duration int64 `xml:"duration"`
+	Start    int64 `xml:"start"`
+}
+
+type espiIntervalReading struct {
+	TimePeriod espiInterval `xml:"timePeriod"`
+	Value      int64        `xml:"value"`
+}
+
+type espiIntervalBlock struct {
+	Interval espiInterval          `xml:"interval"`
+	Readings []espiIntervalReading `xml:"IntervalReading"`
+}
+
+// WriteGreenButtonXML soma o consumo de todos os dispositivos por intervalo e gera o feed Atom do
+// Green Button, com um IntervalBlock por dia local e valores em Wh.
+func WriteGreenButtonXML(cfg GreenButtonConfig, data []HvacSensorData) ([]byte, error) {
+	cfg = cfg.withDefaults()
+	location, err := time.LoadLocation(cfg.Location)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar o fuso horário '%s' do Green Button: %w", cfg.Location, err)
+	}
+	interval := time.Duration(cfg.IntervalMinutes) * time.Minute
+
+	energyWh := make(map[time.Time]float64)
+	for _, record := range data {
+		energyWh[record.Timestamp.Truncate(interval)] += record.PowerConsumptionKwH * 1000.0
+	}
+	starts := make([]time.Time, 0, len(energyWh))
+	for start := range energyWh {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	var blocks []*espiIntervalBlock
+	var currentDay string
+	for _, start := range starts {
+		day := start.In(location).Format("2006-01-02")
+		if day != currentDay {
+			currentDay = day
+			local := start.In(location)
+			dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+			blocks = append(blocks, &espiIntervalBlock{Interval: espiInterval{Duration: 86400, Start: dayStart.Unix()}})
+		}
+		block := blocks[len(blocks)-1]
+		block.Readings = append(block.Readings, espiIntervalReading{
+			TimePeriod: espiInterval{Duration: int64(interval.Seconds()), Start: start.Unix()},
+			Value:      int64(math.Round(energyWh[start])),
+		})
+	}
+
+	first, last := time.Now(), time.Now()
+	if len(starts) > 0 {
+		first, last = starts[0], starts[len(starts)-1]
+	}
+	updated := last.UTC().Format(time.RFC3339)
+	_, tzOffset := first.In(location).Zone()
+
+	base := "/espi/1_1/resource/RetailCustomer/1/UsagePoint/1"
+	entry := func(kind, href, up string, content atomContent) atomEntry {
+		return atomEntry{
+			ID:        greenButtonUUID(cfg.Title + "/" + href),
+			Title:     cfg.Title + " " + kind,
+			Links:     []atomLink{{"self", href}, {"up", up}},
+			Published: updated,
+			Updated:   updated,
+			Content:   content,
+		}
+	}
+
+	feed := atomFeed{
+		ID:      greenButtonUUID(cfg.Title),
+		Title:   "Green Button Usage Feed - " + cfg.Title,
+		Updated: updated,
+		Links:   []atomLink{{"self", "/espi/1_1/resource/Batch/RetailCustomer/1/UsagePoint"}},
+	}
+	usagePoint := entry("UsagePoint", base, "/espi/1_1/resource/RetailCustomer/1/UsagePoint",
+		atomContent{UsagePoint: &espiUsagePoint{ServiceCategoryKind: espiElectricityService}})
+	usagePoint.Links = append(usagePoint.Links,
+		atomLink{"related", base + "/MeterReading"},
+		atomLink{"related", "/espi/1_1/resource/LocalTimeParameters/1"})
+	meterReading := entry("MeterReading", base+"/MeterReading/1", base+"/MeterReading", atomContent{MeterReading: &struct{}{}})
+	meterReading.Links = append(meterReading.Links,
+		atomLink{"related", base + "/MeterReading/1/IntervalBlock"},
+		atomLink{"related", "/espi/1_1/resource/ReadingType/1"})
+
+	feed.Entries = append(feed.Entries,
+		usagePoint,
+		entry("LocalTimeParameters", "/espi/1_1/resource/LocalTimeParameters/1", "/espi/1_1/resource/LocalTimeParameters",
+			atomContent{LocalTimeParameters: &espiLocalTimeParameters{
+				DstEndRule: espiNoDaylightSavingHex, DstStartRule: espiNoDaylightSavingHex, TzOffset: tzOffset,
+			}}),
+		meterReading,
+		entry("ReadingType", "/espi/1_1/resource/ReadingType/1", "/espi/1_1/resource/ReadingType",
+			atomContent{ReadingType: &espiReadingType{
+				AccumulationBehaviour: espiDeltaData,
+				Commodity:             espiElectricity,
+				Currency:              espiCurrencyBRL,
+				FlowDirection:         espiForward,
+				IntervalLength:        int(interval.Seconds()),
+				Kind:                  espiEnergy,
+				Phase:                 espiPhaseAll,
+				Uom:                   espiWattHours,
+			}}),
+	)
+	for i, block := range blocks {
+		href := fmt.Sprintf("%s/MeterReading/1/IntervalBlock/%d", base, i+1)
+		feed.Entries = append(feed.Entries, entry("IntervalBlock", href, base+"/MeterReading/1/IntervalBlock", atomContent{IntervalBlock: block}))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return nil, fmt.Errorf("erro ao serializar o feed Green Button: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// greenButtonUUID gera um urn:uuid determinístico (estilo versão 5) a partir do nome do recurso.
+func greenButtonUUID(name string) string {
+	sum := sha1.Sum([]byte(name))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
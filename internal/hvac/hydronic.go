@@ -0,0 +1,106 @@
+package hvac
+
+import "math"
+
+// HydronicConfig troca a serpentina de expansão direta por serpentinas de água: a de resfriamento
+// alimentada pelo chiller e a de aquecimento pela caldeira. Cada registro passa a trazer o lado de
+// água da serpentina que atende a sala (vazão, bomba, ΔT e válvula).
+type HydronicConfig struct {
+	ChilledWaterSupplyTemp float64 `json:"chilledWaterSupplyTemp"` // Temperatura de alimentação da água gelada (°C, padrão: 6.7)
+	ChilledWaterDeltaT     float64 `json:"chilledWaterDeltaT"`     // ΔT de projeto da serpentina de resfriamento (K, padrão: 5.5)
+	HotWaterSupplyTemp     float64 `json:"hotWaterSupplyTemp"`     // Temperatura de alimentação da água quente (°C, padrão: 60)
+	HotWaterDeltaT         float64 `json:"hotWaterDeltaT"`         // ΔT de projeto da serpentina de aquecimento (K, padrão: 11)
+	MinPumpSpeedPct        float64 `json:"minPumpSpeedPct"`        // Velocidade mínima da bomba com vazão (%, padrão: 30)
+}
+
+func (c HydronicConfig) withDefaults() HydronicConfig {
+	if c.ChilledWaterSupplyTemp == 0 {
+		c.ChilledWaterSupplyTemp = 6.7
+	}
+	if c.ChilledWaterDeltaT == 0 {
+		c.ChilledWaterDeltaT = 5.5
+	}
+	if c.HotWaterSupplyTemp == 0 {
+		c.HotWaterSupplyTemp = 60.0
+	}
+	if c.HotWaterDeltaT == 0 {
+		c.HotWaterDeltaT = 11.0
+	}
+	if c.MinPumpSpeedPct == 0 {
+		c.MinPumpSpeedPct = 30.0
+	}
+	return c
+}
+
+// HydronicPoints são os pontos do lado de água das serpentinas da AHU que atende a sala.
+type HydronicPoints struct {
+	CoilId      string     `json:"coilId"`      // AHU dona das serpentinas (a AHU do G36 ou o próprio dispositivo)
+	CoolingCoil CoilPoints `json:"coolingCoil"` // Serpentina de água gelada (chiller)
+	HeatingCoil CoilPoints `json:"heatingCoil"` // Serpentina de água quente (caldeira)
+}
+
+// CoilPoints são as grandezas de água de uma serpentina no passo.
+type CoilPoints struct {
+	ValvePositionPct float64 `json:"valvePositionPct"` // Abertura da válvula de duas vias (%)
+	FlowLps          float64 `json:"flowLps"`          // Vazão de água (L/s)
+	PumpSpeedPct     float64 `json:"pumpSpeedPct"`     // Velocidade da bomba secundária (%)
+	SupplyWaterTemp  float64 `json:"supplyWaterTemp"`  // Temperatura da água na entrada da serpentina (°C)
+	ReturnWaterTemp  float64 `json:"returnWaterTemp"`  // Temperatura da água na saída da serpentina (°C)
+	DeltaT           float64 `json:"deltaT"`           // Diferença entre retorno e alimentação (K, sempre positiva)
+}
+
+const (
+	waterHeatCapacity  = 4.186 // Calor específico da água (kJ/kg·K), com 1 kg por litro
+	valveRangeability  = 50.0  // Rangeabilidade da válvula de igual porcentagem
+	lowDeltaTDegrading = 0.3   // Queda relativa do ΔT em carga mínima (síndrome do ΔT baixo)
+)
+
+// coilPoints calcula o lado de água de uma serpentina com a fração de carga do passo: a vazão
+// entrega a carga com um ΔT que cai em carga parcial, a válvula de igual porcentagem abre conforme
+// a vazão pedida e a bomba acompanha a vazão pelas leis de afinidade, acima da velocidade mínima.
+func (c HydronicConfig) coilPoints(loadFraction, designKw, supplyTemp, designDeltaT float64, heating bool) CoilPoints {
+	points := CoilPoints{SupplyWaterTemp: supplyTemp, ReturnWaterTemp: supplyTemp}
+	if loadFraction <= 0 || designKw <= 0 {
+		return points
+	}
+	loadFraction = math.Min(1.0, loadFraction)
+	designFlow := designKw / (waterHeatCapacity * designDeltaT)
+	deltaT := designDeltaT * (1.0 - lowDeltaTDegrading*(1.0-loadFraction))
+	flow := loadFraction * designKw / (waterHeatCapacity * deltaT)
+
+	points.FlowLps = flow
+	points.DeltaT = deltaT
+	points.ValvePositionPct = math.Max(0, 100.0*(1.0+math.Log(flow/designFlow)/math.Log(valveRangeability)))
+	points.PumpSpeedPct = math.Max(c.MinPumpSpeedPct, 100.0*flow/designFlow)
+	if heating {
+		points.ReturnWaterTemp = supplyTemp - deltaT
+	} else {
+		points.ReturnWaterTemp = supplyTemp + deltaT
+	}
+	return points
+}
+
+// hydronicPoints monta o lado de água das serpentinas da sala no passo. Em pré-resfriamento a
+// fração de carga vem da carga da sala, já que o compressor não registra tempo ligado.
+func (s *Simulator) hydronicPoints(device *deviceState, systemStatus string, outdoorTemp, internalTemp, runtimeFraction float64, fuelFired bool) *HydronicPoints {
+	coilId := device.ID
+	if device.airHandler != nil {
+		coilId = device.airHandler.id
+	}
+	coolingLoad, heatingLoad := 0.0, 0.0
+	switch systemStatus {
+	case "COOLING":
+		coolingLoad = runtimeFraction
+	case "PRE_COOLING":
+		coolingLoad = device.partLoadRatio(outdoorTemp, internalTemp, false, true)
+	case "HEATING":
+		if !fuelFired {
+			heatingLoad = runtimeFraction
+		}
+	}
+	return &HydronicPoints{
+		CoilId:      coilId,
+		CoolingCoil: s.hydronic.coilPoints(coolingLoad, device.CapacityKw, s.hydronic.ChilledWaterSupplyTemp, s.hydronic.ChilledWaterDeltaT, false),
+		HeatingCoil: s.hydronic.coilPoints(heatingLoad, device.CapacityKw*heatingCapacityRatio, s.hydronic.HotWaterSupplyTemp, s.hydronic.HotWaterDeltaT, true),
+	}
+}
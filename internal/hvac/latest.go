@@ -0,0 +1,20 @@
+package hvac
+
+import "sort"
+
+// LatestReadings retorna a leitura mais recente de cada dispositivo, ordenadas por deviceId: o
+// "estado atual" que um device shadow exporia ao fim da simulação.
+func LatestReadings(data []HvacSensorData) []HvacSensorData {
+	latest := make(map[string]HvacSensorData)
+	for _, record := range data {
+		if current, ok := latest[record.DeviceId]; !ok || !record.Timestamp.Before(current.Timestamp) {
+			latest[record.DeviceId] = record
+		}
+	}
+	readings := make([]HvacSensorData, 0, len(latest))
+	for _, record := range latest {
+		readings = append(readings, record)
+	}
+	sort.Slice(readings, func(i, j int) bool { return readings[i].DeviceId < readings[j].DeviceId })
+	return readings
+}
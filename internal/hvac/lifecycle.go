@@ -0,0 +1,238 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// Tipos de evento do ciclo de vida dos equipamentos.
+const (
+	LifecycleInstall      = "INSTALL"      // Equipamento instalado: passa a reportar a partir daqui
+	LifecycleReplace      = "REPLACE"      // Equipamento trocado por um novo, com outra identidade
+	LifecycleDecommission = "DECOMMISSION" // Equipamento retirado: deixa de reportar
+)
+
+// LifecycleEvent é um evento programado do ciclo de vida de um dispositivo.
+type LifecycleEvent struct {
+	DeviceId    string `json:"deviceId"`    // Dispositivo da frota (devices[].id)
+	Type        string `json:"type"`        // INSTALL, REPLACE ou DECOMMISSION
+	Date        Date   `json:"date"`        // Data do evento (AAAA-MM-DD ou RFC 3339)
+	NewDeviceId string `json:"newDeviceId"` // REPLACE: identidade do novo equipamento (padrão: <id>-R<n>)
+	AssetModel  string `json:"assetModel"`  // REPLACE: modelo do novo equipamento (padrão: o mesmo)
+}
+
+// LifecycleConfig descreve a rotatividade da frota: equipamentos instalados no meio da série,
+// trocados (programados ou após falhas repetidas) e retirados. Sem equipamento, a sala fica sem
+// telemetria e deriva para a temperatura de equilíbrio.
+type LifecycleConfig struct {
+	Events              []LifecycleEvent `json:"events"`              // Eventos programados
+	FailureAlarmHours   float64          `json:"failureAlarmHours"`   // Horas com alarme de compressor que levam à troca do equipamento (0: sem trocas por falha)
+	ReplacementGapHours float64          `json:"replacementGapHours"` // Horas sem telemetria entre a retirada e o novo equipamento (padrão: 24)
+}
+
+func (c LifecycleConfig) withDefaults() LifecycleConfig {
+	if c.ReplacementGapHours == 0 {
+		c.ReplacementGapHours = 24.0
+	}
+	return c
+}
+
+// LifecycleRecord é um evento do ciclo de vida ocorrido na simulação.
+type LifecycleRecord struct {
+	Timestamp        time.Time  `json:"timestamp"`                  // Instante em que o equipamento passa a reportar (INSTALL, REPLACE) ou deixa de reportar (DECOMMISSION)
+	Type             string     `json:"type"`                       // INSTALL, REPLACE ou DECOMMISSION
+	DeviceId         string     `json:"deviceId"`                   // Identidade do equipamento em operação após o evento (a retirada, no DECOMMISSION)
+	PreviousDeviceId string     `json:"previousDeviceId,omitempty"` // REPLACE: identidade do equipamento retirado
+	RemovedAt        *time.Time `json:"removedAt,omitempty"`        // REPLACE: retirada do equipamento anterior, início da lacuna de telemetria
+	AssetModel       string     `json:"assetModel"`                 // Modelo do equipamento
+	Zone             string     `json:"zone"`                       // Zona atendida
+	Reason           string     `json:"reason"`                     // SCHEDULED (programado) ou FAILURE (falhas repetidas)
+}
+
+// compressorAlarms são os alarmes que contam para a troca por falhas repetidas.
+var compressorAlarms = map[string]bool{"HP-AL-01": true, "HT-FL-02": true}
+
+// lifecycleState guarda o ciclo de vida de um dispositivo.
+type lifecycleState struct {
+	baseID         string // Identidade original, base das identidades geradas nas trocas
+	baseModel      string // Modelo do equipamento original, antes das trocas
+	installAt      time.Time
+	decommissionAt time.Time
+	replacements   []LifecycleEvent // Trocas programadas ainda não executadas, por data
+
+	installed      bool
+	decommissioned bool
+	removedAt      time.Time // Retirada do equipamento em troca (zero fora da lacuna)
+	returnAt       time.Time // Início da operação do novo equipamento
+	replacement    LifecycleEvent
+	reason         string
+	replaced       int     // Trocas executadas
+	alarmHours     float64 // Horas com alarme de compressor do equipamento atual
+}
+
+// attachLifecycle distribui os eventos programados pelos dispositivos.
+func (s *Simulator) attachLifecycle(cfg *LifecycleConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	lifecycle := cfg.withDefaults()
+	if lifecycle.FailureAlarmHours < 0 || lifecycle.ReplacementGapHours < 0 {
+		return fmt.Errorf("horas de alarme e lacuna de troca não podem ser negativas")
+	}
+	byID := make(map[string]*deviceState, len(s.devices))
+	for _, device := range s.devices {
+		device.lifecycle = &lifecycleState{baseID: device.ID, baseModel: device.AssetModel}
+		byID[device.ID] = device
+	}
+	for _, event := range lifecycle.Events {
+		device, ok := byID[event.DeviceId]
+		if !ok {
+			return fmt.Errorf("evento de ciclo de vida em dispositivo inexistente '%s'", event.DeviceId)
+		}
+		if event.Date.IsZero() {
+			return fmt.Errorf("evento %s do dispositivo '%s' sem data", event.Type, event.DeviceId)
+		}
+		switch event.Type {
+		case LifecycleInstall:
+			device.lifecycle.installAt = event.Date.Time
+		case LifecycleDecommission:
+			device.lifecycle.decommissionAt = event.Date.Time
+		case LifecycleReplace:
+			device.lifecycle.replacements = append(device.lifecycle.replacements, event)
+		default:
+			return fmt.Errorf("tipo de evento de ciclo de vida '%s' desconhecido (use INSTALL, REPLACE ou DECOMMISSION)", event.Type)
+		}
+	}
+	for _, device := range s.devices {
+		state := device.lifecycle
+		if !state.installAt.IsZero() && !state.decommissionAt.IsZero() && !state.decommissionAt.After(state.installAt) {
+			return fmt.Errorf("dispositivo '%s' retirado antes de ser instalado", device.ID)
+		}
+		sort.SliceStable(state.replacements, func(i, j int) bool {
+			return state.replacements[i].Date.Before(state.replacements[j].Date.Time)
+		})
+	}
+	s.lifecycle = &lifecycle
+	return nil
+}
+
+// offline indica se a sala está sem equipamento no instante t: antes da instalação, depois da
+// retirada ou na lacuna de uma troca.
+func (d *deviceState) offline(t time.Time) bool {
+	state := d.lifecycle
+	if state == nil {
+		return false
+	}
+	if !state.installAt.IsZero() && t.Before(state.installAt) {
+		return true
+	}
+	if !state.decommissionAt.IsZero() && !t.Before(state.decommissionAt) {
+		return true
+	}
+	return !state.removedAt.IsZero() && t.Before(state.returnAt)
+}
+
+// advanceLifecycle aplica os eventos do ciclo de vida vencidos até o passo e indica se o
+// dispositivo reporta nele. Sem equipamento, a sala só deriva.
+func (s *Simulator) advanceLifecycle(device *deviceState, climateData climate.InmetClimateData) bool {
+	state := device.lifecycle
+	t := climateData.Timestamp
+	if len(state.replacements) > 0 && !t.Before(state.replacements[0].Date.Time) && state.removedAt.IsZero() {
+		event := state.replacements[0]
+		state.replacements = state.replacements[1:]
+		s.removeForReplacement(device, event, "SCHEDULED", event.Date.Time)
+	}
+	if device.offline(t) {
+		if !state.decommissionAt.IsZero() && !t.Before(state.decommissionAt) && !state.decommissioned {
+			state.decommissioned = true
+			s.recordLifecycle(LifecycleRecord{Timestamp: state.decommissionAt, Type: LifecycleDecommission, Reason: "SCHEDULED"}, device)
+		}
+		device.drift(climateData)
+		return false
+	}
+	if !state.installAt.IsZero() && !state.installed {
+		state.installed = true
+		s.recordLifecycle(LifecycleRecord{Timestamp: t, Type: LifecycleInstall, Reason: "SCHEDULED"}, device)
+	}
+	if !state.removedAt.IsZero() {
+		s.installReplacement(device, t)
+	}
+	return true
+}
+
+// removeForReplacement retira o equipamento para a troca; o novo só reporta após a lacuna.
+func (s *Simulator) removeForReplacement(device *deviceState, event LifecycleEvent, reason string, at time.Time) {
+	state := device.lifecycle
+	state.removedAt = at
+	state.returnAt = at.Add(time.Duration(s.lifecycle.ReplacementGapHours * float64(time.Hour)))
+	state.replacement = event
+	state.reason = reason
+}
+
+// installReplacement coloca o novo equipamento em operação: outra identidade, desgaste zerado e
+// falhas do equipamento anterior encerradas. A sala mantém o estado térmico.
+func (s *Simulator) installReplacement(device *deviceState, t time.Time) {
+	state := device.lifecycle
+	state.replaced++
+	previousID := device.ID
+	device.ID = state.replacement.NewDeviceId
+	if device.ID == "" {
+		device.ID = fmt.Sprintf("%s-R%d", state.baseID, state.replaced)
+	}
+	if state.replacement.AssetModel != "" {
+		device.AssetModel = state.replacement.AssetModel
+	}
+	device.filterLoad = 0
+	device.filterChangedAt = t
+	device.rechargedAt = t
+	for _, f := range device.Faults {
+		device.repairFault(f.Type, t)
+	}
+	device.pendingOrders = nil
+	device.frostLevel = 0
+	device.leadCompressor, device.leadRunHours = 0, 0
+	state.alarmHours = 0
+
+	removedAt := state.removedAt
+	s.recordLifecycle(LifecycleRecord{Timestamp: t, Type: LifecycleReplace, PreviousDeviceId: previousID, RemovedAt: &removedAt, Reason: state.reason}, device)
+	state.removedAt, state.returnAt = time.Time{}, time.Time{}
+}
+
+// countFailure acumula as horas com alarme de compressor do equipamento e o retira para troca
+// ao atingir FailureAlarmHours.
+func (s *Simulator) countFailure(device *deviceState, record HvacSensorData, hours float64) {
+	if s.lifecycle.FailureAlarmHours <= 0 || !compressorAlarms[record.FaultCode] {
+		return
+	}
+	state := device.lifecycle
+	state.alarmHours += hours
+	if state.alarmHours >= s.lifecycle.FailureAlarmHours && state.removedAt.IsZero() {
+		s.removeForReplacement(device, LifecycleEvent{}, "FAILURE", record.Timestamp)
+	}
+}
+
+func (s *Simulator) recordLifecycle(record LifecycleRecord, device *deviceState) {
+	record.DeviceId = device.ID
+	record.AssetModel = device.AssetModel
+	record.Zone = device.Zone
+	s.lifecycleRecords = append(s.lifecycleRecords, record)
+}
+
+// LifecycleEvents retorna os eventos do ciclo de vida ocorridos desde o início da simulação, com
+// LifecycleConfig ativo.
+func (s *Simulator) LifecycleEvents() []LifecycleRecord {
+	return s.lifecycleRecords
+}
+
+// WriteLifecycleJSON serializa os eventos do ciclo de vida dos equipamentos.
+func WriteLifecycleJSON(records []LifecycleRecord) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar os eventos de ciclo de vida para JSON: %w", err)
+	}
+	return jsonData, nil
+}
@@ -0,0 +1,236 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Tipos de ordem de serviço de manutenção.
+const (
+	WorkOrderFilterChange        = "FILTER_CHANGE"        // Troca do filtro de ar
+	WorkOrderCoilClean           = "COIL_CLEAN"           // Limpeza da serpentina do condensador
+	WorkOrderRefrigerantRecharge = "REFRIGERANT_RECHARGE" // Recarga de refrigerante
+	WorkOrderBeltReplacement     = "BELT_REPLACEMENT"     // Troca da correia do ventilador
+)
+
+// ScheduledWorkOrder é uma manutenção preventiva programada, única ou recorrente.
+type ScheduledWorkOrder struct {
+	DeviceId  string  `json:"deviceId"`  // Dispositivo atendido (vazio: toda a frota)
+	Type      string  `json:"type"`      // Tipo da ordem (ex: FILTER_CHANGE)
+	Date      Date    `json:"date"`      // Data da primeira execução (AAAA-MM-DD ou RFC 3339)
+	EveryDays float64 `json:"everyDays"` // Intervalo entre as execuções (dias, 0: execução única)
+}
+
+// MaintenanceConfig descreve as ordens de serviço de manutenção: as preventivas programadas e,
+// com OnCondition, as corretivas abertas quando o equipamento mostra o problema e executadas após
+// o tempo de atendimento. Cada ordem executada altera o estado do dispositivo.
+type MaintenanceConfig struct {
+	Orders        []ScheduledWorkOrder `json:"orders"`        // Manutenções preventivas programadas
+	OnCondition   bool                 `json:"onCondition"`   // Abre ordens corretivas pelas condições do equipamento
+	ResponseHours float64              `json:"responseHours"` // Tempo médio entre a abertura e a execução da ordem corretiva (h, padrão: 48)
+	FilterClog    float64              `json:"filterClog"`    // Colmatação que abre a troca de filtro (0 a 1, padrão: 0.8)
+	RechargeAt    float64              `json:"rechargeAt"`    // Saúde do compressor que abre a recarga de refrigerante (0.4 a 1, padrão: 0.6)
+}
+
+func (c MaintenanceConfig) withDefaults() MaintenanceConfig {
+	if c.ResponseHours == 0 {
+		c.ResponseHours = 48.0
+	}
+	if c.FilterClog == 0 {
+		c.FilterClog = 0.8
+	}
+	if c.RechargeAt == 0 {
+		c.RechargeAt = 0.6
+	}
+	return c
+}
+
+func (c MaintenanceConfig) validate() error {
+	for _, order := range c.Orders {
+		if !knownWorkOrder(order.Type) {
+			return fmt.Errorf("tipo de ordem de serviço '%s' desconhecido", order.Type)
+		}
+		if order.Date.IsZero() {
+			return fmt.Errorf("ordem de serviço %s programada sem data", order.Type)
+		}
+		if order.EveryDays < 0 {
+			return fmt.Errorf("intervalo da ordem de serviço %s não pode ser negativo", order.Type)
+		}
+	}
+	if c.ResponseHours < 0 {
+		return fmt.Errorf("tempo de atendimento das ordens de serviço não pode ser negativo")
+	}
+	return nil
+}
+
+func knownWorkOrder(orderType string) bool {
+	switch orderType {
+	case WorkOrderFilterChange, WorkOrderCoilClean, WorkOrderRefrigerantRecharge, WorkOrderBeltReplacement:
+		return true
+	}
+	return false
+}
+
+// WorkOrder é uma ordem de serviço executada, com o efeito sobre o estado do dispositivo.
+type WorkOrder struct {
+	WorkOrderId string          `json:"workOrderId"` // Identificador sequencial (ex: WO-000001)
+	DeviceId    string          `json:"deviceId"`    // Dispositivo atendido
+	Type        string          `json:"type"`        // FILTER_CHANGE, COIL_CLEAN, REFRIGERANT_RECHARGE ou BELT_REPLACEMENT
+	Trigger     string          `json:"trigger"`     // SCHEDULED (preventiva) ou CONDITION (corretiva)
+	OpenedAt    time.Time       `json:"openedAt"`    // Abertura da ordem
+	CompletedAt time.Time       `json:"completedAt"` // Execução da ordem; as leituras a partir daqui já refletem o efeito
+	Effect      WorkOrderEffect `json:"effect"`      // Grandeza do estado alterada pela ordem
+}
+
+// WorkOrderEffect é a grandeza do estado do dispositivo antes e depois da ordem de serviço.
+type WorkOrderEffect struct {
+	State  string  `json:"state"`  // filterClog, condenserFoulingSeverity, equipmentHealth ou airflowDegradationSeverity
+	Before float64 `json:"before"` // Valor antes da ordem
+	After  float64 `json:"after"`  // Valor depois da ordem
+}
+
+const serviceWearDays = 270.0 // Dias para voltar ao desgaste anterior à manutenção, como no ciclo anual do modelo sazonal
+
+// pendingOrder é uma ordem corretiva aberta e ainda não executada.
+type pendingOrder struct {
+	orderType string
+	openedAt  time.Time
+	dueAt     time.Time
+}
+
+// performMaintenance executa as ordens do dispositivo vencidas até o instante t, antes de o passo
+// calcular o estado do equipamento.
+func (s *Simulator) performMaintenance(device *deviceState, t time.Time) {
+	for i, order := range s.maintenance.Orders {
+		if order.DeviceId != "" && order.DeviceId != device.ID {
+			continue
+		}
+		for {
+			done := device.scheduledDone[i]
+			due := order.Date.Add(time.Duration(float64(done) * order.EveryDays * 24 * float64(time.Hour)))
+			if t.Before(due) || (done > 0 && order.EveryDays == 0) {
+				break
+			}
+			if device.scheduledDone == nil {
+				device.scheduledDone = make(map[int]int)
+			}
+			device.scheduledDone[i]++
+			if !device.hasState && due.Before(t) {
+				continue // Execução anterior ao início da série
+			}
+			s.completeWorkOrder(device, order.Type, "SCHEDULED", due, t)
+		}
+	}
+
+	remaining := device.pendingOrders[:0]
+	for _, order := range device.pendingOrders {
+		if t.Before(order.dueAt) {
+			remaining = append(remaining, order)
+			continue
+		}
+		s.completeWorkOrder(device, order.orderType, "CONDITION", order.openedAt, t)
+	}
+	device.pendingOrders = remaining
+}
+
+// completeWorkOrder aplica o efeito da ordem no estado do dispositivo e a registra.
+func (s *Simulator) completeWorkOrder(device *deviceState, orderType, trigger string, openedAt, t time.Time) {
+	effect := WorkOrderEffect{}
+	switch orderType {
+	case WorkOrderFilterChange:
+		effect = WorkOrderEffect{State: "filterClog", Before: device.lastFilterClog}
+		device.filterLoad = 0
+		device.filterChangedAt = t
+	case WorkOrderRefrigerantRecharge:
+		effect = WorkOrderEffect{State: "equipmentHealth", Before: device.lastHealth, After: 1.0}
+		device.rechargedAt = t
+	case WorkOrderCoilClean:
+		effect = WorkOrderEffect{State: "condenserFoulingSeverity", Before: device.activeFaults(t)[FaultCondenserFouling]}
+		device.repairFault(FaultCondenserFouling, t)
+	case WorkOrderBeltReplacement:
+		effect = WorkOrderEffect{State: "airflowDegradationSeverity", Before: device.activeFaults(t)[FaultAirflowDegradation]}
+		device.repairFault(FaultAirflowDegradation, t)
+	}
+	s.workOrders = append(s.workOrders, WorkOrder{
+		WorkOrderId: fmt.Sprintf("WO-%06d", len(s.workOrders)+1),
+		DeviceId:    device.ID,
+		Type:        orderType,
+		Trigger:     trigger,
+		OpenedAt:    openedAt,
+		CompletedAt: t,
+		Effect:      effect,
+	})
+}
+
+// serviceCondition limita o desgaste do filtro e do compressor pelo tempo desde a última troca de
+// filtro e recarga: após a manutenção, o equipamento volta a se desgastar a partir do novo.
+func (d *deviceState) serviceCondition(condition EquipmentCondition, t time.Time, filterModel bool) EquipmentCondition {
+	if !d.filterChangedAt.IsZero() && !filterModel {
+		condition.FilterClog = math.Min(condition.FilterClog, t.Sub(d.filterChangedAt).Hours()/24.0/serviceWearDays*0.8)
+	}
+	if !d.rechargedAt.IsZero() {
+		condition.Health = math.Max(condition.Health, 1.0-t.Sub(d.rechargedAt).Hours()/24.0/serviceWearDays*0.4)
+	}
+	return condition
+}
+
+// repairFault encerra as falhas injetadas do tipo já iniciadas: falhas que começam depois do
+// reparo continuam valendo.
+func (d *deviceState) repairFault(faultType string, t time.Time) {
+	if d.repairedFaults == nil {
+		d.repairedFaults = make(map[string]time.Time)
+	}
+	d.repairedFaults[faultType] = t
+}
+
+// openWorkOrders abre as ordens corretivas pelas condições do equipamento no fim do passo. Cada
+// tipo tem no máximo uma ordem aberta por dispositivo.
+func (s *Simulator) openWorkOrders(device *deviceState, t time.Time, condition EquipmentCondition, faults map[string]float64, highPressureTrip bool) {
+	device.lastFilterClog, device.lastHealth = condition.FilterClog, condition.Health
+	if !s.maintenance.OnCondition {
+		return
+	}
+	needed := map[string]bool{
+		WorkOrderFilterChange:        condition.FilterClog >= s.maintenance.FilterClog,
+		WorkOrderRefrigerantRecharge: condition.Health < s.maintenance.RechargeAt,
+		WorkOrderCoilClean:           highPressureTrip || faults[FaultCondenserFouling] >= 0.5,
+		WorkOrderBeltReplacement:     faults[FaultAirflowDegradation] >= 0.5,
+	}
+	for _, orderType := range []string{WorkOrderFilterChange, WorkOrderRefrigerantRecharge, WorkOrderCoilClean, WorkOrderBeltReplacement} {
+		if !needed[orderType] || device.hasPendingOrder(orderType) {
+			continue
+		}
+		response := s.maintenance.ResponseHours * (0.5 + s.rng.Float64())
+		device.pendingOrders = append(device.pendingOrders, pendingOrder{
+			orderType: orderType,
+			openedAt:  t,
+			dueAt:     t.Add(time.Duration(response * float64(time.Hour))),
+		})
+	}
+}
+
+func (d *deviceState) hasPendingOrder(orderType string) bool {
+	for _, order := range d.pendingOrders {
+		if order.orderType == orderType {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkOrders retorna as ordens de serviço executadas desde o início da simulação, na ordem de
+// execução, com MaintenanceConfig ativo.
+func (s *Simulator) WorkOrders() []WorkOrder {
+	return s.workOrders
+}
+
+// WriteWorkOrdersJSON serializa as ordens de serviço executadas.
+func WriteWorkOrdersJSON(orders []WorkOrder) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(orders, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar as ordens de serviço para JSON: %w", err)
+	}
+	return jsonData, nil
+}
@@ -0,0 +1,169 @@
+package hvac
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Padrões de ausência de dados por campo.
+const (
+	MissingRandom = "random" // Leituras perdidas de forma independente
+	MissingBursty = "bursty" // Lacunas contínuas, como um sensor que trava ou perde a comunicação
+	MissingAlways = "always" // Campo nunca reportado pelos dispositivos listados (sensor não instalado)
+)
+
+// FieldMissingness descreve a ausência de um campo nas leituras.
+type FieldMissingness struct {
+	Field      string   `json:"field"`      // Caminho do campo (ex: co2LevelPpm, erv.supplyAirTemp)
+	Pattern    string   `json:"pattern"`    // random (padrão), bursty ou always
+	Rate       float64  `json:"rate"`       // random e bursty: fração das leituras sem o campo (0 a 1)
+	BurstHours float64  `json:"burstHours"` // bursty: duração média das lacunas (h, padrão: 6)
+	Devices    []string `json:"devices"`    // Dispositivos afetados (vazio: toda a frota; obrigatório em always)
+}
+
+// MissingnessConfig remove campos das leituras, como sensores que falham, perdem pacotes ou não
+// existem em parte da frota. Os formatos colunares gravam o campo ausente como nulo; no JSON,
+// Representation escolhe entre null e omitir a chave.
+type MissingnessConfig struct {
+	Fields         []FieldMissingness `json:"fields"`         // Regras de ausência, uma por campo e padrão
+	Representation string             `json:"representation"` // JSON: null (padrão) ou omit
+}
+
+func (c MissingnessConfig) withDefaults() MissingnessConfig {
+	if c.Representation == "" {
+		c.Representation = "null"
+	}
+	fields := make([]FieldMissingness, len(c.Fields))
+	for i, field := range c.Fields {
+		if field.Pattern == "" {
+			field.Pattern = MissingRandom
+		}
+		if field.BurstHours == 0 {
+			field.BurstHours = 6.0
+		}
+		fields[i] = field
+	}
+	c.Fields = fields
+	return c
+}
+
+func (c MissingnessConfig) validate(devices []*deviceState) error {
+	if c.Representation != "null" && c.Representation != "omit" {
+		return fmt.Errorf("representação de campo ausente '%s' desconhecida (use null ou omit)", c.Representation)
+	}
+	known := recordFieldPaths()
+	fleet := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		fleet[device.ID] = true
+	}
+	for _, field := range c.Fields {
+		if !known[field.Field] || strings.HasPrefix(field.Field, "expected.") {
+			return fmt.Errorf("campo '%s' desconhecido na configuração de ausência de dados", field.Field)
+		}
+		switch field.Pattern {
+		case MissingRandom, MissingBursty:
+			if field.Rate < 0 || field.Rate >= 1 {
+				return fmt.Errorf("taxa de ausência de '%s' deve estar entre 0 e 1, recebido %.2f", field.Field, field.Rate)
+			}
+		case MissingAlways:
+			if len(field.Devices) == 0 {
+				return fmt.Errorf("ausência always de '%s' exige a lista de dispositivos", field.Field)
+			}
+		default:
+			return fmt.Errorf("padrão de ausência '%s' desconhecido (use random, bursty ou always)", field.Pattern)
+		}
+		if field.BurstHours < 0 {
+			return fmt.Errorf("duração das lacunas de '%s' não pode ser negativa", field.Field)
+		}
+		for _, id := range field.Devices {
+			if !fleet[id] {
+				return fmt.Errorf("dispositivo '%s' da ausência de '%s' não existe na frota", id, field.Field)
+			}
+		}
+	}
+	return nil
+}
+
+// markMissing sorteia os campos ausentes da leitura do dispositivo e os registra em record.Missing.
+// Cada regra sorteia em todo passo, mesmo sem o campo no registro, para que a sequência não dependa
+// dos blocos opcionais presentes.
+func (s *Simulator) markMissing(device *deviceState, record *HvacSensorData, hours float64) {
+	for i, rule := range s.missingness.Fields {
+		if len(rule.Devices) > 0 && !slices.Contains(rule.Devices, device.ID) {
+			continue
+		}
+		missing := false
+		switch rule.Pattern {
+		case MissingAlways:
+			missing = true
+		case MissingRandom:
+			missing = s.rng.Float64() < rule.Rate
+		case MissingBursty:
+			missing = s.missingBurst(device, i, rule, record.Timestamp, hours)
+		}
+		if missing && !slices.Contains(record.Missing, rule.Field) {
+			record.Missing = append(record.Missing, rule.Field)
+		}
+	}
+}
+
+// missingBurst mantém as lacunas contínuas do campo: cada lacuna dura em média BurstHours, e os
+// inícios são sorteados para que a fração de leituras perdidas fique em Rate.
+func (s *Simulator) missingBurst(device *deviceState, rule int, cfg FieldMissingness, t time.Time, hours float64) bool {
+	if device.missingUntil == nil {
+		device.missingUntil = make(map[int]time.Time)
+	}
+	until := device.missingUntil[rule]
+	if t.Before(until) {
+		return true
+	}
+	if cfg.Rate <= 0 {
+		return false
+	}
+	gapHours := cfg.BurstHours * (1.0 - cfg.Rate) / cfg.Rate // Intervalo médio entre as lacunas
+	if s.rng.Float64() >= hours/(gapHours+hours) {
+		return false
+	}
+	duration := math.Max(hours, s.rng.ExpFloat64()*cfg.BurstHours)
+	device.missingUntil[rule] = t.Add(time.Duration(duration * float64(time.Hour)))
+	return true
+}
+
+// applyMissing aplica os campos ausentes ao payload JSON canônico: null, ou a chave removida com omit.
+func applyMissing(payload map[string]any, missing []string, omit bool) {
+	for _, path := range missing {
+		if lookupPath(payload, path) == nil {
+			continue
+		}
+		if omit {
+			deletePath(payload, path)
+		} else {
+			setPath(payload, path, nil)
+		}
+	}
+}
+
+// nullableMissing torna anuláveis as colunas dos campos ausentes em algum registro: o valor sai
+// nulo nos registros que não o reportaram.
+func nullableMissing(columns []column, missing map[string]bool) []column {
+	for i, c := range columns {
+		path := strings.ReplaceAll(c.name, "_", ".")
+		if !missing[path] || (c.kind != kindFloat && c.kind != kindNullableFloat) {
+			continue
+		}
+		value := c.value
+		columns[i] = column{c.name, kindNullableFloat, func(d *HvacSensorData) any {
+			if slices.Contains(d.Missing, path) {
+				return (*float64)(nil)
+			}
+			if v, ok := value(d).(float64); ok {
+				return &v
+			}
+			return value(d)
+		}}
+	}
+	return columns
+}
@@ -0,0 +1,398 @@
+package hvac
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/apache/arrow-go/v18/parquet/compress"
+)
+
+// MLDatasetConfig ativa a exportação do conjunto de dados pronto para ML: os registros divididos em
+// treino, validação e teste e, opcionalmente, as janelas deslizantes com rótulo de cada divisão.
+type MLDatasetConfig struct {
+	SplitBy    string         `json:"splitBy"`    // time (padrão): por período, sem vazamento do futuro; device: por dispositivo
+	Train      float64        `json:"train"`      // Fração do treino (padrão: 0.7)
+	Validation float64        `json:"validation"` // Fração da validação (padrão: 0.15)
+	Test       float64        `json:"test"`       // Fração do teste (padrão: 0.15)
+	Windows    *WindowsConfig `json:"windows"`    // Janelas deslizantes em NPZ (desativadas se ausente)
+}
+
+// WindowsConfig descreve os tensores de janelas deslizantes: cada amostra são Length leituras
+// consecutivas de um dispositivo, com o rótulo lido Horizon leituras após o fim da janela.
+type WindowsConfig struct {
+	Length   int      `json:"length"`   // Leituras por janela (padrão: 24, um dia de dados horários)
+	Stride   int      `json:"stride"`   // Leituras entre o início de janelas consecutivas (padrão: 1)
+	Horizon  int      `json:"horizon"`  // Leituras entre o fim da janela e o rótulo (padrão: 1)
+	Features []string `json:"features"` // Caminhos dos campos de entrada (padrão: as grandezas medidas da leitura)
+	Label    string   `json:"label"`    // fault (padrão: alarme em faultCode), activeFault (falha injetada ativa) ou caminho de um campo numérico
+}
+
+var defaultWindowFeatures = []string{
+	"internalTemperature", "setPointTemperature", "outdoorTemperature", "outdoorHumidity", "supplyAirTemperature",
+	"returnAirTemperature", "ductStaticPressurePa", "co2LevelPpm", "refrigerantPressurePsi", "powerConsumptionKwH",
+}
+
+var mlSplits = []string{"train", "validation", "test"}
+
+func (c MLDatasetConfig) withDefaults() MLDatasetConfig {
+	if c.SplitBy == "" {
+		c.SplitBy = "time"
+	}
+	if c.Train == 0 && c.Validation == 0 && c.Test == 0 {
+		c.Train, c.Validation, c.Test = 0.7, 0.15, 0.15
+	}
+	if c.Windows != nil {
+		windows := *c.Windows
+		if windows.Length == 0 {
+			windows.Length = 24
+		}
+		if windows.Stride == 0 {
+			windows.Stride = 1
+		}
+		if windows.Horizon == 0 {
+			windows.Horizon = 1
+		}
+		if len(windows.Features) == 0 {
+			windows.Features = defaultWindowFeatures
+		}
+		if windows.Label == "" {
+			windows.Label = "fault"
+		}
+		c.Windows = &windows
+	}
+	return c
+}
+
+func (c MLDatasetConfig) validate() error {
+	if c.SplitBy != "time" && c.SplitBy != "device" {
+		return fmt.Errorf("divisão do conjunto de ML '%s' desconhecida (use time ou device)", c.SplitBy)
+	}
+	if c.Train <= 0 || c.Validation < 0 || c.Test < 0 || math.Abs(c.Train+c.Validation+c.Test-1.0) > 1e-6 {
+		return fmt.Errorf("frações de treino, validação e teste devem ser positivas e somar 1, recebido %.2f, %.2f e %.2f", c.Train, c.Validation, c.Test)
+	}
+	if c.Windows == nil {
+		return nil
+	}
+	if c.Windows.Length < 1 || c.Windows.Stride < 1 || c.Windows.Horizon < 0 {
+		return fmt.Errorf("comprimento, passo e horizonte das janelas devem ser positivos")
+	}
+	known := recordFieldPaths()
+	for _, feature := range c.Windows.Features {
+		if !known[feature] {
+			return fmt.Errorf("campo '%s' desconhecido nas entradas das janelas", feature)
+		}
+	}
+	if c.Windows.Label != "fault" && c.Windows.Label != "activeFault" && !known[c.Windows.Label] {
+		return fmt.Errorf("rótulo das janelas '%s' desconhecido (use fault, activeFault ou o caminho de um campo numérico)", c.Windows.Label)
+	}
+	return nil
+}
+
+// BuildMLDataset gera os arquivos do conjunto de dados para ML, sempre na mesma ordem: um Parquet por
+// divisão (train.parquet, validation.parquet, test.parquet), com o esquema colunar achatado comum a
+// todas, e, com janelas, um NPZ por divisão em seguida (windows_train.npz...). As janelas não
+// atravessam divisões nem lacunas da série do dispositivo.
+func BuildMLDataset(cfg MLDatasetConfig, data []HvacSensorData) ([]OutputFile, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	columns := tabularColumns(data)
+	splits := splitDataset(cfg, data)
+	var files []OutputFile
+	for i, split := range splits {
+		parquetData, err := writeParquet(split, columns, compress.Codecs.Snappy)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao gravar a divisão %s do conjunto de ML: %w", mlSplits[i], err)
+		}
+		files = append(files, OutputFile{Name: mlSplits[i] + ".parquet", Data: parquetData})
+	}
+	if cfg.Windows == nil {
+		return files, nil
+	}
+	for i, split := range splits {
+		npz, err := writeWindowsNPZ(*cfg.Windows, split)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao gravar as janelas da divisão %s do conjunto de ML: %w", mlSplits[i], err)
+		}
+		files = append(files, OutputFile{Name: "windows_" + mlSplits[i] + ".npz", Data: npz})
+	}
+	return files, nil
+}
+
+// splitDataset divide os registros em treino, validação e teste. Por tempo, os cortes caem nas
+// frações do período coberto; por dispositivo, nas frações da lista ordenada de dispositivos.
+func splitDataset(cfg MLDatasetConfig, data []HvacSensorData) [3][]HvacSensorData {
+	var splits [3][]HvacSensorData
+	if len(data) == 0 {
+		return splits
+	}
+	splitOf := func(position float64) int {
+		switch {
+		case position < cfg.Train:
+			return 0
+		case position < cfg.Train+cfg.Validation:
+			return 1
+		}
+		return 2
+	}
+
+	if cfg.SplitBy == "device" {
+		var ids []string
+		seen := make(map[string]bool)
+		for _, record := range data {
+			if !seen[record.DeviceId] {
+				seen[record.DeviceId] = true
+				ids = append(ids, record.DeviceId)
+			}
+		}
+		sort.Strings(ids)
+		index := make(map[string]int, len(ids))
+		for i, id := range ids {
+			index[id] = splitOf((float64(i) + 0.5) / float64(len(ids)))
+		}
+		for _, record := range data {
+			splits[index[record.DeviceId]] = append(splits[index[record.DeviceId]], record)
+		}
+		return splits
+	}
+
+	first, last := data[0].Timestamp, data[0].Timestamp
+	for _, record := range data {
+		if record.Timestamp.Before(first) {
+			first = record.Timestamp
+		}
+		if record.Timestamp.After(last) {
+			last = record.Timestamp
+		}
+	}
+	span := last.Sub(first).Seconds()
+	for _, record := range data {
+		position := 0.0
+		if span > 0 {
+			position = record.Timestamp.Sub(first).Seconds() / span
+		}
+		i := splitOf(math.Min(position, math.Nextafter(1, 0)))
+		splits[i] = append(splits[i], record)
+	}
+	return splits
+}
+
+// windowSample é uma janela deslizante de um dispositivo.
+type windowSample struct {
+	deviceId string
+	start    time.Time
+	values   []float32 // Length × Features, em ordem de linha
+	label    float32
+}
+
+// buildWindows monta as janelas de cada dispositivo. Campos ausentes (Missingness) viram NaN; uma
+// lacuna maior que o dobro do intervalo típico do dispositivo interrompe as janelas.
+func buildWindows(cfg WindowsConfig, data []HvacSensorData) []windowSample {
+	byDevice := make(map[string][]HvacSensorData)
+	var ids []string
+	for _, record := range data {
+		if _, ok := byDevice[record.DeviceId]; !ok {
+			ids = append(ids, record.DeviceId)
+		}
+		byDevice[record.DeviceId] = append(byDevice[record.DeviceId], record)
+	}
+	sort.Strings(ids)
+
+	var samples []windowSample
+	for _, id := range ids {
+		series := byDevice[id]
+		sort.SliceStable(series, func(i, j int) bool { return series[i].Timestamp.Before(series[j].Timestamp) })
+		rows := make([][]float32, len(series))
+		for i := range series {
+			rows[i] = featureRow(&series[i], cfg.Features)
+		}
+		maxGap := 2 * typicalInterval(series)
+		span := cfg.Length + cfg.Horizon
+		for start := 0; start+span <= len(series); start += cfg.Stride {
+			if hasGap(series[start:start+span], maxGap) {
+				continue
+			}
+			sample := windowSample{deviceId: id, start: series[start].Timestamp, values: make([]float32, 0, cfg.Length*len(cfg.Features))}
+			for _, row := range rows[start : start+cfg.Length] {
+				sample.values = append(sample.values, row...)
+			}
+			target := series[start+cfg.Length-1+cfg.Horizon]
+			switch cfg.Label {
+			case "fault":
+				sample.label = boolFloat(target.FaultCode != "" && target.FaultCode != "OK")
+			case "activeFault":
+				sample.label = boolFloat(len(target.ActiveFaults) > 0)
+			default:
+				sample.label = featureRow(&target, []string{cfg.Label})[0]
+			}
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+// typicalInterval é a mediana dos intervalos entre leituras consecutivas.
+func typicalInterval(series []HvacSensorData) time.Duration {
+	if len(series) < 2 {
+		return time.Hour
+	}
+	intervals := make([]time.Duration, 0, len(series)-1)
+	for i := 1; i < len(series); i++ {
+		intervals = append(intervals, series[i].Timestamp.Sub(series[i-1].Timestamp))
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
+
+func hasGap(series []HvacSensorData, maxGap time.Duration) bool {
+	for i := 1; i < len(series); i++ {
+		if series[i].Timestamp.Sub(series[i-1].Timestamp) > maxGap {
+			return true
+		}
+	}
+	return false
+}
+
+// featureRow lê os campos numéricos do registro pelos caminhos; campos ausentes (Missingness) ou
+// fora do registro valem NaN.
+func featureRow(record *HvacSensorData, paths []string) []float32 {
+	values := make(map[string]float64)
+	for _, field := range recordFloatFields(record) {
+		if _, ok := values[field.path]; !ok {
+			values[field.path] = *field.value
+		}
+	}
+	for _, missing := range record.Missing {
+		delete(values, missing)
+	}
+	row := make([]float32, len(paths))
+	for i, path := range paths {
+		v, ok := values[path]
+		if !ok {
+			v = math.NaN()
+		}
+		row[i] = float32(v)
+	}
+	return row
+}
+
+func boolFloat(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// writeWindowsNPZ grava as janelas no formato NPZ do NumPy: X (amostras × Length × features,
+// float32), y (rótulos, float32), window_start (início de cada janela, ms Unix), device_id e
+// features (nomes das entradas).
+func writeWindowsNPZ(cfg WindowsConfig, data []HvacSensorData) ([]byte, error) {
+	samples := buildWindows(cfg, data)
+	x := make([]float32, 0, len(samples)*cfg.Length*len(cfg.Features))
+	y := make([]float32, len(samples))
+	starts := make([]int64, len(samples))
+	devices := make([]string, len(samples))
+	for i, sample := range samples {
+		x = append(x, sample.values...)
+		y[i] = sample.label
+		starts[i] = sample.start.UnixMilli()
+		devices[i] = sample.deviceId
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	arrays := []struct {
+		name  string
+		shape []int
+		data  any
+	}{
+		{"X", []int{len(samples), cfg.Length, len(cfg.Features)}, x},
+		{"y", []int{len(samples)}, y},
+		{"window_start", []int{len(samples)}, starts},
+		{"device_id", []int{len(samples)}, devices},
+		{"features", []int{len(cfg.Features)}, cfg.Features},
+	}
+	for _, array := range arrays {
+		w, err := archive.Create(array.name + ".npy")
+		if err != nil {
+			return nil, fmt.Errorf("erro ao criar o array '%s' no NPZ: %w", array.name, err)
+		}
+		if err := writeNPY(w, array.shape, array.data); err != nil {
+			return nil, fmt.Errorf("erro ao gravar o array '%s' no NPZ: %w", array.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar o NPZ: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeNPY grava um array no formato .npy versão 1.0: cabeçalho com o dicionário do tipo e da
+// forma, alinhado a 64 bytes, seguido dos dados em little-endian. Aceita []float32, []int64 e
+// []string, gravado como texto UTF-32 com a largura da maior string.
+func writeNPY(w io.Writer, shape []int, data any) error {
+	var descr string
+	width := 1
+	switch values := data.(type) {
+	case []float32:
+		descr = "<f4"
+	case []int64:
+		descr = "<i8"
+	case []string:
+		for _, v := range values {
+			width = max(width, utf8.RuneCountInString(v))
+		}
+		descr = fmt.Sprintf("<U%d", width)
+	default:
+		return fmt.Errorf("tipo %T não suportado no NPY", data)
+	}
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		dims[i] = fmt.Sprint(d)
+	}
+	shapeText := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		shapeText += ","
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, shapeText)
+	padding := 64 - (10+len(header)+1)%64
+	header += strings.Repeat(" ", padding%64) + "\n"
+
+	var prefix bytes.Buffer
+	prefix.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&prefix, binary.LittleEndian, uint16(len(header)))
+	prefix.WriteString(header)
+	if _, err := w.Write(prefix.Bytes()); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	switch values := data.(type) {
+	case []string:
+		for _, v := range values {
+			runes := []rune(v)
+			for i := range width {
+				r := rune(0)
+				if i < len(runes) {
+					r = runes[i]
+				}
+				binary.Write(&body, binary.LittleEndian, uint32(r))
+			}
+		}
+	default:
+		if err := binary.Write(&body, binary.LittleEndian, values); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
@@ -0,0 +1,152 @@
+package hvac
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// CorrelatedNoiseConfig soma às leituras um ruído de medição correlacionado entre campos
+// relacionados, como os de sensores que compartilham alimentação, cabeamento e condições do
+// ambiente. Sem Fields, usa as temperaturas de insuflamento e retorno e as pressões de refrigerante
+// e de dutos, com a correlação padrão entre elas.
+type CorrelatedNoiseConfig struct {
+	Fields      []string    `json:"fields"`      // Caminhos dos campos (ex: supplyAirTemperature, erv.supplyAirTemp)
+	StdDev      []float64   `json:"stdDev"`      // Desvio padrão do ruído de cada campo, na unidade do campo
+	Correlation [][]float64 `json:"correlation"` // Matriz de correlação entre os campos, simétrica e positiva definida (padrão: identidade)
+}
+
+var (
+	defaultNoiseFields      = []string{"supplyAirTemperature", "returnAirTemperature", "refrigerantPressurePsi", "ductStaticPressurePa"}
+	defaultNoiseStdDev      = []float64{0.3, 0.2, 2.0, 2.0}
+	defaultNoiseCorrelation = [][]float64{
+		{1.0, 0.7, 0.4, 0.2},
+		{0.7, 1.0, 0.3, 0.1},
+		{0.4, 0.3, 1.0, 0.5},
+		{0.2, 0.1, 0.5, 1.0},
+	}
+)
+
+func (c CorrelatedNoiseConfig) withDefaults() CorrelatedNoiseConfig {
+	if len(c.Fields) == 0 {
+		c.Fields = defaultNoiseFields
+		if len(c.StdDev) == 0 {
+			c.StdDev = defaultNoiseStdDev
+		}
+		if len(c.Correlation) == 0 {
+			c.Correlation = defaultNoiseCorrelation
+		}
+	}
+	if len(c.Correlation) == 0 {
+		c.Correlation = make([][]float64, len(c.Fields))
+		for i := range c.Correlation {
+			c.Correlation[i] = make([]float64, len(c.Fields))
+			c.Correlation[i][i] = 1.0
+		}
+	}
+	return c
+}
+
+// correlatedNoise é o ruído correlacionado validado, com o fator de Cholesky da matriz de covariância.
+type correlatedNoise struct {
+	fields []string
+	factor [][]float64 // Triangular inferior L, com L·Lᵀ igual à covariância
+}
+
+func newCorrelatedNoise(cfg CorrelatedNoiseConfig) (*correlatedNoise, error) {
+	n := len(cfg.Fields)
+	known := recordFieldPaths()
+	seen := make(map[string]bool, n)
+	for _, field := range cfg.Fields {
+		if !known[field] {
+			return nil, fmt.Errorf("campo '%s' desconhecido na configuração de ruído correlacionado", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("campo '%s' repetido na configuração de ruído correlacionado", field)
+		}
+		seen[field] = true
+	}
+	if len(cfg.StdDev) != n {
+		return nil, fmt.Errorf("ruído correlacionado com %d campos e %d desvios padrão", n, len(cfg.StdDev))
+	}
+	if len(cfg.Correlation) != n {
+		return nil, fmt.Errorf("matriz de correlação deve ser %dx%d, recebida com %d linhas", n, n, len(cfg.Correlation))
+	}
+	covariance := make([][]float64, n)
+	for i, row := range cfg.Correlation {
+		if len(row) != n {
+			return nil, fmt.Errorf("linha %d da matriz de correlação com %d colunas, esperado %d", i+1, len(row), n)
+		}
+		if cfg.StdDev[i] < 0 {
+			return nil, fmt.Errorf("desvio padrão do ruído de '%s' não pode ser negativo", cfg.Fields[i])
+		}
+		covariance[i] = make([]float64, n)
+		for j, r := range row {
+			if r < -1 || r > 1 || math.Abs(r-cfg.Correlation[j][i]) > 1e-9 || (i == j && r != 1) {
+				return nil, fmt.Errorf("matriz de correlação inválida em (%d, %d): deve ser simétrica, com diagonal 1 e valores entre -1 e 1", i+1, j+1)
+			}
+			covariance[i][j] = r * cfg.StdDev[i] * cfg.StdDev[j]
+		}
+	}
+	factor, err := cholesky(covariance)
+	if err != nil {
+		return nil, err
+	}
+	return &correlatedNoise{fields: cfg.Fields, factor: factor}, nil
+}
+
+// cholesky decompõe a matriz simétrica em L·Lᵀ. Desvios padrão nulos deixam a matriz só
+// semidefinida, e o campo fica sem ruído.
+func cholesky(m [][]float64) ([][]float64, error) {
+	n := len(m)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+	}
+	for i := range n {
+		for j := 0; j <= i; j++ {
+			sum := m[i][j]
+			for k := range j {
+				sum -= l[i][k] * l[j][k]
+			}
+			switch {
+			case i == j && sum < -1e-12:
+				return nil, fmt.Errorf("matriz de correlação não é positiva definida")
+			case i == j:
+				l[i][i] = math.Sqrt(math.Max(0, sum))
+			case l[j][j] > 0:
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+	return l, nil
+}
+
+// apply soma o ruído correlacionado aos campos presentes no registro. Campos fora de °C, como
+// pressões e concentrações, não ficam negativos.
+func (n *correlatedNoise) apply(record *HvacSensorData, rng *rand.Rand, scale float64) {
+	z := make([]float64, len(n.fields))
+	for i := range z {
+		z[i] = rng.NormFloat64()
+	}
+	fields := make(map[string]floatField)
+	for _, field := range recordFloatFields(record) {
+		if _, ok := fields[field.path]; !ok {
+			fields[field.path] = field
+		}
+	}
+	for i, path := range n.fields {
+		field, ok := fields[path]
+		if !ok {
+			continue
+		}
+		delta := 0.0
+		for k := 0; k <= i; k++ {
+			delta += n.factor[i][k] * z[k]
+		}
+		*field.value += delta * scale
+		if field.unit != "°C" {
+			*field.value = math.Max(0, *field.value)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package hvac
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// OccupancyModel decide se a sala está ocupada no instante informado. Deve sortear com o rng
+// recebido, para que a simulação seja reprodutível pela semente.
+type OccupancyModel interface {
+	Occupied(t time.Time, rng *rand.Rand) bool
+}
+
+// ScheduleOccupancy é o modelo de ocupação por horário: probabilidades de presença no horário
+// comercial dos dias úteis, no almoço e fora do expediente.
+type ScheduleOccupancy struct {
+	StartHour     int     // Início do horário comercial (padrão: 8)
+	EndHour       int     // Fim do horário comercial (padrão: 18)
+	BusinessHours float64 // Probabilidade de ocupação no horário comercial (padrão: 0.90)
+	Lunch         float64 // Probabilidade de ocupação entre 12h e 14h, todos os dias (padrão: 0.30)
+	OffHours      float64 // Probabilidade de ocupação fora do expediente e nos fins de semana (padrão: 0.10)
+}
+
+// DefaultOccupancy retorna o modelo de ocupação padrão do simulador.
+func DefaultOccupancy() ScheduleOccupancy {
+	return ScheduleOccupancy{StartHour: 8, EndHour: 18, BusinessHours: 0.90, Lunch: 0.30, OffHours: 0.10}
+}
+
+// Occupied implementa OccupancyModel.
+func (o ScheduleOccupancy) Occupied(t time.Time, rng *rand.Rand) bool {
+	hour := t.Hour()
+	weekday := t.Weekday()
+
+	if hour >= 12 && hour < 14 { // Hora do almoço
+		return rng.Float64() < o.Lunch
+	}
+	if weekday >= time.Monday && weekday <= time.Friday {
+		if hour >= o.StartHour && hour < o.EndHour { // Horário comercial
+			return rng.Float64() < o.BusinessHours
+		}
+	}
+	return rng.Float64() < o.OffHours // Fora do horário comercial / Fim de semana
+}
+
+// ModelParams reúne as constantes do modelo de termostato, ocupação, falhas e ruído.
+type ModelParams struct {
+	BaseSetpoint float64        // Setpoint programado das salas (°C)
+	Deadband     float64        // Desvio do setpoint que liga o resfriamento ou o aquecimento (°C)
+	Occupancy    OccupancyModel // Modelo de ocupação das salas
+	FaultRate    float64        // Multiplicador da taxa de alarmes de compressor e filtro do modelo padrão (0 desliga)
+	NoiseScale   float64        // Multiplicador do ruído de processo e de medição (0 remove)
+}
+
+// DefaultModelParams retorna os parâmetros padrão do modelo.
+func DefaultModelParams() ModelParams {
+	return ModelParams{
+		BaseSetpoint: baseInternalTemp,
+		Deadband:     defaultDeadband,
+		Occupancy:    DefaultOccupancy(),
+		FaultRate:    1.0,
+		NoiseScale:   1.0,
+	}
+}
+
+func (p ModelParams) validate() error {
+	if p.Deadband <= 0 {
+		return fmt.Errorf("banda morta deve ser positiva, recebido %.2f", p.Deadband)
+	}
+	if p.FaultRate < 0 {
+		return fmt.Errorf("taxa de falhas não pode ser negativa, recebido %.2f", p.FaultRate)
+	}
+	if p.NoiseScale < 0 {
+		return fmt.Errorf("escala de ruído não pode ser negativa, recebido %.2f", p.NoiseScale)
+	}
+	if p.Occupancy == nil {
+		return fmt.Errorf("modelo de ocupação não informado")
+	}
+	return nil
+}
+
+// Option ajusta a configuração de um Generator.
+type Option func(*SimulatorConfig)
+
+// WithConfig parte de uma configuração completa do simulador (estratégias, falhas injetadas,
+// sensores); as demais opções são aplicadas por cima.
+func WithConfig(cfg SimulatorConfig) Option {
+	return func(c *SimulatorConfig) {
+		model := c.Model
+		*c = cfg
+		if c.Model == nil {
+			c.Model = model
+		}
+	}
+}
+
+// WithSeed fixa a semente dos geradores aleatórios.
+func WithSeed(seed int64) Option {
+	return func(c *SimulatorConfig) { c.Seed = seed }
+}
+
+// WithUnits define o catálogo de unidades simuladas (padrão: DefaultDevices).
+func WithUnits(devices ...Device) Option {
+	return func(c *SimulatorConfig) { c.Devices = devices }
+}
+
+// WithBaseSetpoint define o setpoint programado das salas (°C).
+func WithBaseSetpoint(celsius float64) Option {
+	return func(c *SimulatorConfig) { c.Model.BaseSetpoint = celsius }
+}
+
+// WithDeadband define o desvio do setpoint que liga o resfriamento ou o aquecimento (°C).
+func WithDeadband(celsius float64) Option {
+	return func(c *SimulatorConfig) { c.Model.Deadband = celsius }
+}
+
+// WithOccupancyModel substitui o modelo de ocupação por horário.
+func WithOccupancyModel(model OccupancyModel) Option {
+	return func(c *SimulatorConfig) { c.Model.Occupancy = model }
+}
+
+// WithFaultRate multiplica a taxa de alarmes de compressor e filtro (1 mantém, 0 desliga).
+func WithFaultRate(scale float64) Option {
+	return func(c *SimulatorConfig) { c.Model.FaultRate = scale }
+}
+
+// WithNoise multiplica o ruído de processo e de medição (1 mantém, 0 gera séries sem ruído).
+func WithNoise(scale float64) Option {
+	return func(c *SimulatorConfig) { c.Model.NoiseScale = scale }
+}
+
+// WithControlStrategy substitui o termostato simples por outra estratégia de controle, embutida
+// (ScheduledStrategy, G36Strategy) ou própria.
+func WithControlStrategy(strategy ControlStrategy) Option {
+	return func(c *SimulatorConfig) { c.Control = strategy }
+}
+
+// WithFaultModel substitui o desgaste sazonal e os alarmes sorteados por outro modelo de falhas,
+// embutido (ReliabilityFaultModel, FaultLogReplay) ou próprio. WithFaultRate deixa de valer.
+func WithFaultModel(model FaultModel) Option {
+	return func(c *SimulatorConfig) { c.Faults = model }
+}
+
+// Generator é a entrada da biblioteca para gerar telemetria: um Simulator com os parâmetros do
+// modelo ajustados por opções.
+type Generator struct {
+	*Simulator
+}
+
+// NewGenerator cria o gerador com os parâmetros padrão ajustados pelas opções, na ordem informada.
+func NewGenerator(opts ...Option) (*Generator, error) {
+	model := DefaultModelParams()
+	cfg := SimulatorConfig{Model: &model}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	simulator, err := NewSimulator(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{Simulator: simulator}, nil
+}
+
+// Generate gera uma leitura isolada, sem estado térmico anterior, para uma unidade sorteada do
+// catálogo. Para séries temporais com inércia entre as horas, use Step.
+func (g *Generator) Generate(climateData climate.InmetClimateData) HvacSensorData {
+	unit := g.devices[g.rng.Intn(len(g.devices))]
+	device := &deviceState{
+		Device:              unit.Device,
+		airHandler:          unit.airHandler,
+		servedAreaM2:        unit.servedAreaM2,
+		servedVolumeM3:      unit.servedVolumeM3,
+		undervoltageTripPct: unit.undervoltageTripPct,
+	}
+	return g.step(device, climateData)
+}
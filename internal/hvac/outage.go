@@ -0,0 +1,98 @@
+package hvac
+
+import (
+	"math"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// OutageWindow é uma queda de energia programada no site.
+type OutageWindow struct {
+	Start         Date    `json:"start"`         // Início da queda (AAAA-MM-DD ou RFC 3339)
+	DurationHours float64 `json:"durationHours"` // Duração da queda (h)
+}
+
+// OutageConfig descreve as quedas de energia do site: todos os dispositivos ficam sem
+// telemetria e, na volta da energia, religam escalonados com pico de partida.
+type OutageConfig struct {
+	Events                []OutageWindow `json:"events"`                // Quedas programadas
+	RandomPerYear         float64        `json:"randomPerYear"`         // Quedas aleatórias esperadas por ano
+	MeanDurationHours     float64        `json:"meanDurationHours"`     // Duração média das quedas aleatórias (padrão: 3 h)
+	RestartStaggerSeconds float64        `json:"restartStaggerSeconds"` // Intervalo entre o religamento de cada dispositivo (padrão: 45 s)
+}
+
+const (
+	inrushMultiplier  = 5.0  // Pico de partida relativo à potência nominal
+	bootRecordEnergy  = 0.02 // Energia consumida na partida registrada no evento de religamento (kWh)
+	powerRestoreFault = "PW-RS-01"
+)
+
+func (c OutageConfig) withDefaults() OutageConfig {
+	if c.MeanDurationHours == 0 {
+		c.MeanDurationHours = 3.0
+	}
+	if c.RestartStaggerSeconds == 0 {
+		c.RestartStaggerSeconds = 45.0
+	}
+	return c
+}
+
+// updateOutage atualiza o fim da queda corrente no instante t e informa se o site está sem energia.
+func (s *Simulator) updateOutage(t time.Time, hours float64) bool {
+	for _, e := range s.outages.Events {
+		end := e.Start.Add(time.Duration(e.DurationHours * float64(time.Hour)))
+		if !t.Before(e.Start.Time) && !t.After(end) && end.After(s.outageUntil) {
+			s.outageUntil = end
+		}
+	}
+	if s.outages.RandomPerYear > 0 && t.After(s.outageUntil) {
+		if s.rng.Float64() < s.outages.RandomPerYear/8760.0*hours {
+			duration := s.rng.ExpFloat64() * s.outages.MeanDurationHours
+			s.outageUntil = t.Add(time.Duration(math.Max(0.25, duration) * float64(time.Hour)))
+		}
+	}
+	return !t.After(s.outageUntil) && !s.outageUntil.IsZero()
+}
+
+// drift avança o estado térmico de um dispositivo desligado, sem emitir leitura.
+func (d *deviceState) drift(climateData climate.InmetClimateData) {
+	equilibrium := zoneEquilibriumTemp(climateData.TemperatureAir)
+	if !d.hasState {
+		d.internalTemp = equilibrium
+	}
+	hours := periodHours(d, climateData.Timestamp)
+	d.internalTemp += (equilibrium - d.internalTemp) * math.Min(1.0, thermalResponse*hours)
+	d.hasState = true
+	d.lastTimestamp = climateData.Timestamp
+	d.lastStatus = "OFF"
+	d.wasRecovering = false
+}
+
+// bootRecord é a leitura emitida pelo dispositivo ao religar após a queda de energia.
+func (s *Simulator) bootRecord(device *deviceState, t time.Time, climateData climate.InmetClimateData) HvacSensorData {
+	dewPoint, wetBulb, enthalpy := s.outdoorAirState(climateData)
+	return HvacSensorData{
+		Timestamp:              t,
+		InternalTemperature:    device.internalTemp,
+		SetPointTemperature:    s.model.BaseSetpoint,
+		SystemStatus:           "STARTUP",
+		PowerConsumptionKwH:    bootRecordEnergy,
+		OutdoorTemperature:     climateData.TemperatureAir,
+		OutdoorHumidity:        climateData.RelativeHumidity,
+		OutdoorDewPoint:        dewPoint,
+		OutdoorWetBulb:         wetBulb,
+		OutdoorEnthalpy:        enthalpy,
+		DeviceId:               device.ID,
+		SupplyAirTemperature:   device.internalTemp,
+		ReturnAirTemperature:   device.internalTemp,
+		CO2LevelPpm:            math.Max(outdoorCO2, device.lastCO2),
+		RefrigerantPressurePsi: 90.0 + s.rng.Float64()*5.0, // Pressões equalizadas com o compressor parado
+		FaultCode:              powerRestoreFault,
+		AssetModel:             device.AssetModel,
+		LocationZone:           device.Zone,
+		StationId:              s.station,
+		ExtremeEvent:           climateData.ExtremeEvent,
+		InrushPowerKw:          device.ratedPower(true) * inrushMultiplier * (0.9 + s.rng.Float64()*0.2),
+	}
+}
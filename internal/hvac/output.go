@@ -0,0 +1,155 @@
+package hvac
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OutputConfig escolhe o formato do arquivo principal de dados.
+type OutputConfig struct {
+	Format      string      `json:"format"`      // json (padrão), arrow, orc, delta ou sqlite
+	Compression Compression `json:"compression"` // Compressão dos formatos colunares: zstd em todos, lz4 (Arrow), snappy (ORC, Delta) ou zlib (ORC); padrão: sem compressão (snappy no Delta)
+	Table       string      `json:"table"`       // Prefixo da tabela Delta no bucket (padrão: delta/hvac_A701)
+	LocalDir    string      `json:"localDir"`    // Grava o arquivo principal neste diretório local em vez do bucket
+	Partition   string      `json:"partition"`   // day: um arquivo arrow ou orc por dia UTC, em pastas dt=AAAA-MM-DD (estilo Hive); padrão: um único arquivo
+}
+
+// Compression é a compressão dos formatos colunares, a mesma opção em todos os escritores: cada
+// formato aceita parte dos codecs, e CheckCompression rejeita os demais.
+type Compression string
+
+const (
+	CompressionNone   Compression = ""
+	CompressionZstd   Compression = "zstd"
+	CompressionLZ4    Compression = "lz4"
+	CompressionSnappy Compression = "snappy"
+	CompressionZlib   Compression = "zlib"
+)
+
+// formatCompressions são os codecs aceitos por formato. zstd vale em todos os colunares.
+var formatCompressions = map[string][]Compression{
+	"arrow": {CompressionZstd, CompressionLZ4},
+	"orc":   {CompressionZstd, CompressionSnappy, CompressionZlib},
+	"delta": {CompressionZstd, CompressionSnappy},
+}
+
+// CheckCompression confere se o formato aceita a compressão; a ausência de compressão vale em
+// todos. Os escritores colunares e a validação do cenário rejeitam com o mesmo erro.
+func CheckCompression(format string, compression Compression) error {
+	accepted := formatCompressions[format]
+	if compression == CompressionNone || slices.Contains(accepted, compression) {
+		return nil
+	}
+	if len(accepted) == 0 {
+		return fmt.Errorf("não se aplica ao formato %s, recebido '%s'", cmp.Or(format, "json"), compression)
+	}
+	names := make([]string, len(accepted))
+	for i, c := range accepted {
+		names[i] = string(c)
+	}
+	list := strings.Join(names[:len(names)-1], ", ") + " ou " + names[len(names)-1]
+	return fmt.Errorf("deve ser %s no formato %s, recebido '%s'", list, format, compression)
+}
+
+// OutputFile é um arquivo das exportações com vários arquivos, na ordem em que deve ser gravado.
+type OutputFile struct {
+	Name string // Nome do arquivo, relativo ao prefixo da exportação
+	Data []byte
+}
+
+// IsTable indica se a saída é uma tabela (Delta), gravada como append em vez de arquivo avulso.
+func (c OutputConfig) IsTable() bool {
+	return c.Format == "delta"
+}
+
+// TablePath retorna o prefixo da tabela no bucket, terminado em "/".
+func (c OutputConfig) TablePath() string {
+	table := strings.Trim(c.Table, "/")
+	if table == "" {
+		table = "delta/hvac_A701"
+	}
+	return table + "/"
+}
+
+// Extension retorna a extensão do arquivo gerado no formato configurado.
+func (c OutputConfig) Extension() string {
+	switch c.Format {
+	case "arrow":
+		return ".arrow"
+	case "orc":
+		return ".orc"
+	case "sqlite":
+		return ".sqlite"
+	}
+	return ".json"
+}
+
+// WriteOutput serializa os registros no formato configurado. Dialetos, modelos de fabricante,
+// envelope e lotes só se aplicam ao JSON; os formatos colunares usam o esquema canônico achatado.
+func WriteOutput(cfg OutputConfig, renderer *PayloadRenderer, data []HvacSensorData) ([]byte, error) {
+	switch cfg.Format {
+	case "", "json":
+		return renderer.WriteJSON(data)
+	case "arrow":
+		return WriteArrowIPC(data, cfg.Compression)
+	case "orc":
+		return WriteORC(data, cfg.Compression)
+	case "sqlite":
+		return WriteSQLite(data)
+	case "delta":
+		return nil, fmt.Errorf("a tabela Delta é gravada com WriteDeltaCommit, não como arquivo avulso")
+	}
+	return nil, fmt.Errorf("formato de saída '%s' desconhecido", cfg.Format)
+}
+
+// partitionFormats são os formatos gravados em partições com Partition.
+var partitionFormats = []string{"arrow", "orc"}
+
+// CheckPartition confere se o formato aceita o particionamento configurado.
+func CheckPartition(format, partition string) error {
+	if partition == "" {
+		return nil
+	}
+	if partition != "day" {
+		return fmt.Errorf("deve ser day, recebido '%s'", partition)
+	}
+	if !slices.Contains(partitionFormats, format) {
+		return fmt.Errorf("só se aplica aos formatos arrow e orc, recebido o formato %s", cmp.Or(format, "json"))
+	}
+	return nil
+}
+
+// WritePartitionedOutput serializa os registros em um arquivo por dia UTC, fileName dentro da pasta
+// dt=AAAA-MM-DD da partição, na ordem dos dias. Todos os arquivos têm o esquema do conjunto
+// inteiro, para que as partições de uma execução tenham as mesmas colunas.
+func WritePartitionedOutput(cfg OutputConfig, data []HvacSensorData, fileName string) ([]OutputFile, error) {
+	if err := CheckPartition(cfg.Format, cfg.Partition); err != nil {
+		return nil, fmt.Errorf("partição da saída: %w", err)
+	}
+	columns := tabularColumns(data)
+	days := make(map[string][]HvacSensorData)
+	for i := range data {
+		day := data[i].Timestamp.UTC().Format(time.DateOnly)
+		days[day] = append(days[day], data[i])
+	}
+
+	files := make([]OutputFile, 0, len(days))
+	for _, day := range slices.Sorted(maps.Keys(days)) {
+		var payload []byte
+		var err error
+		if cfg.Format == "arrow" {
+			payload, err = writeArrowIPC(days[day], columns, cfg.Compression)
+		} else {
+			payload, err = writeORC(days[day], columns, cfg.Compression)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("partição dt=%s: %w", day, err)
+		}
+		files = append(files, OutputFile{Name: "dt=" + day + "/" + fileName, Data: payload})
+	}
+	return files, nil
+}
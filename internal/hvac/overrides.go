@@ -0,0 +1,66 @@
+package hvac
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// OverrideConfig simula ocupantes alterando o setpoint no termostato. O ajuste vale até o
+// fim do tempo limite ou até a sala ficar desocupada, quando o setpoint programado volta.
+type OverrideConfig struct {
+	ProbabilityPerHour float64 `json:"probabilityPerHour"` // Chance por hora de um ocupante ajustar o termostato (padrão: 0.15)
+	HotOutdoorTemp     float64 `json:"hotOutdoorTemp"`     // Temperatura externa a partir da qual pedem mais frio (°C, padrão: 28)
+	CoolSetpoint       float64 `json:"coolSetpoint"`       // Setpoint escolhido nas tardes quentes (°C, padrão: 19)
+	ColdOutdoorTemp    float64 `json:"coldOutdoorTemp"`    // Temperatura externa abaixo da qual pedem mais calor (°C, padrão: 15)
+	WarmSetpoint       float64 `json:"warmSetpoint"`       // Setpoint escolhido nos dias frios (°C, padrão: 25)
+	TimeoutHours       float64 `json:"timeoutHours"`       // Tempo até o termostato reverter o ajuste (h, padrão: 2)
+}
+
+func (c OverrideConfig) withDefaults() OverrideConfig {
+	if c.ProbabilityPerHour == 0 {
+		c.ProbabilityPerHour = 0.15
+	}
+	if c.HotOutdoorTemp == 0 {
+		c.HotOutdoorTemp = 28.0
+	}
+	if c.CoolSetpoint == 0 {
+		c.CoolSetpoint = 19.0
+	}
+	if c.ColdOutdoorTemp == 0 {
+		c.ColdOutdoorTemp = 15.0
+	}
+	if c.WarmSetpoint == 0 {
+		c.WarmSetpoint = 25.0
+	}
+	if c.TimeoutHours == 0 {
+		c.TimeoutHours = 2.0
+	}
+	return c
+}
+
+// applyOverride retorna o setpoint efetivo do passo e se há ajuste de ocupante ativo.
+func (d *deviceState) applyOverride(cfg *OverrideConfig, climateData climate.InmetClimateData, isOccupied bool, setPoint float64, rng *rand.Rand) (float64, bool) {
+	t := climateData.Timestamp
+	if !isOccupied || !t.Before(d.overrideUntil) {
+		// Sala vazia ou tempo limite esgotado: volta ao setpoint programado
+		d.overrideUntil = time.Time{}
+	}
+	if d.overrideUntil.IsZero() && isOccupied && rng.Float64() < cfg.ProbabilityPerHour*periodHours(d, t) {
+		hour := t.Hour()
+		switch {
+		case climateData.TemperatureAir >= cfg.HotOutdoorTemp && hour >= 12 && hour < 18:
+			d.overrideSetpoint = cfg.CoolSetpoint
+		case climateData.TemperatureAir <= cfg.ColdOutdoorTemp:
+			d.overrideSetpoint = cfg.WarmSetpoint
+		default:
+			return setPoint, false
+		}
+		d.overrideUntil = t.Add(time.Duration(cfg.TimeoutHours * float64(time.Hour)))
+	}
+	if d.overrideUntil.IsZero() {
+		return setPoint, false
+	}
+	return d.overrideSetpoint, true
+}
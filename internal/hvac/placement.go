@@ -0,0 +1,56 @@
+package hvac
+
+// Posições problemáticas do sensor de temperatura da sala (termostato).
+const (
+	SensorPlacementHeatSource     = "HEAT_SOURCE"     // Próximo a uma fonte de calor: lê acima da sala
+	SensorPlacementSupplyDiffuser = "SUPPLY_DIFFUSER" // No jato do difusor: acompanha o ar insuflado
+)
+
+const (
+	defaultSensorOffset      = 2.0  // Viés do sensor próximo a uma fonte de calor (°C)
+	diffuserCouplingFactor   = 0.25 // Fração da diferença insuflamento-sala percebida pelo sensor no difusor
+	unoccupiedHeatSourceBias = 0.5  // Fração do viés com a sala desocupada (equipamentos em standby)
+)
+
+// decisionBias é o erro do sensor visto pelo controlador ao decidir o modo de operação.
+func (d *deviceState) decisionBias(occupied bool) float64 {
+	switch d.SensorPlacement {
+	case SensorPlacementHeatSource:
+		return d.heatSourceBias(occupied)
+	case SensorPlacementSupplyDiffuser:
+		return d.lastSensorBias
+	}
+	return 0
+}
+
+func (d *deviceState) heatSourceBias(occupied bool) float64 {
+	offset := d.SensorOffset
+	if offset == 0 {
+		offset = defaultSensorOffset
+	}
+	if !occupied {
+		return offset * unoccupiedHeatSourceBias
+	}
+	return offset
+}
+
+// placeSensor separa a temperatura real da sala da temperatura lida pelo termostato. Nos modos
+// controlados o controlador mantém a leitura no alvo, logo a sala real diverge dela pelo viés.
+func (d *deviceState) placeSensor(systemStatus string, temp, supplyTemp float64, occupied bool) (zoneTemp, sensedTemp float64) {
+	bias := 0.0
+	switch d.SensorPlacement {
+	case SensorPlacementHeatSource:
+		bias = d.heatSourceBias(occupied)
+	case SensorPlacementSupplyDiffuser:
+		if systemStatus != "OFF" && systemStatus != "IDLE" {
+			bias = (supplyTemp - temp) * diffuserCouplingFactor
+		}
+	}
+	d.lastSensorBias = bias
+
+	switch systemStatus {
+	case "COOLING", "HEATING", "IDLE", "FAN_ONLY", "PRE_COOLING":
+		return temp - bias, temp
+	}
+	return temp, temp + bias
+}
@@ -0,0 +1,269 @@
+package hvac
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Point descreve um ponto emitido por um dispositivo, no formato de uma lista de pontos de BMS.
+type Point struct {
+	PointName               string   `json:"pointName"`               // Nome completo do ponto (ex: SALA-1.internalTemperature)
+	DeviceId                string   `json:"deviceId"`                // Dispositivo que emite o ponto
+	AssetModel              string   `json:"assetModel"`              // Modelo do equipamento
+	LocationZone            string   `json:"locationZone"`            // Zona do dispositivo
+	Field                   string   `json:"field"`                   // Campo correspondente no JSON emitido
+	Kind                    string   `json:"kind"`                    // Tipo Haystack do valor: Number, Bool ou Str
+	Unit                    string   `json:"unit,omitempty"`          // Unidade de medida
+	Min                     *float64 `json:"min,omitempty"`           // Limite inferior esperado
+	Max                     *float64 `json:"max,omitempty"`           // Limite superior esperado
+	SamplingIntervalSeconds int      `json:"samplingIntervalSeconds"` // Intervalo de amostragem
+	HaystackTags            []string `json:"haystackTags"`            // Marcadores Project Haystack
+}
+
+// pointDefinition é o modelo de um ponto, replicado para cada dispositivo do catálogo.
+type pointDefinition struct {
+	field    string
+	kind     string
+	unit     string
+	min, max float64
+	tags     string
+}
+
+var basePointDefinitions = []pointDefinition{
+	{"internalTemperature", "Number", "°C", -10, 50, "point sensor zone air temp"},
+	{"setPointTemperature", "Number", "°C", 10, 35, "point sp zone air temp"},
+	{"systemStatus", "Str", "", 0, 0, "point sensor hvacMode"},
+	{"occupancyStatus", "Bool", "", 0, 0, "point sensor occupied"},
+	{"powerConsumptionKwH", "Number", "kWh", 0, 20, "point sensor elec energy"},
+	{"outdoorTemperature", "Number", "°C", -10, 50, "point sensor outside air temp weather"},
+	{"outdoorHumidity", "Number", "%RH", 0, 100, "point sensor outside air humidity weather"},
+	{"supplyAirTemperature", "Number", "°C", -5, 60, "point sensor discharge air temp"},
+	{"returnAirTemperature", "Number", "°C", -10, 50, "point sensor return air temp"},
+	{"ductStaticPressurePa", "Number", "Pa", 0, 40, "point sensor discharge duct air pressure"},
+	{"co2LevelPpm", "Number", "ppm", 350, 5000, "point sensor zone air co2"},
+	{"refrigerantPressurePsi", "Number", "psi", 0, 400, "point sensor refrig pressure"},
+	{"faultCode", "Str", "", 0, 0, "point sensor fault"},
+}
+
+var g36PointDefinitions = []pointDefinition{
+	{"g36.supplyAirTempSetpoint", "Number", "°C", 10, 20, "point sp discharge air temp"},
+	{"g36.ductStaticPressureSetpointPa", "Number", "Pa", 0, 40, "point sp discharge duct air pressure"},
+	{"g36.damperPositionPct", "Number", "%", 0, 100, "point cmd damper"},
+	{"g36.zoneCoolingRequests", "Number", "", 0, 3, "point sensor cool request"},
+	{"g36.zonePressureRequests", "Number", "", 0, 3, "point sensor pressure request"},
+	{"g36.ahuCoolingRequests", "Number", "", 0, 100, "point sensor ahu cool request"},
+	{"g36.ahuPressureRequests", "Number", "", 0, 100, "point sensor ahu pressure request"},
+}
+
+var expectedPointDefinitions = []pointDefinition{
+	{"expected.supplyAirTemperature", "Number", "°C", -5, 60, "point discharge air temp modeled"},
+	{"expected.powerConsumptionKwH", "Number", "kWh", 0, 20, "point elec energy modeled"},
+	{"expected.refrigerantPressurePsi", "Number", "psi", 0, 400, "point refrig pressure modeled"},
+	{"expected.ductStaticPressurePa", "Number", "Pa", 0, 40, "point discharge duct air pressure modeled"},
+}
+
+var intensityPointDefinitions = []pointDefinition{
+	{"intensity.powerDensityWm2", "Number", "W/m²", 0, 500, "point sensor elec power density"},
+	{"intensity.powerDensityWm3", "Number", "W/m³", 0, 200, "point sensor elec power volume density"},
+	{"intensity.energyIntensityKwhM2Day", "Number", "kWh/m²", 0, 5, "point sensor elec energy intensity"},
+}
+
+var hydronicPointDefinitions = []pointDefinition{
+	{"hydronic.coolingCoil.valvePositionPct", "Number", "%", 0, 100, "point cmd chilled water valve"},
+	{"hydronic.coolingCoil.flowLps", "Number", "L/s", 0, 2, "point sensor chilled water flow"},
+	{"hydronic.coolingCoil.pumpSpeedPct", "Number", "%", 0, 100, "point sensor chilled water pump speed"},
+	{"hydronic.coolingCoil.supplyWaterTemp", "Number", "°C", 4, 15, "point sensor chilled water entering temp"},
+	{"hydronic.coolingCoil.returnWaterTemp", "Number", "°C", 4, 20, "point sensor chilled water leaving temp"},
+	{"hydronic.coolingCoil.deltaT", "Number", "K", 0, 10, "point sensor chilled water delta temp"},
+	{"hydronic.heatingCoil.valvePositionPct", "Number", "%", 0, 100, "point cmd hot water valve"},
+	{"hydronic.heatingCoil.flowLps", "Number", "L/s", 0, 2, "point sensor hot water flow"},
+	{"hydronic.heatingCoil.pumpSpeedPct", "Number", "%", 0, 100, "point sensor hot water pump speed"},
+	{"hydronic.heatingCoil.supplyWaterTemp", "Number", "°C", 30, 90, "point sensor hot water entering temp"},
+	{"hydronic.heatingCoil.returnWaterTemp", "Number", "°C", 20, 90, "point sensor hot water leaving temp"},
+	{"hydronic.heatingCoil.deltaT", "Number", "K", 0, 20, "point sensor hot water delta temp"},
+}
+
+var vrfPointDefinitions = []pointDefinition{
+	{"vrf.outdoorUnitId", "Str", "", 0, 0, "point sensor vrf outdoor unit"},
+	{"vrf.outdoorUnitMode", "Str", "", 0, 0, "point sensor vrf outdoor unit hvacMode"},
+	{"vrf.capacityFactor", "Number", "", 0, 1, "point sensor vrf capacity"},
+	{"vrf.modeConflict", "Bool", "", 0, 0, "point sensor vrf mode conflict"},
+}
+
+// stagePointDefinitions lista os pontos de estágio de um equipamento com vários compressores.
+func stagePointDefinitions(stages int) []pointDefinition {
+	definitions := []pointDefinition{
+		{"stagesActive", "Number", "", 0, float64(stages), "point sensor stage"},
+		{"leadCompressor", "Number", "", 1, float64(stages), "point sensor lead compressor"},
+	}
+	for i := range stages {
+		definitions = append(definitions, pointDefinition{fmt.Sprintf("stageRuntimeFractions[%d]", i), "Number", "", 0, 1, "point sensor compressor run"})
+	}
+	return definitions
+}
+
+var defrostPointDefinitions = []pointDefinition{
+	{"defrostCycles", "Number", "", 0, 4, "point sensor defrost"},
+	{"auxHeatKwh", "Number", "kWh", 0, 10, "point sensor aux heat elec energy"},
+}
+
+var filterPointDefinitions = []pointDefinition{
+	{"filterDifferentialPressurePa", "Number", "Pa", 0, 400, "point sensor filter air pressure"},
+}
+
+var ervPointDefinitions = []pointDefinition{
+	{"erv.mode", "Str", "", 0, 0, "point sensor erv hvacMode"},
+	{"erv.outdoorAirTemp", "Number", "°C", -30, 50, "point sensor erv outside air temp"},
+	{"erv.supplyAirTemp", "Number", "°C", -30, 50, "point sensor erv discharge air temp"},
+	{"erv.returnAirTemp", "Number", "°C", -10, 50, "point sensor erv return air temp"},
+	{"erv.exhaustAirTemp", "Number", "°C", -30, 50, "point sensor erv exhaust air temp"},
+	{"erv.effectiveness", "Number", "", 0, 1, "point sensor erv effectiveness"},
+	{"erv.recoveredKw", "Number", "kW", -10, 10, "point sensor erv heat recovered power"},
+	{"erv.fanEnergyKwh", "Number", "kWh", 0, 2, "point sensor erv fan elec energy"},
+}
+
+var economizerPointDefinitions = []pointDefinition{
+	{"economizer.highLimit", "Str", "", 0, 0, "point sensor economizer enable"},
+	{"economizer.lockout", "Bool", "", 0, 0, "point sensor economizer lockout"},
+	{"economizer.lockoutReason", "Str", "", 0, 0, "point sensor economizer lockout"},
+	{"economizer.active", "Bool", "", 0, 0, "point sensor economizing"},
+	{"economizer.outdoorAirDamperPct", "Number", "%", 0, 100, "point sensor outside air damper cmd"},
+	{"economizer.outdoorAirEnthalpy", "Number", "kJ/kg", 0, 120, "point sensor outside air enthalpy"},
+	{"economizer.returnAirEnthalpy", "Number", "kJ/kg", 0, 120, "point sensor return air enthalpy"},
+	{"economizer.freeCoolingFraction", "Number", "", 0, 1, "point sensor economizer cool"},
+}
+
+var overridePointDefinitions = []pointDefinition{
+	{"overrideActive", "Bool", "", 0, 0, "point sensor sp override"},
+}
+
+// pointRanges indexa a faixa [min, max] de cada campo numérico pelas definições do catálogo de
+// pontos. Os campos sem faixa no catálogo ficam de fora.
+func pointRanges() map[string][2]float64 {
+	ranges := make(map[string][2]float64)
+	for _, defs := range [][]pointDefinition{basePointDefinitions, g36PointDefinitions, intensityPointDefinitions, hydronicPointDefinitions, vrfPointDefinitions, defrostPointDefinitions, filterPointDefinitions, ervPointDefinitions, economizerPointDefinitions} {
+		for _, def := range defs {
+			if def.kind == "Number" {
+				ranges[def.field] = [2]float64{def.min, def.max}
+			}
+		}
+	}
+	ranges["stageRuntimeFractions"] = [2]float64{0, 1}
+	return ranges
+}
+
+// CatalogOptions complementa o catálogo com informações que não pertencem ao simulador.
+type CatalogOptions struct {
+	SamplingInterval time.Duration // Intervalo entre leituras (padrão: 1 h, o passo dos dados do INMET)
+	ForecastHorizons []int         // Horizontes de previsão anexados aos registros, se houver
+}
+
+// PointCatalog lista todos os pontos emitidos por cada dispositivo da frota, considerando
+// os recursos opcionais habilitados no simulador.
+func (s *Simulator) PointCatalog(opts CatalogOptions) []Point {
+	interval := opts.SamplingInterval
+	if interval == 0 {
+		interval = time.Hour
+	}
+
+	definitions := append([]pointDefinition(nil), basePointDefinitions...)
+	for _, h := range opts.ForecastHorizons {
+		definitions = append(definitions, pointDefinition{
+			fmt.Sprintf("outdoorTemperatureForecast[%dh]", h), "Number", "°C", -10, 50, "point outside air temp weather forecast",
+		})
+	}
+	if s.g36 != nil {
+		definitions = append(definitions, g36PointDefinitions...)
+	}
+	if s.defrost != nil {
+		definitions = append(definitions, defrostPointDefinitions...)
+	}
+	if s.filter != nil {
+		definitions = append(definitions, filterPointDefinitions...)
+	}
+	if s.economizer != nil {
+		definitions = append(definitions, economizerPointDefinitions...)
+	}
+	if s.erv != nil {
+		definitions = append(definitions, ervPointDefinitions...)
+	}
+	if s.hydronic != nil {
+		definitions = append(definitions, hydronicPointDefinitions...)
+	}
+	if s.overrides != nil {
+		definitions = append(definitions, overridePointDefinitions...)
+	}
+	if s.fddBaseline {
+		definitions = append(definitions, expectedPointDefinitions...)
+	}
+
+	var points []Point
+	for _, device := range s.devices {
+		deviceDefinitions := definitions
+		if device.servedAreaM2 > 0 {
+			deviceDefinitions = append(append([]pointDefinition(nil), definitions...), intensityPointDefinitions...)
+		}
+		if device.vrf != nil {
+			deviceDefinitions = append(append([]pointDefinition(nil), deviceDefinitions...), vrfPointDefinitions...)
+		}
+		if stages := s.stages(device); stages > 1 {
+			deviceDefinitions = append(append([]pointDefinition(nil), deviceDefinitions...), stagePointDefinitions(stages)...)
+		}
+		for _, def := range deviceDefinitions {
+			point := Point{
+				PointName:               device.ID + "." + def.field,
+				DeviceId:                device.ID,
+				AssetModel:              device.AssetModel,
+				LocationZone:            device.Zone,
+				Field:                   def.field,
+				Kind:                    def.kind,
+				Unit:                    def.unit,
+				SamplingIntervalSeconds: int(interval.Seconds()),
+				HaystackTags:            strings.Fields(def.tags),
+			}
+			if def.kind == "Number" {
+				min, max := def.min, def.max
+				point.Min, point.Max = &min, &max
+			}
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+// WritePointCatalogCSV serializa o catálogo de pontos como CSV (planilha de pontos para integração).
+func WritePointCatalogCSV(points []Point) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"pointName", "deviceId", "assetModel", "locationZone", "field", "kind", "unit", "min", "max", "samplingIntervalSeconds", "haystackTags"}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("erro ao escrever cabeçalho do catálogo de pontos: %w", err)
+	}
+	for _, p := range points {
+		row := []string{
+			p.PointName, p.DeviceId, p.AssetModel, p.LocationZone, p.Field, p.Kind, p.Unit,
+			formatOptionalFloat(p.Min), formatOptionalFloat(p.Max),
+			strconv.Itoa(p.SamplingIntervalSeconds), strings.Join(p.HaystackTags, " "),
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("erro ao escrever o ponto '%s' no catálogo: %w", p.PointName, err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar o catálogo de pontos: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
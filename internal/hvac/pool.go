@@ -0,0 +1,30 @@
+package hvac
+
+import "sync"
+
+// RecordPool reaproveita as fatias de registros dos passos da simulação, para reduzir o trabalho do
+// coletor de lixo nas gerações contínuas em alta taxa. O uso típico é obter um buffer com Get,
+// preenchê-lo com Simulator.StepInto e devolvê-lo com Put quando os registros já foram consumidos
+// (codificados, publicados ou copiados). Pode ser usado por várias goroutines.
+type RecordPool struct {
+	pool sync.Pool
+}
+
+// Get retorna um buffer vazio, com a capacidade de um buffer devolvido quando houver.
+func (p *RecordPool) Get() []HvacSensorData {
+	if buf, ok := p.pool.Get().(*[]HvacSensorData); ok {
+		return (*buf)[:0]
+	}
+	return nil
+}
+
+// Put devolve o buffer ao pool. Os registros são zerados, para não manter vivos os blocos
+// opcionais apontados por eles, e não podem mais ser lidos depois da devolução.
+func (p *RecordPool) Put(records []HvacSensorData) {
+	if cap(records) == 0 {
+		return
+	}
+	records = records[:cap(records)]
+	clear(records)
+	p.pool.Put(&records)
+}
@@ -0,0 +1,241 @@
+package hvac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PrecisionConfig fixa a resolução dos valores numéricos, como nos sensores reais, em vez do ruído
+// completo de float64. A precisão de um campo vem de Fields, depois da unidade do campo em Units e
+// por fim de Default; campos sem nenhuma delas mantêm a precisão total.
+type PrecisionConfig struct {
+	Default *int           `json:"default"` // Casas decimais dos demais campos numéricos (omitido: precisão total)
+	Units   map[string]int `json:"units"`   // Unidade (°C, kWh, Pa, psi, ppm, %RH, ...) -> casas decimais
+	Fields  map[string]int `json:"fields"`  // Caminho do campo (ex: internalTemperature, g36.damperPositionPct) -> casas decimais
+}
+
+// Precision arredonda os registros conforme a configuração validada.
+type Precision struct {
+	cfg    PrecisionConfig
+	scales map[string]float64 // Escala já resolvida por caminho, preenchida sob demanda
+}
+
+// floatField é um campo numérico do registro, com o caminho canônico e a unidade do catálogo de pontos.
+type floatField struct {
+	path  string
+	unit  string
+	value *float64
+}
+
+// NewPrecision valida a configuração de precisão.
+func NewPrecision(cfg PrecisionConfig) (*Precision, error) {
+	if cfg.Default != nil && *cfg.Default < 0 {
+		return nil, fmt.Errorf("casas decimais padrão negativas: %d", *cfg.Default)
+	}
+	known := recordFieldPaths()
+	for _, field := range sensorFloatFields(&WirelessSensorReading{}) {
+		known[field.path] = true
+	}
+	for _, name := range sortedKeys(cfg.Fields) {
+		if !known[name] {
+			return nil, fmt.Errorf("campo '%s' desconhecido na configuração de precisão", name)
+		}
+		if cfg.Fields[name] < 0 {
+			return nil, fmt.Errorf("casas decimais negativas para o campo '%s'", name)
+		}
+	}
+	for _, unit := range sortedKeys(cfg.Units) {
+		if cfg.Units[unit] < 0 {
+			return nil, fmt.Errorf("casas decimais negativas para a unidade '%s'", unit)
+		}
+	}
+	return &Precision{cfg: cfg, scales: make(map[string]float64)}, nil
+}
+
+// Apply arredonda os campos numéricos dos registros, no lugar.
+func (p *Precision) Apply(data []HvacSensorData) {
+	for i := range data {
+		p.round(recordFloatFields(&data[i]))
+	}
+}
+
+// ApplySensors arredonda as leituras dos sensores sem fio, no lugar.
+func (p *Precision) ApplySensors(readings []WirelessSensorReading) {
+	for i := range readings {
+		p.round(sensorFloatFields(&readings[i]))
+	}
+}
+
+func (p *Precision) round(fields []floatField) {
+	for _, field := range fields {
+		scale, ok := p.scales[field.path]
+		if !ok {
+			scale = p.scaleFor(field)
+			p.scales[field.path] = scale
+		}
+		if scale > 0 {
+			*field.value = math.Round(*field.value*scale) / scale
+		}
+	}
+}
+
+// scaleFor resolve a escala do campo (10^casas), ou zero quando o campo mantém a precisão total.
+func (p *Precision) scaleFor(field floatField) float64 {
+	if decimals, ok := p.cfg.Fields[field.path]; ok {
+		return math.Pow(10, float64(decimals))
+	}
+	if decimals, ok := p.cfg.Units[field.unit]; ok && field.unit != "" {
+		return math.Pow(10, float64(decimals))
+	}
+	if p.cfg.Default != nil {
+		return math.Pow(10, float64(*p.cfg.Default))
+	}
+	return 0
+}
+
+// recordFieldPaths é o conjunto dos caminhos dos campos numéricos do registro, com todos os
+// blocos opcionais presentes.
+func recordFieldPaths() map[string]bool {
+	known := make(map[string]bool)
+	for _, field := range recordFloatFields(&HvacSensorData{G36: &G36Points{}, Expected: &ExpectedValues{}, Intensity: &IntensityMetrics{}, Hydronic: &HydronicPoints{}, Vrf: &VRFPoints{}, Erv: &ERVPoints{}, Economizer: &EconomizerPoints{}, TrueZoneTemperature: new(float64), FilterDifferentialPressurePa: new(float64), StageRuntimeFractions: []float64{0}}) {
+		known[field.path] = true
+	}
+	return known
+}
+
+// IsRecordField indica se o caminho é de um campo numérico do registro (ex: co2LevelPpm),
+// como os usados nas médias móveis e nas janelas de ML.
+func IsRecordField(path string) bool {
+	return recordFieldPaths()[path]
+}
+
+// recordFloatFields lista os campos numéricos fracionários do registro, incluindo os blocos
+// opcionais presentes. Os contadores inteiros ficam de fora.
+func recordFloatFields(d *HvacSensorData) []floatField {
+	fields := []floatField{
+		{"internalTemperature", "°C", &d.InternalTemperature},
+		{"setPointTemperature", "°C", &d.SetPointTemperature},
+		{"powerConsumptionKwH", "kWh", &d.PowerConsumptionKwH},
+		{"outdoorTemperature", "°C", &d.OutdoorTemperature},
+		{"outdoorHumidity", "%RH", &d.OutdoorHumidity},
+		{"outdoorDewPoint", "°C", &d.OutdoorDewPoint},
+		{"outdoorWetBulb", "°C", &d.OutdoorWetBulb},
+		{"outdoorEnthalpy", "kJ/kg", &d.OutdoorEnthalpy},
+		{"supplyAirTemperature", "°C", &d.SupplyAirTemperature},
+		{"returnAirTemperature", "°C", &d.ReturnAirTemperature},
+		{"ductStaticPressurePa", "Pa", &d.DuctStaticPressurePa},
+		{"co2LevelPpm", "ppm", &d.CO2LevelPpm},
+		{"refrigerantPressurePsi", "psi", &d.RefrigerantPressurePsi},
+		{"compressorRuntimeFraction", "", &d.CompressorRuntimeFraction},
+		{"inrushPowerKw", "kW", &d.InrushPowerKw},
+		{"supplyVoltageV", "V", &d.SupplyVoltageV},
+		{"gasConsumptionM3", "m³", &d.GasConsumptionM3},
+		{"gasConsumptionTherms", "thm", &d.GasConsumptionTherms},
+		{"auxHeatKwh", "kWh", &d.AuxHeatKwh},
+	}
+	if d.FilterDifferentialPressurePa != nil {
+		fields = append(fields, floatField{"filterDifferentialPressurePa", "Pa", d.FilterDifferentialPressurePa})
+	}
+	for i := range d.StageRuntimeFractions {
+		fields = append(fields, floatField{"stageRuntimeFractions", "", &d.StageRuntimeFractions[i]})
+	}
+	if d.TrueZoneTemperature != nil {
+		fields = append(fields, floatField{"trueZoneTemperature", "°C", d.TrueZoneTemperature})
+	}
+	for i := range d.OutdoorTemperatureForecast {
+		fields = append(fields, floatField{"outdoorTemperatureForecast.temperature", "°C", &d.OutdoorTemperatureForecast[i].Temperature})
+	}
+	if d.G36 != nil {
+		fields = append(fields,
+			floatField{"g36.supplyAirTempSetpoint", "°C", &d.G36.SupplyAirTempSetpoint},
+			floatField{"g36.ductStaticPressureSetpointPa", "Pa", &d.G36.DuctStaticPressureSetpointPa},
+			floatField{"g36.damperPositionPct", "%", &d.G36.DamperPositionPct},
+		)
+	}
+	if d.Expected != nil {
+		for _, expected := range []struct {
+			path  string
+			unit  string
+			value *ExpectedRange
+		}{
+			{"expected.supplyAirTemperature", "°C", &d.Expected.SupplyAirTemperature},
+			{"expected.powerConsumptionKwH", "kWh", &d.Expected.PowerConsumptionKwH},
+			{"expected.refrigerantPressurePsi", "psi", &d.Expected.RefrigerantPressurePsi},
+			{"expected.ductStaticPressurePa", "Pa", &d.Expected.DuctStaticPressurePa},
+		} {
+			// Os limites da faixa seguem a precisão do valor esperado
+			fields = append(fields,
+				floatField{expected.path, expected.unit, &expected.value.Expected},
+				floatField{expected.path, expected.unit, &expected.value.Min},
+				floatField{expected.path, expected.unit, &expected.value.Max},
+			)
+		}
+	}
+	if d.Intensity != nil {
+		fields = append(fields,
+			floatField{"intensity.servedAreaM2", "m²", &d.Intensity.ServedAreaM2},
+			floatField{"intensity.servedVolumeM3", "m³", &d.Intensity.ServedVolumeM3},
+			floatField{"intensity.powerDensityWm2", "W/m²", &d.Intensity.PowerDensityWm2},
+			floatField{"intensity.powerDensityWm3", "W/m³", &d.Intensity.PowerDensityWm3},
+			floatField{"intensity.energyIntensityKwhM2Day", "kWh/m²", &d.Intensity.EnergyIntensityKwhM2Day},
+		)
+	}
+	if d.Vrf != nil {
+		fields = append(fields, floatField{"vrf.capacityFactor", "", &d.Vrf.CapacityFactor})
+	}
+	if d.Erv != nil {
+		fields = append(fields,
+			floatField{"erv.outdoorAirTemp", "°C", &d.Erv.OutdoorAirTemp},
+			floatField{"erv.supplyAirTemp", "°C", &d.Erv.SupplyAirTemp},
+			floatField{"erv.returnAirTemp", "°C", &d.Erv.ReturnAirTemp},
+			floatField{"erv.exhaustAirTemp", "°C", &d.Erv.ExhaustAirTemp},
+			floatField{"erv.effectiveness", "", &d.Erv.Effectiveness},
+			floatField{"erv.recoveredKw", "kW", &d.Erv.RecoveredKw},
+			floatField{"erv.fanEnergyKwh", "kWh", &d.Erv.FanEnergyKwh},
+		)
+	}
+	if d.Economizer != nil {
+		fields = append(fields,
+			floatField{"economizer.outdoorAirDamperPct", "%", &d.Economizer.OutdoorAirDamperPct},
+			floatField{"economizer.outdoorAirEnthalpy", "kJ/kg", &d.Economizer.OutdoorAirEnthalpy},
+			floatField{"economizer.returnAirEnthalpy", "kJ/kg", &d.Economizer.ReturnAirEnthalpy},
+			floatField{"economizer.freeCoolingFraction", "", &d.Economizer.FreeCoolingFraction},
+		)
+	}
+	if d.Hydronic != nil {
+		for _, coil := range []struct {
+			path   string
+			points *CoilPoints
+		}{{"hydronic.coolingCoil", &d.Hydronic.CoolingCoil}, {"hydronic.heatingCoil", &d.Hydronic.HeatingCoil}} {
+			fields = append(fields,
+				floatField{coil.path + ".valvePositionPct", "%", &coil.points.ValvePositionPct},
+				floatField{coil.path + ".flowLps", "L/s", &coil.points.FlowLps},
+				floatField{coil.path + ".pumpSpeedPct", "%", &coil.points.PumpSpeedPct},
+				floatField{coil.path + ".supplyWaterTemp", "°C", &coil.points.SupplyWaterTemp},
+				floatField{coil.path + ".returnWaterTemp", "°C", &coil.points.ReturnWaterTemp},
+				floatField{coil.path + ".deltaT", "K", &coil.points.DeltaT},
+			)
+		}
+	}
+	return fields
+}
+
+// sensorFloatFields lista os campos numéricos das leituras dos sensores sem fio.
+func sensorFloatFields(r *WirelessSensorReading) []floatField {
+	return []floatField{
+		{"zoneTemperature", "°C", &r.ZoneTemperature},
+		{"zoneHumidity", "%RH", &r.ZoneHumidity},
+		{"co2LevelPpm", "ppm", &r.CO2LevelPpm},
+		{"batteryPercent", "%", &r.BatteryPercent},
+	}
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,49 @@
+package hvac
+
+import "time"
+
+// PrecoolingConfig descreve a estratégia de pré-resfriamento antes da ocupação,
+// usando ar externo frio (night purge) ou o compressor na tarifa fora de ponta.
+type PrecoolingConfig struct {
+	StartHour         int     `json:"startHour"`         // Hora de início da janela (padrão: 4)
+	EndHour           int     `json:"endHour"`           // Hora de término, normalmente o início da ocupação (padrão: 8)
+	TargetOffset      float64 `json:"targetOffset"`      // Quanto abaixo do setpoint pré-resfriar (°C, padrão: 1.5)
+	PurgeMinDelta     float64 `json:"purgeMinDelta"`     // Diferença mínima interna-externa para usar só ar externo (°C, padrão: 2)
+	MechanicalCooling bool    `json:"mechanicalCooling"` // Usa o compressor na tarifa fora de ponta quando o ar externo não basta
+	OffPeakEndHour    int     `json:"offPeakEndHour"`    // Fim da tarifa fora de ponta (padrão: 6)
+}
+
+func (c PrecoolingConfig) withDefaults() PrecoolingConfig {
+	if c.StartHour == 0 && c.EndHour == 0 {
+		c.StartHour, c.EndHour = 4, 8
+	}
+	if c.TargetOffset == 0 {
+		c.TargetOffset = 1.5
+	}
+	if c.PurgeMinDelta == 0 {
+		c.PurgeMinDelta = 2.0
+	}
+	if c.OffPeakEndHour == 0 {
+		c.OffPeakEndHour = 6
+	}
+	return c
+}
+
+// decide retorna NIGHT_PURGE, PRE_COOLING ou OFF para um dispositivo desocupado.
+func (c *PrecoolingConfig) decide(t time.Time, internalTemp, outdoorTemp, setPoint float64) string {
+	weekday := t.Weekday()
+	hour := t.Hour()
+	if weekday < time.Monday || weekday > time.Friday || hour < c.StartHour || hour >= c.EndHour {
+		return "OFF"
+	}
+	if internalTemp <= setPoint-c.TargetOffset {
+		return "OFF"
+	}
+	if internalTemp-outdoorTemp >= c.PurgeMinDelta {
+		return "NIGHT_PURGE"
+	}
+	if c.MechanicalCooling && hour < c.OffPeakEndHour {
+		return "PRE_COOLING"
+	}
+	return "OFF"
+}
@@ -0,0 +1,45 @@
+package hvac
+
+import (
+	"context"
+	"iter"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// Records percorre a série climática em ordem e produz os registros de cada passo só quando são
+// consumidos, sem acumular a série gerada em memória. Interromper o laço (break) para a simulação
+// no passo corrente; o estado do simulador avança apenas até ele. Os registros são cópias e podem
+// ser guardados pelo consumidor.
+func (s *Simulator) Records(climateRecords []climate.InmetClimateData) iter.Seq[HvacSensorData] {
+	return func(yield func(HvacSensorData) bool) {
+		var buf []HvacSensorData
+		for _, climateData := range climateRecords {
+			buf = s.StepInto(buf[:0], climateData)
+			for _, record := range buf {
+				if !yield(record) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Stream gera os registros da série climática em uma goroutine e os entrega pelo canal retornado,
+// que é fechado ao fim da série ou quando ctx é cancelado. O canal não tem buffer: a simulação
+// acompanha o ritmo do consumidor. O simulador não pode ser usado por outra goroutine enquanto o
+// canal estiver aberto; para liberar a goroutine antes do fim, cancele ctx.
+func (s *Simulator) Stream(ctx context.Context, climateRecords []climate.InmetClimateData) <-chan HvacSensorData {
+	out := make(chan HvacSensorData)
+	go func() {
+		defer close(out)
+		for record := range s.Records(climateRecords) {
+			select {
+			case out <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
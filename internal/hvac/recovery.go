@@ -0,0 +1,100 @@
+package hvac
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	defaultCapacityKw         = 20.0 // Capacidade nominal de resfriamento (kW térmicos)
+	defaultThermalMassKwhPerK = 4.0  // Massa térmica efetiva da sala quando não há geometria (kWh/K)
+	thermalMassPerM3          = 0.03
+	envelopeUaKwPerK          = 1.2 // Troca de calor pela envoltória (kW/K)
+	occupiedGainsKw           = 2.0 // Ganhos internos com a sala ocupada (kW)
+	heatingCapacityRatio      = 0.8 // Capacidade de aquecimento relativa à de resfriamento
+	ratedCop                  = 2.8 // COP nominal, usado para a potência elétrica em plena carga
+
+	designOutdoorTemp = 33.0 // Temperatura externa de projeto para dimensionamento (°C)
+	designSetpoint    = 22.0
+	maxCyclesPerHour  = 6.0  // Ciclos por hora do termostato com carga parcial de 50%
+	startPenaltyKwh   = 0.03 // Energia perdida a cada partida do compressor (kWh)
+)
+
+// designLoad é a carga térmica da sala no dia de projeto, base do dimensionamento relativo.
+func designLoad() float64 {
+	return envelopeUaKwPerK*(designOutdoorTemp-designSetpoint) + occupiedGainsKw
+}
+
+// partLoadRatio é a fração da capacidade exigida para manter a sala na temperatura informada.
+func (d *deviceState) partLoadRatio(outdoorTemp, internalTemp float64, occupied, cooling bool) float64 {
+	load := envelopeUaKwPerK * (outdoorTemp - internalTemp)
+	if occupied {
+		load += occupiedGainsKw
+	}
+	capacity := d.CapacityKw
+	if !cooling {
+		load = -load
+		capacity *= heatingCapacityRatio
+	}
+	return math.Max(0.05, math.Min(1.0, load/capacity))
+}
+
+// compressorCycles estima as partidas do compressor no período pelo modelo clássico de ciclagem
+// de termostato (N = Nmax·4·PLR·(1−PLR)): equipamentos superdimensionados ciclam mais.
+func compressorCycles(plr, hours float64) int {
+	return int(math.Round(maxCyclesPerHour * 4 * plr * (1 - plr) * hours))
+}
+
+// periodHours retorna a duração do passo corrente do dispositivo (1 h no primeiro passo).
+func periodHours(device *deviceState, t time.Time) float64 {
+	if !device.lastTimestamp.IsZero() && t.After(device.lastTimestamp) {
+		return t.Sub(device.lastTimestamp).Hours()
+	}
+	return 1.0
+}
+
+// thermalMass estima a massa térmica da sala pelo volume atendido, quando conhecido.
+func (d *deviceState) thermalMass() float64 {
+	if d.servedVolumeM3 > 0 {
+		return math.Max(1.0, d.servedVolumeM3*thermalMassPerM3)
+	}
+	return defaultThermalMassKwhPerK
+}
+
+// maxTempChange é a variação máxima de temperatura que o equipamento consegue impor à sala no
+// período operando em plena capacidade, descontada a carga da envoltória e dos ocupantes.
+func (d *deviceState) maxTempChange(outdoorTemp, target float64, occupied, cooling bool, hours float64) float64 {
+	load := envelopeUaKwPerK * (outdoorTemp - target) // Positivo: ganho de calor
+	if occupied {
+		load += occupiedGainsKw
+	}
+
+	net := d.CapacityKw - load
+	if !cooling {
+		net = d.CapacityKw*heatingCapacityRatio + load
+	}
+	return math.Max(0, net) * hours / d.thermalMass()
+}
+
+// ratedPower é a potência elétrica do compressor em plena carga.
+func (d *deviceState) ratedPower(cooling bool) float64 {
+	if cooling {
+		return d.CapacityKw / ratedCop
+	}
+	return d.CapacityKw * heatingCapacityRatio / ratedCop
+}
+
+// markCapacityLimited classifica um passo limitado pela capacidade: logo após o equipamento
+// ligar é retomada do setback; em operação contínua é saturação (subdimensionamento).
+func (d *deviceState) markCapacityLimited() {
+	switch d.lastStatus {
+	case "", "OFF", "NIGHT_PURGE":
+		d.recovering = true
+	default:
+		if d.wasRecovering {
+			d.recovering = true
+		} else {
+			d.saturated = true
+		}
+	}
+}
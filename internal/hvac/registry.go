@@ -0,0 +1,164 @@
+package hvac
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RegisteredDevice é um equipamento da frota simulada no registro de dispositivos, com os
+// metadados usados para cadastrá-lo (coisas do IoT Core, base de ativos) antes do envio dos dados.
+// Cada troca de equipamento gera uma entrada com a nova identidade.
+type RegisteredDevice struct {
+	DeviceId        string     `json:"deviceId"`                  // Identidade do equipamento nas leituras
+	AssetModel      string     `json:"assetModel"`                // Modelo do equipamento
+	Site            string     `json:"site,omitempty"`            // Código da estação meteorológica do site
+	Zone            string     `json:"zone"`                      // Zona atendida
+	CapacityKw      float64    `json:"capacityKw"`                // Capacidade nominal de resfriamento (kW térmicos), já dimensionada
+	Stages          int        `json:"stages"`                    // Estágios de compressor
+	Protocol        string     `json:"protocol,omitempty"`        // Protocolo até o gateway (vazio: o padrão do envelope)
+	Firmware        string     `json:"firmware"`                  // Versão de firmware
+	Dialect         string     `json:"dialect,omitempty"`         // Formato de payload do dispositivo
+	Template        string     `json:"template,omitempty"`        // Modelo de payload de fabricante
+	SensorPlacement string     `json:"sensorPlacement,omitempty"` // Posição do termostato, se mal posicionado
+	Setpoint        float64    `json:"setpoint"`                  // Setpoint base da sala (°C)
+	ServedAreaM2    float64    `json:"servedAreaM2,omitempty"`    // Área da zona atribuída ao equipamento (m²)
+	ServedVolumeM3  float64    `json:"servedVolumeM3,omitempty"`  // Volume da zona atribuído ao equipamento (m³)
+	VrfOutdoorUnit  string     `json:"vrfOutdoorUnit,omitempty"`  // Unidade externa VRF que atende a sala
+	Sensors         []string   `json:"sensors,omitempty"`         // Sensores sem fio instalados na sala
+	InstalledAt     *time.Time `json:"installedAt,omitempty"`     // Início da operação, se instalado ou trocado durante a série
+	RemovedAt       *time.Time `json:"removedAt,omitempty"`       // Retirada, se trocado ou retirado durante a série
+	Replaces        string     `json:"replaces,omitempty"`        // Equipamento substituído por este
+	ReplacedBy      string     `json:"replacedBy,omitempty"`      // Equipamento que substituiu este
+}
+
+// DeviceRegistry lista todos os equipamentos que reportaram na simulação, inclusive os trocados
+// pelo ciclo de vida, na ordem da frota. Deve ser chamado após a simulação.
+func (s *Simulator) DeviceRegistry() []RegisteredDevice {
+	sensors := make(map[*deviceState][]string)
+	for _, sensor := range s.sensors {
+		sensors[sensor.room] = append(sensors[sensor.room], sensor.ID)
+	}
+
+	var registry []RegisteredDevice
+	for _, device := range s.devices {
+		entry := RegisteredDevice{
+			DeviceId:        device.ID,
+			AssetModel:      device.AssetModel,
+			Site:            s.station,
+			Zone:            device.Zone,
+			CapacityKw:      device.CapacityKw,
+			Protocol:        device.Protocol,
+			Firmware:        cmp.Or(device.Firmware, defaultFirmware),
+			Dialect:         device.Dialect,
+			Template:        device.Template,
+			SensorPlacement: device.SensorPlacement,
+			Setpoint:        cmp.Or(device.Setpoint, s.model.BaseSetpoint),
+			ServedAreaM2:    device.servedAreaM2,
+			ServedVolumeM3:  device.servedVolumeM3,
+			Sensors:         sensors[device],
+		}
+		if device.vrf != nil {
+			entry.VrfOutdoorUnit = device.vrf.id
+		}
+		if device.lifecycle == nil {
+			entry.Stages = s.modelStages(entry.AssetModel)
+			registry = append(registry, entry)
+			continue
+		}
+		registry = append(registry, s.deviceHistory(device, entry)...)
+	}
+	return registry
+}
+
+// deviceHistory reconstrói, pelos eventos do ciclo de vida, as identidades que o equipamento da
+// sala assumiu ao longo da série, a partir dos metadados comuns em entry.
+func (s *Simulator) deviceHistory(device *deviceState, entry RegisteredDevice) []RegisteredDevice {
+	state := device.lifecycle
+	current := entry
+	current.DeviceId, current.AssetModel = state.baseID, state.baseModel
+
+	var history []RegisteredDevice
+	for _, record := range s.lifecycleRecords {
+		switch {
+		case record.Type == LifecycleReplace && record.PreviousDeviceId == current.DeviceId:
+			current.RemovedAt, current.ReplacedBy = record.RemovedAt, record.DeviceId
+			history = append(history, current)
+			installedAt := record.Timestamp
+			current = entry
+			current.DeviceId, current.AssetModel = record.DeviceId, record.AssetModel
+			current.InstalledAt, current.Replaces = &installedAt, record.PreviousDeviceId
+		case record.Type == LifecycleInstall && record.DeviceId == current.DeviceId:
+			installedAt := record.Timestamp
+			current.InstalledAt = &installedAt
+		case record.Type == LifecycleDecommission && record.DeviceId == current.DeviceId:
+			removedAt := record.Timestamp
+			current.RemovedAt = &removedAt
+		}
+	}
+	if !state.removedAt.IsZero() && current.RemovedAt == nil { // Retirado para uma troca que não chegou a ocorrer na série
+		removedAt := state.removedAt
+		current.RemovedAt = &removedAt
+	}
+	history = append(history, current)
+	for i := range history {
+		history[i].Stages = s.modelStages(history[i].AssetModel)
+	}
+	return history
+}
+
+// WriteDeviceRegistryJSON serializa o registro de dispositivos.
+func WriteDeviceRegistryJSON(registry []RegisteredDevice) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar o registro de dispositivos para JSON: %w", err)
+	}
+	return jsonData, nil
+}
+
+// WriteDeviceRegistryCSV serializa o registro de dispositivos como CSV, uma linha por equipamento
+// (os sensores da sala separados por espaço).
+func WriteDeviceRegistryCSV(registry []RegisteredDevice) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"deviceId", "assetModel", "site", "zone", "capacityKw", "stages", "protocol", "firmware", "dialect", "template",
+		"sensorPlacement", "setpoint", "servedAreaM2", "servedVolumeM3", "vrfOutdoorUnit", "sensors", "installedAt", "removedAt", "replaces", "replacedBy"}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("erro ao escrever cabeçalho do registro de dispositivos: %w", err)
+	}
+	for _, d := range registry {
+		row := []string{
+			d.DeviceId, d.AssetModel, d.Site, d.Zone, strconv.FormatFloat(d.CapacityKw, 'f', -1, 64), strconv.Itoa(d.Stages), d.Protocol, d.Firmware, d.Dialect, d.Template,
+			d.SensorPlacement, strconv.FormatFloat(d.Setpoint, 'f', -1, 64), formatNonZero(d.ServedAreaM2), formatNonZero(d.ServedVolumeM3), d.VrfOutdoorUnit,
+			strings.Join(d.Sensors, " "), formatOptionalTime(d.InstalledAt), formatOptionalTime(d.RemovedAt), d.Replaces, d.ReplacedBy,
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("erro ao escrever o dispositivo '%s' no registro: %w", d.DeviceId, err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar o registro de dispositivos: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func formatNonZero(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,187 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// RollupConfig ativa a exportação de agregados por janela, como os históricos de BAS.
+type RollupConfig struct {
+	IntervalsMinutes []int `json:"intervalsMinutes"` // Janelas de agregação (min, padrão: 15 e 60)
+}
+
+// Intervals retorna as janelas configuradas ou as janelas padrão.
+func (c RollupConfig) Intervals() []time.Duration {
+	minutes := c.IntervalsMinutes
+	if len(minutes) == 0 {
+		minutes = []int{15, 60}
+	}
+	intervals := make([]time.Duration, len(minutes))
+	for i, m := range minutes {
+		intervals[i] = time.Duration(m) * time.Minute
+	}
+	return intervals
+}
+
+// RollupStats são a média, o mínimo e o máximo de uma grandeza na janela.
+type RollupStats struct {
+	Avg float64 `json:"avg"`
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Rollup é o agregado das leituras de um dispositivo em uma janela.
+type Rollup struct {
+	DeviceId      string    `json:"deviceId"`      // Dispositivo agregado
+	AssetModel    string    `json:"assetModel"`    // Modelo do equipamento
+	LocationZone  string    `json:"locationZone"`  // Zona do dispositivo
+	WindowStart   time.Time `json:"windowStart"`   // Início da janela
+	WindowMinutes int       `json:"windowMinutes"` // Duração da janela (min)
+	SampleCount   int       `json:"sampleCount"`   // Leituras brutas na janela
+
+	InternalTemperature    RollupStats `json:"internalTemperature"`
+	SetPointTemperature    RollupStats `json:"setPointTemperature"`
+	OutdoorTemperature     RollupStats `json:"outdoorTemperature"`
+	OutdoorHumidity        RollupStats `json:"outdoorHumidity"`
+	SupplyAirTemperature   RollupStats `json:"supplyAirTemperature"`
+	ReturnAirTemperature   RollupStats `json:"returnAirTemperature"`
+	DuctStaticPressurePa   RollupStats `json:"ductStaticPressurePa"`
+	CO2LevelPpm            RollupStats `json:"co2LevelPpm"`
+	RefrigerantPressurePsi RollupStats `json:"refrigerantPressurePsi"`
+
+	EnergyKwh        float64 `json:"energyKwh"`           // Energia total da janela (soma, não média)
+	GasM3            float64 `json:"gasM3,omitempty"`     // Gás total da janela, com aquecimento a gás (m³, soma)
+	GasTherms        float64 `json:"gasTherms,omitempty"` // Gás total da janela, com aquecimento a gás (therms, soma)
+	OccupiedFraction float64 `json:"occupiedFraction"`    // Fração das leituras com a sala ocupada
+	DominantStatus   string  `json:"dominantStatus"`      // Estado operacional mais frequente
+	FaultCount       int     `json:"faultCount"`          // Leituras com código de falha diferente de OK
+}
+
+// statsAccumulator acumula média, mínimo e máximo de uma grandeza.
+type statsAccumulator struct {
+	sum, min, max float64
+	n             int
+}
+
+func (a *statsAccumulator) add(v float64) {
+	if a.n == 0 || v < a.min {
+		a.min = v
+	}
+	if a.n == 0 || v > a.max {
+		a.max = v
+	}
+	a.sum += v
+	a.n++
+}
+
+func (a *statsAccumulator) stats() RollupStats {
+	if a.n == 0 {
+		return RollupStats{}
+	}
+	return RollupStats{Avg: a.sum / float64(a.n), Min: a.min, Max: a.max}
+}
+
+type rollupAccumulator struct {
+	rollup                                                                     Rollup
+	internal, setPoint, outdoor, humidity, supply, ret, duct, co2, refrigerant statsAccumulator
+	occupied                                                                   int
+	statuses                                                                   map[string]int
+}
+
+// BuildRollups agrega as leituras por dispositivo em janelas alinhadas ao relógio. Janelas menores
+// que o passo dos dados (1 h no INMET) contêm uma única leitura.
+func BuildRollups(data []HvacSensorData, interval time.Duration) []Rollup {
+	type key struct {
+		deviceId string
+		window   time.Time
+	}
+	accumulators := make(map[key]*rollupAccumulator)
+	var keys []key
+	for _, record := range data {
+		k := key{record.DeviceId, record.Timestamp.Truncate(interval)}
+		acc, ok := accumulators[k]
+		if !ok {
+			acc = &rollupAccumulator{
+				rollup: Rollup{
+					DeviceId:      record.DeviceId,
+					AssetModel:    record.AssetModel,
+					LocationZone:  record.LocationZone,
+					WindowStart:   k.window,
+					WindowMinutes: int(interval.Minutes()),
+				},
+				statuses: make(map[string]int),
+			}
+			accumulators[k] = acc
+			keys = append(keys, k)
+		}
+		acc.rollup.SampleCount++
+		acc.internal.add(record.InternalTemperature)
+		acc.setPoint.add(record.SetPointTemperature)
+		acc.outdoor.add(record.OutdoorTemperature)
+		acc.humidity.add(record.OutdoorHumidity)
+		acc.supply.add(record.SupplyAirTemperature)
+		acc.ret.add(record.ReturnAirTemperature)
+		acc.duct.add(record.DuctStaticPressurePa)
+		acc.co2.add(record.CO2LevelPpm)
+		acc.refrigerant.add(record.RefrigerantPressurePsi)
+		acc.rollup.EnergyKwh += record.PowerConsumptionKwH
+		acc.rollup.GasM3 += record.GasConsumptionM3
+		acc.rollup.GasTherms += record.GasConsumptionTherms
+		if record.OccupancyStatus {
+			acc.occupied++
+		}
+		if record.FaultCode != "" && record.FaultCode != "OK" {
+			acc.rollup.FaultCount++
+		}
+		acc.statuses[record.SystemStatus]++
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if !keys[i].window.Equal(keys[j].window) {
+			return keys[i].window.Before(keys[j].window)
+		}
+		return keys[i].deviceId < keys[j].deviceId
+	})
+
+	rollups := make([]Rollup, 0, len(keys))
+	for _, k := range keys {
+		acc := accumulators[k]
+		r := acc.rollup
+		r.InternalTemperature = acc.internal.stats()
+		r.SetPointTemperature = acc.setPoint.stats()
+		r.OutdoorTemperature = acc.outdoor.stats()
+		r.OutdoorHumidity = acc.humidity.stats()
+		r.SupplyAirTemperature = acc.supply.stats()
+		r.ReturnAirTemperature = acc.ret.stats()
+		r.DuctStaticPressurePa = acc.duct.stats()
+		r.CO2LevelPpm = acc.co2.stats()
+		r.RefrigerantPressurePsi = acc.refrigerant.stats()
+		r.OccupiedFraction = float64(acc.occupied) / float64(r.SampleCount)
+		r.DominantStatus = dominantStatus(acc.statuses)
+		rollups = append(rollups, r)
+	}
+	return rollups
+}
+
+// dominantStatus retorna o estado mais frequente; empates são resolvidos pela ordem alfabética.
+func dominantStatus(statuses map[string]int) string {
+	best, bestCount := "", math.MinInt
+	for status, count := range statuses {
+		if count > bestCount || (count == bestCount && status < best) {
+			best, bestCount = status, count
+		}
+	}
+	return best
+}
+
+// WriteRollupsJSON serializa os agregados em JSON.
+func WriteRollupsJSON(rollups []Rollup) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(rollups, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar agregados HVAC para JSON: %w", err)
+	}
+	return jsonData, nil
+}
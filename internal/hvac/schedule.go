@@ -0,0 +1,316 @@
+package hvac
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SchedulePeriod é um intervalo ocupado da programação, em horário do dia.
+type SchedulePeriod struct {
+	Start    int     // Início, em minutos desde a meia-noite
+	End      int     // Fim, em minutos desde a meia-noite (1440 para 24:00)
+	Setpoint float64 // Setpoint ocupado do intervalo (°C, 0 mantém o programado)
+}
+
+// BASSchedule é uma programação semanal exportada da automação predial, com exceções por data
+// (feriados, eventos), aplicada às zonas listadas.
+type BASSchedule struct {
+	Name       string
+	Zones      []string
+	Weekly     map[time.Weekday][]SchedulePeriod
+	Exceptions map[string][]SchedulePeriod // Por data AAAA-MM-DD; lista vazia: desocupado o dia todo
+}
+
+// periods retorna os intervalos ocupados do dia do instante, com as exceções valendo sobre a semana.
+func (s BASSchedule) periods(t time.Time) []SchedulePeriod {
+	if periods, ok := s.Exceptions[t.Format("2006-01-02")]; ok {
+		return periods
+	}
+	return s.Weekly[t.Weekday()]
+}
+
+// ScheduleImportConfig aponta o arquivo de programação exportado da automação.
+type ScheduleImportConfig struct {
+	File          string  `json:"file"`          // CSV ou JSON com as programações semanais e exceções
+	Format        string  `json:"format"`        // csv ou json (padrão: pela extensão do arquivo)
+	SetbackOffset float64 `json:"setbackOffset"` // Afastamento dos setpoints fora dos intervalos ocupados (°C, padrão: 4)
+}
+
+// ScheduleFormat resolve o formato do arquivo de programação.
+func (c ScheduleImportConfig) ScheduleFormat() (string, error) {
+	format := strings.ToLower(c.Format)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(c.File)), ".")
+	}
+	if format != "csv" && format != "json" {
+		return "", fmt.Errorf("formato de programação '%s' desconhecido (use csv ou json)", format)
+	}
+	return format, nil
+}
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday, "dom": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday, "seg": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "ter": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "qua": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "qui": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "sex": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "sab": time.Saturday, "sáb": time.Saturday,
+}
+
+// parseTimeOfDay lê um horário HH:MM (24:00 é o fim do dia) em minutos desde a meia-noite.
+func parseTimeOfDay(raw string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(strings.TrimSpace(raw), "%d:%d", &hour, &minute); err != nil {
+		return 0, fmt.Errorf("horário inválido '%s': use HH:MM", raw)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("horário fora do dia '%s'", raw)
+	}
+	return hour*60 + minute, nil
+}
+
+func parsePeriod(start, end string, setpoint float64) (SchedulePeriod, error) {
+	period := SchedulePeriod{Setpoint: setpoint}
+	var err error
+	if period.Start, err = parseTimeOfDay(start); err != nil {
+		return period, err
+	}
+	if period.End, err = parseTimeOfDay(end); err != nil {
+		return period, err
+	}
+	if period.End <= period.Start {
+		return period, fmt.Errorf("intervalo %s-%s termina antes de começar", start, end)
+	}
+	return period, nil
+}
+
+// addDay registra os intervalos de um dia da semana (mon, seg, monday...) ou de uma exceção
+// (AAAA-MM-DD) na programação.
+func (s *BASSchedule) addDay(day string, periods ...SchedulePeriod) error {
+	day = strings.ToLower(strings.TrimSpace(day))
+	if weekday, ok := scheduleWeekdays[day]; ok {
+		s.Weekly[weekday] = append(s.Weekly[weekday], periods...)
+		return nil
+	}
+	date, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return fmt.Errorf("dia '%s' inválido: use o dia da semana ou AAAA-MM-DD", day)
+	}
+	key := date.Format("2006-01-02")
+	s.Exceptions[key] = append(s.Exceptions[key], periods...)
+	return nil
+}
+
+func newBASSchedule(name string) *BASSchedule {
+	return &BASSchedule{Name: name, Weekly: make(map[time.Weekday][]SchedulePeriod), Exceptions: make(map[string][]SchedulePeriod)}
+}
+
+// ReadBASScheduleCSV lê programações com o cabeçalho schedule,zone,day,start,end e a coluna
+// opcional setpoint, uma linha por intervalo ocupado. zone aceita várias zonas separadas por ';';
+// day é o dia da semana ou a data de uma exceção, e uma exceção sem start e end deixa o dia
+// desocupado.
+func ReadBASScheduleCSV(r io.Reader) ([]BASSchedule, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o cabeçalho da programação: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"schedule", "zone", "day", "start", "end"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("coluna '%s' ausente na programação", name)
+		}
+	}
+	setpointColumn, hasSetpoint := columns["setpoint"]
+
+	var order []string
+	byName := make(map[string]*BASSchedule)
+	zoneSeen := make(map[string]map[string]bool)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler a linha %d da programação: %w", line, err)
+		}
+		name := record[columns["schedule"]]
+		schedule, ok := byName[name]
+		if !ok {
+			schedule = newBASSchedule(name)
+			byName[name] = schedule
+			zoneSeen[name] = make(map[string]bool)
+			order = append(order, name)
+		}
+		for _, zone := range strings.Split(record[columns["zone"]], ";") {
+			if zone = strings.TrimSpace(zone); zone != "" && !zoneSeen[name][zone] {
+				zoneSeen[name][zone] = true
+				schedule.Zones = append(schedule.Zones, zone)
+			}
+		}
+
+		var periods []SchedulePeriod
+		start, end := record[columns["start"]], record[columns["end"]]
+		if start != "" || end != "" {
+			setpoint := 0.0
+			if hasSetpoint && record[setpointColumn] != "" {
+				if _, err := fmt.Sscanf(record[setpointColumn], "%g", &setpoint); err != nil {
+					return nil, fmt.Errorf("setpoint inválido na linha %d da programação: %w", line, err)
+				}
+			}
+			period, err := parsePeriod(start, end, setpoint)
+			if err != nil {
+				return nil, fmt.Errorf("linha %d da programação: %w", line, err)
+			}
+			periods = append(periods, period)
+		}
+		if err := schedule.addDay(record[columns["day"]], periods...); err != nil {
+			return nil, fmt.Errorf("linha %d da programação: %w", line, err)
+		}
+	}
+
+	schedules := make([]BASSchedule, 0, len(order))
+	for _, name := range order {
+		schedules = append(schedules, *byName[name])
+	}
+	return schedules, nil
+}
+
+type basScheduleJSON struct {
+	Name       string                         `json:"name"`
+	Zones      []string                       `json:"zones"`
+	Weekly     map[string][]basSchedulePeriod `json:"weekly"`
+	Exceptions []basScheduleExceptionJSON     `json:"exceptions"`
+}
+
+type basSchedulePeriod struct {
+	Start    string  `json:"start"`
+	End      string  `json:"end"`
+	Setpoint float64 `json:"setpoint"`
+}
+
+type basScheduleExceptionJSON struct {
+	Date    string              `json:"date"`
+	Periods []basSchedulePeriod `json:"periods"`
+}
+
+// ReadBASScheduleJSON lê programações no formato
+// [{"name", "zones", "weekly": {"mon": [{"start", "end", "setpoint"}]}, "exceptions": [{"date", "periods"}]}].
+func ReadBASScheduleJSON(r io.Reader) ([]BASSchedule, error) {
+	var raw []basScheduleJSON
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar a programação JSON: %w", err)
+	}
+	schedules := make([]BASSchedule, 0, len(raw))
+	for _, entry := range raw {
+		schedule := newBASSchedule(entry.Name)
+		schedule.Zones = entry.Zones
+		for day, periods := range entry.Weekly {
+			parsed, err := parsePeriods(periods)
+			if err != nil {
+				return nil, fmt.Errorf("programação '%s', %s: %w", entry.Name, day, err)
+			}
+			if _, ok := scheduleWeekdays[strings.ToLower(day)]; !ok {
+				return nil, fmt.Errorf("programação '%s': dia da semana '%s' desconhecido", entry.Name, day)
+			}
+			if err := schedule.addDay(day, parsed...); err != nil {
+				return nil, err
+			}
+		}
+		for _, exception := range entry.Exceptions {
+			parsed, err := parsePeriods(exception.Periods)
+			if err != nil {
+				return nil, fmt.Errorf("programação '%s', exceção %s: %w", entry.Name, exception.Date, err)
+			}
+			if err := schedule.addDay(exception.Date, parsed...); err != nil {
+				return nil, fmt.Errorf("programação '%s': %w", entry.Name, err)
+			}
+		}
+		schedules = append(schedules, *schedule)
+	}
+	return schedules, nil
+}
+
+func parsePeriods(raw []basSchedulePeriod) ([]SchedulePeriod, error) {
+	periods := make([]SchedulePeriod, 0, len(raw))
+	for _, p := range raw {
+		period, err := parsePeriod(p.Start, p.End, p.Setpoint)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+// ReadBASSchedules lê o arquivo de programação no formato informado (csv ou json).
+func ReadBASSchedules(r io.Reader, format string) ([]BASSchedule, error) {
+	switch format {
+	case "csv":
+		return ReadBASScheduleCSV(r)
+	case "json":
+		return ReadBASScheduleJSON(r)
+	}
+	return nil, fmt.Errorf("formato de programação '%s' desconhecido (use csv ou json)", format)
+}
+
+// BASScheduleStrategy segue as programações importadas da automação: dentro de um intervalo
+// ocupado da zona, controla no setpoint do intervalo; fora dele, só atua para manter os setpoints
+// de setback. Zonas sem programação seguem o termostato simples.
+type BASScheduleStrategy struct {
+	SetbackOffset float64 // Afastamento dos setpoints fora dos intervalos ocupados (°C, padrão: 4)
+
+	byZone map[string]BASSchedule
+}
+
+// NewBASScheduleStrategy associa as programações às zonas. Uma zona em duas programações é erro.
+func NewBASScheduleStrategy(schedules []BASSchedule, setbackOffset float64) (*BASScheduleStrategy, error) {
+	if setbackOffset == 0 {
+		setbackOffset = 4.0
+	}
+	strategy := &BASScheduleStrategy{SetbackOffset: setbackOffset, byZone: make(map[string]BASSchedule)}
+	for _, schedule := range schedules {
+		for _, zone := range schedule.Zones {
+			if other, ok := strategy.byZone[zone]; ok {
+				return nil, fmt.Errorf("zona '%s' nas programações '%s' e '%s'", zone, other.Name, schedule.Name)
+			}
+			strategy.byZone[zone] = schedule
+		}
+	}
+	return strategy, nil
+}
+
+// Decide implementa ControlStrategy.
+func (c *BASScheduleStrategy) Decide(state ZoneState) ControlDecision {
+	schedule, ok := c.byZone[state.Zone]
+	if !ok {
+		return ThermostatStrategy{}.Decide(state)
+	}
+	minute := state.Timestamp.Hour()*60 + state.Timestamp.Minute()
+	for _, period := range schedule.periods(state.Timestamp) {
+		if minute < period.Start || minute >= period.End {
+			continue
+		}
+		setpoint := state.Setpoint
+		if period.Setpoint != 0 {
+			setpoint = period.Setpoint
+		}
+		decision := ThermostatStrategy{}.Decide(ZoneState{Occupied: true, Temperature: state.Temperature, Setpoint: setpoint, Deadband: state.Deadband})
+		if decision.Mode == "OFF" {
+			decision.Mode = "IDLE" // No intervalo ocupado o ventilador segue ligado
+		}
+		return decision
+	}
+	return setback(state, c.SetbackOffset)
+}
@@ -0,0 +1,109 @@
+package hvac
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+const defaultFirmware = "1.0.0"
+
+// ShadowConfig ativa as atualizações de estado reportado (AWS IoT Device Shadow) de cada dispositivo.
+type ShadowConfig struct {
+	ThingPrefix string `json:"thingPrefix"` // Prefixo do nome do thing; o nome é prefixo + deviceId
+	ShadowName  string `json:"shadowName"`  // Shadow nomeado (padrão: shadow clássico, sem nome)
+}
+
+// ShadowReportedState é o estado que o termostato reporta ao shadow.
+type ShadowReportedState struct {
+	SetPointTemperature float64 `json:"setPointTemperature"` // Setpoint do termostato: o programado ou o ajuste do ocupante (°C, passo de 0,5)
+	Mode                string  `json:"mode"`                // Modo programado no termostato: auto ou off
+	Firmware            string  `json:"firmware"`            // Versão de firmware do dispositivo
+}
+
+// ShadowDocument é o corpo publicado no tópico de update do shadow.
+type ShadowDocument struct {
+	State struct {
+		Reported ShadowReportedState `json:"reported"`
+	} `json:"state"`
+	ClientToken string `json:"clientToken"`
+}
+
+// ShadowUpdate é uma atualização de shadow no instante em que o estado reportado mudou.
+type ShadowUpdate struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	ThingName  string         `json:"thingName"`
+	ShadowName string         `json:"shadowName,omitempty"`
+	Topic      string         `json:"topic"`
+	Payload    ShadowDocument `json:"payload"`
+}
+
+// shadowMode é o modo programado no termostato: automático no horário comercial dos dias úteis
+// (a mesma janela do modelo de ocupação) e desligado no restante.
+func shadowMode(t time.Time) string {
+	if weekday := t.Weekday(); weekday >= time.Monday && weekday <= time.Friday && t.Hour() >= 8 && t.Hour() < 18 {
+		return "auto"
+	}
+	return "off"
+}
+
+// BuildShadowUpdates percorre as leituras e gera uma atualização de shadow por dispositivo sempre
+// que o estado reportado muda (modo programado ou ajuste de setpoint pelo ocupante), como um
+// termostato real que só reporta mudanças. A primeira leitura de cada dispositivo sempre gera atualização.
+func BuildShadowUpdates(cfg ShadowConfig, devices []Device, data []HvacSensorData) []ShadowUpdate {
+	firmware := make(map[string]string, len(devices))
+	for _, d := range devices {
+		firmware[d.ID] = d.Firmware
+	}
+
+	last := make(map[string]ShadowReportedState)
+	sequence := make(map[string]int)
+	var updates []ShadowUpdate
+	for _, record := range data {
+		reported := ShadowReportedState{
+			SetPointTemperature: baseInternalTemp,
+			Mode:                shadowMode(record.Timestamp),
+			Firmware:            firmware[record.DeviceId],
+		}
+		if reported.Firmware == "" {
+			reported.Firmware = defaultFirmware
+		}
+		if record.OverrideActive {
+			reported.SetPointTemperature = math.Round(record.SetPointTemperature*2) / 2
+		}
+		if previous, ok := last[record.DeviceId]; ok && previous == reported {
+			continue
+		}
+		last[record.DeviceId] = reported
+		sequence[record.DeviceId]++
+
+		thing := cfg.ThingPrefix + record.DeviceId
+		update := ShadowUpdate{
+			Timestamp:  record.Timestamp,
+			ThingName:  thing,
+			ShadowName: cfg.ShadowName,
+			Topic:      shadowUpdateTopic(thing, cfg.ShadowName),
+		}
+		update.Payload.State.Reported = reported
+		update.Payload.ClientToken = fmt.Sprintf("%s-%d", record.DeviceId, sequence[record.DeviceId])
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+func shadowUpdateTopic(thing, shadowName string) string {
+	if shadowName != "" {
+		return fmt.Sprintf("$aws/things/%s/shadow/name/%s/update", thing, shadowName)
+	}
+	return fmt.Sprintf("$aws/things/%s/shadow/update", thing)
+}
+
+// WriteShadowUpdatesJSON serializa as atualizações de shadow em ordem cronológica.
+func WriteShadowUpdatesJSON(updates []ShadowUpdate) ([]byte, error) {
+	jsonData, err := json.MarshalIndent(updates, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar as atualizações de shadow para JSON: %w", err)
+	}
+	return jsonData, nil
+}
@@ -0,0 +1,488 @@
+package hvac
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+const (
+	defaultAssetModel = "HVAC-Model-B"
+	defaultZone       = "Zona-A"
+)
+
+// Device identifica uma unidade HVAC simulada e a sala que ela atende.
+type Device struct {
+	ID         string  `json:"id"`         // Identificador do dispositivo (ex: SALA-1)
+	AssetModel string  `json:"assetModel"` // Modelo do equipamento
+	Zone       string  `json:"zone"`       // Zona ou localização do dispositivo
+	CapacityKw float64 `json:"capacityKw"` // Capacidade nominal de resfriamento em kW térmicos (padrão: 20)
+	// SizingRatio define a capacidade relativa à carga de projeto da sala (ex: 1.5 = 150%,
+	// superdimensionado), substituindo CapacityKw quando informado.
+	SizingRatio float64 `json:"sizingRatio"`
+	// SensorPlacement simula o termostato mal posicionado (HEAT_SOURCE ou SUPPLY_DIFFUSER);
+	// SensorOffset ajusta o viés da fonte de calor em °C (padrão: 2).
+	SensorPlacement string          `json:"sensorPlacement"`
+	SensorOffset    float64         `json:"sensorOffset"`
+	Faults          []FaultScenario `json:"faults"`   // Falhas injetadas no dispositivo
+	Dialect         string          `json:"dialect"`  // Formato de payload do dispositivo (nome de um dialeto do cenário)
+	Template        string          `json:"template"` // Modelo de payload de fabricante (honeywell, trane, daikin ou do cenário)
+	Protocol        string          `json:"protocol"` // Protocolo até o gateway no envelope (ex: BACnet/IP, Modbus, LoRaWAN, Zigbee)
+	Firmware        string          `json:"firmware"` // Versão de firmware reportada no Device Shadow (padrão: 1.0.0)
+	// PhaseOffsetSeconds atrasa o instante das leituras do dispositivo em relação ao passo (ex: 37
+	// reporta aos :00:37), como os dispositivos reais, que não reportam todos no início da hora.
+	PhaseOffsetSeconds float64 `json:"phaseOffsetSeconds"`
+	JitterSeconds      float64 `json:"jitterSeconds"` // Variação de ±N s no instante de cada leitura (padrão: a de timestamps)
+	Setpoint           float64 `json:"setpoint"`      // Setpoint próprio da sala (°C, padrão: o do modelo)
+}
+
+// DefaultDevices retorna a frota padrão: dez salas na Zona-A.
+func DefaultDevices() []Device {
+	devices := make([]Device, 10)
+	for i := range devices {
+		devices[i] = Device{
+			ID:         fmt.Sprintf("SALA-%d", i+1),
+			AssetModel: defaultAssetModel,
+			Zone:       defaultZone,
+		}
+	}
+	return devices
+}
+
+// SimulatorConfig reúne os parâmetros de criação do simulador.
+type SimulatorConfig struct {
+	Devices     []Device               // Frota simulada (padrão: DefaultDevices)
+	Zones       []Zone                 // Geometria das zonas, usada nas métricas de intensidade
+	Seed        int64                  // Semente do gerador aleatório (0 usa o relógio)
+	Precooling  *PrecoolingConfig      // Estratégia de pré-resfriamento (desativada se nil)
+	G36         *G36Config             // Sequência G36 com AHU por zona (desativada se nil)
+	FddBaseline bool                   // Emite em cada leitura os valores esperados pelo modelo físico
+	Outages     *OutageConfig          // Quedas de energia do site (desativadas se nil)
+	Brownouts   *BrownoutConfig        // Afundamentos de tensão do site (desativados se nil)
+	Overrides   *OverrideConfig        // Ajustes de setpoint pelos ocupantes (desativados se nil)
+	Sensors     []WirelessSensor       // Sensores de ambiente a bateria instalados nas salas
+	Model       *ModelParams           // Setpoint, banda morta, ocupação, falhas e ruído (padrão: DefaultModelParams)
+	Control     ControlStrategy        // Decide modo e setpoint de cada sala (padrão: ThermostatStrategy)
+	Faults      FaultModel             // Desgaste e alarmes do equipamento (padrão: SeasonalFaultModel com Model.FaultRate)
+	Consistency *ConsistencyConfig     // Verificação da coerência entre modo e grandezas (desativada se nil)
+	Energy      *EnergyBalanceConfig   // Balanço de energia diário por zona (desativado se nil)
+	Timestamps  *TimestampConfig       // Defasagem e jitter do instante de leitura dos dispositivos (desativados se nil)
+	AssetModels []AssetModelSpec       // Curvas de eficiência por modelo de equipamento (modelos sem curva usam as potências base)
+	Hydronic    *HydronicConfig        // Serpentinas de água gelada e quente com telemetria do lado de água (desativadas se nil)
+	VRF         *VRFConfig             // Sistemas VRF multi-split com capacidade compartilhada (desativados se nil)
+	ERV         *ERVConfig             // Recuperador de calor (HRV/ERV) no ar de renovação de cada sala (desativado se nil)
+	Defrost     *DefrostConfig         // Ciclos de degelo das bombas de calor no frio (desativados se nil)
+	Filter      *FilterConfig          // Carga do filtro por horas de ventilador e poeira externa (colmatação sazonal se nil)
+	Maintenance *MaintenanceConfig     // Ordens de serviço de manutenção preventivas e corretivas (desativadas se nil)
+	Lifecycle   *LifecycleConfig       // Instalação, troca e retirada de equipamentos ao longo da série (desativadas se nil)
+	Economizer  *EconomizerConfig      // Economizador com limite alto pela zona climática (desativado se nil)
+	Noise       *CorrelatedNoiseConfig // Ruído de medição correlacionado entre campos relacionados (desativado se nil)
+	Missingness *MissingnessConfig     // Ausência de campos por padrão aleatório, em rajadas ou por dispositivo (desativada se nil)
+	Boundary    *BoundaryConfig        // Leituras levadas aos limites das faixas do catálogo de pontos (desativadas se nil)
+	Station     string                 // Código da estação meteorológica do site, gravado em cada leitura (omitido se vazio)
+	Altitude    float64                // Altitude do site (m), que corrige a pressão atmosférica e a densidade do ar (padrão: nível do mar)
+}
+
+// deviceState guarda o estado de um dispositivo entre passos de simulação.
+type deviceState struct {
+	Device
+	internalTemp float64     // Temperatura interna ao fim do último passo (°C)
+	hasState     bool        // Indica se já houve um passo anterior
+	airHandler   *airHandler // AHU que atende a sala no modo G36
+
+	vrf               *vrfOutdoorUnit // Unidade externa VRF que atende a sala
+	vrfCapacityFactor float64         // Fração da capacidade nominal liberada pela unidade externa no passo
+	frostLevel        float64         // Gelo acumulado na serpentina externa, em frações de um degelo
+	leadCompressor    int             // Compressor líder (índice), nos equipamentos com vários estágios
+	leadRunHours      float64         // Horas de operação do líder desde o último rodízio
+	filterLoad        float64         // Carga do filtro, de 0 (limpo) a 1 (perda de carga final)
+
+	pendingOrders   []pendingOrder       // Ordens de serviço corretivas abertas
+	scheduledDone   map[int]int          // Execuções de cada manutenção programada, pelo índice em Orders
+	repairedFaults  map[string]time.Time // Reparo de cada tipo de falha injetada por ordem de serviço
+	filterChangedAt time.Time            // Última troca de filtro (zero se não houve)
+	rechargedAt     time.Time            // Última recarga de refrigerante (zero se não houve)
+	lastFilterClog  float64              // Colmatação do filtro no passo anterior
+	lastHealth      float64              // Saúde do compressor no passo anterior
+	lifecycle       *lifecycleState      // Instalação, trocas e retirada do equipamento da sala
+	missingUntil    map[int]time.Time    // Fim da lacuna corrente de cada regra de ausência em rajadas
+
+	servedAreaM2   float64   // Área da zona atribuída ao dispositivo (m²)
+	servedVolumeM3 float64   // Volume da zona atribuído ao dispositivo (m³)
+	lastTimestamp  time.Time // Instante do último passo simulado
+	lastEmitted    time.Time // Instante do último registro emitido, já com a defasagem
+	phaseOffset    time.Duration
+	jitter         time.Duration
+	dayEnergyKwh   float64 // Energia acumulada no dia corrente (kWh)
+
+	occupied       bool    // Sala ocupada no passo corrente
+	recovering     bool    // Passo corrente em retomada de setpoint em plena carga
+	saturated      bool    // Passo corrente com carga acima da capacidade do equipamento
+	lastStatus     string  // Estado operacional do passo anterior
+	wasRecovering  bool    // Passo anterior em retomada de setpoint
+	lastSensorBias float64 // Erro do termostato no passo anterior (°C)
+	lastCO2        float64 // Nível de CO2 do passo anterior (ppm)
+
+	undervoltageTripPct float64   // Tensão (% da nominal) abaixo da qual o relé desarma o compressor
+	overrideUntil       time.Time // Fim do ajuste de setpoint do ocupante (zero se não houver)
+	overrideSetpoint    float64   // Setpoint escolhido pelo ocupante (°C)
+}
+
+// Simulator avança uma frota de dispositivos no tempo, preservando o estado térmico de cada sala.
+type Simulator struct {
+	devices    []*deviceState
+	rng        *rand.Rand
+	jitterRng  *rand.Rand // Jitter dos instantes de leitura, separado para não deslocar os sorteios da simulação
+	model      ModelParams
+	control    ControlStrategy
+	faultModel FaultModel
+	precooling *PrecoolingConfig
+	g36        *G36Config
+	overrides  *OverrideConfig
+
+	sensors        []*sensorState
+	sensorReadings []WirelessSensorReading // Leituras dos sensores sem fio no último passo
+	ahus           []*airHandler
+	vrfUnits       []*vrfOutdoorUnit
+
+	fddBaseline bool
+	station     string  // Código da estação meteorológica gravado nas leituras
+	pressure    float64 // Pressão atmosférica do site (kPa)
+	airDensity  float64 // Densidade do ar no site relativa à do nível do mar
+
+	outages     *OutageConfig
+	outageUntil time.Time // Fim da queda de energia corrente ou da última
+	dark        bool      // Site sem energia no passo anterior
+
+	brownouts     *BrownoutConfig
+	voltagePct    float64   // Tensão disponível no site no passo corrente (% da nominal)
+	sagUntil      time.Time // Fim do afundamento aleatório corrente ou do último
+	sagVoltagePct float64   // Tensão do afundamento aleatório corrente (%)
+
+	consistency     *ConsistencyConfig
+	inconsistencies []Violation // Registros incoerentes encontrados desde o início
+
+	assetModels map[string]AssetModelSpec // Curvas de eficiência por nome de modelo
+	hydronic    *HydronicConfig
+	erv         *ERVConfig
+	defrost     *DefrostConfig
+	filter      *FilterConfig
+	dustUntil   time.Time // Fim do evento de poeira corrente ou do último
+	maintenance *MaintenanceConfig
+	workOrders  []WorkOrder // Ordens de serviço executadas desde o início
+	economizer  *EconomizerConfig
+	noise       *correlatedNoise
+	missingness *MissingnessConfig
+
+	boundary       *BoundaryConfig
+	boundaryFaults map[string]*boundaryFault // Falha mantida pelos casos de borda em cada dispositivo
+
+	lifecycle        *LifecycleConfig
+	lifecycleRecords []LifecycleRecord // Eventos do ciclo de vida ocorridos desde o início
+
+	energyBalance  *EnergyBalanceConfig
+	energyBalances map[zoneDay]*ZoneEnergyBalance // Balanço acumulado por zona e dia
+}
+
+// NewSimulator cria o simulador com a frota e as estratégias configuradas.
+func NewSimulator(cfg SimulatorConfig) (*Simulator, error) {
+	devices := cfg.Devices
+	if len(devices) == 0 {
+		devices = DefaultDevices()
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	model := DefaultModelParams()
+	if cfg.Model != nil {
+		model = *cfg.Model
+		if err := model.validate(); err != nil {
+			return nil, fmt.Errorf("parâmetros do modelo inválidos: %w", err)
+		}
+	}
+
+	s := &Simulator{
+		rng:         rand.New(rand.NewSource(seed)),
+		jitterRng:   rand.New(rand.NewSource(seed ^ jitterSeedSalt)),
+		model:       model,
+		control:     cfg.Control,
+		faultModel:  cfg.Faults,
+		fddBaseline: cfg.FddBaseline,
+		station:     cfg.Station,
+		voltagePct:  100.0,
+	}
+	if s.control == nil {
+		s.control = ThermostatStrategy{}
+	}
+	if s.faultModel == nil {
+		s.faultModel = SeasonalFaultModel{AlarmRate: model.FaultRate}
+	}
+	for _, d := range devices {
+		if d.AssetModel == "" {
+			d.AssetModel = defaultAssetModel
+		}
+		if d.Zone == "" {
+			d.Zone = defaultZone
+		}
+		if d.SizingRatio > 0 {
+			d.CapacityKw = d.SizingRatio * designLoad()
+		}
+		if d.CapacityKw <= 0 {
+			d.CapacityKw = defaultCapacityKw
+		}
+		s.devices = append(s.devices, &deviceState{Device: d})
+	}
+	s.assignZones(cfg.Zones)
+	if err := s.setAltitude(cfg.Altitude); err != nil {
+		return nil, err
+	}
+	if err := s.assignPhaseOffsets(cfg.Timestamps); err != nil {
+		return nil, err
+	}
+	if err := s.attachSensors(cfg.Sensors); err != nil {
+		return nil, err
+	}
+	if err := s.attachVRF(cfg.VRF); err != nil {
+		return nil, err
+	}
+	if err := s.attachLifecycle(cfg.Lifecycle); err != nil {
+		return nil, err
+	}
+	if cfg.Outages != nil {
+		outages := cfg.Outages.withDefaults()
+		s.outages = &outages
+	}
+	if cfg.Brownouts != nil {
+		brownouts := cfg.Brownouts.withDefaults()
+		s.brownouts = &brownouts
+		for _, device := range s.devices {
+			device.undervoltageTripPct = brownouts.TripVoltagePct + (s.rng.Float64()*2.0-1.0)*undervoltageTripSpread
+		}
+	}
+	if cfg.Consistency != nil {
+		if mode := cfg.Consistency.Mode; mode != "" && mode != "report" && mode != "fix" {
+			return nil, fmt.Errorf("modo de verificação de coerência '%s' desconhecido (use report ou fix)", mode)
+		}
+		consistency := *cfg.Consistency
+		s.consistency = &consistency
+	}
+	s.assetModels = make(map[string]AssetModelSpec, len(cfg.AssetModels))
+	for _, spec := range cfg.AssetModels {
+		if err := spec.validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := s.assetModels[spec.Name]; ok {
+			return nil, fmt.Errorf("modelo de equipamento '%s' repetido", spec.Name)
+		}
+		s.assetModels[spec.Name] = spec.withDefaults()
+	}
+	if cfg.Energy != nil {
+		energy := cfg.Energy.withDefaults()
+		if energy.Tolerance < 0 {
+			return nil, fmt.Errorf("tolerância do balanço de energia não pode ser negativa, recebido %.2f", energy.Tolerance)
+		}
+		s.energyBalance = &energy
+		s.energyBalances = make(map[zoneDay]*ZoneEnergyBalance)
+	}
+	if cfg.Hydronic != nil {
+		hydronic := cfg.Hydronic.withDefaults()
+		if hydronic.ChilledWaterDeltaT < 0 || hydronic.HotWaterDeltaT < 0 {
+			return nil, fmt.Errorf("ΔT de projeto das serpentinas não pode ser negativo")
+		}
+		if hydronic.MinPumpSpeedPct < 0 || hydronic.MinPumpSpeedPct > 100 {
+			return nil, fmt.Errorf("velocidade mínima da bomba deve estar entre 0 e 100%%, recebido %.1f", hydronic.MinPumpSpeedPct)
+		}
+		s.hydronic = &hydronic
+	}
+	if cfg.ERV != nil {
+		erv := cfg.ERV.withDefaults()
+		if erv.Effectiveness < 0 || erv.Effectiveness > 1 {
+			return nil, fmt.Errorf("efetividade do recuperador deve estar entre 0 e 1, recebido %.2f", erv.Effectiveness)
+		}
+		if erv.AirflowLps < 0 || erv.FanKw < 0 {
+			return nil, fmt.Errorf("vazão e potência do recuperador não podem ser negativas")
+		}
+		if erv.Schedule != "occupied" && erv.Schedule != "always" {
+			return nil, fmt.Errorf("programação do recuperador '%s' desconhecida (use occupied ou always)", erv.Schedule)
+		}
+		s.erv = &erv
+	}
+	if cfg.Defrost != nil {
+		defrost := cfg.Defrost.withDefaults()
+		if defrost.IntervalMinutes < 0 || defrost.DurationMinutes < 0 || defrost.AuxHeatKw < 0 {
+			return nil, fmt.Errorf("intervalo, duração e resistência auxiliar do degelo não podem ser negativos")
+		}
+		s.defrost = &defrost
+	}
+	if cfg.Filter != nil {
+		filter := cfg.Filter.withDefaults()
+		if filter.FinalPressurePa <= filter.CleanPressurePa {
+			return nil, fmt.Errorf("perda de carga final do filtro (%.0f Pa) deve ser maior que a do filtro limpo (%.0f Pa)", filter.FinalPressurePa, filter.CleanPressurePa)
+		}
+		if filter.LifeHours < 0 || filter.DustEventsPerYear < 0 || filter.DustEventHours < 0 || filter.DustLoadMultiplier < 0 {
+			return nil, fmt.Errorf("vida, eventos de poeira e multiplicador de carga do filtro não podem ser negativos")
+		}
+		s.filter = &filter
+		for _, device := range s.devices {
+			// Filtros trocados em datas diferentes: cada unidade começa em um ponto da vida
+			device.filterLoad = s.rng.Float64() * 0.6
+		}
+	}
+	if cfg.Maintenance != nil {
+		maintenance := cfg.Maintenance.withDefaults()
+		if err := maintenance.validate(); err != nil {
+			return nil, err
+		}
+		s.maintenance = &maintenance
+	}
+	if cfg.Economizer != nil {
+		economizer := cfg.Economizer.withDefaults()
+		if err := economizer.validate(); err != nil {
+			return nil, err
+		}
+		s.economizer = &economizer
+	}
+	if cfg.Noise != nil {
+		noise, err := newCorrelatedNoise(cfg.Noise.withDefaults())
+		if err != nil {
+			return nil, err
+		}
+		s.noise = noise
+	}
+	if cfg.Missingness != nil {
+		missingness := cfg.Missingness.withDefaults()
+		if err := missingness.validate(s.devices); err != nil {
+			return nil, err
+		}
+		s.missingness = &missingness
+	}
+	if cfg.Boundary != nil {
+		boundary := cfg.Boundary.withDefaults()
+		if err := boundary.validate(); err != nil {
+			return nil, err
+		}
+		s.boundary = &boundary
+		s.boundaryFaults = make(map[string]*boundaryFault)
+	}
+	if cfg.Overrides != nil {
+		overrides := cfg.Overrides.withDefaults()
+		s.overrides = &overrides
+	}
+	if cfg.Precooling != nil {
+		precooling := cfg.Precooling.withDefaults()
+		s.precooling = &precooling
+	}
+	if cfg.G36 != nil {
+		g36 := cfg.G36.withDefaults()
+		s.g36 = &g36
+		byZone := make(map[string]*airHandler)
+		for _, device := range s.devices {
+			ahu, ok := byZone[device.Zone]
+			if !ok {
+				ahu = newAirHandler("AHU-"+device.Zone, s.g36)
+				byZone[device.Zone] = ahu
+				s.ahus = append(s.ahus, ahu)
+			}
+			device.airHandler = ahu
+		}
+	}
+	return s, nil
+}
+
+// Step simula um passo de tempo para todos os dispositivos da frota.
+func (s *Simulator) Step(climateData climate.InmetClimateData) []HvacSensorData {
+	return s.StepInto(make([]HvacSensorData, 0, len(s.devices)), climateData)
+}
+
+// StepInto é o Step que acrescenta os registros do passo a dst e retorna a fatia resultante, como
+// append. Com um buffer reaproveitado (ver RecordPool), o passo não aloca a fatia de registros.
+func (s *Simulator) StepInto(dst []HvacSensorData, climateData climate.InmetClimateData) []HvacSensorData {
+	for _, ahu := range s.ahus {
+		ahu.trimAndRespond(s.g36)
+	}
+	for _, unit := range s.vrfUnits {
+		unit.allocate(climateData.TemperatureAir)
+	}
+
+	records := dst
+	if s.outages != nil {
+		hours := 1.0
+		if len(s.devices) > 0 {
+			hours = periodHours(s.devices[0], climateData.Timestamp)
+		}
+		if s.updateOutage(climateData.Timestamp, hours) {
+			// Site sem energia: nenhum dispositivo reporta, mas as salas continuam esquentando/esfriando
+			for _, device := range s.devices {
+				device.drift(climateData)
+			}
+			// O gateway também fica sem energia: as leituras dos sensores sem fio se perdem
+			s.sensorReadings = s.sensorReadings[:0]
+			s.dark = true
+			return records
+		}
+		if s.dark {
+			s.dark = false
+			stagger := time.Duration(s.outages.RestartStaggerSeconds * float64(time.Second))
+			for i, device := range s.devices {
+				bootTime := s.outageUntil.Add(stagger * time.Duration(i+1))
+				if !bootTime.Before(climateData.Timestamp) || device.offline(bootTime) {
+					continue
+				}
+				if record := s.bootRecord(device, bootTime, climateData); device.emit(&record) {
+					records = append(records, record)
+				}
+			}
+		}
+	}
+
+	if s.brownouts != nil {
+		hours := 1.0
+		if len(s.devices) > 0 {
+			hours = periodHours(s.devices[0], climateData.Timestamp)
+		}
+		s.updateBrownout(climateData.Timestamp, hours)
+	}
+	if s.filter != nil {
+		hours := 1.0
+		if len(s.devices) > 0 {
+			hours = periodHours(s.devices[0], climateData.Timestamp)
+		}
+		s.updateDust(climateData.Timestamp, hours)
+	}
+
+	for _, device := range s.devices {
+		if device.hasState && !climateData.Timestamp.After(device.lastTimestamp) {
+			continue // Registro climático repetido ou fora de ordem: o dispositivo não volta no tempo
+		}
+		if s.lifecycle != nil && !s.advanceLifecycle(device, climateData) {
+			continue // Sala sem equipamento: sem telemetria
+		}
+		hours := periodHours(device, climateData.Timestamp)
+		record := s.step(device, climateData)
+		if s.noise != nil {
+			s.noise.apply(&record, s.rng, s.model.NoiseScale)
+		}
+		if s.missingness != nil {
+			s.markMissing(device, &record, hours)
+		}
+		if s.lifecycle != nil {
+			s.countFailure(device, record, hours)
+		}
+		record.Timestamp = s.reportTime(device, record.Timestamp)
+		if device.emit(&record) {
+			records = append(records, record)
+		}
+	}
+	s.stepSensors(climateData)
+	if s.consistency != nil {
+		s.assertConsistency(records[len(dst):])
+	}
+	if s.boundary != nil {
+		s.applyBoundaries(records[len(dst):])
+	}
+	return records
+}
@@ -0,0 +1,66 @@
+package hvac
+
+import "math"
+
+const maxCompressorStages = 8
+
+// stages retorna o número de compressores ou estágios do modelo do equipamento (1 sem modelo).
+func (s *Simulator) stages(device *deviceState) int {
+	return s.modelStages(device.AssetModel)
+}
+
+// modelStages retorna o número de estágios do modelo de equipamento (1 se não informado).
+func (s *Simulator) modelStages(assetModel string) int {
+	if spec, ok := s.assetModels[assetModel]; ok && spec.Stages > 1 {
+		return spec.Stages
+	}
+	return 1
+}
+
+// stagesFor é o número de estágios ligados para atender a fração de carga: os estágios só entregam
+// capacidade em degraus, e o último cicla para completar a carga.
+func stagesFor(plr float64, stages int) int {
+	if plr <= 0 {
+		return 0
+	}
+	return min(stages, max(1, int(math.Ceil(plr*float64(stages)-1e-9))))
+}
+
+// stageRuntimes distribui a fração de carga entre os compressores a partir do líder: os estágios
+// abaixo do último ficam ligados o período todo e o último cicla com o restante. Retorna a fração
+// ligada de cada compressor, na ordem física.
+func stageRuntimes(plr float64, stages, lead int) []float64 {
+	runtimes := make([]float64, stages)
+	active := stagesFor(plr, stages)
+	for position := 0; position < active; position++ {
+		runtimes[(lead+position)%stages] = math.Min(1.0, plr*float64(stages)-float64(position))
+	}
+	return runtimes
+}
+
+// cyclingFraction é a fração ligada do estágio que cicla, o único que parte e para no período.
+func cyclingFraction(plr float64, stages int) float64 {
+	active := stagesFor(plr, stages)
+	if active == 0 {
+		return 0
+	}
+	return math.Min(1.0, plr*float64(stages)-float64(active-1))
+}
+
+// applyStages preenche os estágios do passo no registro, trocando o compressor líder a cada
+// LeadLagRotationHours horas de operação dele, como o rodízio dos controladores reais. Sem
+// rodízio, o líder acumula mais horas que os demais (desbalanceamento de estágios).
+func (s *Simulator) applyStages(device *deviceState, plr, hours float64, data *HvacSensorData) {
+	spec := s.assetModels[device.AssetModel]
+	runtimes := stageRuntimes(plr, spec.Stages, device.leadCompressor)
+	data.StagesActive = stagesFor(plr, spec.Stages)
+	data.LeadCompressor = device.leadCompressor + 1
+	data.StageRuntimeFractions = runtimes
+	if spec.LeadLagRotationHours > 0 {
+		device.leadRunHours += runtimes[device.leadCompressor] * hours
+		if device.leadRunHours >= spec.LeadLagRotationHours {
+			device.leadRunHours = 0
+			device.leadCompressor = (device.leadCompressor + 1) % spec.Stages
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package hvac
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// columnKind é o tipo lógico de uma coluna nos formatos colunares.
+type columnKind int
+
+const (
+	kindFloat          columnKind = iota // float64
+	kindNullableFloat                    // *float64
+	kindInt                              // int64
+	kindNullableInt                      // *int64
+	kindBool                             // bool
+	kindString                           // string
+	kindNullableString                   // *string
+	kindTime                             // time.Time (UTC, milissegundos)
+	kindStringList                       // []string
+)
+
+// column descreve uma coluna dos formatos colunares: o registro é achatado, e os objetos
+// aninhados (g36, expected, intensity) viram colunas com prefixo e nulas quando ausentes.
+type column struct {
+	name  string
+	kind  columnKind
+	value func(d *HvacSensorData) any
+}
+
+func optionalFloat(present bool, v float64) *float64 {
+	if !present {
+		return nil
+	}
+	return &v
+}
+
+func optionalInt(present bool, v int) *int64 {
+	if !present {
+		return nil
+	}
+	i := int64(v)
+	return &i
+}
+
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+var baseColumns = []column{
+	{"timestamp", kindTime, func(d *HvacSensorData) any { return d.Timestamp }},
+	{"deviceId", kindString, func(d *HvacSensorData) any { return d.DeviceId }},
+	{"assetModel", kindString, func(d *HvacSensorData) any { return d.AssetModel }},
+	{"locationZone", kindString, func(d *HvacSensorData) any { return d.LocationZone }},
+	{"stationId", kindNullableString, func(d *HvacSensorData) any { return optionalString(d.StationId) }},
+	{"runId", kindNullableString, func(d *HvacSensorData) any { return optionalString(d.RunId) }},
+	{"internalTemperature", kindFloat, func(d *HvacSensorData) any { return d.InternalTemperature }},
+	{"setPointTemperature", kindFloat, func(d *HvacSensorData) any { return d.SetPointTemperature }},
+	{"systemStatus", kindString, func(d *HvacSensorData) any { return d.SystemStatus }},
+	{"occupancyStatus", kindBool, func(d *HvacSensorData) any { return d.OccupancyStatus }},
+	{"powerConsumptionKwH", kindFloat, func(d *HvacSensorData) any { return d.PowerConsumptionKwH }},
+	{"outdoorTemperature", kindFloat, func(d *HvacSensorData) any { return d.OutdoorTemperature }},
+	{"outdoorHumidity", kindFloat, func(d *HvacSensorData) any { return d.OutdoorHumidity }},
+	{"outdoorDewPoint", kindFloat, func(d *HvacSensorData) any { return d.OutdoorDewPoint }},
+	{"outdoorWetBulb", kindFloat, func(d *HvacSensorData) any { return d.OutdoorWetBulb }},
+	{"outdoorEnthalpy", kindFloat, func(d *HvacSensorData) any { return d.OutdoorEnthalpy }},
+	{"supplyAirTemperature", kindFloat, func(d *HvacSensorData) any { return d.SupplyAirTemperature }},
+	{"returnAirTemperature", kindFloat, func(d *HvacSensorData) any { return d.ReturnAirTemperature }},
+	{"ductStaticPressurePa", kindFloat, func(d *HvacSensorData) any { return d.DuctStaticPressurePa }},
+	{"co2LevelPpm", kindFloat, func(d *HvacSensorData) any { return d.CO2LevelPpm }},
+	{"refrigerantPressurePsi", kindFloat, func(d *HvacSensorData) any { return d.RefrigerantPressurePsi }},
+	{"faultCode", kindString, func(d *HvacSensorData) any { return d.FaultCode }},
+	{"extremeEvent", kindNullableString, func(d *HvacSensorData) any { return optionalString(d.ExtremeEvent) }},
+	{"recoveryActive", kindBool, func(d *HvacSensorData) any { return d.RecoveryActive }},
+	{"capacitySaturated", kindBool, func(d *HvacSensorData) any { return d.CapacitySaturated }},
+	{"compressorRuntimeFraction", kindFloat, func(d *HvacSensorData) any { return d.CompressorRuntimeFraction }},
+	{"compressorCycles", kindInt, func(d *HvacSensorData) any { return int64(d.CompressorCycles) }},
+	{"trueZoneTemperature", kindNullableFloat, func(d *HvacSensorData) any {
+		if d.TrueZoneTemperature == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, *d.TrueZoneTemperature)
+	}},
+	{"activeFaults", kindStringList, func(d *HvacSensorData) any { return d.ActiveFaults }},
+	{"inrushPowerKw", kindNullableFloat, func(d *HvacSensorData) any { return optionalFloat(d.InrushPowerKw != 0, d.InrushPowerKw) }},
+	{"supplyVoltageV", kindNullableFloat, func(d *HvacSensorData) any { return optionalFloat(d.SupplyVoltageV != 0, d.SupplyVoltageV) }},
+	{"overrideActive", kindBool, func(d *HvacSensorData) any { return d.OverrideActive }},
+}
+
+var g36Columns = []column{
+	{"g36_ahuId", kindNullableString, func(d *HvacSensorData) any {
+		if d.G36 == nil {
+			return (*string)(nil)
+		}
+		return optionalString(d.G36.AhuId)
+	}},
+	g36Column("supplyAirTempSetpoint", func(g *G36Points) float64 { return g.SupplyAirTempSetpoint }),
+	g36Column("ductStaticPressureSetpointPa", func(g *G36Points) float64 { return g.DuctStaticPressureSetpointPa }),
+	g36Column("damperPositionPct", func(g *G36Points) float64 { return g.DamperPositionPct }),
+	g36IntColumn("zoneCoolingRequests", func(g *G36Points) int { return g.ZoneCoolingRequests }),
+	g36IntColumn("zonePressureRequests", func(g *G36Points) int { return g.ZonePressureRequests }),
+	g36IntColumn("ahuCoolingRequests", func(g *G36Points) int { return g.AhuCoolingRequests }),
+	g36IntColumn("ahuPressureRequests", func(g *G36Points) int { return g.AhuPressureRequests }),
+}
+
+func g36Column(field string, get func(*G36Points) float64) column {
+	return column{"g36_" + field, kindNullableFloat, func(d *HvacSensorData) any {
+		if d.G36 == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, get(d.G36))
+	}}
+}
+
+func g36IntColumn(field string, get func(*G36Points) int) column {
+	return column{"g36_" + field, kindNullableInt, func(d *HvacSensorData) any {
+		if d.G36 == nil {
+			return (*int64)(nil)
+		}
+		return optionalInt(true, get(d.G36))
+	}}
+}
+
+var expectedColumns = []column{
+	expectedColumn("supplyAirTemperature", func(e *ExpectedValues) ExpectedRange { return e.SupplyAirTemperature }),
+	expectedColumn("powerConsumptionKwH", func(e *ExpectedValues) ExpectedRange { return e.PowerConsumptionKwH }),
+	expectedColumn("refrigerantPressurePsi", func(e *ExpectedValues) ExpectedRange { return e.RefrigerantPressurePsi }),
+	expectedColumn("ductStaticPressurePa", func(e *ExpectedValues) ExpectedRange { return e.DuctStaticPressurePa }),
+}
+
+// expectedColumn publica apenas o valor esperado; a faixa normal fica no JSON.
+func expectedColumn(field string, get func(*ExpectedValues) ExpectedRange) column {
+	return column{"expected_" + field, kindNullableFloat, func(d *HvacSensorData) any {
+		if d.Expected == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, get(d.Expected).Expected)
+	}}
+}
+
+var intensityColumns = []column{
+	intensityColumn("powerDensityWm2", func(m *IntensityMetrics) float64 { return m.PowerDensityWm2 }),
+	intensityColumn("powerDensityWm3", func(m *IntensityMetrics) float64 { return m.PowerDensityWm3 }),
+	intensityColumn("energyIntensityKwhM2Day", func(m *IntensityMetrics) float64 { return m.EnergyIntensityKwhM2Day }),
+}
+
+func intensityColumn(field string, get func(*IntensityMetrics) float64) column {
+	return column{"intensity_" + field, kindNullableFloat, func(d *HvacSensorData) any {
+		if d.Intensity == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, get(d.Intensity))
+	}}
+}
+
+var gasColumns = []column{
+	{"gasConsumptionM3", kindNullableFloat, func(d *HvacSensorData) any {
+		return optionalFloat(d.GasConsumptionM3 > 0, d.GasConsumptionM3)
+	}},
+	{"gasConsumptionTherms", kindNullableFloat, func(d *HvacSensorData) any {
+		return optionalFloat(d.GasConsumptionTherms > 0, d.GasConsumptionTherms)
+	}},
+}
+
+var hydronicColumns = []column{
+	{"hydronic_coilId", kindNullableString, func(d *HvacSensorData) any {
+		if d.Hydronic == nil {
+			return (*string)(nil)
+		}
+		return optionalString(d.Hydronic.CoilId)
+	}},
+	coilColumn("coolingCoil", "valvePositionPct", func(h *HydronicPoints) float64 { return h.CoolingCoil.ValvePositionPct }),
+	coilColumn("coolingCoil", "flowLps", func(h *HydronicPoints) float64 { return h.CoolingCoil.FlowLps }),
+	coilColumn("coolingCoil", "pumpSpeedPct", func(h *HydronicPoints) float64 { return h.CoolingCoil.PumpSpeedPct }),
+	coilColumn("coolingCoil", "supplyWaterTemp", func(h *HydronicPoints) float64 { return h.CoolingCoil.SupplyWaterTemp }),
+	coilColumn("coolingCoil", "returnWaterTemp", func(h *HydronicPoints) float64 { return h.CoolingCoil.ReturnWaterTemp }),
+	coilColumn("coolingCoil", "deltaT", func(h *HydronicPoints) float64 { return h.CoolingCoil.DeltaT }),
+	coilColumn("heatingCoil", "valvePositionPct", func(h *HydronicPoints) float64 { return h.HeatingCoil.ValvePositionPct }),
+	coilColumn("heatingCoil", "flowLps", func(h *HydronicPoints) float64 { return h.HeatingCoil.FlowLps }),
+	coilColumn("heatingCoil", "pumpSpeedPct", func(h *HydronicPoints) float64 { return h.HeatingCoil.PumpSpeedPct }),
+	coilColumn("heatingCoil", "supplyWaterTemp", func(h *HydronicPoints) float64 { return h.HeatingCoil.SupplyWaterTemp }),
+	coilColumn("heatingCoil", "returnWaterTemp", func(h *HydronicPoints) float64 { return h.HeatingCoil.ReturnWaterTemp }),
+	coilColumn("heatingCoil", "deltaT", func(h *HydronicPoints) float64 { return h.HeatingCoil.DeltaT }),
+}
+
+func coilColumn(coil, field string, get func(*HydronicPoints) float64) column {
+	return column{"hydronic_" + coil + "_" + field, kindNullableFloat, func(d *HvacSensorData) any {
+		if d.Hydronic == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, get(d.Hydronic))
+	}}
+}
+
+var vrfColumns = []column{
+	{"vrf_outdoorUnitId", kindNullableString, func(d *HvacSensorData) any {
+		if d.Vrf == nil {
+			return (*string)(nil)
+		}
+		return optionalString(d.Vrf.OutdoorUnitId)
+	}},
+	{"vrf_outdoorUnitMode", kindNullableString, func(d *HvacSensorData) any {
+		if d.Vrf == nil {
+			return (*string)(nil)
+		}
+		return optionalString(d.Vrf.OutdoorUnitMode)
+	}},
+	{"vrf_capacityFactor", kindNullableFloat, func(d *HvacSensorData) any {
+		if d.Vrf == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, d.Vrf.CapacityFactor)
+	}},
+	{"vrf_modeConflict", kindBool, func(d *HvacSensorData) any { return d.Vrf != nil && d.Vrf.ModeConflict }},
+}
+
+var stageColumns = []column{
+	{"stagesActive", kindNullableInt, func(d *HvacSensorData) any { return optionalInt(d.LeadCompressor > 0, d.StagesActive) }},
+	{"leadCompressor", kindNullableInt, func(d *HvacSensorData) any { return optionalInt(d.LeadCompressor > 0, d.LeadCompressor) }},
+}
+
+func valueAt(values []float64, i int) float64 {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
+var defrostColumns = []column{
+	{"defrostCycles", kindInt, func(d *HvacSensorData) any { return int64(d.DefrostCycles) }},
+	{"auxHeatKwh", kindFloat, func(d *HvacSensorData) any { return d.AuxHeatKwh }},
+}
+
+var filterColumns = []column{
+	{"filterDifferentialPressurePa", kindNullableFloat, func(d *HvacSensorData) any {
+		if d.FilterDifferentialPressurePa == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, *d.FilterDifferentialPressurePa)
+	}},
+}
+
+var economizerColumns = []column{
+	{"economizer_highLimit", kindNullableString, func(d *HvacSensorData) any {
+		if d.Economizer == nil {
+			return (*string)(nil)
+		}
+		return optionalString(d.Economizer.HighLimit)
+	}},
+	{"economizer_lockout", kindBool, func(d *HvacSensorData) any { return d.Economizer != nil && d.Economizer.Lockout }},
+	{"economizer_lockoutReason", kindNullableString, func(d *HvacSensorData) any {
+		if d.Economizer == nil {
+			return (*string)(nil)
+		}
+		return optionalString(d.Economizer.LockoutReason)
+	}},
+	{"economizer_active", kindBool, func(d *HvacSensorData) any { return d.Economizer != nil && d.Economizer.Active }},
+	economizerColumn("outdoorAirDamperPct", func(e *EconomizerPoints) float64 { return e.OutdoorAirDamperPct }),
+	economizerColumn("outdoorAirEnthalpy", func(e *EconomizerPoints) float64 { return e.OutdoorAirEnthalpy }),
+	economizerColumn("returnAirEnthalpy", func(e *EconomizerPoints) float64 { return e.ReturnAirEnthalpy }),
+	economizerColumn("freeCoolingFraction", func(e *EconomizerPoints) float64 { return e.FreeCoolingFraction }),
+}
+
+func economizerColumn(field string, get func(*EconomizerPoints) float64) column {
+	return column{"economizer_" + field, kindNullableFloat, func(d *HvacSensorData) any {
+		if d.Economizer == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, get(d.Economizer))
+	}}
+}
+
+var ervColumns = []column{
+	{"erv_mode", kindNullableString, func(d *HvacSensorData) any {
+		if d.Erv == nil {
+			return (*string)(nil)
+		}
+		return optionalString(d.Erv.Mode)
+	}},
+	ervColumn("outdoorAirTemp", func(e *ERVPoints) float64 { return e.OutdoorAirTemp }),
+	ervColumn("supplyAirTemp", func(e *ERVPoints) float64 { return e.SupplyAirTemp }),
+	ervColumn("returnAirTemp", func(e *ERVPoints) float64 { return e.ReturnAirTemp }),
+	ervColumn("exhaustAirTemp", func(e *ERVPoints) float64 { return e.ExhaustAirTemp }),
+	ervColumn("effectiveness", func(e *ERVPoints) float64 { return e.Effectiveness }),
+	ervColumn("recoveredKw", func(e *ERVPoints) float64 { return e.RecoveredKw }),
+	ervColumn("fanEnergyKwh", func(e *ERVPoints) float64 { return e.FanEnergyKwh }),
+}
+
+func ervColumn(field string, get func(*ERVPoints) float64) column {
+	return column{"erv_" + field, kindNullableFloat, func(d *HvacSensorData) any {
+		if d.Erv == nil {
+			return (*float64)(nil)
+		}
+		return optionalFloat(true, get(d.Erv))
+	}}
+}
+
+// tabularColumns monta o esquema colunar dos registros. As colunas dos recursos opcionais só
+// entram quando algum registro as preenche; cada horizonte de previsão vira uma coluna.
+func tabularColumns(data []HvacSensorData) []column {
+	columns := append([]column(nil), baseColumns...)
+	var hasG36, hasExpected, hasIntensity, hasGas, hasHydronic, hasVrf, hasErv, hasDefrost, hasFilter, hasEconomizer bool
+	maxStages := 0
+	horizons := make(map[int]bool)
+	missing := make(map[string]bool)
+	for i := range data {
+		for _, path := range data[i].Missing {
+			missing[path] = true
+		}
+		hasG36 = hasG36 || data[i].G36 != nil
+		hasExpected = hasExpected || data[i].Expected != nil
+		hasIntensity = hasIntensity || data[i].Intensity != nil
+		hasHydronic = hasHydronic || data[i].Hydronic != nil
+		hasVrf = hasVrf || data[i].Vrf != nil
+		hasErv = hasErv || data[i].Erv != nil
+		hasDefrost = hasDefrost || data[i].DefrostCycles > 0
+		hasFilter = hasFilter || data[i].FilterDifferentialPressurePa != nil
+		hasEconomizer = hasEconomizer || data[i].Economizer != nil
+		maxStages = max(maxStages, len(data[i].StageRuntimeFractions))
+		hasGas = hasGas || data[i].GasConsumptionM3 > 0 || data[i].GasConsumptionTherms > 0
+		for _, f := range data[i].OutdoorTemperatureForecast {
+			horizons[f.HorizonHours] = true
+		}
+	}
+
+	sortedHorizons := make([]int, 0, len(horizons))
+	for h := range horizons {
+		sortedHorizons = append(sortedHorizons, h)
+	}
+	sort.Ints(sortedHorizons)
+	for _, h := range sortedHorizons {
+		columns = append(columns, column{fmt.Sprintf("outdoorTemperatureForecast_%dh", h), kindNullableFloat, func(d *HvacSensorData) any {
+			for _, f := range d.OutdoorTemperatureForecast {
+				if f.HorizonHours == h {
+					return optionalFloat(true, f.Temperature)
+				}
+			}
+			return (*float64)(nil)
+		}})
+	}
+	if hasG36 {
+		columns = append(columns, g36Columns...)
+	}
+	if hasExpected {
+		columns = append(columns, expectedColumns...)
+	}
+	if hasIntensity {
+		columns = append(columns, intensityColumns...)
+	}
+	if hasGas {
+		columns = append(columns, gasColumns...)
+	}
+	if hasHydronic {
+		columns = append(columns, hydronicColumns...)
+	}
+	if hasVrf {
+		columns = append(columns, vrfColumns...)
+	}
+	if hasErv {
+		columns = append(columns, ervColumns...)
+	}
+	if hasDefrost {
+		columns = append(columns, defrostColumns...)
+	}
+	if hasFilter {
+		columns = append(columns, filterColumns...)
+	}
+	if hasEconomizer {
+		columns = append(columns, economizerColumns...)
+	}
+	if maxStages > 0 {
+		columns = append(columns, stageColumns...)
+		for i := range maxStages {
+			columns = append(columns, column{fmt.Sprintf("stageRuntimeFraction_%d", i+1), kindNullableFloat, func(d *HvacSensorData) any {
+				return optionalFloat(i < len(d.StageRuntimeFractions), valueAt(d.StageRuntimeFractions, i))
+			}})
+		}
+	}
+	if len(missing) > 0 {
+		columns = nullableMissing(columns, missing)
+	}
+	return columns
+}
+
+// timestampMillis converte o timestamp para milissegundos UTC, a precisão dos formatos colunares.
+func timestampMillis(t time.Time) int64 {
+	return t.UTC().UnixMilli()
+}
@@ -0,0 +1,94 @@
+package hvac
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Biblioteca de modelos de payload no estilo de cada fabricante, selecionados por Device.Template.
+//
+//go:embed templates/*.json.tmpl
+var vendorTemplateFS embed.FS
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"round": func(v float64, digits int) float64 {
+		p := math.Pow(10, float64(digits))
+		return math.Round(v*p) / p
+	},
+	"mul":        func(v, factor float64) float64 { return v * factor },
+	"fahrenheit": unitConversions["F"].convert,
+	"kPa":        unitConversions["kPa"].convert,
+	"bar":        unitConversions["bar"].convert,
+	"inH2O":      unitConversions["inH2O"].convert,
+	"rfc3339":    func(t time.Time) string { return t.Format(time.RFC3339) },
+	"unix":       func(t time.Time) int64 { return t.Unix() },
+	"unixMs":     func(t time.Time) int64 { return t.UnixMilli() },
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+}
+
+// VendorTemplates lista os nomes dos modelos de payload embutidos (ex: honeywell, trane, daikin).
+func VendorTemplates() []string {
+	entries, _ := vendorTemplateFS.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json.tmpl"))
+	}
+	return names
+}
+
+// parsePayloadTemplates carrega os modelos embutidos e os modelos do cenário, que podem
+// substituir um embutido com o mesmo nome.
+func parsePayloadTemplates(custom map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template)
+	for _, name := range VendorTemplates() {
+		file := path.Join("templates", name+".json.tmpl")
+		text, err := vendorTemplateFS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler o modelo de payload '%s': %w", name, err)
+		}
+		if override := custom[name]; override != "" {
+			text = []byte(override)
+		}
+		tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao interpretar o modelo de payload '%s': %w", name, err)
+		}
+		templates[name] = tmpl
+	}
+	for name, text := range custom {
+		if _, ok := templates[name]; ok {
+			continue
+		}
+		tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao interpretar o modelo de payload '%s': %w", name, err)
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+// renderTemplate executa o modelo sobre o registro e garante que o resultado é um JSON válido.
+func renderTemplate(tmpl *template.Template, record HvacSensorData) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, record); err != nil {
+		return nil, fmt.Errorf("erro ao aplicar o modelo '%s' ao registro de '%s': %w", tmpl.Name(), record.DeviceId, err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("modelo '%s' gerou JSON inválido para '%s': %w", tmpl.Name(), record.DeviceId, err)
+	}
+	return json.RawMessage(compact.Bytes()), nil
+}
@@ -0,0 +1,71 @@
+package hvac
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimestampConfig defasa e varia o instante de leitura dos dispositivos, que na prática não
+// reportam todos no início da hora nem em intervalos exatos.
+type TimestampConfig struct {
+	RandomPhaseSeconds float64 `json:"randomPhaseSeconds"` // Defasagem sorteada em [0, N) s para os dispositivos sem phaseOffsetSeconds
+	JitterSeconds      float64 `json:"jitterSeconds"`      // Variação sorteada em ±N s a cada leitura, para os dispositivos sem jitterSeconds
+}
+
+// jitterSeedSalt deriva a semente do jitter da semente do cenário: ligar ou mudar o jitter não
+// altera as leituras, só os instantes.
+const jitterSeedSalt = 0x6a6974746572
+
+// assignPhaseOffsets fixa a defasagem e o jitter de cada dispositivo: os informados no catálogo
+// ou, com TimestampConfig, a defasagem sorteada pela semente e o jitter comum.
+func (s *Simulator) assignPhaseOffsets(cfg *TimestampConfig) error {
+	if cfg != nil && cfg.RandomPhaseSeconds < 0 {
+		return fmt.Errorf("defasagem aleatória não pode ser negativa, recebido %.1f s", cfg.RandomPhaseSeconds)
+	}
+	if cfg != nil && cfg.JitterSeconds < 0 {
+		return fmt.Errorf("jitter não pode ser negativo, recebido %.1f s", cfg.JitterSeconds)
+	}
+	for _, device := range s.devices {
+		if device.PhaseOffsetSeconds < 0 {
+			return fmt.Errorf("defasagem do dispositivo '%s' não pode ser negativa, recebido %.1f s", device.ID, device.PhaseOffsetSeconds)
+		}
+		offset := device.PhaseOffsetSeconds
+		if offset == 0 && cfg != nil && cfg.RandomPhaseSeconds > 0 {
+			offset = s.rng.Float64() * cfg.RandomPhaseSeconds
+		}
+		device.phaseOffset = time.Duration(offset * float64(time.Second)).Truncate(time.Millisecond)
+
+		if device.JitterSeconds < 0 {
+			return fmt.Errorf("jitter do dispositivo '%s' não pode ser negativo, recebido %.1f s", device.ID, device.JitterSeconds)
+		}
+		jitter := device.JitterSeconds
+		if jitter == 0 && cfg != nil {
+			jitter = cfg.JitterSeconds
+		}
+		device.jitter = time.Duration(jitter * float64(time.Second))
+	}
+	return nil
+}
+
+// reportTime é o instante em que o dispositivo reporta a leitura do passo: o instante nominal com
+// a defasagem e o jitter sorteado. O jitter nunca leva a leitura para antes da anterior.
+func (s *Simulator) reportTime(device *deviceState, nominal time.Time) time.Time {
+	t := nominal.Add(device.phaseOffset)
+	if device.jitter > 0 {
+		t = t.Add(time.Duration((s.jitterRng.Float64()*2 - 1) * float64(device.jitter)).Truncate(time.Millisecond))
+		if !device.lastEmitted.IsZero() && !t.After(device.lastEmitted) {
+			t = device.lastEmitted.Add(time.Millisecond)
+		}
+	}
+	return t
+}
+
+// emit garante a série do dispositivo estritamente crescente: retorna false para um registro que
+// não avança além do último emitido.
+func (d *deviceState) emit(record *HvacSensorData) bool {
+	if !d.lastEmitted.IsZero() && !record.Timestamp.After(d.lastEmitted) {
+		return false
+	}
+	d.lastEmitted = record.Timestamp
+	return true
+}
@@ -0,0 +1,40 @@
+package hvac
+
+import (
+	"reflect"
+	"testing"
+)
+
+// O jitter tem gerador próprio: ligá-lo muda só os instantes das leituras, não os valores.
+func TestJitterKeepsReadings(t *testing.T) {
+	climateData := syntheticClimate(30)
+	run := func(jitter float64) []HvacSensorData {
+		simulator, err := NewSimulator(SimulatorConfig{Seed: 42, Timestamps: &TimestampConfig{JitterSeconds: jitter}})
+		if err != nil {
+			t.Fatalf("NewSimulator: %v", err)
+		}
+		var records []HvacSensorData
+		for _, record := range climateData {
+			records = append(records, simulator.Step(record)...)
+		}
+		return records
+	}
+
+	plain, jittered := run(0), run(30)
+	if len(plain) != len(jittered) {
+		t.Fatalf("%d registros com jitter, esperado %d", len(jittered), len(plain))
+	}
+	moved := 0
+	for i := range plain {
+		if !plain[i].Timestamp.Equal(jittered[i].Timestamp) {
+			moved++
+		}
+		plain[i].Timestamp = jittered[i].Timestamp
+		if !reflect.DeepEqual(plain[i], jittered[i]) {
+			t.Fatalf("registro %d mudou com o jitter: %+v, esperado %+v", i, jittered[i], plain[i])
+		}
+	}
+	if moved == 0 {
+		t.Error("o jitter não moveu nenhuma leitura")
+	}
+}
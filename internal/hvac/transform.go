@@ -0,0 +1,186 @@
+package hvac
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Transformer ajusta o payload de um registro antes da serialização. O payload é o objeto JSON
+// já no formato do dispositivo (dialeto ou modelo de fabricante), sem o envelope de gateway.
+type Transformer interface {
+	Transform(payload map[string]any) error
+}
+
+// TransformerFunc adapta uma função comum a Transformer, para ganchos próprios no modo biblioteca.
+type TransformerFunc func(payload map[string]any) error
+
+// Transform implementa Transformer.
+func (f TransformerFunc) Transform(payload map[string]any) error {
+	return f(payload)
+}
+
+// TransformConfig descreve um passo do pipeline de pós-processamento no arquivo de cenário.
+type TransformConfig struct {
+	Type     string            `json:"type"`     // units, rename, round ou anonymize
+	Units    map[string]string `json:"units"`    // units: campo canônico -> unidade (F, K, kPa, bar, inH2O, Wh, MJ)
+	Fields   map[string]string `json:"fields"`   // rename: caminho -> novo caminho ("-" remove o campo)
+	Paths    []string          `json:"paths"`    // round e anonymize: campos afetados (round sem paths: todos os números)
+	Decimals int               `json:"decimals"` // round: casas decimais
+	Salt     string            `json:"salt"`     // anonymize: segredo do pseudônimo, para que não seja revertido por força bruta
+}
+
+// NewTransformers valida os passos configurados e monta o pipeline na ordem informada.
+func NewTransformers(cfgs []TransformConfig) ([]Transformer, error) {
+	units := fieldUnits()
+	transformers := make([]Transformer, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		switch cfg.Type {
+		case "units":
+			for field, unit := range cfg.Units {
+				conversion, ok := unitConversions[unit]
+				if !ok {
+					return nil, fmt.Errorf("unidade '%s' não suportada no passo %d do pipeline", unit, i+1)
+				}
+				if units[field] != conversion.from {
+					return nil, fmt.Errorf("campo '%s' não pode ser convertido para '%s' no passo %d do pipeline", field, unit, i+1)
+				}
+			}
+			transformers = append(transformers, unitTransform(cfg.Units))
+		case "rename":
+			transformers = append(transformers, renameTransform(cfg.Fields))
+		case "round":
+			if cfg.Decimals < 0 {
+				return nil, fmt.Errorf("casas decimais negativas no passo %d do pipeline", i+1)
+			}
+			transformers = append(transformers, roundTransform{paths: cfg.Paths, decimals: cfg.Decimals})
+		case "anonymize":
+			if len(cfg.Paths) == 0 {
+				return nil, fmt.Errorf("passo %d do pipeline (anonymize) sem campos em paths", i+1)
+			}
+			transformers = append(transformers, anonymizeTransform{paths: cfg.Paths, salt: []byte(cfg.Salt)})
+		default:
+			return nil, fmt.Errorf("passo %d do pipeline com tipo '%s' desconhecido (use units, rename, round ou anonymize)", i+1, cfg.Type)
+		}
+	}
+	return transformers, nil
+}
+
+type unitTransform map[string]string
+
+func (t unitTransform) Transform(payload map[string]any) error {
+	for field, unit := range t {
+		if v, ok := lookupPath(payload, field).(float64); ok {
+			setPath(payload, field, unitConversions[unit].convert(v))
+		}
+	}
+	return nil
+}
+
+type renameTransform map[string]string
+
+func (t renameTransform) Transform(payload map[string]any) error {
+	renameFields(payload, t)
+	return nil
+}
+
+// renameFields move cada campo para o novo caminho. Todos os campos são retirados antes de serem
+// regravados, em ordem estável, para que trocas de nome (a -> b, b -> a) funcionem.
+func renameFields(payload map[string]any, fields map[string]string) {
+	sources := make([]string, 0, len(fields))
+	for field := range fields {
+		sources = append(sources, field)
+	}
+	sort.Strings(sources)
+	moved := make(map[string]any, len(sources))
+	for _, field := range sources {
+		if v := lookupPath(payload, field); v != nil {
+			moved[field] = v
+			deletePath(payload, field)
+		}
+	}
+	for _, field := range sources {
+		target := fields[field]
+		if v, ok := moved[field]; ok && target != "-" {
+			setPath(payload, target, v)
+		}
+	}
+}
+
+type roundTransform struct {
+	paths    []string
+	decimals int
+}
+
+func (t roundTransform) Transform(payload map[string]any) error {
+	scale := math.Pow(10, float64(t.decimals))
+	if len(t.paths) == 0 {
+		roundAll(payload, scale)
+		return nil
+	}
+	for _, path := range t.paths {
+		if v, ok := lookupPath(payload, path).(float64); ok {
+			setPath(payload, path, math.Round(v*scale)/scale)
+		}
+	}
+	return nil
+}
+
+// roundAll arredonda todos os números do payload, inclusive em objetos e listas aninhados.
+func roundAll(value any, scale float64) any {
+	switch v := value.(type) {
+	case float64:
+		return math.Round(v*scale) / scale
+	case map[string]any:
+		for key, item := range v {
+			v[key] = roundAll(item, scale)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = roundAll(item, scale)
+		}
+	}
+	return value
+}
+
+type anonymizeTransform struct {
+	paths []string
+	salt  []byte
+}
+
+// Transform troca cada identificador por um pseudônimo estável (HMAC-SHA256 com o salt), que
+// preserva junções e contagens por dispositivo sem expor o nome real.
+func (t anonymizeTransform) Transform(payload map[string]any) error {
+	for _, path := range t.paths {
+		v := lookupPath(payload, path)
+		if v == nil {
+			continue
+		}
+		mac := hmac.New(sha256.New, t.salt)
+		fmt.Fprint(mac, v)
+		setPath(payload, path, hex.EncodeToString(mac.Sum(nil))[:16])
+	}
+	return nil
+}
+
+// applyTransformers converte o payload em objeto JSON e aplica o pipeline em ordem.
+func applyTransformers(transformers []Transformer, deviceId string, payload any) (any, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar o registro de '%s': %w", deviceId, err)
+	}
+	object := make(map[string]any)
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("o pipeline de pós-processamento exige um objeto JSON no payload de '%s': %w", deviceId, err)
+	}
+	for _, transformer := range transformers {
+		if err := transformer.Transform(object); err != nil {
+			return nil, fmt.Errorf("erro no pipeline de pós-processamento do registro de '%s': %w", deviceId, err)
+		}
+	}
+	return object, nil
+}
@@ -0,0 +1,151 @@
+package hvac
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrendLogConfig ativa a exportação no formato de trend logs de BAS: um arquivo CSV por ponto,
+// com nomes de ponto no estilo do fabricante.
+type TrendLogConfig struct {
+	Style    string `json:"style"`    // Estilo do export: niagara (padrão) ou alc
+	Station  string `json:"station"`  // Nome da estação/site usado nos nomes dos pontos (padrão: A701)
+	Location string `json:"location"` // Fuso horário dos timestamps (padrão: America/Sao_Paulo)
+}
+
+// trendPoint é um ponto exportado em trend log, com o nome curto usado pelos integradores.
+type trendPoint struct {
+	name  string
+	value func(HvacSensorData) string
+}
+
+func formatTrendFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+var trendPoints = []trendPoint{
+	{"ZN-T", func(d HvacSensorData) string { return formatTrendFloat(d.InternalTemperature) }},
+	{"ZN-SP", func(d HvacSensorData) string { return formatTrendFloat(d.SetPointTemperature) }},
+	{"SA-T", func(d HvacSensorData) string { return formatTrendFloat(d.SupplyAirTemperature) }},
+	{"RA-T", func(d HvacSensorData) string { return formatTrendFloat(d.ReturnAirTemperature) }},
+	{"OA-T", func(d HvacSensorData) string { return formatTrendFloat(d.OutdoorTemperature) }},
+	{"OA-H", func(d HvacSensorData) string { return formatTrendFloat(d.OutdoorHumidity) }},
+	{"DA-SP", func(d HvacSensorData) string { return formatTrendFloat(d.DuctStaticPressurePa) }},
+	{"ZN-CO2", func(d HvacSensorData) string { return formatTrendFloat(d.CO2LevelPpm) }},
+	{"RFG-P", func(d HvacSensorData) string { return formatTrendFloat(d.RefrigerantPressurePsi) }},
+	{"KWH", func(d HvacSensorData) string { return formatTrendFloat(d.PowerConsumptionKwH) }},
+	{"MODE", func(d HvacSensorData) string { return d.SystemStatus }},
+	{"OCC", func(d HvacSensorData) string {
+		if d.OccupancyStatus {
+			return "Occupied"
+		}
+		return "Unoccupied"
+	}},
+	{"ALM", func(d HvacSensorData) string { return d.FaultCode }},
+}
+
+func (c TrendLogConfig) withDefaults() TrendLogConfig {
+	if c.Style == "" {
+		c.Style = "niagara"
+	}
+	if c.Station == "" {
+		c.Station = "A701"
+	}
+	if c.Location == "" {
+		c.Location = "America/Sao_Paulo"
+	}
+	return c
+}
+
+// BuildTrendLogs gera os trend logs de cada ponto de cada dispositivo, em ordem de dispositivo e de
+// ponto, para que a gravação e o manifesto sejam os mesmos a cada execução. No estilo niagara as colunas são Timestamp, Trend Flags, Status e Value; no estilo
+// alc, Date/Time, Value e Status.
+func BuildTrendLogs(cfg TrendLogConfig, data []HvacSensorData) ([]OutputFile, error) {
+	cfg = cfg.withDefaults()
+	location, err := time.LoadLocation(cfg.Location)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar o fuso horário '%s' dos trend logs: %w", cfg.Location, err)
+	}
+
+	var header []string
+	var timeLayout string
+	switch cfg.Style {
+	case "niagara":
+		header = []string{"Timestamp", "Trend Flags", "Status", "Value"}
+		timeLayout = "02-Jan-06 3:04:05 PM MST"
+	case "alc":
+		header = []string{"Date/Time", "Value", "Status"}
+		timeLayout = "01/02/2006 15:04:05"
+	default:
+		return nil, fmt.Errorf("estilo de trend log '%s' desconhecido", cfg.Style)
+	}
+
+	byDevice := make(map[string][]HvacSensorData)
+	for _, record := range data {
+		byDevice[record.DeviceId] = append(byDevice[record.DeviceId], record)
+	}
+	deviceIds := make([]string, 0, len(byDevice))
+	for id := range byDevice {
+		deviceIds = append(deviceIds, id)
+	}
+	sort.Strings(deviceIds)
+
+	files := make([]OutputFile, 0, len(deviceIds)*len(trendPoints))
+	for _, deviceId := range deviceIds {
+		records := byDevice[deviceId]
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+		for _, point := range trendPoints {
+			pointName := strings.Join([]string{cfg.Station, strings.ReplaceAll(deviceId, "-", "_"), point.name}, "_")
+			if cfg.Style == "alc" {
+				pointName = strings.ToLower(pointName) // Nomes de referência do WebCTRL são minúsculos
+			}
+			var buf bytes.Buffer
+			writer := csv.NewWriter(&buf)
+			if err := writer.Write(header); err != nil {
+				return nil, fmt.Errorf("erro ao escrever cabeçalho do trend log '%s': %w", pointName, err)
+			}
+			var previous time.Time
+			for i, record := range records {
+				timestamp := record.Timestamp.In(location).Format(timeLayout)
+				status := trendStatus(record, previous)
+				previous = record.Timestamp
+				var row []string
+				if cfg.Style == "niagara" {
+					flags := "{}"
+					if i == 0 {
+						flags = "{start}" // Primeiro registro do histórico
+					}
+					row = []string{timestamp, flags, status, point.value(record)}
+				} else {
+					row = []string{timestamp, point.value(record), strings.Trim(status, "{}")}
+				}
+				if err := writer.Write(row); err != nil {
+					return nil, fmt.Errorf("erro ao escrever o trend log '%s': %w", pointName, err)
+				}
+			}
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return nil, fmt.Errorf("erro ao finalizar o trend log '%s': %w", pointName, err)
+			}
+			files = append(files, OutputFile{Name: pointName + ".csv", Data: buf.Bytes()})
+		}
+	}
+	return files, nil
+}
+
+// trendStatus segue as flags de status do histórico: alarm com falha ativa, stale quando a
+// leitura chega depois de uma lacuna (queda de energia, linhas faltantes no INMET) e ok no resto.
+func trendStatus(record HvacSensorData, previous time.Time) string {
+	switch {
+	case record.FaultCode != "" && record.FaultCode != "OK":
+		return "{alarm}"
+	case !previous.IsZero() && record.Timestamp.Sub(previous) > 90*time.Minute:
+		return "{stale}"
+	}
+	return "{ok}"
+}
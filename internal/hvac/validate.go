@@ -0,0 +1,129 @@
+package hvac
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Violation é um registro que fere um invariante físico.
+type Violation struct {
+	DeviceId  string    `json:"deviceId"`
+	Timestamp time.Time `json:"timestamp"`
+	Rule      string    `json:"rule"`    // supply-return, power, co2-range ou temperature-jump
+	Message   string    `json:"message"` // Descrição com os valores encontrados
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s %s [%s] %s", v.DeviceId, v.Timestamp.Format(time.RFC3339), v.Rule, v.Message)
+}
+
+// ValidationLimits são os limites dos invariantes verificados por Validate.
+type ValidationLimits struct {
+	MinCO2Ppm        float64 // CO2 mínimo plausível (ppm, padrão: 350, abaixo do ar externo)
+	MaxCO2Ppm        float64 // CO2 máximo plausível (ppm, padrão: 5000, limite de exposição ocupacional)
+	MaxTempJumpC     float64 // Maior variação da temperatura interna entre leituras seguidas (°C, padrão: 10)
+	SupplyReturnTolC float64 // Folga entre insuflamento e retorno para ruído de medição (°C, padrão: 0)
+}
+
+// DefaultValidationLimits retorna os limites padrão da validação.
+func DefaultValidationLimits() ValidationLimits {
+	return ValidationLimits{MinCO2Ppm: 350, MaxCO2Ppm: 5000, MaxTempJumpC: 10}
+}
+
+// Validate verifica os registros contra os invariantes físicos: insuflamento mais frio que o retorno
+// ao resfriar (e mais quente ao aquecer), consumo não negativo, CO2 dentro dos limites e nenhum
+// salto de temperatura interna maior que MaxTempJumpC entre leituras seguidas do mesmo dispositivo.
+// As violações saem na ordem dos dispositivos e do tempo.
+func Validate(data []HvacSensorData, limits ValidationLimits) []Violation {
+	byDevice := make(map[string][]*HvacSensorData)
+	for i := range data {
+		byDevice[data[i].DeviceId] = append(byDevice[data[i].DeviceId], &data[i])
+	}
+	devices := make([]string, 0, len(byDevice))
+	for device := range byDevice {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+
+	var violations []Violation
+	for _, device := range devices {
+		records := byDevice[device]
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+		for i, record := range records {
+			violate := func(rule, format string, args ...any) {
+				violations = append(violations, Violation{DeviceId: device, Timestamp: record.Timestamp, Rule: rule, Message: fmt.Sprintf(format, args...)})
+			}
+
+			switch record.SystemStatus {
+			case "COOLING", "PRE_COOLING":
+				if record.SupplyAirTemperature >= record.ReturnAirTemperature+limits.SupplyReturnTolC {
+					violate("supply-return", "insuflamento %.2f °C não é mais frio que o retorno %.2f °C em %s", record.SupplyAirTemperature, record.ReturnAirTemperature, record.SystemStatus)
+				}
+			case "HEATING":
+				if record.SupplyAirTemperature <= record.ReturnAirTemperature-limits.SupplyReturnTolC {
+					violate("supply-return", "insuflamento %.2f °C não é mais quente que o retorno %.2f °C em HEATING", record.SupplyAirTemperature, record.ReturnAirTemperature)
+				}
+			}
+			if record.PowerConsumptionKwH < 0 {
+				violate("power", "consumo negativo: %.3f kWh", record.PowerConsumptionKwH)
+			}
+			if record.InrushPowerKw < 0 {
+				violate("power", "pico de partida negativo: %.3f kW", record.InrushPowerKw)
+			}
+			if record.CO2LevelPpm < limits.MinCO2Ppm || record.CO2LevelPpm > limits.MaxCO2Ppm {
+				violate("co2-range", "CO2 %.0f ppm fora de [%.0f, %.0f]", record.CO2LevelPpm, limits.MinCO2Ppm, limits.MaxCO2Ppm)
+			}
+			if i > 0 {
+				jump := record.InternalTemperature - records[i-1].InternalTemperature
+				if jump > limits.MaxTempJumpC || -jump > limits.MaxTempJumpC {
+					violate("temperature-jump", "temperatura interna variou %.2f °C desde a leitura anterior (limite: %.1f °C)", jump, limits.MaxTempJumpC)
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// ReadRecordsJSON lê registros no formato canônico, como array JSON (saída json sem dialetos,
+// modelos, envelope nem lotes) ou JSON Lines (WriteHvacDataToJSONL).
+func ReadRecordsJSON(r io.Reader) ([]HvacSensorData, error) {
+	reader := bufio.NewReader(r)
+	head, err := reader.Peek(1)
+	for err == nil && len(bytes.TrimSpace(head)) == 0 {
+		if _, err = reader.ReadByte(); err == nil {
+			head, err = reader.Peek(1)
+		}
+	}
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler os registros: %w", err)
+	}
+
+	decoder := json.NewDecoder(reader)
+	if head[0] == '[' {
+		var data []HvacSensorData
+		if err := decoder.Decode(&data); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar o array de registros: %w", err)
+		}
+		return data, nil
+	}
+	var data []HvacSensorData
+	for {
+		var record HvacSensorData
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao decodificar o registro %d: %w", len(data)+1, err)
+		}
+		data = append(data, record)
+	}
+}
@@ -0,0 +1,197 @@
+package hvac
+
+import (
+	"fmt"
+	"math"
+)
+
+// VRFConfig agrupa os dispositivos em sistemas VRF multi-split: cada unidade externa atende várias
+// unidades internas (os dispositivos), que disputam a mesma capacidade de compressor.
+type VRFConfig struct {
+	Systems []VRFSystem `json:"systems"`
+}
+
+// VRFSystem é uma unidade externa e as unidades internas que ela atende.
+type VRFSystem struct {
+	ID           string   `json:"id"`           // Identificador da unidade externa (ex: VRF-1)
+	IndoorUnits  []string `json:"indoorUnits"`  // Dispositivos atendidos (devices[].id)
+	CapacityKw   float64  `json:"capacityKw"`   // Capacidade nominal da unidade externa (kW, padrão: soma das internas / 1.3)
+	HeatRecovery bool     `json:"heatRecovery"` // Recuperação de calor: resfria e aquece ao mesmo tempo; sem ela, um único modo por vez
+}
+
+// VRFPoints são os pontos da unidade externa e da distribuição de refrigerante no passo.
+type VRFPoints struct {
+	OutdoorUnitId   string  `json:"outdoorUnitId"`          // Unidade externa que atende a unidade interna
+	OutdoorUnitMode string  `json:"outdoorUnitMode"`        // Modo da unidade externa: COOLING, HEATING ou MIXED (recuperação de calor)
+	CapacityFactor  float64 `json:"capacityFactor"`         // Fração da capacidade nominal da unidade interna disponível no passo
+	ModeConflict    bool    `json:"modeConflict,omitempty"` // Modo pedido oposto ao da unidade externa: a unidade interna só ventila
+}
+
+const (
+	vrfConnectionRatio   = 1.3   // Soma das capacidades internas sobre a da unidade externa (diversidade)
+	vrfCoolingDerateTemp = 35.0  // Temperatura externa a partir da qual a capacidade de resfriamento cai (°C)
+	vrfCoolingDeratePerK = 0.02  // Perda de capacidade de resfriamento por °C acima da referência
+	vrfHeatingDerateTemp = 7.0   // Temperatura externa abaixo da qual a capacidade de aquecimento cai (°C)
+	vrfHeatingDeratePerK = 0.025 // Perda de capacidade de aquecimento por °C abaixo da referência
+	vrfStarvationSpread  = 0.4   // Diferença de capacidade entre a primeira e a última unidade da linha sob escassez
+	vrfMinCapacityFactor = 0.1
+)
+
+// vrfOutdoorUnit guarda o modo da unidade externa e a demanda das unidades internas, acumulada
+// no passo e repartida no passo seguinte, como as requisições das AHUs do G36.
+type vrfOutdoorUnit struct {
+	id           string
+	capacityKw   float64
+	heatRecovery bool
+	indoor       []*deviceState
+	nominalKw    []float64 // Capacidade nominal de cada unidade interna, na ordem de indoor
+	mode         string
+
+	coolingDemandKw, heatingDemandKw         float64 // Demanda recebida no passo corrente
+	lastCoolingDemandKw, lastHeatingDemandKw float64
+}
+
+// attachVRF cria as unidades externas e liga a elas as unidades internas configuradas.
+func (s *Simulator) attachVRF(cfg *VRFConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	byID := make(map[string]*deviceState, len(s.devices))
+	for _, device := range s.devices {
+		byID[device.ID] = device
+	}
+	seen := make(map[string]bool)
+	for _, system := range cfg.Systems {
+		if system.ID == "" {
+			return fmt.Errorf("sistema VRF sem id")
+		}
+		if seen[system.ID] {
+			return fmt.Errorf("sistema VRF '%s' repetido", system.ID)
+		}
+		seen[system.ID] = true
+		if len(system.IndoorUnits) == 0 {
+			return fmt.Errorf("sistema VRF '%s' sem indoorUnits", system.ID)
+		}
+		if system.CapacityKw < 0 {
+			return fmt.Errorf("sistema VRF '%s' com capacityKw negativa", system.ID)
+		}
+
+		unit := &vrfOutdoorUnit{id: system.ID, capacityKw: system.CapacityKw, heatRecovery: system.HeatRecovery, mode: "COOLING"}
+		indoorKw := 0.0
+		for _, id := range system.IndoorUnits {
+			device, ok := byID[id]
+			if !ok {
+				return fmt.Errorf("sistema VRF '%s' com unidade interna '%s' fora da frota", system.ID, id)
+			}
+			if device.vrf != nil {
+				return fmt.Errorf("unidade interna '%s' em mais de um sistema VRF", id)
+			}
+			device.vrf = unit
+			unit.indoor = append(unit.indoor, device)
+			unit.nominalKw = append(unit.nominalKw, device.CapacityKw)
+			indoorKw += device.CapacityKw
+		}
+		if unit.capacityKw == 0 {
+			unit.capacityKw = indoorKw / vrfConnectionRatio
+		}
+		s.vrfUnits = append(s.vrfUnits, unit)
+	}
+	return nil
+}
+
+// allocate define o modo da unidade externa e reparte a capacidade disponível, reduzida pela
+// temperatura externa, pela demanda do passo anterior. Sob escassez o refrigerante chega com
+// menos vazão às unidades do fim da linha, que ficam com menos capacidade que as primeiras.
+func (u *vrfOutdoorUnit) allocate(outdoorTemp float64) {
+	switch {
+	case u.heatRecovery && u.lastCoolingDemandKw > 0 && u.lastHeatingDemandKw > 0:
+		u.mode = "MIXED"
+	case u.lastCoolingDemandKw > u.lastHeatingDemandKw:
+		u.mode = "COOLING"
+	case u.lastHeatingDemandKw > u.lastCoolingDemandKw:
+		u.mode = "HEATING"
+	}
+
+	coolingCapacity := u.capacityKw * (1.0 - vrfCoolingDeratePerK*math.Max(0, outdoorTemp-vrfCoolingDerateTemp))
+	heatingCapacity := u.capacityKw * heatingCapacityRatio * (1.0 - vrfHeatingDeratePerK*math.Max(0, vrfHeatingDerateTemp-outdoorTemp))
+	ratio := 1.0
+	switch u.mode {
+	case "COOLING":
+		ratio = capacityRatio(coolingCapacity, u.lastCoolingDemandKw)
+	case "HEATING":
+		ratio = capacityRatio(heatingCapacity, u.lastHeatingDemandKw)
+	case "MIXED":
+		// Com recuperação de calor o compressor atende a soma das demandas, na proporção de cada modo
+		demand := u.lastCoolingDemandKw + u.lastHeatingDemandKw
+		available := (coolingCapacity*u.lastCoolingDemandKw + heatingCapacity*u.lastHeatingDemandKw) / demand
+		ratio = capacityRatio(available, demand)
+	}
+
+	for i, device := range u.indoor {
+		factor := 1.0
+		if ratio < 1.0 {
+			position := 0.5
+			if len(u.indoor) > 1 {
+				position = float64(i) / float64(len(u.indoor)-1)
+			}
+			factor = ratio * (1.0 + vrfStarvationSpread*(0.5-position))
+			factor = math.Max(vrfMinCapacityFactor, math.Min(1.0, factor))
+		}
+		device.vrfCapacityFactor = factor
+		device.CapacityKw = u.nominalKw[i] * factor
+	}
+
+	u.lastCoolingDemandKw, u.lastHeatingDemandKw = u.coolingDemandKw, u.heatingDemandKw
+	u.coolingDemandKw, u.heatingDemandKw = 0, 0
+}
+
+func capacityRatio(available, demand float64) float64 {
+	if demand <= 0 {
+		return 1.0
+	}
+	return math.Max(0, available) / demand
+}
+
+// conflicts indica se o modo pedido pela unidade interna é oposto ao da unidade externa.
+func (u *vrfOutdoorUnit) conflicts(systemStatus string) bool {
+	if u.heatRecovery {
+		return false
+	}
+	cooling := systemStatus == "COOLING" || systemStatus == "PRE_COOLING"
+	return (cooling && u.mode == "HEATING") || (systemStatus == "HEATING" && u.mode == "COOLING")
+}
+
+// request registra a demanda da unidade interna no modo pedido: a carga da sala mais o calor para
+// levá-la ao setpoint em uma hora, limitada à capacidade nominal da unidade interna.
+func (u *vrfOutdoorUnit) request(device *deviceState, systemStatus string, outdoorTemp, internalTemp, setPoint float64) {
+	if !compressorRunning(systemStatus) {
+		return
+	}
+	nominal := device.CapacityKw
+	for i, indoor := range u.indoor {
+		if indoor == device {
+			nominal = u.nominalKw[i]
+		}
+	}
+	load := envelopeUaKwPerK * (outdoorTemp - internalTemp)
+	if device.occupied {
+		load += occupiedGainsKw
+	}
+	pulldown := device.thermalMass() * (internalTemp - setPoint)
+	if systemStatus == "HEATING" {
+		demand := math.Min(nominal*heatingCapacityRatio, math.Max(0, -load-pulldown))
+		u.heatingDemandKw += demand
+		return
+	}
+	u.coolingDemandKw += math.Min(nominal, math.Max(0, load+pulldown))
+}
+
+// vrfPoints monta os pontos VRF da unidade interna no passo.
+func (d *deviceState) vrfPoints(modeConflict bool) *VRFPoints {
+	return &VRFPoints{
+		OutdoorUnitId:   d.vrf.id,
+		OutdoorUnitMode: d.vrf.mode,
+		CapacityFactor:  d.vrfCapacityFactor,
+		ModeConflict:    modeConflict,
+	}
+}
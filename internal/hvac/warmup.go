@@ -0,0 +1,20 @@
+package hvac
+
+import "github.com/patrik-rangel/mock-data-hvac/internal/climate"
+
+// WarmUp simula os passos climáticos informados sem emitir os registros, para que o estado da
+// simulação (temperatura das salas, desgaste, carga do filtro, medidores) chegue ao primeiro passo
+// emitido como numa série contínua. Os relatórios acumulados pelo simulador (incoerências, balanço
+// de energia, ordens de serviço, eventos de ciclo de vida e leituras dos sensores sem fio) são
+// descartados e passam a cobrir só os passos seguintes.
+func (s *Simulator) WarmUp(climateRecords []climate.InmetClimateData) {
+	var buf []HvacSensorData
+	for _, climateData := range climateRecords {
+		buf = s.StepInto(buf[:0], climateData)
+	}
+	s.inconsistencies = nil
+	s.workOrders = nil
+	s.lifecycleRecords = nil
+	s.sensorReadings = nil
+	clear(s.energyBalances)
+}
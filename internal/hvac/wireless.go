@@ -0,0 +1,128 @@
+package hvac
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+// WirelessSensor é um sensor de ambiente a bateria (temperatura, umidade e CO2) instalado
+// na sala atendida por um dispositivo HVAC.
+type WirelessSensor struct {
+	ID                string  `json:"id"`                // Identificador do sensor (ex: TH-SALA-1)
+	Device            string  `json:"device"`            // Dispositivo HVAC da sala onde o sensor está instalado
+	Protocol          string  `json:"protocol"`          // Protocolo de rádio até o gateway (padrão: LoRaWAN)
+	ReportEveryHours  int     `json:"reportEveryHours"`  // Intervalo entre leituras (h, padrão: 2)
+	InitialBatteryPct float64 `json:"initialBatteryPct"` // Carga da bateria no início da simulação (%, padrão: 100)
+	DrainPctPerReport float64 `json:"drainPctPerReport"` // Carga consumida por transmissão (%, padrão: 0.05)
+}
+
+// WirelessSensorReading é a leitura de um sensor a bateria: apenas grandezas da sala.
+type WirelessSensorReading struct {
+	Timestamp       time.Time `json:"timestamp"`       // Momento da medição
+	SensorId        string    `json:"sensorId"`        // Sensor que fez a leitura
+	DeviceId        string    `json:"deviceId"`        // Dispositivo HVAC da sala
+	LocationZone    string    `json:"locationZone"`    // Zona da sala
+	ZoneTemperature float64   `json:"zoneTemperature"` // Temperatura da sala (°C)
+	ZoneHumidity    float64   `json:"zoneHumidity"`    // Umidade relativa da sala (%)
+	CO2LevelPpm     float64   `json:"co2LevelPpm"`     // Nível de CO₂ da sala (ppm)
+	BatteryPercent  float64   `json:"batteryPercent"`  // Carga restante da bateria (%)
+}
+
+const (
+	lowBatteryPct       = 10.0 // Abaixo desta carga o rádio começa a perder transmissões (%)
+	defaultSensorRadio  = "LoRaWAN"
+	coolingDehumidifyRH = 55.0 // Umidade máxima da sala com a serpentina de resfriamento ativa (%)
+)
+
+func (w WirelessSensor) withDefaults() WirelessSensor {
+	if w.Protocol == "" {
+		w.Protocol = defaultSensorRadio
+	}
+	if w.ReportEveryHours == 0 {
+		w.ReportEveryHours = 2
+	}
+	if w.InitialBatteryPct == 0 {
+		w.InitialBatteryPct = 100.0
+	}
+	if w.DrainPctPerReport == 0 {
+		w.DrainPctPerReport = 0.05
+	}
+	return w
+}
+
+// sensorState guarda a bateria e a última transmissão de um sensor sem fio.
+type sensorState struct {
+	WirelessSensor
+	room       *deviceState
+	battery    float64
+	lastReport time.Time
+}
+
+// attachSensors associa cada sensor ao estado da sala em que está instalado.
+func (s *Simulator) attachSensors(sensors []WirelessSensor) error {
+	rooms := make(map[string]*deviceState, len(s.devices))
+	for _, device := range s.devices {
+		rooms[device.ID] = device
+	}
+	for _, sensor := range sensors {
+		room, ok := rooms[sensor.Device]
+		if !ok {
+			return fmt.Errorf("sensor '%s' instalado em dispositivo inexistente '%s'", sensor.ID, sensor.Device)
+		}
+		sensor = sensor.withDefaults()
+		s.sensors = append(s.sensors, &sensorState{WirelessSensor: sensor, room: room, battery: sensor.InitialBatteryPct})
+	}
+	return nil
+}
+
+// stepSensors gera as leituras dos sensores sem fio com transmissão prevista no passo.
+// Sensores com bateria esgotada não transmitem; com bateria fraca perdem parte das leituras.
+func (s *Simulator) stepSensors(climateData climate.InmetClimateData) {
+	s.sensorReadings = s.sensorReadings[:0]
+	t := climateData.Timestamp
+	for _, sensor := range s.sensors {
+		interval := time.Duration(sensor.ReportEveryHours) * time.Hour
+		if !sensor.lastReport.IsZero() && t.Sub(sensor.lastReport) < interval {
+			continue
+		}
+		if sensor.battery <= 0 {
+			continue
+		}
+		sensor.lastReport = t
+		sensor.battery = math.Max(0, sensor.battery-sensor.DrainPctPerReport)
+		if sensor.battery < lowBatteryPct && s.rng.Float64() > sensor.battery/lowBatteryPct {
+			continue
+		}
+
+		room := sensor.room
+		s.sensorReadings = append(s.sensorReadings, WirelessSensorReading{
+			Timestamp:       t,
+			SensorId:        sensor.ID,
+			DeviceId:        room.ID,
+			LocationZone:    room.Zone,
+			ZoneTemperature: room.internalTemp + s.rng.NormFloat64()*0.2,
+			ZoneHumidity:    zoneHumidity(climateData.TemperatureAir, climateData.RelativeHumidity, room.internalTemp, room.lastStatus) + s.rng.NormFloat64()*1.5,
+			CO2LevelPpm:     math.Max(outdoorCO2, room.lastCO2+s.rng.NormFloat64()*15.0),
+			BatteryPercent:  math.Round(sensor.battery*10) / 10,
+		})
+	}
+}
+
+// SensorReadings retorna as leituras dos sensores sem fio geradas no último Step.
+func (s *Simulator) SensorReadings() []WirelessSensorReading {
+	return append([]WirelessSensorReading(nil), s.sensorReadings...)
+}
+
+// zoneHumidity estima a umidade relativa da sala mantendo a umidade absoluta do ar externo
+// (pressão de vapor pela fórmula de Magnus); o resfriamento desumidifica o ar.
+func zoneHumidity(outdoorTemp, outdoorHumidity, zoneTemp float64, systemStatus string) float64 {
+	vapor := outdoorHumidity / 100.0 * climate.SaturationPressure(outdoorTemp)
+	humidity := 100.0 * vapor / climate.SaturationPressure(zoneTemp)
+	if systemStatus == "COOLING" || systemStatus == "PRE_COOLING" {
+		humidity = math.Min(humidity, coolingDehumidifyRH)
+	}
+	return math.Max(15.0, math.Min(95.0, humidity))
+}
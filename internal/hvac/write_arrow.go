@@ -0,0 +1,148 @@
+package hvac
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// arrowBatchRows é o tamanho de cada record batch do arquivo Arrow.
+const arrowBatchRows = 65536
+
+func arrowType(kind columnKind) arrow.DataType {
+	switch kind {
+	case kindFloat, kindNullableFloat:
+		return arrow.PrimitiveTypes.Float64
+	case kindInt, kindNullableInt:
+		return arrow.PrimitiveTypes.Int64
+	case kindBool:
+		return arrow.FixedWidthTypes.Boolean
+	case kindTime:
+		return &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}
+	case kindStringList:
+		return arrow.ListOf(arrow.BinaryTypes.String)
+	}
+	return arrow.BinaryTypes.String
+}
+
+func isNullable(kind columnKind) bool {
+	return kind == kindNullableFloat || kind == kindNullableInt || kind == kindNullableString
+}
+
+// arrowSchema monta o esquema Arrow a partir das colunas achatadas.
+func arrowSchema(columns []column) *arrow.Schema {
+	fields := make([]arrow.Field, len(columns))
+	for i, c := range columns {
+		fields[i] = arrow.Field{Name: c.name, Type: arrowType(c.kind), Nullable: isNullable(c.kind)}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// eachArrowRecord monta os registros em record batches de até arrowBatchRows linhas e os entrega
+// a write, liberando cada batch em seguida.
+func eachArrowRecord(data []HvacSensorData, columns []column, schema *arrow.Schema, write func(arrow.Record) error) error {
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	for start := 0; start < len(data); start += arrowBatchRows {
+		end := min(start+arrowBatchRows, len(data))
+		for row := start; row < end; row++ {
+			for i, c := range columns {
+				appendArrowValue(builder.Field(i), c.kind, c.value(&data[row]))
+			}
+		}
+		record := builder.NewRecord()
+		err := write(record)
+		record.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteArrowIPC serializa os registros no formato de arquivo Arrow IPC (Feather v2), com o esquema
+// colunar achatado. compression aceita zstd ou lz4.
+func WriteArrowIPC(data []HvacSensorData, compression Compression) ([]byte, error) {
+	return writeArrowIPC(data, tabularColumns(data), compression)
+}
+
+// writeArrowIPC serializa os registros no arquivo Arrow com as colunas informadas.
+func writeArrowIPC(data []HvacSensorData, columns []column, compression Compression) ([]byte, error) {
+	if err := CheckCompression("arrow", compression); err != nil {
+		return nil, fmt.Errorf("compressão Arrow: %w", err)
+	}
+	schema := arrowSchema(columns)
+
+	opts := []ipc.Option{ipc.WithSchema(schema)}
+	switch compression {
+	case CompressionZstd:
+		opts = append(opts, ipc.WithZstd())
+	case CompressionLZ4:
+		opts = append(opts, ipc.WithLZ4())
+	}
+
+	var buf bytes.Buffer
+	writer, err := ipc.NewFileWriter(&buf, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o arquivo Arrow: %w", err)
+	}
+
+	err = eachArrowRecord(data, columns, schema, func(record arrow.Record) error {
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("erro ao escrever o record batch Arrow: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar o arquivo Arrow: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func appendArrowValue(b array.Builder, kind columnKind, value any) {
+	switch kind {
+	case kindFloat:
+		b.(*array.Float64Builder).Append(value.(float64))
+	case kindNullableFloat:
+		if v := value.(*float64); v != nil {
+			b.(*array.Float64Builder).Append(*v)
+		} else {
+			b.AppendNull()
+		}
+	case kindInt:
+		b.(*array.Int64Builder).Append(value.(int64))
+	case kindNullableInt:
+		if v := value.(*int64); v != nil {
+			b.(*array.Int64Builder).Append(*v)
+		} else {
+			b.AppendNull()
+		}
+	case kindBool:
+		b.(*array.BooleanBuilder).Append(value.(bool))
+	case kindString:
+		b.(*array.StringBuilder).Append(value.(string))
+	case kindNullableString:
+		if v := value.(*string); v != nil {
+			b.(*array.StringBuilder).Append(*v)
+		} else {
+			b.AppendNull()
+		}
+	case kindTime:
+		b.(*array.TimestampBuilder).Append(arrow.Timestamp(timestampMillis(value.(time.Time))))
+	case kindStringList:
+		lb := b.(*array.ListBuilder)
+		lb.Append(true)
+		values := lb.ValueBuilder().(*array.StringBuilder)
+		for _, s := range value.([]string) {
+			values.Append(s)
+		}
+	}
+}
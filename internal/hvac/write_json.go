@@ -1,11 +1,89 @@
 package hvac
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 )
 
+// estimatedJSONRecordBytes é o tamanho aproximado de um registro canônico no JSON indentado, usado
+// para reservar o buffer de saída de uma vez.
+const estimatedJSONRecordBytes = 1024
+
+// encodeJSONArray grava em w o array JSON de n elementos com a mesma saída de
+// json.MarshalIndent(v, "", "  "), mas elemento a elemento: sem montar a árvore do array inteiro e
+// reaproveitando o buffer de codificação entre os elementos.
+func encodeJSONArray(w io.Writer, n int, element func(i int) (any, error)) error {
+	array := newJSONArrayWriter(w)
+	for i := range n {
+		value, err := element(i)
+		if err != nil {
+			return err
+		}
+		if err := array.add(value); err != nil {
+			return err
+		}
+	}
+	return array.close()
+}
+
+// jsonArrayWriter grava um array JSON indentado um elemento por vez, para arrays produzidos aos
+// poucos, como os registros de uma geração em andamento.
+type jsonArrayWriter struct {
+	out     *bufio.Writer
+	buf     bytes.Buffer
+	encoder *json.Encoder
+	count   int
+}
+
+// jsonArrayWriters reaproveita os buffers de saída e de codificação entre os arrays gravados.
+var jsonArrayWriters = sync.Pool{New: func() any {
+	a := &jsonArrayWriter{out: bufio.NewWriterSize(nil, 64*1024)}
+	a.encoder = json.NewEncoder(&a.buf)
+	a.encoder.SetIndent("  ", "  ")
+	return a
+}}
+
+func newJSONArrayWriter(w io.Writer) *jsonArrayWriter {
+	a := jsonArrayWriters.Get().(*jsonArrayWriter)
+	a.out.Reset(w)
+	a.count = 0
+	return a
+}
+
+func (a *jsonArrayWriter) add(value any) error {
+	a.buf.Reset()
+	if err := a.encoder.Encode(value); err != nil {
+		return fmt.Errorf("erro ao serializar o elemento %d para JSON: %w", a.count, err)
+	}
+	if a.count == 0 {
+		a.out.WriteString("[\n  ")
+	} else {
+		a.out.WriteString(",\n  ")
+	}
+	a.count++
+	_, err := a.out.Write(bytes.TrimSuffix(a.buf.Bytes(), []byte("\n"))) // Encode termina cada valor com uma quebra de linha
+	return err
+}
+
+// close fecha o array e devolve os buffers ao pool; o escritor não pode mais ser usado.
+func (a *jsonArrayWriter) close() error {
+	if a.count == 0 {
+		a.out.WriteString("[]")
+	} else {
+		a.out.WriteString("\n]")
+	}
+	err := a.out.Flush()
+	a.out.Reset(nil)
+	a.buf.Reset()
+	jsonArrayWriters.Put(a)
+	return err
+}
+
 func WriteHvacDataToJSONL(filename string, data []HvacSensorData) error {
 	file, err := os.Create(filename)
 	if err != nil {
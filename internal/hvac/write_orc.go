@@ -0,0 +1,71 @@
+package hvac
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/orc"
+)
+
+func orcType(kind columnKind) orc.Type {
+	switch kind {
+	case kindFloat, kindNullableFloat:
+		return orc.Double
+	case kindInt, kindNullableInt:
+		return orc.Long
+	case kindBool:
+		return orc.Boolean
+	case kindTime:
+		return orc.Timestamp
+	case kindStringList:
+		return orc.StringList
+	}
+	return orc.String
+}
+
+// WriteORC serializa os registros no formato ORC, com o mesmo esquema colunar achatado do Arrow.
+// compression aceita zstd, snappy ou zlib.
+func WriteORC(data []HvacSensorData, compression Compression) ([]byte, error) {
+	return writeORC(data, tabularColumns(data), compression)
+}
+
+// writeORC serializa os registros no arquivo ORC com as colunas informadas.
+func writeORC(data []HvacSensorData, columns []column, compression Compression) ([]byte, error) {
+	if err := CheckCompression("orc", compression); err != nil {
+		return nil, fmt.Errorf("compressão ORC: %w", err)
+	}
+	fields := make([]orc.Field, len(columns))
+	for i, c := range columns {
+		fields[i] = orc.Field{Name: c.name, Type: orcType(c.kind)}
+	}
+
+	codec := orc.None
+	switch compression {
+	case CompressionZstd:
+		codec = orc.Zstd
+	case CompressionSnappy:
+		codec = orc.Snappy
+	case CompressionZlib:
+		codec = orc.Zlib
+	}
+
+	var buf bytes.Buffer
+	writer, err := orc.NewWriter(&buf, fields, orc.Options{Compression: codec})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o arquivo ORC: %w", err)
+	}
+
+	row := make([]any, len(columns))
+	for i := range data {
+		for j, c := range columns {
+			row[j] = flatValue(c.kind, c.value(&data[i]))
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("erro ao escrever a linha %d no ORC: %w", i, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar o arquivo ORC: %w", err)
+	}
+	return buf.Bytes(), nil
+}
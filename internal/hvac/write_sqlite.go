@@ -0,0 +1,125 @@
+package hvac
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // Driver SQLite em Go puro, sem cgo
+)
+
+// sqliteTimestampLayout grava o timestamp como texto UTC, que as funções de data do SQLite e o
+// cast do DuckDB entendem.
+const sqliteTimestampLayout = "2006-01-02 15:04:05.000"
+
+func sqliteType(kind columnKind) string {
+	switch kind {
+	case kindFloat, kindNullableFloat:
+		return "REAL"
+	case kindInt, kindNullableInt, kindBool:
+		return "INTEGER"
+	}
+	return "TEXT"
+}
+
+// sqliteIndexes são os índices das consultas mais comuns: série de um dispositivo, recorte de
+// período e comparação entre zonas.
+var sqliteIndexes = []string{
+	`CREATE UNIQUE INDEX idx_hvac_readings_device_time ON hvac_readings ("deviceId", "timestamp")`,
+	`CREATE INDEX idx_hvac_readings_time ON hvac_readings ("timestamp")`,
+	`CREATE INDEX idx_hvac_readings_zone_time ON hvac_readings ("locationZone", "timestamp")`,
+}
+
+// WriteSQLite grava os registros num arquivo SQLite com a tabela hvac_readings, no esquema colunar
+// achatado, e os índices de consulta. Listas (activeFaults) ficam como texto JSON. O arquivo também
+// abre no DuckDB com ATTACH 'arquivo' (TYPE sqlite).
+func WriteSQLite(data []HvacSensorData) ([]byte, error) {
+	tmp, err := os.CreateTemp("", "hvac-*.sqlite")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o arquivo SQLite temporário: %w", err)
+	}
+	path := tmp.Name()
+	tmp.Close()
+	defer os.Remove(path)
+
+	if err := writeSQLiteFile(path, data); err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo SQLite: %w", err)
+	}
+	return content, nil
+}
+
+func writeSQLiteFile(path string, data []HvacSensorData) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir o arquivo SQLite: %w", err)
+	}
+	defer db.Close()
+
+	columns := tabularColumns(data)
+	definitions := make([]string, len(columns))
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		definitions[i] = fmt.Sprintf(`"%s" %s`, c.name, sqliteType(c.kind))
+		names[i] = fmt.Sprintf(`"%s"`, c.name)
+	}
+	if _, err := db.Exec("CREATE TABLE hvac_readings (" + strings.Join(definitions, ", ") + ")"); err != nil {
+		return fmt.Errorf("erro ao criar a tabela SQLite: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar a transação SQLite: %w", err)
+	}
+	defer tx.Rollback()
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.Prepare("INSERT INTO hvac_readings (" + strings.Join(names, ", ") + ") VALUES (" + placeholders + ")")
+	if err != nil {
+		return fmt.Errorf("erro ao preparar a inserção SQLite: %w", err)
+	}
+	defer stmt.Close()
+
+	row := make([]any, len(columns))
+	for i := range data {
+		for j, c := range columns {
+			value, err := sqliteValue(c.kind, flatValue(c.kind, c.value(&data[i])))
+			if err != nil {
+				return err
+			}
+			row[j] = value
+		}
+		if _, err := stmt.Exec(row...); err != nil {
+			return fmt.Errorf("erro ao inserir o registro %d no SQLite: %w", i, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar a transação SQLite: %w", err)
+	}
+
+	for _, index := range sqliteIndexes {
+		if _, err := db.Exec(index); err != nil {
+			return fmt.Errorf("erro ao criar o índice SQLite: %w", err)
+		}
+	}
+	return nil
+}
+
+func sqliteValue(kind columnKind, value any) (any, error) {
+	switch kind {
+	case kindTime:
+		return value.(time.Time).Format(sqliteTimestampLayout), nil
+	case kindStringList:
+		list, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao serializar lista para o SQLite: %w", err)
+		}
+		return string(list), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,66 @@
+package hvac
+
+import "time"
+
+// Zone descreve a geometria de uma zona atendida pelos dispositivos.
+type Zone struct {
+	ID       string  `json:"id"`       // Identificador da zona (mesmo valor de Device.Zone)
+	AreaM2   float64 `json:"areaM2"`   // Área de piso da zona (m²)
+	VolumeM3 float64 `json:"volumeM3"` // Volume de ar da zona (m³)
+}
+
+// IntensityMetrics normaliza o consumo pela área e pelo volume atendidos pelo dispositivo.
+// A área e o volume de uma zona são divididos igualmente entre os seus dispositivos.
+type IntensityMetrics struct {
+	ServedAreaM2            float64 `json:"servedAreaM2"`            // Área atendida pelo dispositivo (m²)
+	ServedVolumeM3          float64 `json:"servedVolumeM3"`          // Volume atendido pelo dispositivo (m³)
+	PowerDensityWm2         float64 `json:"powerDensityWm2"`         // Potência média no período por área (W/m²)
+	PowerDensityWm3         float64 `json:"powerDensityWm3"`         // Potência média no período por volume (W/m³)
+	EnergyIntensityKwhM2Day float64 `json:"energyIntensityKwhM2Day"` // Energia acumulada no dia por área (kWh/m²·dia)
+}
+
+// assignZones distribui a área e o volume de cada zona entre os dispositivos que ela contém.
+func (s *Simulator) assignZones(zones []Zone) {
+	counts := make(map[string]int)
+	for _, device := range s.devices {
+		counts[device.Zone]++
+	}
+	for _, zone := range zones {
+		n := counts[zone.ID]
+		if n == 0 {
+			continue
+		}
+		for _, device := range s.devices {
+			if device.Zone == zone.ID {
+				device.servedAreaM2 = zone.AreaM2 / float64(n)
+				device.servedVolumeM3 = zone.VolumeM3 / float64(n)
+			}
+		}
+	}
+}
+
+// intensity acumula a energia diária do dispositivo e calcula as métricas normalizadas.
+func (s *Simulator) intensity(device *deviceState, t time.Time, energyKwh float64) *IntensityMetrics {
+	if device.servedAreaM2 <= 0 {
+		return nil
+	}
+
+	hours := periodHours(device, t)
+	year, month, day := t.Date()
+	if y, m, d := device.lastTimestamp.Date(); y != year || m != month || d != day {
+		device.dayEnergyKwh = 0
+	}
+	device.dayEnergyKwh += energyKwh
+
+	averageWatts := energyKwh / hours * 1000.0
+	metrics := &IntensityMetrics{
+		ServedAreaM2:            device.servedAreaM2,
+		ServedVolumeM3:          device.servedVolumeM3,
+		PowerDensityWm2:         averageWatts / device.servedAreaM2,
+		EnergyIntensityKwhM2Day: device.dayEnergyKwh / device.servedAreaM2,
+	}
+	if device.servedVolumeM3 > 0 {
+		metrics.PowerDensityWm3 = averageWatts / device.servedVolumeM3
+	}
+	return metrics
+}
@@ -0,0 +1,256 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iot"
+	"github.com/aws/aws-sdk-go-v2/service/iot/types"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// ProvisionConfig ajusta o cadastro dos dispositivos simulados como things do AWS IoT Core.
+type ProvisionConfig struct {
+	ThingPrefix    string          `json:"thingPrefix"`    // Prefixo do nome do thing; o nome é prefixo + deviceId (padrão: o de shadows)
+	ThingType      string          `json:"thingType"`      // Tipo de thing, já existente na conta (opcional)
+	PolicyName     string          `json:"policyName"`     // Política anexada aos certificados (padrão: hvac-mock-devices)
+	PolicyDocument json.RawMessage `json:"policyDocument"` // Documento da política (padrão: conectar com o nome do thing e usar os tópicos do próprio thing)
+	Certificates   bool            `json:"certificates"`   // Cria e anexa um certificado ativo por thing, com a política
+	CertificateDir string          `json:"certificateDir"` // Diretório local dos certificados e chaves privadas (padrão: iot-certs)
+	Cleanup        bool            `json:"cleanup"`        // Remove no fim da execução os things, certificados e a política criados por ela
+}
+
+func (c ProvisionConfig) withDefaults() ProvisionConfig {
+	if c.PolicyName == "" {
+		c.PolicyName = "hvac-mock-devices"
+	}
+	if len(c.PolicyDocument) == 0 {
+		c.PolicyDocument = json.RawMessage(defaultPolicyDocument)
+	}
+	if c.CertificateDir == "" {
+		c.CertificateDir = "iot-certs"
+	}
+	return c
+}
+
+// defaultPolicyDocument deixa cada certificado conectar só com o nome do próprio thing como client
+// ID e usar só os tópicos reservados do thing (shadow, jobs), além de hvac/<thing>/#.
+const defaultPolicyDocument = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {"Effect": "Allow", "Action": "iot:Connect", "Resource": "arn:aws:iot:*:*:client/${iot:Connection.Thing.ThingName}"},
+    {"Effect": "Allow", "Action": ["iot:Publish", "iot:Receive"], "Resource": [
+      "arn:aws:iot:*:*:topic/$aws/things/${iot:Connection.Thing.ThingName}/*",
+      "arn:aws:iot:*:*:topic/hvac/${iot:Connection.Thing.ThingName}/*"
+    ]},
+    {"Effect": "Allow", "Action": "iot:Subscribe", "Resource": [
+      "arn:aws:iot:*:*:topicfilter/$aws/things/${iot:Connection.Thing.ThingName}/*",
+      "arn:aws:iot:*:*:topicfilter/hvac/${iot:Connection.Thing.ThingName}/*"
+    ]}
+  ]
+}`
+
+// invalidAttributeChars são os caracteres não aceitos pelo IoT Core nos valores de atributo.
+var invalidAttributeChars = regexp.MustCompile(`[^a-zA-Z0-9_.,@/:#=\[\]-]`)
+
+// provisionedCertificate é um certificado criado na execução e o thing ao qual foi anexado.
+type provisionedCertificate struct {
+	thing string
+	id    string
+	arn   string
+}
+
+// Provisioning guarda o que Provision criou na conta, para que Cleanup remova só isso: os things
+// e a política que já existiam são mantidos.
+type Provisioning struct {
+	client        *iot.Client
+	cfg           ProvisionConfig
+	createdThings []string
+	certificates  []provisionedCertificate
+	createdPolicy bool
+}
+
+// Provision cadastra cada dispositivo do registro como um thing do IoT Core, pela API de controle
+// (com a região e o endpoint de awsConfig), com o modelo, a zona e o site como atributos. Os things já existentes são atualizados. Com Certificates, cria a
+// política, se não existir, e um certificado ativo por thing, anexado ao thing e à política, e
+// grava o certificado e a chave privada em CertificateDir. Em caso de erro, o Provisioning
+// retornado traz o que já foi criado, para a limpeza.
+func Provision(awsConfig aws.Config, cfg ProvisionConfig, devices []hvac.RegisteredDevice) (*Provisioning, error) {
+	cfg = cfg.withDefaults()
+	ctx := context.TODO()
+	p := &Provisioning{client: iot.NewFromConfig(awsConfig), cfg: cfg}
+	log.Printf("Cadastrando %d dispositivos como things do IoT Core na região '%s'...", len(devices), awsConfig.Region)
+
+	if cfg.Certificates {
+		if err := p.ensurePolicy(ctx); err != nil {
+			return p, err
+		}
+		if err := os.MkdirAll(cfg.CertificateDir, 0o700); err != nil {
+			return p, fmt.Errorf("falha ao criar o diretório de certificados '%s': %w", cfg.CertificateDir, err)
+		}
+	}
+	for _, device := range devices {
+		thing := cfg.ThingPrefix + device.DeviceId
+		if err := p.upsertThing(ctx, thing, device); err != nil {
+			return p, err
+		}
+		if cfg.Certificates {
+			if err := p.createCertificate(ctx, thing); err != nil {
+				return p, err
+			}
+		}
+	}
+
+	log.Printf("%d things cadastrados (%d novos) e %d certificados criados.", len(devices), len(p.createdThings), len(p.certificates))
+	return p, nil
+}
+
+// upsertThing cria o thing ou, se já existir, atualiza os atributos.
+func (p *Provisioning) upsertThing(ctx context.Context, thing string, device hvac.RegisteredDevice) error {
+	attributes := map[string]string{
+		"assetModel": sanitizeAttribute(device.AssetModel),
+		"zone":       sanitizeAttribute(device.Zone),
+	}
+	if device.Site != "" {
+		attributes["site"] = sanitizeAttribute(device.Site)
+	}
+	var thingType *string
+	if p.cfg.ThingType != "" {
+		thingType = aws.String(p.cfg.ThingType)
+	}
+
+	_, err := p.client.CreateThing(ctx, &iot.CreateThingInput{
+		ThingName:        aws.String(thing),
+		ThingTypeName:    thingType,
+		AttributePayload: &types.AttributePayload{Attributes: attributes},
+	})
+	var exists *types.ResourceAlreadyExistsException
+	switch {
+	case errors.As(err, &exists): // Já existe com outros atributos
+		_, err := p.client.UpdateThing(ctx, &iot.UpdateThingInput{
+			ThingName:        aws.String(thing),
+			ThingTypeName:    thingType,
+			AttributePayload: &types.AttributePayload{Attributes: attributes, Merge: true},
+		})
+		if err != nil {
+			return fmt.Errorf("falha ao atualizar o thing '%s': %w", thing, err)
+		}
+	case err != nil:
+		return fmt.Errorf("falha ao criar o thing '%s': %w", thing, err)
+	default:
+		p.createdThings = append(p.createdThings, thing)
+	}
+	return nil
+}
+
+// ensurePolicy cria a política dos certificados, se ainda não existir.
+func (p *Provisioning) ensurePolicy(ctx context.Context) error {
+	_, err := p.client.CreatePolicy(ctx, &iot.CreatePolicyInput{
+		PolicyName:     aws.String(p.cfg.PolicyName),
+		PolicyDocument: aws.String(string(p.cfg.PolicyDocument)),
+	})
+	var exists *types.ResourceAlreadyExistsException
+	switch {
+	case errors.As(err, &exists):
+		log.Printf("Usando a política '%s', já existente na conta.", p.cfg.PolicyName)
+	case err != nil:
+		return fmt.Errorf("falha ao criar a política '%s': %w", p.cfg.PolicyName, err)
+	default:
+		p.createdPolicy = true
+	}
+	return nil
+}
+
+// createCertificate cria um certificado ativo para o thing, anexa o thing e a política a ele e
+// grava o certificado e a chave privada no diretório de certificados.
+func (p *Provisioning) createCertificate(ctx context.Context, thing string) error {
+	created, err := p.client.CreateKeysAndCertificate(ctx, &iot.CreateKeysAndCertificateInput{SetAsActive: true})
+	if err != nil {
+		return fmt.Errorf("falha ao criar o certificado do thing '%s': %w", thing, err)
+	}
+	arn := aws.ToString(created.CertificateArn)
+	p.certificates = append(p.certificates, provisionedCertificate{thing: thing, id: aws.ToString(created.CertificateId), arn: arn})
+
+	var privateKey string
+	if created.KeyPair != nil {
+		privateKey = aws.ToString(created.KeyPair.PrivateKey)
+	}
+	base := filepath.Join(p.cfg.CertificateDir, thing)
+	if err := os.WriteFile(base+".cert.pem", []byte(aws.ToString(created.CertificatePem)), 0o644); err != nil {
+		return fmt.Errorf("falha ao gravar o certificado do thing '%s': %w", thing, err)
+	}
+	if err := os.WriteFile(base+".private.key", []byte(privateKey), 0o600); err != nil {
+		return fmt.Errorf("falha ao gravar a chave privada do thing '%s': %w", thing, err)
+	}
+
+	_, err = p.client.AttachPolicy(ctx, &iot.AttachPolicyInput{PolicyName: aws.String(p.cfg.PolicyName), Target: aws.String(arn)})
+	if err != nil {
+		return fmt.Errorf("falha ao anexar a política ao certificado do thing '%s': %w", thing, err)
+	}
+	_, err = p.client.AttachThingPrincipal(ctx, &iot.AttachThingPrincipalInput{ThingName: aws.String(thing), Principal: aws.String(arn)})
+	if err != nil {
+		return fmt.Errorf("falha ao anexar o certificado ao thing '%s': %w", thing, err)
+	}
+	return nil
+}
+
+// Cleanup remove o que a execução criou: desanexa, desativa e apaga os certificados, apaga os
+// things novos e, por último, a política, se criada por ela. Continua após os erros, que retornam
+// juntos. Os arquivos de certificado locais são mantidos.
+func (p *Provisioning) Cleanup() error {
+	log.Printf("Removendo %d things e %d certificados criados no IoT Core...", len(p.createdThings), len(p.certificates))
+	ctx := context.TODO()
+	var errs []error
+	for _, cert := range p.certificates {
+		steps := []func() error{
+			func() error {
+				_, err := p.client.DetachThingPrincipal(ctx, &iot.DetachThingPrincipalInput{ThingName: aws.String(cert.thing), Principal: aws.String(cert.arn)})
+				return err
+			},
+			func() error {
+				_, err := p.client.DetachPolicy(ctx, &iot.DetachPolicyInput{PolicyName: aws.String(p.cfg.PolicyName), Target: aws.String(cert.arn)})
+				return err
+			},
+			func() error {
+				_, err := p.client.UpdateCertificate(ctx, &iot.UpdateCertificateInput{CertificateId: aws.String(cert.id), NewStatus: types.CertificateStatusInactive})
+				return err
+			},
+			func() error {
+				_, err := p.client.DeleteCertificate(ctx, &iot.DeleteCertificateInput{CertificateId: aws.String(cert.id), ForceDelete: true})
+				return err
+			},
+		}
+		for _, step := range steps {
+			if err := step(); err != nil {
+				errs = append(errs, fmt.Errorf("falha ao remover o certificado '%s' do thing '%s': %w", cert.id, cert.thing, err))
+				break
+			}
+		}
+	}
+	for _, thing := range p.createdThings {
+		if _, err := p.client.DeleteThing(ctx, &iot.DeleteThingInput{ThingName: aws.String(thing)}); err != nil {
+			errs = append(errs, fmt.Errorf("falha ao apagar o thing '%s': %w", thing, err))
+		}
+	}
+	if p.createdPolicy {
+		if _, err := p.client.DeletePolicy(ctx, &iot.DeletePolicyInput{PolicyName: aws.String(p.cfg.PolicyName)}); err != nil {
+			errs = append(errs, fmt.Errorf("falha ao apagar a política '%s': %w", p.cfg.PolicyName, err))
+		}
+	}
+	if len(errs) == 0 {
+		log.Printf("Things, certificados e política da execução removidos do IoT Core.")
+	}
+	return errors.Join(errs...)
+}
+
+func sanitizeAttribute(value string) string {
+	return invalidAttributeChars.ReplaceAllString(value, "_")
+}
@@ -0,0 +1,45 @@
+package iot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iotdataplane"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// UpdateThingShadows publica as atualizações com UpdateThingShadow no endpoint de dados da conta
+// (ex: xxxx-ats.iot.us-east-1.amazonaws.com), em ordem, pelo cliente iotdataplane do AWS SDK, que
+// repete as chamadas limitadas pela API, como nas de Provision.
+func UpdateThingShadows(awsConfig aws.Config, dataEndpoint string, updates []hvac.ShadowUpdate) error {
+	log.Printf("Publicando %d atualizações de shadow em '%s'...", len(updates), dataEndpoint)
+
+	if !strings.Contains(dataEndpoint, "://") {
+		dataEndpoint = "https://" + dataEndpoint
+	}
+	client := iotdataplane.NewFromConfig(awsConfig, func(o *iotdataplane.Options) {
+		o.BaseEndpoint = aws.String(dataEndpoint)
+	})
+	ctx := context.TODO()
+	for _, update := range updates {
+		payload, err := json.Marshal(update.Payload)
+		if err != nil {
+			return fmt.Errorf("falha ao serializar o shadow de '%s': %w", update.ThingName, err)
+		}
+		in := &iotdataplane.UpdateThingShadowInput{ThingName: aws.String(update.ThingName), Payload: payload}
+		if update.ShadowName != "" {
+			in.ShadowName = aws.String(update.ShadowName)
+		}
+		if _, err := client.UpdateThingShadow(ctx, in); err != nil {
+			return fmt.Errorf("falha ao atualizar o shadow de '%s': %w", update.ThingName, err)
+		}
+	}
+
+	log.Printf("%d atualizações de shadow publicadas com sucesso!", len(updates))
+	return nil
+}
@@ -0,0 +1,116 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// insertBatchSize é o número de documentos por InsertMany.
+const insertBatchSize = 10000
+
+// Config ajusta o sink de coleção time-series do MongoDB.
+type Config struct {
+	Database    string `json:"database"`    // Banco de dados (padrão: hvac)
+	Collection  string `json:"collection"`  // Coleção time-series, criada se não existir (padrão: telemetry)
+	Granularity string `json:"granularity"` // Granularidade dos buckets: seconds, minutes (padrão) ou hours
+}
+
+func (c Config) withDefaults() Config {
+	if c.Database == "" {
+		c.Database = "hvac"
+	}
+	if c.Collection == "" {
+		c.Collection = "telemetry"
+	}
+	if c.Granularity == "" {
+		c.Granularity = "minutes"
+	}
+	return c
+}
+
+// metaFields são os campos do registro que identificam o dispositivo e vão para o metaField
+// da coleção, pelo qual o MongoDB agrupa as medições em buckets.
+var metaFields = map[string]bool{"deviceId": true, "assetModel": true, "locationZone": true, "stationId": true, "runId": true}
+
+// InsertTimeSeries grava os registros na coleção time-series (timeField timestamp, metaField
+// metadata com deviceId, assetModel, locationZone, stationId e runId), criando-a se ainda não existir.
+func InsertTimeSeries(uri string, cfg Config, data []hvac.HvacSensorData) error {
+	cfg = cfg.withDefaults()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		return fmt.Errorf("falha ao conectar ao MongoDB: %w", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	db := client.Database(cfg.Database)
+	existing, err := db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: cfg.Collection}})
+	if err != nil {
+		return fmt.Errorf("falha ao listar as coleções de '%s': %w", cfg.Database, err)
+	}
+	if len(existing) == 0 {
+		log.Printf("Criando a coleção time-series '%s.%s'...", cfg.Database, cfg.Collection)
+		timeSeries := options.TimeSeries().SetTimeField("timestamp").SetMetaField("metadata").SetGranularity(cfg.Granularity)
+		if err := db.CreateCollection(ctx, cfg.Collection, options.CreateCollection().SetTimeSeriesOptions(timeSeries)); err != nil {
+			return fmt.Errorf("falha ao criar a coleção time-series '%s': %w", cfg.Collection, err)
+		}
+	}
+
+	collection := db.Collection(cfg.Collection)
+	log.Printf("Gravando %d registros na coleção time-series '%s.%s'...", len(data), cfg.Database, cfg.Collection)
+	for start := 0; start < len(data); start += insertBatchSize {
+		end := min(start+insertBatchSize, len(data))
+		documents := make([]any, 0, end-start)
+		for _, record := range data[start:end] {
+			document, err := timeSeriesDocument(record)
+			if err != nil {
+				return err
+			}
+			documents = append(documents, document)
+		}
+		if _, err := collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false)); err != nil {
+			return fmt.Errorf("falha ao gravar os registros %d a %d no MongoDB: %w", start, end-1, err)
+		}
+	}
+
+	log.Printf("%d registros gravados no MongoDB com sucesso!", len(data))
+	return nil
+}
+
+// timeSeriesDocument converte o registro para BSON com os nomes de campo do JSON, movendo a
+// identificação do dispositivo para o subdocumento metadata.
+func timeSeriesDocument(record hvac.HvacSensorData) (bson.D, error) {
+	var buf bytes.Buffer
+	encoder := bson.NewEncoder(bson.NewDocumentWriter(&buf))
+	encoder.UseJSONStructTags()
+	encoder.OmitZeroStruct()
+	if err := encoder.Encode(record); err != nil {
+		return nil, fmt.Errorf("falha ao converter o registro de '%s' para BSON: %w", record.DeviceId, err)
+	}
+	var fields bson.D
+	if err := bson.Unmarshal(buf.Bytes(), &fields); err != nil {
+		return nil, fmt.Errorf("falha ao converter o registro de '%s' para BSON: %w", record.DeviceId, err)
+	}
+
+	document := make(bson.D, 0, len(fields)+1)
+	var metadata bson.D
+	for _, field := range fields {
+		if metaFields[field.Key] {
+			metadata = append(metadata, field)
+			continue
+		}
+		document = append(document, field)
+	}
+	return append(document, bson.E{Key: "metadata", Value: metadata}), nil
+}
@@ -0,0 +1,187 @@
+package opensearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// Config ajusta a indexação no OpenSearch/Elasticsearch.
+type Config struct {
+	IndexPrefix string `json:"indexPrefix"` // Prefixo dos índices (padrão: hvac-a701); o índice é prefixo-data
+	Interval    string `json:"interval"`    // Granularidade dos índices por data: day (padrão) ou month
+	BulkSize    int    `json:"bulkSize"`    // Documentos por requisição _bulk (padrão: 5000)
+}
+
+func (c Config) withDefaults() Config {
+	if c.IndexPrefix == "" {
+		c.IndexPrefix = "hvac-a701"
+	}
+	if c.Interval == "" {
+		c.Interval = "day"
+	}
+	if c.BulkSize <= 0 {
+		c.BulkSize = 5000
+	}
+	return c
+}
+
+// indexName retorna o índice por data do registro (ex: hvac-a701-2024.02.05).
+func (c Config) indexName(t time.Time) string {
+	if c.Interval == "month" {
+		return c.IndexPrefix + "-" + t.UTC().Format("2006.01")
+	}
+	return c.IndexPrefix + "-" + t.UTC().Format("2006.01.02")
+}
+
+// indexTemplate define o mapeamento dos índices prefixo-*: timestamp como date, textos como
+// keyword (filtros e agregações dos alertas) e números como double, inclusive nos objetos aninhados.
+func indexTemplate(cfg Config) map[string]any {
+	return map[string]any{
+		"index_patterns": []string{cfg.IndexPrefix + "-*"},
+		"template": map[string]any{
+			"settings": map[string]any{"number_of_shards": 1},
+			"mappings": map[string]any{
+				"dynamic_templates": []any{
+					map[string]any{"strings_as_keyword": map[string]any{
+						"match_mapping_type": "string",
+						"mapping":            map[string]any{"type": "keyword"},
+					}},
+					map[string]any{"numbers_as_double": map[string]any{
+						"match_mapping_type": "long",
+						"mapping":            map[string]any{"type": "double"},
+					}},
+				},
+				"properties": map[string]any{
+					"timestamp":        map[string]any{"type": "date"},
+					"deviceId":         map[string]any{"type": "keyword"},
+					"locationZone":     map[string]any{"type": "keyword"},
+					"assetModel":       map[string]any{"type": "keyword"},
+					"systemStatus":     map[string]any{"type": "keyword"},
+					"faultCode":        map[string]any{"type": "keyword"},
+					"activeFaults":     map[string]any{"type": "keyword"},
+					"occupancyStatus":  map[string]any{"type": "boolean"},
+					"compressorCycles": map[string]any{"type": "integer"},
+				},
+			},
+		},
+	}
+}
+
+type client struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+}
+
+func (c *client) do(method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao montar a requisição %s %s: %w", method, path, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("falha na requisição %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler a resposta de %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s retornou HTTP %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+// bulkResponse traz só o necessário para detectar documentos rejeitados.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// IndexRecords instala o index template e indexa os registros via _bulk em índices por data.
+// O _id de cada documento é deviceId + timestamp, então reexecutar o mesmo período sobrescreve os
+// documentos em vez de duplicá-los.
+func IndexRecords(baseURL, username, password string, cfg Config, data []hvac.HvacSensorData) error {
+	cfg = cfg.withDefaults()
+	switch cfg.Interval {
+	case "day", "month":
+	default:
+		return fmt.Errorf("intervalo de índice '%s' desconhecido (use day ou month)", cfg.Interval)
+	}
+	c := &client{baseURL: strings.TrimRight(baseURL, "/"), username: username, password: password, http: &http.Client{Timeout: 2 * time.Minute}}
+
+	log.Printf("Instalando o index template '%s' no OpenSearch '%s'...", cfg.IndexPrefix, c.baseURL)
+	template, err := json.Marshal(indexTemplate(cfg))
+	if err != nil {
+		return fmt.Errorf("falha ao serializar o index template: %w", err)
+	}
+	if _, err := c.do(http.MethodPut, "/_index_template/"+cfg.IndexPrefix, "application/json", template); err != nil {
+		return fmt.Errorf("falha ao instalar o index template: %w", err)
+	}
+
+	log.Printf("Indexando %d registros no OpenSearch em lotes de %d...", len(data), cfg.BulkSize)
+	for start := 0; start < len(data); start += cfg.BulkSize {
+		end := min(start+cfg.BulkSize, len(data))
+		var body bytes.Buffer
+		for _, record := range data[start:end] {
+			action := map[string]map[string]string{"index": {
+				"_index": cfg.indexName(record.Timestamp),
+				"_id":    record.DeviceId + "_" + record.Timestamp.UTC().Format(time.RFC3339),
+			}}
+			if err := writeNDJSON(&body, action); err != nil {
+				return err
+			}
+			if err := writeNDJSON(&body, record); err != nil {
+				return err
+			}
+		}
+
+		respBody, err := c.do(http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes())
+		if err != nil {
+			return fmt.Errorf("falha ao indexar os registros %d a %d: %w", start, end-1, err)
+		}
+		var result bulkResponse
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return fmt.Errorf("falha ao ler a resposta do _bulk: %w", err)
+		}
+		if result.Errors {
+			for i, item := range result.Items {
+				for _, status := range item {
+					if status.Status >= 300 {
+						return fmt.Errorf("registro %d rejeitado pelo OpenSearch (HTTP %d): %s", start+i, status.Status, status.Error)
+					}
+				}
+			}
+		}
+	}
+
+	log.Printf("%d registros indexados no OpenSearch com sucesso!", len(data))
+	return nil
+}
+
+func writeNDJSON(buf *bytes.Buffer, v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("falha ao serializar a linha do _bulk: %w", err)
+	}
+	buf.Write(line)
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,96 @@
+package orc
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression é o codec das streams e dos rodapés do arquivo, com o valor do CompressionKind da
+// PostScript.
+type Compression int
+
+const (
+	None   Compression = 0
+	Zlib   Compression = 1
+	Snappy Compression = 2
+	Zstd   Compression = 5
+)
+
+// compressionBlockSize é o tamanho máximo de cada bloco comprimido (256 KiB, o padrão do ORC Java).
+const compressionBlockSize = 256 * 1024
+
+// compressor comprime as streams em blocos de até compressionBlockSize, cada um com um cabeçalho de
+// 3 bytes (tamanho << 1, com o bit menos significativo ligado nos blocos gravados sem compressão,
+// quando a compressão não reduz o tamanho).
+type compressor struct {
+	kind   Compression
+	encode func(dst, src []byte) ([]byte, error)
+}
+
+func newCompressor(kind Compression) (*compressor, error) {
+	c := &compressor{kind: kind}
+	switch kind {
+	case None:
+	case Zlib: // Deflate sem o cabeçalho zlib, como o ORC Java
+		var buf bytes.Buffer
+		w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		c.encode = func(dst, src []byte) ([]byte, error) {
+			buf.Reset()
+			w.Reset(&buf)
+			if _, err := w.Write(src); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			return append(dst, buf.Bytes()...), nil
+		}
+	case Snappy:
+		c.encode = func(dst, src []byte) ([]byte, error) {
+			return append(dst, snappy.Encode(nil, src)...), nil
+		}
+	case Zstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		c.encode = func(dst, src []byte) ([]byte, error) {
+			return encoder.EncodeAll(src, dst), nil
+		}
+	default:
+		return nil, fmt.Errorf("compressão ORC %d não suportada", kind)
+	}
+	return c, nil
+}
+
+// compress retorna a stream como gravada no arquivo.
+func (c *compressor) compress(data []byte) ([]byte, error) {
+	if c.kind == None {
+		return data, nil
+	}
+	var out []byte
+	for start := 0; start < len(data); start += compressionBlockSize {
+		chunk := data[start:min(start+compressionBlockSize, len(data))]
+		header := len(out)
+		out = append(out, 0, 0, 0)
+		var err error
+		if out, err = c.encode(out, chunk); err != nil {
+			return nil, fmt.Errorf("falha ao comprimir a stream ORC: %w", err)
+		}
+		length, original := len(out)-header-3, 0
+		if length >= len(chunk) {
+			out = append(out[:header+3], chunk...)
+			length, original = len(chunk), 1
+		}
+		value := length<<1 | original
+		out[header], out[header+1], out[header+2] = byte(value), byte(value>>8), byte(value>>16)
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+package orc
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Codificações de run length da versão 0.11 do formato (DIRECT), que todos os leitores ORC aceitam.
+// Cada run é um cabeçalho de um byte: de 0 a 127, uma repetição de cabeçalho + 3 valores; negativo,
+// -cabeçalho valores literais.
+
+const (
+	minRepeat  = 3   // Menor repetição codificada como run
+	maxRepeat  = 130 // Maior repetição em um run (127 + minRepeat)
+	maxLiteral = 128 // Maior sequência de literais em um run
+)
+
+// appendByteRLE codifica os bytes com o run length de bytes: um run repete o mesmo byte.
+func appendByteRLE(dst []byte, values []byte) []byte {
+	for i := 0; i < len(values); {
+		run := 1
+		for i+run < len(values) && run < maxRepeat && values[i+run] == values[i] {
+			run++
+		}
+		if run >= minRepeat {
+			dst = append(dst, byte(run-minRepeat), values[i])
+			i += run
+			continue
+		}
+		start := i
+		for i < len(values) && i-start < maxLiteral {
+			if i+2 < len(values) && values[i] == values[i+1] && values[i] == values[i+2] {
+				break
+			}
+			i++
+		}
+		dst = append(dst, byte(-(i - start)))
+		dst = append(dst, values[start:i]...)
+	}
+	return dst
+}
+
+// appendBoolRLE empacota os booleanos em bits, do mais significativo para o menos, e codifica os
+// bytes com appendByteRLE. O último byte é completado com zeros.
+func appendBoolRLE(dst []byte, values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			packed[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return appendByteRLE(dst, packed)
+}
+
+// appendIntRLE codifica os inteiros com o run length de inteiros da versão 1: um run é uma
+// sequência com diferença constante entre -128 e 127, gravada como o primeiro valor e a diferença.
+// Os valores são varints, em zigzag quando signed.
+func appendIntRLE(dst []byte, values []int64, signed bool) []byte {
+	for i := 0; i < len(values); {
+		if run := deltaRun(values[i:]); run >= minRepeat {
+			dst = append(dst, byte(run-minRepeat), byte(int8(values[i+1]-values[i])))
+			dst = appendVarint(dst, values[i], signed)
+			i += run
+			continue
+		}
+		start := i
+		for i < len(values) && i-start < maxLiteral {
+			if deltaRun(values[i:]) >= minRepeat {
+				break
+			}
+			i++
+		}
+		dst = append(dst, byte(-(i - start)))
+		for _, v := range values[start:i] {
+			dst = appendVarint(dst, v, signed)
+		}
+	}
+	return dst
+}
+
+// deltaRun retorna quantos valores do início seguem a diferença entre os dois primeiros, se ela
+// couber em um byte, até maxRepeat.
+func deltaRun(values []int64) int {
+	if len(values) < minRepeat {
+		return 0
+	}
+	delta := values[1] - values[0]
+	if delta < math.MinInt8 || delta > math.MaxInt8 {
+		return 0
+	}
+	run := 2
+	for run < len(values) && run < maxRepeat && values[run]-values[run-1] == delta {
+		run++
+	}
+	return run
+}
+
+func appendVarint(dst []byte, v int64, signed bool) []byte {
+	if signed {
+		return binary.AppendVarint(dst, v) // Zigzag, como o ORC
+	}
+	return binary.AppendUvarint(dst, uint64(v))
+}
+
+// appendDouble grava o valor em IEEE 754 little-endian, sem run length.
+func appendDouble(dst []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint64(dst, math.Float64bits(v))
+}
+
+// encodeNanos codifica os nanossegundos do timestamp como o ORC: os zeros à direita (a partir de
+// dois) saem do valor e vão, menos um, nos 3 bits menos significativos.
+func encodeNanos(nanos int64) int64 {
+	if nanos == 0 {
+		return 0
+	}
+	if nanos%100 != 0 {
+		return nanos << 3
+	}
+	nanos /= 100
+	zeros := int64(1)
+	for nanos%10 == 0 && zeros < 7 {
+		nanos /= 10
+		zeros++
+	}
+	return nanos<<3 | zeros
+}
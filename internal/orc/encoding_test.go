@@ -0,0 +1,54 @@
+package orc
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Os casos seguem os exemplos da especificação ORC v1 (seção Run Length Encoding).
+func TestRunLengthEncoding(t *testing.T) {
+	var sevens, countdown []int64
+	for i := 100; i >= 1; i-- {
+		sevens = append(sevens, 7)
+		countdown = append(countdown, int64(i))
+	}
+
+	tests := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{name: "bytes repetidos", got: appendByteRLE(nil, bytes.Repeat([]byte{0x00}, 100)), want: []byte{0x61, 0x00}},
+		{name: "bytes literais", got: appendByteRLE(nil, []byte{0x44, 0x45}), want: []byte{0xfe, 0x44, 0x45}},
+		{name: "inteiros repetidos", got: appendIntRLE(nil, sevens, false), want: []byte{0x61, 0x00, 0x07}},
+		{name: "inteiros decrescentes", got: appendIntRLE(nil, countdown, false), want: []byte{0x61, 0xff, 0x64}},
+		{name: "inteiros literais", got: appendIntRLE(nil, []int64{2, 3, 6, 7, 11}, false), want: []byte{0xfb, 0x02, 0x03, 0x06, 0x07, 0x0b}},
+		{name: "inteiros com sinal", got: appendIntRLE(nil, []int64{-1, 1}, true), want: []byte{0xfe, 0x01, 0x02}},
+		{name: "booleanos", got: appendBoolRLE(nil, []bool{true, false, true, true, false, false, false, false, true}), want: []byte{0xfe, 0xb0, 0x80}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !bytes.Equal(tt.got, tt.want) {
+				t.Errorf("codificado como % x, esperado % x", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeNanos(t *testing.T) {
+	tests := []struct {
+		nanos int64
+		want  int64
+	}{
+		{nanos: 0, want: 0},
+		{nanos: 123456789, want: 123456789 << 3},
+		{nanos: 1000, want: 1<<3 | 2},
+		{nanos: 500000000, want: 5<<3 | 7},
+		{nanos: 120000000, want: 12<<3 | 6},
+	}
+	for _, tt := range tests {
+		if got := encodeNanos(tt.nanos); got != tt.want {
+			t.Errorf("encodeNanos(%d) = %d, esperado %d", tt.nanos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package orc
+
+import "google.golang.org/protobuf/encoding/protowire"
+
+// Mensagens protobuf de metadados do arquivo (orc_proto.proto), serializadas campo a campo: o
+// escritor só usa uma parte pequena do esquema.
+
+// Tipos de Type.kind.
+const (
+	kindBoolean   = 0
+	kindLong      = 4
+	kindDouble    = 6
+	kindString    = 7
+	kindTimestamp = 9
+	kindList      = 10
+	kindStruct    = 12
+)
+
+// Tipos de Stream.kind.
+const (
+	streamPresent   = 0
+	streamData      = 1
+	streamLength    = 2
+	streamSecondary = 5
+)
+
+// columnEncodingDirect é o ColumnEncoding.kind DIRECT, da codificação de run length versão 1.
+const columnEncodingDirect = 0
+
+// writerVersion é a versão do escritor na PostScript: ORC-135, a partir da qual os leitores usam os
+// nomes reais das colunas e não aplicam correções de bugs dos escritores antigos.
+const writerVersion = 6
+
+func appendUint(b []byte, field protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, field, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendBool(b []byte, field protowire.Number, v bool) []byte {
+	return appendUint(b, field, protowire.EncodeBool(v))
+}
+
+func appendString(b []byte, field protowire.Number, v string) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendMessage(b []byte, field protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendPacked(b []byte, field protowire.Number, values []uint64) []byte {
+	var packed []byte
+	for _, v := range values {
+		packed = protowire.AppendVarint(packed, v)
+	}
+	return appendMessage(b, field, packed)
+}
+
+// streamInfo é uma Stream do rodapé da stripe.
+type streamInfo struct {
+	kind   uint64
+	column int
+	length int
+}
+
+func (s streamInfo) marshal() []byte {
+	b := appendUint(nil, 1, s.kind)
+	b = appendUint(b, 2, uint64(s.column))
+	return appendUint(b, 3, uint64(s.length))
+}
+
+// stripeFooter serializa o StripeFooter: as streams na ordem em que foram gravadas, a codificação
+// DIRECT de cada coluna e o fuso dos timestamps.
+func stripeFooter(streams []streamInfo, columns int) []byte {
+	var b []byte
+	for _, s := range streams {
+		b = appendMessage(b, 1, s.marshal())
+	}
+	encoding := appendUint(nil, 1, columnEncodingDirect)
+	for range columns {
+		b = appendMessage(b, 2, encoding)
+	}
+	return appendString(b, 3, "UTC")
+}
+
+// stripeInfo é uma StripeInformation do rodapé do arquivo.
+type stripeInfo struct {
+	offset       int
+	dataLength   int
+	footerLength int
+	rows         int
+}
+
+func (s stripeInfo) marshal() []byte {
+	b := appendUint(nil, 1, uint64(s.offset))
+	b = appendUint(b, 2, 0) // Sem índices de linhas
+	b = appendUint(b, 3, uint64(s.dataLength))
+	b = appendUint(b, 4, uint64(s.footerLength))
+	return appendUint(b, 5, uint64(s.rows))
+}
+
+// typeInfo é um Type do rodapé do arquivo.
+type typeInfo struct {
+	kind       uint64
+	subtypes   []uint64
+	fieldNames []string
+}
+
+func (t typeInfo) marshal() []byte {
+	b := appendUint(nil, 1, t.kind)
+	if len(t.subtypes) > 0 {
+		b = appendPacked(b, 2, t.subtypes)
+	}
+	for _, name := range t.fieldNames {
+		b = appendString(b, 3, name)
+	}
+	return b
+}
+
+// columnStats é a ColumnStatistics de uma coluna: só a contagem de valores e a presença de nulos.
+type columnStats struct {
+	values  int
+	hasNull bool
+}
+
+func (s columnStats) marshal() []byte {
+	b := appendUint(nil, 1, uint64(s.values))
+	return appendBool(b, 10, s.hasNull)
+}
+
+// fileFooter serializa o Footer do arquivo, sem índices de linhas (rowIndexStride 0).
+func fileFooter(contentLength, rows int, stripes []stripeInfo, types []typeInfo, stats []columnStats) []byte {
+	b := appendUint(nil, 1, uint64(len(magic)))
+	b = appendUint(b, 2, uint64(contentLength))
+	for _, s := range stripes {
+		b = appendMessage(b, 3, s.marshal())
+	}
+	for _, t := range types {
+		b = appendMessage(b, 4, t.marshal())
+	}
+	b = appendUint(b, 6, uint64(rows))
+	for _, s := range stats {
+		b = appendMessage(b, 7, s.marshal())
+	}
+	return appendUint(b, 8, 0)
+}
+
+// postScript serializa a PostScript, sempre sem compressão.
+func postScript(footerLength int, compression Compression) []byte {
+	b := appendUint(nil, 1, uint64(footerLength))
+	b = appendUint(b, 2, uint64(compression))
+	b = appendUint(b, 3, compressionBlockSize)
+	b = appendPacked(b, 4, []uint64{0, 12})
+	b = appendUint(b, 5, 0) // Sem estatísticas por stripe
+	b = appendUint(b, 6, writerVersion)
+	return appendString(b, 8000, magic)
+}
@@ -0,0 +1,317 @@
+// Package orc grava arquivos Apache ORC (https://orc.apache.org/specification/ORCv1/) com os tipos
+// do esquema colunar do gerador: uma struct de colunas boolean, bigint, double, string, timestamp e
+// array<string>, todas anuláveis. As colunas usam a codificação DIRECT (run length versão 1, do
+// formato 0.11), que os leitores de todas as versões aceitam, e o arquivo não tem índices de linhas.
+package orc
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Type é o tipo de uma coluna.
+type Type int
+
+const (
+	Boolean    Type = iota // boolean (bool)
+	Long                   // bigint (int64)
+	Double                 // double (float64)
+	String                 // string (string)
+	Timestamp              // timestamp (time.Time, gravado em UTC)
+	StringList             // array<string> ([]string)
+)
+
+// Field é uma coluna do arquivo.
+type Field struct {
+	Name string
+	Type Type
+}
+
+// Options ajusta a gravação do arquivo.
+type Options struct {
+	Compression Compression
+	StripeSize  int // Tamanho alvo, sem compressão, dos dados de cada stripe (padrão: 64 MiB, o do Hive)
+}
+
+// defaultStripeSize é o tamanho alvo padrão das stripes.
+const defaultStripeSize = 64 * 1024 * 1024
+
+// magic abre o arquivo e fecha a PostScript.
+const magic = "ORC"
+
+// unixToORCEpoch é o início da contagem de segundos dos timestamps ORC, 2015-01-01 00:00:00 UTC.
+const unixToORCEpoch = 1420070400
+
+// Writer grava as linhas em stripes, que vão para o destino à medida que enchem; Close grava a
+// última stripe e os rodapés.
+type Writer struct {
+	dst        io.Writer
+	columns    []*columnWriter
+	types      []typeInfo
+	compressor *compressor
+	stripeSize int
+
+	offset   int // Bytes já gravados no destino
+	rows     int // Linhas da stripe em montagem
+	buffered int // Tamanho estimado, sem compressão, da stripe em montagem
+	total    int // Linhas do arquivo
+	stripes  []stripeInfo
+}
+
+// NewWriter inicia o arquivo em dst com as colunas informadas.
+func NewWriter(dst io.Writer, fields []Field, opts Options) (*Writer, error) {
+	compressor, err := newCompressor(opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{dst: dst, compressor: compressor, stripeSize: opts.StripeSize}
+	if w.stripeSize <= 0 {
+		w.stripeSize = defaultStripeSize
+	}
+
+	root := typeInfo{kind: kindStruct}
+	w.types = append(w.types, root)
+	for _, field := range fields {
+		id := len(w.types)
+		root.subtypes = append(root.subtypes, uint64(id))
+		root.fieldNames = append(root.fieldNames, field.Name)
+		c := &columnWriter{id: id, name: field.Name, typ: field.Type}
+		switch field.Type {
+		case Boolean:
+			w.types = append(w.types, typeInfo{kind: kindBoolean})
+		case Long:
+			w.types = append(w.types, typeInfo{kind: kindLong})
+		case Double:
+			w.types = append(w.types, typeInfo{kind: kindDouble})
+		case String:
+			w.types = append(w.types, typeInfo{kind: kindString})
+		case Timestamp:
+			w.types = append(w.types, typeInfo{kind: kindTimestamp})
+		case StringList:
+			w.types = append(w.types, typeInfo{kind: kindList, subtypes: []uint64{uint64(id + 1)}}, typeInfo{kind: kindString})
+			c.child = &columnWriter{id: id + 1, name: field.Name, typ: String}
+		default:
+			return nil, fmt.Errorf("tipo %d da coluna ORC '%s' desconhecido", field.Type, field.Name)
+		}
+		w.columns = append(w.columns, c)
+	}
+	w.types[0] = root
+
+	if err := w.write([]byte(magic)); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write acrescenta uma linha, com um valor por coluna, na ordem das colunas; nil é nulo.
+func (w *Writer) Write(row []any) error {
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("linha ORC com %d valores, esperado %d", len(row), len(w.columns))
+	}
+	for i, c := range w.columns {
+		size, err := c.add(row[i])
+		if err != nil {
+			return err
+		}
+		w.buffered += size
+	}
+	w.rows++
+	w.total++
+	if w.buffered >= w.stripeSize {
+		return w.flushStripe()
+	}
+	return nil
+}
+
+// Close grava a última stripe, o rodapé e a PostScript. Não fecha o destino.
+func (w *Writer) Close() error {
+	if err := w.flushStripe(); err != nil {
+		return err
+	}
+	stats := []columnStats{{values: w.total}}
+	for _, c := range w.columns {
+		stats = append(stats, c.stats)
+		if c.child != nil {
+			stats = append(stats, c.child.stats)
+		}
+	}
+	footer, err := w.compressor.compress(fileFooter(w.offset, w.total, w.stripes, w.types, stats))
+	if err != nil {
+		return err
+	}
+	tail := postScript(len(footer), w.compressor.kind)
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	return w.write(append(tail, byte(len(tail))))
+}
+
+// flushStripe grava as streams das colunas e o rodapé da stripe em montagem.
+func (w *Writer) flushStripe() error {
+	if w.rows == 0 {
+		return nil
+	}
+	var streams []rawStream
+	for _, c := range w.columns {
+		streams = c.appendStreams(streams)
+	}
+
+	stripe := stripeInfo{offset: w.offset, rows: w.rows}
+	infos := make([]streamInfo, len(streams))
+	for i, s := range streams {
+		data, err := w.compressor.compress(s.data)
+		if err != nil {
+			return err
+		}
+		if err := w.write(data); err != nil {
+			return err
+		}
+		infos[i] = streamInfo{kind: s.kind, column: s.column, length: len(data)}
+		stripe.dataLength += len(data)
+	}
+	footer, err := w.compressor.compress(stripeFooter(infos, len(w.types)))
+	if err != nil {
+		return err
+	}
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	stripe.footerLength = len(footer)
+	w.stripes = append(w.stripes, stripe)
+
+	for _, c := range w.columns {
+		c.reset()
+	}
+	w.rows, w.buffered = 0, 0
+	return nil
+}
+
+func (w *Writer) write(data []byte) error {
+	n, err := w.dst.Write(data)
+	w.offset += n
+	if err != nil {
+		return fmt.Errorf("falha ao gravar o arquivo ORC: %w", err)
+	}
+	return nil
+}
+
+// rawStream é uma stream da stripe antes da compressão.
+type rawStream struct {
+	kind   uint64
+	column int
+	data   []byte
+}
+
+// columnWriter acumula os valores de uma coluna na stripe em montagem.
+type columnWriter struct {
+	id    int
+	name  string
+	typ   Type
+	child *columnWriter // Os elementos de StringList
+
+	present []bool  // Presença de cada linha; vira stream só se a stripe tiver nulos
+	hasNull bool    // A stripe tem nulos
+	bools   []bool  // Boolean
+	ints    []int64 // Long; segundos do Timestamp; tamanhos de String e StringList
+	nanos   []int64 // Nanossegundos codificados do Timestamp
+	data    []byte  // Double em IEEE 754; bytes de String
+
+	stats columnStats // Do arquivo inteiro
+}
+
+// add acrescenta o valor e retorna o tamanho estimado dele, sem compressão.
+func (c *columnWriter) add(value any) (int, error) {
+	c.present = append(c.present, value != nil)
+	if value == nil {
+		c.hasNull, c.stats.hasNull = true, true
+		return 1, nil
+	}
+	size := 8
+	switch v := value.(type) {
+	case bool:
+		if c.typ != Boolean {
+			return 0, c.typeError(value)
+		}
+		c.bools = append(c.bools, v)
+		size = 1
+	case int64:
+		if c.typ != Long {
+			return 0, c.typeError(value)
+		}
+		c.ints = append(c.ints, v)
+	case float64:
+		if c.typ != Double {
+			return 0, c.typeError(value)
+		}
+		c.data = appendDouble(c.data, v)
+	case string:
+		if c.typ != String {
+			return 0, c.typeError(value)
+		}
+		c.ints = append(c.ints, int64(len(v)))
+		c.data = append(c.data, v...)
+		size = len(v) + 1
+	case time.Time:
+		if c.typ != Timestamp {
+			return 0, c.typeError(value)
+		}
+		c.ints = append(c.ints, v.Unix()-unixToORCEpoch)
+		c.nanos = append(c.nanos, encodeNanos(int64(v.Nanosecond())))
+		size = 12
+	case []string:
+		if c.typ != StringList {
+			return 0, c.typeError(value)
+		}
+		c.ints = append(c.ints, int64(len(v)))
+		size = 1
+		for _, s := range v {
+			n, _ := c.child.add(s)
+			size += n
+		}
+	default:
+		return 0, c.typeError(value)
+	}
+	c.stats.values++
+	return size, nil
+}
+
+func (c *columnWriter) typeError(value any) error {
+	return fmt.Errorf("valor %T inválido na coluna ORC '%s'", value, c.name)
+}
+
+// appendStreams acrescenta as streams da coluna (e dos elementos, em StringList) na stripe.
+func (c *columnWriter) appendStreams(dst []rawStream) []rawStream {
+	if c.hasNull {
+		dst = append(dst, rawStream{kind: streamPresent, column: c.id, data: appendBoolRLE(nil, c.present)})
+	}
+	switch c.typ {
+	case Boolean:
+		dst = append(dst, rawStream{kind: streamData, column: c.id, data: appendBoolRLE(nil, c.bools)})
+	case Long:
+		dst = append(dst, rawStream{kind: streamData, column: c.id, data: appendIntRLE(nil, c.ints, true)})
+	case Double:
+		dst = append(dst, rawStream{kind: streamData, column: c.id, data: c.data})
+	case String:
+		dst = append(dst,
+			rawStream{kind: streamData, column: c.id, data: c.data},
+			rawStream{kind: streamLength, column: c.id, data: appendIntRLE(nil, c.ints, false)})
+	case Timestamp:
+		dst = append(dst,
+			rawStream{kind: streamData, column: c.id, data: appendIntRLE(nil, c.ints, true)},
+			rawStream{kind: streamSecondary, column: c.id, data: appendIntRLE(nil, c.nanos, false)})
+	case StringList:
+		dst = append(dst, rawStream{kind: streamLength, column: c.id, data: appendIntRLE(nil, c.ints, false)})
+		dst = c.child.appendStreams(dst)
+	}
+	return dst
+}
+
+// reset esvazia a coluna para a próxima stripe, mantendo as estatísticas do arquivo.
+func (c *columnWriter) reset() {
+	c.present, c.bools, c.ints, c.nanos, c.data = c.present[:0], c.bools[:0], c.ints[:0], c.nanos[:0], c.data[:0]
+	c.hasNull = false
+	if c.child != nil {
+		c.child.reset()
+	}
+}
@@ -0,0 +1,93 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// writeCheckKey é o objeto temporário gravado e apagado para confirmar a permissão de escrita.
+const writeCheckKey = ".mock-data-hvac-write-check"
+
+// expirationRuleID identifica a regra de ciclo de vida aplicada pelo bootstrap.
+const expirationRuleID = "mock-data-hvac-expiration"
+
+// Bootstrap prepara o bucket antes da geração: cria o bucket na região se ele não existir,
+// aplica a expiração dos objetos (em dias, 0 mantém o ciclo de vida atual) e confirma a permissão
+// de escrita gravando e apagando um objeto de teste.
+func (u *Uploader) Bootstrap(expirationDays int32) error {
+	client, bucketName := u.client, u.bucketName
+	ctx := context.TODO()
+
+	_, err := client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucketName)})
+	var notFound *types.NotFound
+	switch {
+	case errors.As(err, &notFound):
+		if err := createBucket(ctx, client, bucketName, u.region); err != nil {
+			return err
+		}
+	case err != nil:
+		return fmt.Errorf("falha ao verificar o bucket '%s': %w", bucketName, err)
+	}
+
+	if expirationDays > 0 {
+		_, err := client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket: aws.String(bucketName),
+			LifecycleConfiguration: &types.BucketLifecycleConfiguration{
+				Rules: []types.LifecycleRule{{
+					ID:         aws.String(expirationRuleID),
+					Status:     types.ExpirationStatusEnabled,
+					Filter:     &types.LifecycleRuleFilter{Prefix: aws.String("")},
+					Expiration: &types.LifecycleExpiration{Days: aws.Int32(expirationDays)},
+				}},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("falha ao aplicar a expiração de %d dias no bucket '%s': %w", expirationDays, bucketName, err)
+		}
+		log.Printf("Objetos do bucket '%s' expiram após %d dias.", bucketName, expirationDays)
+	}
+
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(u.keyPrefix + writeCheckKey),
+		Body:   bytes.NewReader(nil),
+	})
+	if err != nil {
+		return fmt.Errorf("sem permissão de escrita no bucket '%s': %w", bucketName, err)
+	}
+	_, err = client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(u.keyPrefix + writeCheckKey),
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao apagar o objeto de teste do bucket '%s': %w", bucketName, err)
+	}
+	return nil
+}
+
+// createBucket cria o bucket. Fora de us-east-1 o S3 exige a região como LocationConstraint.
+func createBucket(ctx context.Context, client *s3.Client, bucketName, region string) error {
+	input := &s3.CreateBucketInput{Bucket: aws.String(bucketName)}
+	if region != "" && region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(region),
+		}
+	}
+	_, err := client.CreateBucket(ctx, input)
+	var owned *types.BucketAlreadyOwnedByYou
+	if errors.As(err, &owned) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("falha ao criar o bucket '%s' na região '%s': %w", bucketName, region, err)
+	}
+	log.Printf("Bucket '%s' criado na região '%s'.", bucketName, region)
+	return nil
+}
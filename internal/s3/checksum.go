@@ -0,0 +1,56 @@
+package s3
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/climate"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ObjectChecksum identifica um objeto gravado no bucket e o seu conteúdo, para que a ingestão
+// detecte objetos truncados. Os checksums são hexadecimais (o SHA-256 confere com sha256sum).
+type ObjectChecksum struct {
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	CRC32C string `json:"crc32c"`
+}
+
+// Manifest lista os objetos gravados numa execução do gerador.
+type Manifest struct {
+	RunTimestamp string           `json:"runTimestamp"`
+	RunID        string           `json:"runId"` // ID da execução, também gravado nos metadados dos objetos e nas mensagens
+	Bucket       string           `json:"bucket"`
+	Site         *climate.Station `json:"site,omitempty"` // Estação meteorológica dos dados climáticos, se o arquivo a identifica
+	Objects      []ObjectChecksum `json:"objects"`
+}
+
+// WriteManifestJSON serializa o manifesto da execução.
+func WriteManifestJSON(manifest Manifest) ([]byte, error) {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar o manifesto da execução: %w", err)
+	}
+	return data, nil
+}
+
+func checksumFor(key string, data []byte) (ObjectChecksum, []byte) {
+	sum := sha256.Sum256(data)
+	return ObjectChecksum{
+		Key:    key,
+		Size:   int64(len(data)),
+		SHA256: hex.EncodeToString(sum[:]),
+		CRC32C: fmt.Sprintf("%08x", crc32.Checksum(data, castagnoliTable)),
+	}, sum[:]
+}
+
+// base64SHA256 é o formato do cabeçalho x-amz-checksum-sha256, que o S3 confere ao receber o objeto.
+func base64SHA256(sum []byte) string {
+	return base64.StdEncoding.EncodeToString(sum)
+}
@@ -0,0 +1,387 @@
+// Package s3fake é um servidor compatível com S3 em memória, para testar o Uploader sem Docker nem
+// serviços externos. Implementa só o que o gerador usa, com endereçamento path-style: buckets
+// (HeadBucket, CreateBucket e ciclo de vida), objetos (Put, com a escrita condicional de
+// If-None-Match: *, Get, Delete e ListObjectsV2 paginado) e uploads multipart. Os checksums
+// SHA-256 enviados no cabeçalho ou no trailer dos corpos aws-chunked são conferidos como no S3, e
+// as assinaturas são ignoradas.
+package s3fake
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Object é um objeto gravado no servidor.
+type Object struct {
+	Data        []byte
+	ContentType string
+	Metadata    map[string]string // Metadados do usuário (x-amz-meta-*), com as chaves em minúsculas
+}
+
+// Bucket guarda os objetos e a configuração de um bucket.
+type Bucket struct {
+	Objects        map[string]Object
+	LifecycleRules int    // Regras de ciclo de vida aplicadas (0 sem configuração)
+	Lifecycle      string // XML da última configuração de ciclo de vida recebida
+}
+
+type multipartUpload struct {
+	bucket, key string
+	contentType string
+	metadata    map[string]string
+	parts       map[int][]byte
+}
+
+// Server é o S3 em memória. O endereço do endpoint está em URL.
+type Server struct {
+	*httptest.Server
+
+	// MaxKeys limita as chaves por página do ListObjectsV2 (padrão: 1000), para exercitar a paginação.
+	MaxKeys int
+	// Corrupt, se definido, altera o corpo dos objetos cuja chave ele aceita antes da conferência
+	// do checksum, simulando um corpo corrompido no caminho.
+	Corrupt func(key string) bool
+	// BeforePut, se definido, é chamado antes de gravar cada PutObject; os objetos que ele retorna
+	// são gravados primeiro, simulando outra execução que escreveu no bucket ao mesmo tempo.
+	BeforePut func(key string) map[string][]byte
+
+	mu       sync.Mutex
+	buckets  map[string]*Bucket
+	uploads  map[string]*multipartUpload
+	uploadID int
+	requests map[string]int
+}
+
+// NewServer inicia o servidor vazio. Encerre-o com Close.
+func NewServer() *Server {
+	s := &Server{buckets: make(map[string]*Bucket), uploads: make(map[string]*multipartUpload), requests: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Bucket retorna o bucket, ou nil se não existe. O valor retornado não deve ser alterado.
+func (s *Server) Bucket(name string) *Bucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buckets[name]
+}
+
+// Requests conta as requisições recebidas por operação (ex: PutObject, UploadPart).
+func (s *Server) Requests(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[operation]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket := s.buckets[bucketName]
+	if bucket == nil && !(r.Method == http.MethodPut && key == "" && !query.Has("lifecycle")) {
+		s.count("NoSuchBucket")
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		s.count("HeadBucket")
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.Method == http.MethodPut && query.Has("lifecycle"):
+		s.count("PutBucketLifecycleConfiguration")
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		bucket.Lifecycle = string(body)
+		bucket.LifecycleRules = strings.Count(bucket.Lifecycle, "<Rule>")
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.Method == http.MethodPut:
+		s.count("CreateBucket")
+		if bucket != nil {
+			writeError(w, http.StatusConflict, "BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it")
+			return
+		}
+		s.buckets[bucketName] = &Bucket{Objects: make(map[string]Object)}
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.Method == http.MethodGet:
+		s.count("ListObjectsV2")
+		s.listObjects(w, bucketName, bucket, query)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.count("CreateMultipartUpload")
+		s.uploadID++
+		id := strconv.Itoa(s.uploadID)
+		s.uploads[id] = &multipartUpload{bucket: bucketName, key: key, contentType: r.Header.Get("Content-Type"), metadata: userMetadata(r.Header), parts: make(map[int][]byte)}
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucketName, Key: key, UploadId: id})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		s.count("UploadPart")
+		upload := s.uploads[query.Get("uploadId")]
+		part, err := strconv.Atoi(query.Get("partNumber"))
+		if upload == nil || err != nil {
+			writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist")
+			return
+		}
+		data, ok := readBody(w, r, key, s.Corrupt)
+		if !ok {
+			return
+		}
+		upload.parts[part] = data
+		w.Header().Set("ETag", etag(data))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		s.count("CompleteMultipartUpload")
+		upload := s.uploads[query.Get("uploadId")]
+		if upload == nil {
+			writeError(w, http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist")
+			return
+		}
+		numbers := make([]int, 0, len(upload.parts))
+		for number := range upload.parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, number := range numbers {
+			data = append(data, upload.parts[number]...)
+		}
+		bucket.Objects[key] = Object{Data: data, ContentType: upload.contentType, Metadata: upload.metadata}
+		delete(s.uploads, query.Get("uploadId"))
+		writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucketName, Key: key, ETag: etag(data)})
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		s.count("AbortMultipartUpload")
+		delete(s.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.count("PutObject")
+		data, ok := readBody(w, r, key, s.Corrupt)
+		if !ok {
+			return
+		}
+		if s.BeforePut != nil {
+			for competing, data := range s.BeforePut(key) {
+				bucket.Objects[competing] = Object{Data: data}
+			}
+		}
+		if _, exists := bucket.Objects[key]; exists && r.Header.Get("If-None-Match") == "*" {
+			writeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+			return
+		}
+		bucket.Objects[key] = Object{Data: data, ContentType: r.Header.Get("Content-Type"), Metadata: userMetadata(r.Header)}
+		w.Header().Set("ETag", etag(data))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet:
+		s.count("GetObject")
+		object, ok := bucket.Objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", object.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.Data)))
+		w.Header().Set("ETag", etag(object.Data))
+		for name, value := range object.Metadata {
+			w.Header().Set(metadataPrefix+name, value)
+		}
+		_, _ = w.Write(object.Data)
+	case r.Method == http.MethodDelete:
+		s.count("DeleteObject")
+		delete(bucket.Objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", fmt.Sprintf("%s %s não é suportado pelo s3fake", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) count(operation string) {
+	s.requests[operation]++
+}
+
+// listObjects responde ao ListObjectsV2 em ordem lexicográfica, com o token de continuação sendo a
+// última chave da página anterior.
+func (s *Server) listObjects(w http.ResponseWriter, bucketName string, bucket *Bucket, query map[string][]string) {
+	get := func(name string) string {
+		if values := query[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	prefix, after := get("prefix"), get("continuation-token")
+	maxKeys := s.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+
+	var keys []string
+	for key := range bucket.Objects {
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	type content struct {
+		Key  string
+		Size int
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Name                  string
+		Prefix                string
+		KeyCount              int
+		MaxKeys               int
+		IsTruncated           bool
+		Contents              []content
+		NextContinuationToken string `xml:",omitempty"`
+	}{Name: bucketName, Prefix: prefix, MaxKeys: maxKeys}
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, content{Key: key, Size: len(bucket.Objects[key].Data)})
+	}
+	result.KeyCount = len(keys)
+	writeXML(w, result)
+}
+
+// readBody lê o corpo do objeto ou da parte, decodificando o aws-chunked, e confere o checksum
+// SHA-256 informado no cabeçalho ou no trailer. Em caso de erro já responde ao cliente.
+func readBody(w http.ResponseWriter, r *http.Request, key string, corrupt func(string) bool) ([]byte, bool) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return nil, false
+	}
+	data, trailers := raw, map[string]string{}
+	if strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		if data, trailers, err = decodeChunked(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+			return nil, false
+		}
+	}
+	if corrupt != nil && corrupt(key) && len(data) > 0 {
+		data = append([]byte(nil), data...)
+		data[0] ^= 0xff
+	}
+
+	expected := r.Header.Get("X-Amz-Checksum-Sha256")
+	if expected == "" {
+		expected = trailers["x-amz-checksum-sha256"]
+	}
+	if expected != "" {
+		sum := sha256.Sum256(data)
+		if base64.StdEncoding.EncodeToString(sum[:]) != expected {
+			writeError(w, http.StatusBadRequest, "BadDigest", "The SHA256 you specified did not match the calculated checksum.")
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+// decodeChunked decodifica um corpo aws-chunked: blocos "tamanho-hex[;extensões]\r\n dados \r\n",
+// um bloco vazio e os trailers "nome:valor".
+func decodeChunked(raw []byte) ([]byte, map[string]string, error) {
+	reader := bufio.NewReader(bytes.NewReader(raw))
+	var data []byte
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, nil, fmt.Errorf("corpo aws-chunked truncado: %w", err)
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tamanho de bloco aws-chunked inválido '%s'", sizeHex)
+		}
+		if size == 0 {
+			break
+		}
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, nil, fmt.Errorf("bloco aws-chunked truncado: %w", err)
+		}
+		data = append(data, chunk[:size]...)
+	}
+	trailers := make(map[string]string)
+	for {
+		line, err := reader.ReadString('\n')
+		if name, value, found := strings.Cut(strings.TrimSpace(line), ":"); found {
+			trailers[strings.ToLower(name)] = strings.TrimSpace(value)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return data, trailers, nil
+}
+
+// metadataPrefix é o prefixo dos cabeçalhos de metadados do usuário.
+const metadataPrefix = "X-Amz-Meta-"
+
+// userMetadata extrai os metadados do usuário dos cabeçalhos da requisição.
+func userMetadata(header http.Header) map[string]string {
+	var metadata map[string]string
+	for name, values := range header {
+		if !strings.HasPrefix(name, metadataPrefix) || len(values) == 0 {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[strings.ToLower(strings.TrimPrefix(name, metadataPrefix))] = values[0]
+	}
+	return metadata
+}
+
+func etag(data []byte) string {
+	return fmt.Sprintf("\"%x\"", md5.Sum(data))
+}
+
+func writeXML(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: message})
+}
@@ -3,17 +3,292 @@ package s3
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"hash/crc32"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/encryption"
 )
 
-func UploadDataToS3(bucketName, region, awsEndpointURL string, data []byte, key string) error {
-	log.Printf("Iniciando upload de '%s' para o bucket S3 '%s' na região '%s'...", key, bucketName, region)
+// multipartThreshold é o tamanho a partir do qual Put envia o objeto em partes.
+const multipartThreshold = 64 * 1024 * 1024
+
+// multipartPartSize é o tamanho de cada parte dos uploads multipart (16 MiB).
+const multipartPartSize = 16 * 1024 * 1024
+
+// ClientOptions ajusta o cliente para endpoints compatíveis com S3 (MinIO, Ceph RGW). O valor zero
+// mantém o comportamento padrão: endereçamento path-style, TLS verificado e credenciais da cadeia AWS.
+type ClientOptions struct {
+	VirtualHostStyle   bool   // Usa bucket.endpoint em vez de endpoint/bucket
+	InsecureSkipVerify bool   // Não verifica o certificado TLS do endpoint (apenas desenvolvimento)
+	CABundle           string // Arquivo PEM com CAs adicionais para validar o endpoint (ex: CA interna)
+	Anonymous          bool   // Requisições sem assinatura, para buckets públicos
+}
+
+// Uploader grava e lê objetos de um bucket. É criado uma vez por execução e reaproveita a
+// configuração AWS e o cliente HTTP entre as chamadas; pode ser usado por várias goroutines.
+type Uploader struct {
+	bucketName string
+	region     string
+	client     *s3.Client
+	manager    *manager.Uploader
+	metadata   map[string]string
+	keyPrefix  string
+	encryption []byte
+}
+
+// NewUploader carrega a configuração AWS e cria o cliente do bucket.
+func NewUploader(bucketName, region, awsEndpointURL string, opts ClientOptions) (*Uploader, error) {
+	client, err := newClient(region, awsEndpointURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Uploader{
+		bucketName: bucketName,
+		region:     region,
+		client:     client,
+		manager: manager.NewUploader(client, func(u *manager.Uploader) {
+			u.PartSize = multipartPartSize
+		}),
+	}, nil
+}
+
+// SetMetadata define os metadados do usuário (x-amz-meta-*) gravados em todos os objetos enviados
+// depois da chamada, como o ID da execução que os gerou. Deve ser chamado antes dos uploads.
+func (u *Uploader) SetMetadata(metadata map[string]string) {
+	u.metadata = metadata
+}
+
+// SetKeyPrefix define o prefixo acrescentado a todas as chaves (ex: "cliente-a/", o namespace de
+// um tenant). As chaves recebidas e as retornadas por List continuam relativas ao prefixo; as dos
+// checksums do manifesto são as completas, gravadas no bucket. Deve ser chamado antes dos uploads.
+func (u *Uploader) SetKeyPrefix(prefix string) {
+	u.keyPrefix = prefix
+}
 
+// SetEncryptionKey liga a cifragem dos objetos no cliente com a chave AES-256 informada (ver o
+// pacote encryption): Put e PutStream gravam o conteúdo cifrado, com o Content-Type
+// application/octet-stream e o metadado encryption, e Get decifra os objetos cifrados. Os checksums
+// do manifesto são os do conteúdo cifrado, gravado no bucket.
+func (u *Uploader) SetEncryptionKey(key []byte) {
+	u.encryption = key
+}
+
+// objectMetadata retorna os metadados e o Content-Type do objeto.
+func (u *Uploader) objectMetadata(key string) (map[string]string, string) {
+	if u.encryption == nil {
+		return u.metadata, contentTypeFor(key)
+	}
+	metadata := make(map[string]string, len(u.metadata)+1)
+	for name, value := range u.metadata {
+		metadata[name] = value
+	}
+	metadata["encryption"] = encryption.Algorithm
+	return metadata, "application/octet-stream"
+}
+
+// ErrPreconditionFailed é o erro de PutIfAbsent quando o objeto já existe (HTTP 412) ou está
+// sendo gravado por outra requisição condicional (HTTP 409).
+var ErrPreconditionFailed = errors.New("o objeto já existe")
+
+// Put grava o objeto com o checksum SHA-256 no cabeçalho, para que o S3 rejeite um corpo
+// corrompido, e retorna os checksums para o manifesto da execução. Objetos grandes seguem para
+// PutMultipart.
+func (u *Uploader) Put(key string, data []byte) (ObjectChecksum, error) {
+	if len(data) >= multipartThreshold {
+		return u.PutMultipart(key, data)
+	}
+	return u.put(key, data, false)
+}
+
+// PutIfAbsent grava o objeto como Put, só se a chave ainda não existir (If-None-Match: *). Se
+// existir, retorna ErrPreconditionFailed sem alterá-lo: é a escrita exclusiva usada para reservar
+// uma versão do log Delta entre execuções concorrentes. Não usa o upload em partes.
+func (u *Uploader) PutIfAbsent(key string, data []byte) (ObjectChecksum, error) {
+	return u.put(key, data, true)
+}
+
+func (u *Uploader) put(key string, data []byte, ifAbsent bool) (ObjectChecksum, error) {
+	if u.encryption != nil {
+		sealed, err := encryption.Seal(u.encryption, data)
+		if err != nil {
+			return ObjectChecksum{}, fmt.Errorf("falha ao cifrar '%s': %w", key, err)
+		}
+		data = sealed
+	}
+	metadata, contentType := u.objectMetadata(key)
+	key = u.keyPrefix + key
+	log.Printf("Iniciando upload de '%s' para o bucket S3 '%s' na região '%s'...", key, u.bucketName, u.region)
+
+	checksum, sha256Sum := checksumFor(key, data)
+	input := &s3.PutObjectInput{
+		Bucket:         aws.String(u.bucketName),
+		Key:            aws.String(key),
+		Body:           bytes.NewReader(data),
+		ContentType:    aws.String(contentType),
+		Metadata:       metadata,
+		ChecksumSHA256: aws.String(base64SHA256(sha256Sum)),
+	}
+	if ifAbsent {
+		input.IfNoneMatch = aws.String("*")
+	}
+	_, err := u.client.PutObject(context.TODO(), input)
+	var response *awshttp.ResponseError
+	if ifAbsent && errors.As(err, &response) && (response.HTTPStatusCode() == http.StatusPreconditionFailed || response.HTTPStatusCode() == http.StatusConflict) {
+		return ObjectChecksum{}, fmt.Errorf("'%s': %w", key, ErrPreconditionFailed)
+	}
+	if err != nil {
+		return ObjectChecksum{}, fmt.Errorf("falha ao fazer upload para S3: %w", err)
+	}
+
+	log.Printf("Upload de '%s' para S3 concluído com sucesso!", key)
+	return checksum, nil
+}
+
+// PutMultipart grava o objeto em partes enviadas em paralelo, cada uma com o seu checksum SHA-256.
+func (u *Uploader) PutMultipart(key string, data []byte) (ObjectChecksum, error) {
+	return u.PutStream(key, bytes.NewReader(data))
+}
+
+// PutStream grava o conteúdo lido de body sem mantê-lo inteiro em memória: o gerenciador de
+// transferência divide o fluxo em partes, e os checksums do manifesto são calculados durante a leitura.
+func (u *Uploader) PutStream(key string, body io.Reader) (ObjectChecksum, error) {
+	metadata, contentType := u.objectMetadata(key)
+	key = u.keyPrefix + key
+	if u.encryption != nil {
+		sealed := u.sealStream(body)
+		defer sealed.Close()
+		body = sealed
+	}
+	log.Printf("Iniciando upload em partes de '%s' para o bucket S3 '%s' na região '%s'...", key, u.bucketName, u.region)
+
+	sha := sha256.New()
+	crc := crc32.New(castagnoliTable)
+	counter := &countingWriter{}
+	_, err := u.manager.Upload(context.TODO(), &s3.PutObjectInput{
+		Bucket:            aws.String(u.bucketName),
+		Key:               aws.String(key),
+		Body:              io.TeeReader(body, io.MultiWriter(sha, crc, counter)),
+		ContentType:       aws.String(contentType),
+		Metadata:          metadata,
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+	})
+	if err != nil {
+		return ObjectChecksum{}, fmt.Errorf("falha ao fazer upload em partes para S3: %w", err)
+	}
+
+	log.Printf("Upload de '%s' para S3 concluído com sucesso!", key)
+	return ObjectChecksum{
+		Key:    key,
+		Size:   counter.n,
+		SHA256: fmt.Sprintf("%x", sha.Sum(nil)),
+		CRC32C: fmt.Sprintf("%08x", crc.Sum32()),
+	}, nil
+}
+
+// List lista as chaves do bucket sob o prefixo, em ordem lexicográfica e sem o prefixo de SetKeyPrefix.
+func (u *Uploader) List(prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(u.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(u.bucketName),
+		Prefix: aws.String(u.keyPrefix + prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("falha ao listar objetos em '%s' no S3: %w", prefix, err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, strings.TrimPrefix(aws.ToString(object.Key), u.keyPrefix))
+		}
+	}
+	return keys, nil
+}
+
+// Get lê o conteúdo de um objeto do bucket.
+func (u *Uploader) Get(key string) ([]byte, error) {
+	output, err := u.client.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(u.bucketName),
+		Key:    aws.String(u.keyPrefix + key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("falha ao baixar '%s' do S3: %w", key, err)
+	}
+	defer output.Body.Close()
+
+	data, err := io.ReadAll(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler '%s' do S3: %w", key, err)
+	}
+	if encryption.IsEncrypted(data) {
+		if u.encryption == nil {
+			return nil, fmt.Errorf("o objeto '%s' está cifrado e nenhuma chave de cifragem foi configurada", key)
+		}
+		data, err = encryption.Open(u.encryption, data)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao decifrar '%s': %w", key, err)
+		}
+	}
+	return data, nil
+}
+
+// Delete apaga o objeto do bucket. Apagar uma chave inexistente não é erro.
+func (u *Uploader) Delete(key string) error {
+	_, err := u.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket: aws.String(u.bucketName),
+		Key:    aws.String(u.keyPrefix + key),
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao apagar '%s' do S3: %w", key, err)
+	}
+	return nil
+}
+
+// sealStream cifra o conteúdo lido de body em uma goroutine, entregando-o pelo pipe retornado; os
+// erros da cifragem chegam na leitura do pipe. Se o upload falhar antes do fim, fechar o pipe
+// encerra a goroutine.
+func (u *Uploader) sealStream(body io.Reader) *io.PipeReader {
+	pr, pw := io.Pipe()
+	go func() {
+		writer, err := encryption.NewWriter(pw, u.encryption)
+		if err == nil {
+			_, err = io.Copy(writer, body)
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// countingWriter conta os bytes que passam pelo upload em fluxo.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+func newClient(region, awsEndpointURL string, clientOpts ClientOptions) (*s3.Client, error) {
 	opts := []func(*config.LoadOptions) error{
 		config.WithRegion(region),
 	}
@@ -23,27 +298,74 @@ func UploadDataToS3(bucketName, region, awsEndpointURL string, data []byte, key
 		opts = append(opts, config.WithBaseEndpoint(awsEndpointURL))
 	}
 
+	if clientOpts.InsecureSkipVerify || clientOpts.CABundle != "" {
+		tlsConfig, err := tlsConfigFor(clientOpts)
+		if err != nil {
+			return nil, err
+		}
+		httpClient := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+			tr.TLSClientConfig = tlsConfig
+		})
+		opts = append(opts, config.WithHTTPClient(httpClient))
+	}
+
+	if clientOpts.Anonymous {
+		opts = append(opts, config.WithCredentialsProvider(aws.AnonymousCredentials{}))
+	}
+
 	cfg, err := config.LoadDefaultConfig(context.TODO(), opts...)
 	if err != nil {
-		return fmt.Errorf("falha ao carregar a configuração AWS: %w", err)
+		return nil, fmt.Errorf("falha ao carregar a configuração AWS: %w", err)
 	}
 
-	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.UsePathStyle = true
-	})
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = !clientOpts.VirtualHostStyle
+	}), nil
+}
 
-	putObjectInput := &s3.PutObjectInput{
-		Bucket:      aws.String(bucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String("application/json"),
+// tlsConfigFor monta a configuração TLS do endpoint: as CAs do sistema mais as do bundle informado,
+// ou nenhuma verificação quando InsecureSkipVerify está ligado.
+func tlsConfigFor(clientOpts ClientOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if clientOpts.InsecureSkipVerify {
+		log.Println("Aviso: verificação TLS do endpoint S3 desativada. Use apenas em desenvolvimento.")
+		tlsConfig.InsecureSkipVerify = true
 	}
-
-	_, err = client.PutObject(context.TODO(), putObjectInput)
-	if err != nil {
-		return fmt.Errorf("falha ao fazer upload para S3: %w", err)
+	if clientOpts.CABundle != "" {
+		pem, err := os.ReadFile(clientOpts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao ler o bundle de CAs '%s': %w", clientOpts.CABundle, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("nenhum certificado PEM válido no bundle de CAs '%s'", clientOpts.CABundle)
+		}
+		tlsConfig.RootCAs = pool
 	}
+	return tlsConfig, nil
+}
 
-	log.Printf("Upload de '%s' para S3 concluído com sucesso!", key)
-	return nil
+// contentTypeFor deduz o Content-Type do objeto pela extensão da chave.
+func contentTypeFor(key string) string {
+	switch path.Ext(key) {
+	case ".csv":
+		return "text/csv"
+	case ".xml":
+		return "application/atom+xml"
+	case ".arrow":
+		return "application/vnd.apache.arrow.file"
+	case ".orc":
+		return "application/vnd.apache.orc"
+	case ".parquet":
+		return "application/vnd.apache.parquet"
+	case ".sqlite":
+		return "application/vnd.sqlite3"
+	case ".npz":
+		return "application/zip"
+	default:
+		return "application/json"
+	}
 }
@@ -0,0 +1,317 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/encryption"
+	"github.com/patrik-rangel/mock-data-hvac/internal/s3/s3fake"
+)
+
+// testBackend aponta os testes para o S3 em memória ou, com S3_TEST_ENDPOINT definido (ex:
+// http://localhost:4566 do LocalStack, ou um MinIO), para um endpoint real com as credenciais do
+// ambiente. Os testes que dependem do fake (corrupção e contagem de requisições) são pulados no
+// endpoint real.
+type testBackend struct {
+	endpoint string
+	fake     *s3fake.Server
+}
+
+func newBackend(t *testing.T) testBackend {
+	t.Helper()
+	if endpoint := os.Getenv("S3_TEST_ENDPOINT"); endpoint != "" {
+		return testBackend{endpoint: endpoint}
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", os.DevNull)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", os.DevNull)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	fake := s3fake.NewServer()
+	t.Cleanup(fake.Close)
+	return testBackend{endpoint: fake.URL, fake: fake}
+}
+
+func (b testBackend) requireFake(t *testing.T) *s3fake.Server {
+	t.Helper()
+	if b.fake == nil {
+		t.Skip("teste específico do S3 em memória (S3_TEST_ENDPOINT definido)")
+	}
+	return b.fake
+}
+
+// newTestUploader cria o Uploader de um bucket novo, preparado pelo Bootstrap.
+func newTestUploader(t *testing.T, backend testBackend) *Uploader {
+	t.Helper()
+	bucket := fmt.Sprintf("hvac-test-%d", time.Now().UnixNano())
+	uploader, err := NewUploader(bucket, "us-east-1", backend.endpoint, ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewUploader: %v", err)
+	}
+	if err := uploader.Bootstrap(0); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	return uploader
+}
+
+func TestBootstrapCreatesBucket(t *testing.T) {
+	backend := newBackend(t)
+	fake := backend.requireFake(t)
+	uploader, err := NewUploader("hvac-bootstrap", "sa-east-1", backend.endpoint, ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewUploader: %v", err)
+	}
+
+	for run := range 2 { // A segunda execução encontra o bucket pronto
+		if err := uploader.Bootstrap(7); err != nil {
+			t.Fatalf("Bootstrap (execução %d): %v", run+1, err)
+		}
+	}
+	bucket := fake.Bucket("hvac-bootstrap")
+	if bucket == nil {
+		t.Fatal("bucket não foi criado")
+	}
+	if got := fake.Requests("CreateBucket"); got != 1 {
+		t.Errorf("CreateBucket chamado %d vezes, esperado 1", got)
+	}
+	if bucket.LifecycleRules != 1 || !strings.Contains(bucket.Lifecycle, "<Days>7</Days>") {
+		t.Errorf("ciclo de vida inesperado: %s", bucket.Lifecycle)
+	}
+	if _, ok := bucket.Objects[writeCheckKey]; ok {
+		t.Errorf("objeto de teste de escrita '%s' não foi apagado", writeCheckKey)
+	}
+}
+
+func TestPutRoundTrip(t *testing.T) {
+	backend := newBackend(t)
+	uploader := newTestUploader(t, backend)
+
+	objects := map[string][]byte{
+		"hvac_mock_data_A701.json": []byte(`[{"deviceId":"SALA-1"}]`),
+		"trends/SALA-1.csv":        []byte("timestamp,value\n2024-01-01T00:00:00Z,22.5\n"),
+		"vazio.json":               {},
+	}
+	for key, data := range objects {
+		checksum, err := uploader.Put(key, data)
+		if err != nil {
+			t.Fatalf("Put(%s): %v", key, err)
+		}
+		if want := fmt.Sprintf("%x", sha256.Sum256(data)); checksum.SHA256 != want || checksum.Size != int64(len(data)) {
+			t.Errorf("Put(%s): checksum %+v, esperado SHA-256 %s e %d bytes", key, checksum, want, len(data))
+		}
+		got, err := uploader.Get(key)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", key, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("Get(%s) = %q, esperado %q", key, got, data)
+		}
+	}
+	if backend.fake != nil {
+		bucket := backend.fake.Bucket(uploader.bucketName)
+		if got := bucket.Objects["trends/SALA-1.csv"].ContentType; got != "text/csv" {
+			t.Errorf("Content-Type do CSV = %q, esperado text/csv", got)
+		}
+	}
+}
+
+func TestPutRejectsCorruptedBody(t *testing.T) {
+	backend := newBackend(t)
+	fake := backend.requireFake(t)
+	uploader := newTestUploader(t, backend)
+	fake.Corrupt = func(key string) bool { return key == "corrompido.json" }
+
+	if _, err := uploader.Put("corrompido.json", []byte(`{"deviceId":"SALA-1"}`)); err == nil || !strings.Contains(err.Error(), "BadDigest") {
+		t.Fatalf("Put com corpo corrompido: erro %v, esperado BadDigest", err)
+	}
+	if _, ok := fake.Bucket(uploader.bucketName).Objects["corrompido.json"]; ok {
+		t.Error("objeto corrompido foi gravado")
+	}
+}
+
+func TestPutStreamMultipart(t *testing.T) {
+	backend := newBackend(t)
+	uploader := newTestUploader(t, backend)
+
+	data := make([]byte, multipartPartSize*2+1024) // Três partes, a última pequena
+	rand.New(rand.NewSource(1)).Read(data)
+	checksum, err := uploader.PutStream("grande.arrow", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("PutStream: %v", err)
+	}
+	if want := fmt.Sprintf("%x", sha256.Sum256(data)); checksum.SHA256 != want || checksum.Size != int64(len(data)) {
+		t.Errorf("checksum %+v, esperado SHA-256 %s e %d bytes", checksum, want, len(data))
+	}
+	got, err := uploader.Get("grande.arrow")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("objeto lido difere do enviado (%d bytes lidos, %d enviados)", len(got), len(data))
+	}
+	if backend.fake != nil {
+		if parts := backend.fake.Requests("UploadPart"); parts != 3 {
+			t.Errorf("%d partes enviadas, esperadas 3", parts)
+		}
+	}
+}
+
+func TestPutMetadata(t *testing.T) {
+	backend := newBackend(t)
+	fake := backend.requireFake(t)
+	uploader := newTestUploader(t, backend)
+	uploader.SetMetadata(map[string]string{"run-id": "execucao-1"})
+
+	if _, err := uploader.Put("pequeno.json", []byte("[]")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := uploader.PutStream("grande.json", bytes.NewReader(make([]byte, multipartPartSize+1))); err != nil {
+		t.Fatalf("PutStream: %v", err)
+	}
+	for _, key := range []string{"pequeno.json", "grande.json"} {
+		if got := fake.Bucket(uploader.bucketName).Objects[key].Metadata["run-id"]; got != "execucao-1" {
+			t.Errorf("metadado run-id de '%s' = %q, esperado execucao-1", key, got)
+		}
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	backend := newBackend(t)
+	uploader := newTestUploader(t, backend)
+	uploader.SetKeyPrefix("cliente-a/")
+
+	checksum, err := uploader.Put("delta/_delta_log/00000000000000000000.json", []byte("{}"))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if checksum.Key != "cliente-a/delta/_delta_log/00000000000000000000.json" {
+		t.Errorf("chave do checksum = %s, esperada a chave completa no bucket", checksum.Key)
+	}
+	keys, err := uploader.List("delta/_delta_log/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "delta/_delta_log/00000000000000000000.json" {
+		t.Fatalf("List = %v, esperadas as chaves sem o prefixo", keys)
+	}
+	if _, err := uploader.Get(keys[0]); err != nil {
+		t.Errorf("Get da chave listada: %v", err)
+	}
+}
+
+func TestEncryptedRoundTrip(t *testing.T) {
+	backend := newBackend(t)
+	uploader := newTestUploader(t, backend)
+	key := bytes.Repeat([]byte{7}, encryption.KeySize)
+	uploader.SetEncryptionKey(key)
+
+	small := []byte(`[{"deviceId":"SALA-1"}]`)
+	large := make([]byte, multipartPartSize+1)
+	rand.New(rand.NewSource(2)).Read(large)
+	if _, err := uploader.Put("pequeno.json", small); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	checksum, err := uploader.PutStream("grande.json", bytes.NewReader(large))
+	if err != nil {
+		t.Fatalf("PutStream: %v", err)
+	}
+
+	for objectKey, want := range map[string][]byte{"pequeno.json": small, "grande.json": large} {
+		got, err := uploader.Get(objectKey)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", objectKey, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Get(%s) não retornou o conteúdo original", objectKey)
+		}
+	}
+	if backend.fake != nil {
+		stored := backend.fake.Bucket(uploader.bucketName).Objects["grande.json"]
+		if !encryption.IsEncrypted(stored.Data) || stored.Metadata["encryption"] != encryption.Algorithm {
+			t.Errorf("objeto gravado sem cifragem (metadados %v)", stored.Metadata)
+		}
+		if want := fmt.Sprintf("%x", sha256.Sum256(stored.Data)); checksum.SHA256 != want {
+			t.Errorf("checksum %s, esperado o do conteúdo cifrado %s", checksum.SHA256, want)
+		}
+	}
+
+	plain, err := NewUploader(uploader.bucketName, "us-east-1", backend.endpoint, ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewUploader: %v", err)
+	}
+	if _, err := plain.Get("pequeno.json"); err == nil || !strings.Contains(err.Error(), "cifrado") {
+		t.Errorf("Get sem chave: erro %v, esperado objeto cifrado", err)
+	}
+}
+
+func TestListPaginates(t *testing.T) {
+	backend := newBackend(t)
+	uploader := newTestUploader(t, backend)
+	if backend.fake != nil {
+		backend.fake.MaxKeys = 2
+	}
+
+	var want []string
+	for i := 5; i >= 1; i-- {
+		key := fmt.Sprintf("delta/_delta_log/%020d.json", i)
+		want = append([]string{key}, want...)
+		if _, err := uploader.Put(key, []byte("{}")); err != nil {
+			t.Fatalf("Put(%s): %v", key, err)
+		}
+	}
+	if _, err := uploader.Put("delta/part-0.parquet", []byte("PAR1")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	keys, err := uploader.List("delta/_delta_log/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("List = %v, esperado %v", keys, want)
+	}
+	if backend.fake != nil && backend.fake.Requests("ListObjectsV2") != 3 {
+		t.Errorf("%d páginas listadas, esperadas 3", backend.fake.Requests("ListObjectsV2"))
+	}
+}
+
+func TestGetMissingKey(t *testing.T) {
+	backend := newBackend(t)
+	uploader := newTestUploader(t, backend)
+
+	if _, err := uploader.Get("nao-existe.json"); err == nil || !strings.Contains(err.Error(), "NoSuchKey") {
+		t.Fatalf("Get de chave inexistente: erro %v, esperado NoSuchKey", err)
+	}
+}
+
+func TestPutIfAbsent(t *testing.T) {
+	backend := newBackend(t)
+	uploader := newTestUploader(t, backend)
+	key := "delta/_delta_log/00000000000000000000.json"
+
+	if _, err := uploader.PutIfAbsent(key, []byte(`{"commitInfo":{"n":1}}`)); err != nil {
+		t.Fatalf("PutIfAbsent da chave nova: %v", err)
+	}
+	if _, err := uploader.PutIfAbsent(key, []byte(`{"commitInfo":{"n":2}}`)); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("PutIfAbsent da chave existente: erro %v, esperado ErrPreconditionFailed", err)
+	}
+	if got, err := uploader.Get(key); err != nil || string(got) != `{"commitInfo":{"n":1}}` {
+		t.Errorf("Get = %q, %v; o primeiro commit deveria ter sido mantido", got, err)
+	}
+
+	if err := uploader.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := uploader.PutIfAbsent(key, []byte("{}")); err != nil {
+		t.Errorf("PutIfAbsent depois do Delete: %v", err)
+	}
+}
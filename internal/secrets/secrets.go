@@ -0,0 +1,143 @@
+// Package secrets resolve as referências a segredos do AWS Secrets Manager e do SSM Parameter
+// Store usadas no lugar das credenciais nas variáveis de execução, para que senhas e chaves não
+// fiquem no ambiente do processo em ambientes compartilhados:
+//
+//	secretsmanager:<id ou ARN>           valor do segredo (SecretString)
+//	secretsmanager:<id ou ARN>#<campo>   campo do segredo em JSON (ex: o password de um segredo do RDS)
+//	ssm:<nome do parâmetro>              valor do parâmetro, decifrado se for SecureString
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+const (
+	secretsManagerPrefix = "secretsmanager:"
+	ssmPrefix            = "ssm:"
+)
+
+// IsReference indica se o valor é uma referência a um segredo.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, secretsManagerPrefix) || strings.HasPrefix(value, ssmPrefix)
+}
+
+// Resolver lê os segredos referenciados, criando os clientes na primeira referência e guardando
+// cada valor lido, para que a mesma referência em várias variáveis seja lida uma vez.
+type Resolver struct {
+	region         string
+	awsEndpointURL string
+	cfg            *aws.Config
+	secretsManager *secretsmanager.Client
+	ssm            *ssm.Client
+	cache          map[string]string
+}
+
+// NewResolver cria o Resolver na região informada. awsEndpointURL, se informado, substitui os
+// endpoints do Secrets Manager e do SSM (ex: LocalStack).
+func NewResolver(region, awsEndpointURL string) *Resolver {
+	return &Resolver{region: region, awsEndpointURL: awsEndpointURL, cache: make(map[string]string)}
+}
+
+// Resolve retorna o valor do segredo referenciado. Valores que não são referências voltam como estão.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	if resolved, ok := r.cache[value]; ok {
+		return resolved, nil
+	}
+	if err := r.loadConfig(ctx); err != nil {
+		return "", err
+	}
+
+	var resolved string
+	var err error
+	if name, ok := strings.CutPrefix(value, ssmPrefix); ok {
+		resolved, err = r.parameter(ctx, name)
+	} else {
+		id, field, _ := strings.Cut(strings.TrimPrefix(value, secretsManagerPrefix), "#")
+		resolved, err = r.secret(ctx, id, field)
+	}
+	if err != nil {
+		return "", err
+	}
+	r.cache[value] = resolved
+	return resolved, nil
+}
+
+func (r *Resolver) loadConfig(ctx context.Context) error {
+	if r.cfg != nil {
+		return nil
+	}
+	opts := []func(*config.LoadOptions) error{
+		config.WithRegion(r.region),
+	}
+	if r.awsEndpointURL != "" {
+		log.Printf("Usando endpoint customizado para os segredos: %s\n", r.awsEndpointURL)
+		opts = append(opts, config.WithBaseEndpoint(r.awsEndpointURL))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("falha ao carregar a configuração AWS: %w", err)
+	}
+	r.cfg = &cfg
+	r.secretsManager = secretsmanager.NewFromConfig(cfg)
+	r.ssm = ssm.NewFromConfig(cfg)
+	return nil
+}
+
+// secret lê o segredo do Secrets Manager e, com field, o campo do segredo em JSON.
+func (r *Resolver) secret(ctx context.Context, id, field string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("referência ao Secrets Manager sem o id do segredo")
+	}
+	output, err := r.secretsManager.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", fmt.Errorf("falha ao ler o segredo '%s' do Secrets Manager: %w", id, err)
+	}
+	value := aws.ToString(output.SecretString)
+	if output.SecretString == nil {
+		value = string(output.SecretBinary)
+	}
+	if field == "" {
+		return value, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("o segredo '%s' não é um objeto JSON, e a referência pede o campo '%s'", id, field)
+	}
+	switch v := fields[field].(type) {
+	case nil:
+		return "", fmt.Errorf("campo '%s' ausente no segredo '%s'", field, id)
+	case string:
+		return v, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("falha ao ler o campo '%s' do segredo '%s': %w", field, id, err)
+		}
+		return string(encoded), nil
+	}
+}
+
+// parameter lê o parâmetro do SSM, decifrando os SecureString.
+func (r *Resolver) parameter(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("referência ao SSM sem o nome do parâmetro")
+	}
+	output, err := r.ssm.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(name), WithDecryption: aws.Bool(true)})
+	if err != nil {
+		return "", fmt.Errorf("falha ao ler o parâmetro '%s' do SSM: %w", name, err)
+	}
+	return aws.ToString(output.Parameter.Value), nil
+}
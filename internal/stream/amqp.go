@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// PublishAMQP publica as mensagens no exchange com routing key <site>.<zona>.<dispositivo> (ou
+// <site>.<zona>.<gateway> nos lotes), para que consumidores filtrem por site, zona ou dispositivo
+// com bindings como a701.Zona-A.* ou a701.#. O canal usa publisher confirms: cada mensagem só
+// conta como publicada depois da confirmação do broker.
+func PublishAMQP(amqpURL string, cfg Config, messages []hvac.StreamMessage) error {
+	cfg = cfg.withDefaults()
+	conn, err := amqp.Dial(amqpURL)
+	if err != nil {
+		return fmt.Errorf("falha ao conectar ao broker AMQP: %w", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("falha ao abrir o canal AMQP: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(cfg.AMQP.Exchange, cfg.AMQP.ExchangeType, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("falha ao declarar o exchange '%s': %w", cfg.AMQP.Exchange, err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		return fmt.Errorf("falha ao ativar os publisher confirms: %w", err)
+	}
+
+	log.Printf("Publicando %d mensagens no exchange AMQP '%s'...", len(messages), cfg.AMQP.Exchange)
+	ctx := context.Background()
+	p := newPacer(cfg.RatePerSecond)
+	var headers amqp.Table
+	if cfg.RunID != "" {
+		headers = amqp.Table{runIDHeader: cfg.RunID}
+	}
+	confirms := make([]*amqp.DeferredConfirmation, 0, len(messages))
+	for _, message := range messages {
+		p.wait()
+		confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, cfg.AMQP.Exchange, routingKey(cfg, message), false, false, amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+			Headers:      headers,
+			Body:         message.Payload,
+		})
+		if err != nil {
+			return fmt.Errorf("falha ao publicar no exchange '%s': %w", cfg.AMQP.Exchange, err)
+		}
+		confirms = append(confirms, confirm)
+	}
+
+	nacked := 0
+	for _, confirm := range confirms {
+		ok, err := confirm.WaitContext(ctx)
+		if err != nil {
+			return fmt.Errorf("falha ao aguardar a confirmação do broker: %w", err)
+		}
+		if !ok {
+			nacked++
+		}
+	}
+	if nacked > 0 {
+		return fmt.Errorf("%d mensagens rejeitadas pelo broker AMQP", nacked)
+	}
+
+	log.Printf("%d mensagens publicadas no AMQP com sucesso!", len(messages))
+	return nil
+}
+
+// routingKey monta <site>.<zona>.<chave>, precedido por <tenant>. quando há tenant, trocando
+// pontos e curingas de cada parte.
+func routingKey(cfg Config, message hvac.StreamMessage) string {
+	parts := []string{cfg.AMQP.Site, message.Zone, message.Key}
+	if cfg.Tenant != "" {
+		parts = append([]string{cfg.Tenant}, parts...)
+	}
+	for i, part := range parts {
+		parts[i] = subjectToken(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,79 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// natsAckTimeout é o tempo máximo de espera pelas confirmações de um lote.
+const natsAckTimeout = time.Minute
+
+// PublishNATS publica as mensagens no JetStream em <subject>.<chave>, criando ou atualizando o
+// stream para capturar <subject>.>. As publicações são assíncronas, em lotes de cfg.Batch: ao fim
+// de cada lote, aguarda as confirmações (acks) do servidor, por até natsAckTimeout, antes de
+// seguir para o próximo.
+func PublishNATS(natsURL string, cfg Config, messages []hvac.StreamMessage) error {
+	cfg = cfg.withDefaults()
+	nc, err := nats.Connect(natsURL)
+	if err != nil {
+		return fmt.Errorf("falha ao conectar ao NATS: %w", err)
+	}
+	defer nc.Close()
+
+	var failed atomic.Int64
+	js, err := jetstream.New(nc,
+		jetstream.WithPublishAsyncMaxPending(cfg.Batch),
+		jetstream.WithPublishAsyncErrHandler(func(_ jetstream.JetStream, msg *nats.Msg, err error) {
+			if failed.Add(1) == 1 {
+				log.Printf("Publicação em '%s' rejeitada pelo JetStream: %v", msg.Subject, err)
+			}
+		}))
+	if err != nil {
+		return fmt.Errorf("falha ao abrir o contexto JetStream: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     cfg.NATS.Stream,
+		Subjects: []string{cfg.NATS.Subject + ".>"},
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao criar o stream JetStream '%s': %w", cfg.NATS.Stream, err)
+	}
+
+	log.Printf("Publicando %d mensagens no JetStream '%s' (%s.>)...", len(messages), cfg.NATS.Stream, cfg.NATS.Subject)
+	p := newPacer(cfg.RatePerSecond)
+	for i, message := range messages {
+		p.wait()
+		msg := &nats.Msg{Subject: cfg.NATS.Subject + "." + subjectToken(message.Key), Data: message.Payload}
+		if cfg.RunID != "" {
+			msg.Header = nats.Header{runIDHeader: []string{cfg.RunID}}
+		}
+		if _, err := js.PublishMsgAsync(msg); err != nil {
+			return fmt.Errorf("falha ao publicar no JetStream: %w", err)
+		}
+		if (i+1)%cfg.Batch != 0 && i != len(messages)-1 {
+			continue
+		}
+		select {
+		case <-js.PublishAsyncComplete():
+		case <-time.After(natsAckTimeout):
+			return fmt.Errorf("tempo esgotado aguardando as confirmações do JetStream (%d pendentes)", js.PublishAsyncPending())
+		}
+		if n := failed.Load(); n > 0 {
+			return fmt.Errorf("%d mensagens rejeitadas pelo JetStream", n)
+		}
+	}
+
+	log.Printf("%d mensagens publicadas no JetStream com sucesso!", len(messages))
+	return nil
+}
@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// PublishPulsar publica os registros no tópico Pulsar, com o deviceId como chave da mensagem
+// (em tópicos particionados, cada dispositivo fica sempre na mesma partição). Com o esquema bytes
+// publica as mensagens renderizadas (dialetos, envelope e lotes); com json ou avro, registra o
+// esquema no broker e publica o registro achatado, no mesmo esquema dos formatos colunares.
+func PublishPulsar(pulsarURL string, cfg Config, renderer *hvac.PayloadRenderer, data []hvac.HvacSensorData) error {
+	cfg = cfg.withDefaults()
+
+	var schema pulsar.Schema
+	var records []map[string]any
+	switch cfg.Pulsar.Schema {
+	case "bytes":
+	case "json", "avro":
+		definition, flat, err := hvac.FlatRecords(data)
+		if err != nil {
+			return err
+		}
+		records = flat
+		if cfg.Pulsar.Schema == "json" {
+			schema, err = pulsar.NewJSONSchemaWithValidation(definition, nil)
+			for _, record := range records {
+				record["timestamp"] = record["timestamp"].(time.Time).UnixMilli()
+			}
+		} else {
+			schema, err = pulsar.NewAvroSchemaWithValidation(definition, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("falha ao criar o esquema %s do Pulsar: %w", cfg.Pulsar.Schema, err)
+		}
+	default:
+		return fmt.Errorf("esquema Pulsar '%s' desconhecido (use bytes, json ou avro)", cfg.Pulsar.Schema)
+	}
+
+	var properties map[string]string
+	if cfg.RunID != "" {
+		properties = map[string]string{runIDHeader: cfg.RunID}
+	}
+	var messages []*pulsar.ProducerMessage
+	if records != nil {
+		for i, record := range records {
+			messages = append(messages, &pulsar.ProducerMessage{Key: data[i].DeviceId, Value: record, EventTime: data[i].Timestamp, Properties: properties})
+		}
+	} else {
+		rendered, err := renderer.StreamMessages(data)
+		if err != nil {
+			return err
+		}
+		for _, message := range rendered {
+			messages = append(messages, &pulsar.ProducerMessage{Key: message.Key, Payload: message.Payload, Properties: properties})
+		}
+	}
+
+	client, err := pulsar.NewClient(pulsar.ClientOptions{URL: pulsarURL})
+	if err != nil {
+		return fmt.Errorf("falha ao conectar ao Pulsar: %w", err)
+	}
+	defer client.Close()
+
+	producer, err := client.CreateProducer(pulsar.ProducerOptions{
+		Topic:  cfg.Pulsar.Topic,
+		Schema: schema,
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao criar o producer do tópico '%s': %w", cfg.Pulsar.Topic, err)
+	}
+	defer producer.Close()
+
+	log.Printf("Publicando %d mensagens no tópico Pulsar '%s' (esquema %s)...", len(messages), cfg.Pulsar.Topic, cfg.Pulsar.Schema)
+	ctx := context.Background()
+	p := newPacer(cfg.RatePerSecond)
+	var mu sync.Mutex
+	var sendErr error
+	for _, message := range messages {
+		p.wait()
+		producer.SendAsync(ctx, message, func(_ pulsar.MessageID, _ *pulsar.ProducerMessage, err error) {
+			if err != nil {
+				mu.Lock()
+				if sendErr == nil {
+					sendErr = err
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	if err := producer.Flush(); err != nil {
+		return fmt.Errorf("falha ao confirmar as mensagens no Pulsar: %w", err)
+	}
+	if sendErr != nil {
+		return fmt.Errorf("falha ao publicar no Pulsar: %w", sendErr)
+	}
+
+	log.Printf("%d mensagens publicadas no Pulsar com sucesso!", len(messages))
+	return nil
+}
@@ -0,0 +1,54 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/patrik-rangel/mock-data-hvac/internal/hvac"
+)
+
+// PublishRedis adiciona as mensagens ao Redis Stream com XADD, com os campos key e payload (e
+// run-id, se o ID da execução estiver definido), enviando cfg.Batch XADD por pipeline (um por vez
+// quando há limite de taxa).
+// redisURL segue o formato redis://[usuário:senha@]host:porta/db.
+func PublishRedis(redisURL string, cfg Config, messages []hvac.StreamMessage) error {
+	cfg = cfg.withDefaults()
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return fmt.Errorf("URL do Redis inválida: %w", err)
+	}
+	client := redis.NewClient(options)
+	defer client.Close()
+
+	ctx := context.Background()
+	log.Printf("Publicando %d mensagens no Redis Stream '%s'...", len(messages), cfg.Redis.Stream)
+
+	p := newPacer(cfg.RatePerSecond)
+	pipe := client.Pipeline()
+	for i, message := range messages {
+		p.wait()
+		values := []any{"key", message.Key, "payload", message.Payload}
+		if cfg.RunID != "" {
+			values = append(values, runIDHeader, cfg.RunID)
+		}
+		args := &redis.XAddArgs{
+			Stream: cfg.Redis.Stream,
+			Values: values,
+		}
+		if cfg.Redis.MaxLen > 0 {
+			args.MaxLen, args.Approx = cfg.Redis.MaxLen, true
+		}
+		pipe.XAdd(ctx, args)
+		if p.limited() || pipe.Len() >= cfg.Batch || i == len(messages)-1 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("falha ao publicar no Redis Stream '%s': %w", cfg.Redis.Stream, err)
+			}
+		}
+	}
+
+	log.Printf("%d mensagens publicadas no Redis Stream com sucesso!", len(messages))
+	return nil
+}
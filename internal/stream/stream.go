@@ -0,0 +1,137 @@
+package stream
+
+import (
+	"strings"
+	"time"
+)
+
+// Config reúne as opções comuns dos sinks de streaming e as específicas de cada um.
+type Config struct {
+	RatePerSecond float64      `json:"ratePerSecond"` // Limite de mensagens por segundo (0 = sem limite)
+	Batch         int          `json:"batch"`         // Mensagens por lote no Redis (pipeline) e no NATS (acks aguardados juntos) (padrão: 500)
+	Redis         RedisConfig  `json:"redis"`
+	NATS          NATSConfig   `json:"nats"`
+	Pulsar        PulsarConfig `json:"pulsar"`
+	AMQP          AMQPConfig   `json:"amqp"`
+
+	// RunID identifica a execução do gerador; quando definido, vai em cada mensagem no cabeçalho
+	// run-id (campo run-id no Redis, propriedade run-id no Pulsar). Preenchido pelo gerador, não pelo cenário.
+	RunID string `json:"-"`
+	// Tenant isola os destinos de um tenant: prefixa o stream do Redis (<tenant>:), o stream e o
+	// subject do NATS, o nome do tópico Pulsar (<tenant>-) e o routing key AMQP. Preenchido pelo
+	// gerador, não pelo cenário.
+	Tenant string `json:"-"`
+}
+
+// runIDHeader é o cabeçalho (ou campo, ou propriedade) das mensagens com o ID da execução.
+const runIDHeader = "run-id"
+
+// RedisConfig ajusta o sink de Redis Streams.
+type RedisConfig struct {
+	Stream string `json:"stream"` // Chave do stream (padrão: hvac:a701)
+	MaxLen int64  `json:"maxLen"` // Tamanho máximo aproximado do stream, aparado no XADD (0 = sem limite)
+}
+
+// NATSConfig ajusta o sink de NATS JetStream.
+type NATSConfig struct {
+	Stream  string `json:"stream"`  // Nome do stream JetStream, criado se não existir (padrão: HVAC_A701)
+	Subject string `json:"subject"` // Prefixo do subject; cada mensagem vai para <subject>.<chave> (padrão: hvac.a701)
+}
+
+// PulsarConfig ajusta o sink de Apache Pulsar.
+type PulsarConfig struct {
+	Topic  string `json:"topic"`  // Tópico (padrão: persistent://public/default/hvac-a701)
+	Schema string `json:"schema"` // Esquema registrado: bytes (padrão, mensagens renderizadas), json ou avro (registro achatado)
+}
+
+// AMQPConfig ajusta o sink AMQP 0-9-1 (RabbitMQ).
+type AMQPConfig struct {
+	Exchange     string `json:"exchange"`     // Exchange de destino, declarado se não existir (padrão: hvac)
+	ExchangeType string `json:"exchangeType"` // Tipo do exchange: topic (padrão), direct, fanout ou headers
+	Site         string `json:"site"`         // Site no routing key <site>.<zona>.<dispositivo> (padrão: a701)
+}
+
+// defaultBatch é o tamanho padrão dos lotes do Redis e do NATS.
+const defaultBatch = 500
+
+func (c Config) withDefaults() Config {
+	if c.Batch == 0 {
+		c.Batch = defaultBatch
+	}
+	if c.Redis.Stream == "" {
+		c.Redis.Stream = "hvac:a701"
+	}
+	if c.NATS.Stream == "" {
+		c.NATS.Stream = "HVAC_A701"
+	}
+	if c.NATS.Subject == "" {
+		c.NATS.Subject = "hvac.a701"
+	}
+	if c.Pulsar.Topic == "" {
+		c.Pulsar.Topic = "persistent://public/default/hvac-a701"
+	}
+	if c.Pulsar.Schema == "" {
+		c.Pulsar.Schema = "bytes"
+	}
+	if c.AMQP.Exchange == "" {
+		c.AMQP.Exchange = "hvac"
+	}
+	if c.AMQP.ExchangeType == "" {
+		c.AMQP.ExchangeType = "topic"
+	}
+	if c.AMQP.Site == "" {
+		c.AMQP.Site = "a701"
+	}
+	if c.Tenant != "" {
+		c.Redis.Stream = c.Tenant + ":" + c.Redis.Stream
+		c.NATS.Stream = c.Tenant + "_" + c.NATS.Stream
+		c.NATS.Subject = subjectToken(c.Tenant) + "." + c.NATS.Subject
+		slash := strings.LastIndex(c.Pulsar.Topic, "/")
+		c.Pulsar.Topic = c.Pulsar.Topic[:slash+1] + c.Tenant + "-" + c.Pulsar.Topic[slash+1:]
+	}
+	return c
+}
+
+// pacer espaça as publicações para respeitar o limite de mensagens por segundo.
+type pacer struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newPacer(ratePerSecond float64) *pacer {
+	if ratePerSecond <= 0 {
+		return &pacer{}
+	}
+	return &pacer{interval: time.Duration(float64(time.Second) / ratePerSecond)}
+}
+
+// limited indica se há limite de taxa configurado.
+func (p *pacer) limited() bool {
+	return p.interval > 0
+}
+
+// wait bloqueia até o próximo horário livre para publicar.
+func (p *pacer) wait() {
+	if !p.limited() {
+		return
+	}
+	now := time.Now()
+	if p.next.After(now) {
+		time.Sleep(p.next.Sub(now))
+	} else {
+		p.next = now
+	}
+	p.next = p.next.Add(p.interval)
+}
+
+// subjectToken adapta a chave da mensagem para um token de subject NATS ou de routing key AMQP,
+// que não aceitam pontos, espaços nem curingas.
+func subjectToken(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', ' ', '*', '>', '#', '\t':
+			return '_'
+		}
+		return r
+	}, key)
+}